@@ -0,0 +1,261 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"backend/handlers/account"
+	"backend/handlers/common"
+	"backend/middleware"
+	"backend/models"
+	"backend/tenant"
+)
+
+// seedAccount stores student 1, "Ada Lovelace", with a quest completion, a coin award, a
+// vote, a research post and mentee records, next to student 2's own completion and vote.
+func seedAccount(t *testing.T, ctx context.Context) {
+	t.Helper()
+	hash, err := middleware.HashPassword("correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := time.Date(2026, time.March, 2, 9, 0, 0, 0, time.UTC)
+	inserts := []struct {
+		col *tenant.Collection
+		doc interface{}
+	}{
+		{common.UsersCol, models.User{UserID: 1, Name: "Ada Lovelace", Email: "ada@example.edu", Role: common.RoleStudent, PasswordHash: hash}},
+		{common.UsersCol, models.User{UserID: 2, Name: "Grace Hopper", Role: common.RoleStudent}},
+		{common.QuestsCol, models.Quest{QuestID: 1, Title: "First steps"}},
+		{common.UserQuestsCol, bson.M{"user_id": 1, "quest_id": 1, "completed": true, "completed_at": at}},
+		{common.UserQuestsCol, bson.M{"user_id": 2, "quest_id": 1, "completed": true, "completed_at": at}},
+		{common.CoinLedgerCol, bson.M{"user_id": 1, "coins": 50, "reason": "quest", "quest_id": 1, "at": at}},
+		{common.PollsCol, models.Poll{PollID: 1, Question: "Tabs or spaces?", Options: []models.PollOption{{Text: "Tabs", Votes: 2}, {Text: "Spaces"}}}},
+		{common.VotesCol, bson.M{"user_id": 1, "poll_id": 1, "option_index": 0, "voted_at": at}},
+		{common.VotesCol, bson.M{"user_id": 2, "poll_id": 1, "option_index": 0, "voted_at": at}},
+		{common.ResearchPostsCol, models.ResearchPost{AuthorID: 1, AuthorName: "Ada Lovelace", Title: "Engines", CreatedAt: at}},
+		{common.SnapshotsCol, bson.M{"window": "weekly", "entries": bson.A{bson.M{"user_id": 1, "name": "Ada Lovelace", "rank": 1}, bson.M{"user_id": 2, "name": "Grace Hopper", "rank": 2}}}},
+		{common.FacultyDashboardsCol, bson.M{"faculty_id": 9, "mentorship": bson.M{"mentees": bson.A{
+			bson.M{"_id": objectID(t, "000000000000000000000001"), "student_id": 1, "name": "Ada", "status": "active", "note": "Loves engines", "created_at": at, "updated_at": at},
+			bson.M{"_id": objectID(t, "000000000000000000000002"), "name": "ada lovelace", "status": "alumni", "created_at": at, "updated_at": at},
+			bson.M{"_id": objectID(t, "000000000000000000000003"), "student_id": 2, "name": "Grace", "status": "active", "note": "Compilers", "created_at": at, "updated_at": at},
+		}}}},
+	}
+	for _, in := range inserts {
+		if _, err := in.col.InsertOne(ctx, in.doc); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func objectID(t *testing.T, hex string) primitive.ObjectID {
+	t.Helper()
+	id, err := primitive.ObjectIDFromHex(hex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+func TestAccountExport(t *testing.T) {
+	h := newTestServer(t)
+	ctx := tenant.WithID(context.Background(), testTenantA)
+	seedAccount(t, ctx)
+
+	w := serve(h, http.MethodGet, "/api/v2/me/export", "", testToken(t, testTenantA, 1, common.RoleStudent))
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/zip" {
+		t.Fatalf("status = %d, Content-Type %q: %s", w.Code, w.Header().Get("Content-Type"), w.Body)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(w.Body.Bytes()), int64(w.Body.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string][]byte{}
+	for _, f := range zr.File {
+		r, _ := f.Open()
+		files[f.Name], _ = io.ReadAll(r)
+		r.Close()
+	}
+	read := func(name string, v interface{}) {
+		t.Helper()
+		if err := json.Unmarshal(files[name], v); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+	}
+
+	var manifest struct {
+		UserID int      `json:"userId"`
+		Files  []string `json:"files"`
+	}
+	read("manifest.json", &manifest)
+	if manifest.UserID != 1 || len(manifest.Files) != len(files)-1 {
+		t.Errorf("manifest = %+v for %d files", manifest, len(files))
+	}
+	var profile struct {
+		Email string `json:"email"`
+	}
+	read("profile.json", &profile)
+	if profile.Email != "ada@example.edu" || bytes.Contains(files["profile.json"], []byte("password")) {
+		t.Errorf("profile.json = %s", files["profile.json"])
+	}
+	var completions []struct {
+		QuestID int    `json:"questId"`
+		Title   string `json:"title"`
+	}
+	read("quest_completions.json", &completions)
+	if len(completions) != 1 || completions[0].Title != "First steps" {
+		t.Errorf("quest_completions.json = %s", files["quest_completions.json"])
+	}
+	var votes []struct {
+		Question string `json:"question"`
+		Option   string `json:"option"`
+	}
+	read("votes.json", &votes)
+	if len(votes) != 1 || votes[0].Question != "Tabs or spaces?" || votes[0].Option != "Tabs" {
+		t.Errorf("votes.json = %s", files["votes.json"])
+	}
+	var coins, posts []json.RawMessage
+	read("coin_history.json", &coins)
+	read("research_posts.json", &posts)
+	if len(coins) != 1 || len(posts) != 1 {
+		t.Errorf("coin history %d entries, research posts %d, want 1 each", len(coins), len(posts))
+	}
+	var mentees []struct {
+		RecordID string `json:"recordId"`
+		Note     string `json:"note"`
+	}
+	read("mentee_records.json", &mentees)
+	if len(mentees) != 2 || mentees[0].Note != "Loves engines" || mentees[1].RecordID != "000000000000000000000002" {
+		t.Errorf("mentee_records.json = %s", files["mentee_records.json"])
+	}
+	if _, ok := files["faculty_dashboard.json"]; ok {
+		t.Error("a student's export includes a faculty dashboard")
+	}
+}
+
+func TestAccountDeletionGracePeriod(t *testing.T) {
+	h := newTestServer(t)
+	ctx := tenant.WithID(context.Background(), testTenantA)
+	seedAccount(t, ctx)
+	token := testToken(t, testTenantA, 1, common.RoleStudent)
+
+	if w := serveJSON(h, http.MethodPost, "/api/v2/me/deletion", "", token, `{"password":"wrong"}`); w.Code != http.StatusForbidden {
+		t.Fatalf("wrong password: status = %d: %s", w.Code, w.Body)
+	}
+	before := time.Now()
+	w := serveJSON(h, http.MethodPost, "/api/v2/me/deletion", "", token, `{"password":"correct horse"}`)
+	var status models.AccountDeletionResponse
+	if w.Code != http.StatusAccepted || json.Unmarshal(w.Body.Bytes(), &status) != nil {
+		t.Fatalf("request: status = %d: %s", w.Code, w.Body)
+	}
+	if status.Status != "scheduled" || status.ScheduledFor == nil || status.ScheduledFor.Before(before.Add(common.DeletionGrace)) || status.ScheduledFor.After(time.Now().Add(common.DeletionGrace)) {
+		t.Fatalf("request = %+v, want deletion scheduled one grace period from now", status)
+	}
+	var again models.AccountDeletionResponse
+	w = serveJSON(h, http.MethodPost, "/api/v2/me/deletion", "", token, `{"password":"correct horse"}`)
+	if json.Unmarshal(w.Body.Bytes(), &again) != nil || !again.ScheduledFor.Equal(*status.ScheduledFor) {
+		t.Errorf("repeated request = %s, want the original schedule kept", w.Body)
+	}
+
+	// Still inside the grace period: the purge leaves the account alone.
+	if err := account.PurgeDeletedAccounts(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := common.UsersCol.CountDocuments(ctx, bson.M{"user_id": 1}); n != 1 {
+		t.Fatal("account purged during its grace period")
+	}
+
+	if w := serve(h, http.MethodDelete, "/api/v2/me/deletion", "", token); w.Code != http.StatusOK {
+		t.Fatalf("cancel: status = %d: %s", w.Code, w.Body)
+	}
+	w = serve(h, http.MethodGet, "/api/v2/me/deletion", "", token)
+	if w.Code != http.StatusOK || json.Unmarshal(w.Body.Bytes(), &status) != nil || status.Status != "none" {
+		t.Errorf("after cancel: status = %d: %s", w.Code, w.Body)
+	}
+}
+
+func TestPurgeDeletedAccounts(t *testing.T) {
+	newTestServer(t)
+	ctx := tenant.WithID(context.Background(), testTenantA)
+	seedAccount(t, ctx)
+	past := time.Now().Add(-time.Minute)
+	if _, err := common.UsersCol.UpdateOne(ctx, bson.M{"user_id": 1}, bson.M{"$set": bson.M{"deletion_requested_at": past.Add(-common.DeletionGrace), "deletion_scheduled_for": past}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := account.PurgeDeletedAccounts(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	count := func(col *tenant.Collection, filter bson.M) int64 {
+		t.Helper()
+		n, err := col.CountDocuments(ctx, filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return n
+	}
+	if count(common.UsersCol, bson.M{"user_id": 1}) != 0 || count(common.UsersCol, bson.M{"user_id": 2}) != 1 {
+		t.Error("purge did not delete exactly the due account")
+	}
+	for name, col := range map[string]*tenant.Collection{"completions": common.UserQuestsCol, "votes": common.VotesCol, "coin history": common.CoinLedgerCol} {
+		if n := count(col, bson.M{"user_id": 1}); n != 0 {
+			t.Errorf("%d %s left for the deleted user", n, name)
+		}
+	}
+	if count(common.UserQuestsCol, bson.M{"user_id": 2}) != 1 || count(common.VotesCol, bson.M{"user_id": 2}) != 1 {
+		t.Error("purge touched another user's completion or vote")
+	}
+
+	var poll models.Poll
+	if err := common.PollsCol.FindOne(ctx, bson.M{"poll_id": 1}).Decode(&poll); err != nil || poll.Options[0].Votes != 1 {
+		t.Errorf("poll tally = %+v, %v; want the deleted user's vote taken off", poll.Options, err)
+	}
+	var post models.ResearchPost
+	if err := common.ResearchPostsCol.FindOne(ctx, bson.M{"title": "Engines"}).Decode(&post); err != nil || post.AuthorID != 0 || post.AuthorName != "Deleted user" {
+		t.Errorf("research post = %+v, %v; want it kept under a placeholder author", post, err)
+	}
+	var snapshot struct {
+		Entries []struct {
+			UserID int    `bson:"user_id"`
+			Name   string `bson:"name"`
+			Rank   int    `bson:"rank"`
+		} `bson:"entries"`
+	}
+	if err := common.SnapshotsCol.FindOne(ctx, bson.M{}).Decode(&snapshot); err != nil {
+		t.Fatal(err)
+	}
+	if e := snapshot.Entries; e[0].UserID != 0 || e[0].Name != "Deleted user" || e[0].Rank != 1 || e[1].UserID != 2 {
+		t.Errorf("snapshot entries = %+v", e)
+	}
+
+	var dashboard struct {
+		Mentorship struct {
+			Mentees []bson.M `bson:"mentees"`
+		} `bson:"mentorship"`
+	}
+	if err := common.FacultyDashboardsCol.FindOne(ctx, bson.M{"faculty_id": 9}).Decode(&dashboard); err != nil {
+		t.Fatal(err)
+	}
+	m := dashboard.Mentorship.Mentees
+	for _, i := range []int{0, 1} {
+		if m[i]["name"] != "Former student" || m[i]["student_id"] != nil || m[i]["note"] != nil {
+			t.Errorf("mentee record %d = %v, want it anonymized", i, m[i])
+		}
+	}
+	if m[0]["status"] != "active" || m[1]["status"] != "alumni" {
+		t.Errorf("mentee statuses = %v, %v; want them kept", m[0]["status"], m[1]["status"])
+	}
+	if m[2]["name"] != "Grace" || m[2]["note"] != "Compilers" {
+		t.Errorf("another student's mentee record changed: %v", m[2])
+	}
+}
@@ -0,0 +1,185 @@
+// Package answers checks the answers students submit for quests. A quest stores a Spec
+// naming a checker kind and its solution; Check runs the registered Checker for that kind.
+// Results say whether the answer is right, and for multi-part answers which parts are, but
+// never carry the solution, so they are safe to return to the student.
+package answers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Built-in checker kinds.
+const (
+	KindText      = "text"
+	KindRegex     = "regex"
+	KindNumeric   = "numeric"
+	KindChoice    = "choice"
+	KindMultipart = "multipart"
+)
+
+// Limits on what a Spec may hold.
+const (
+	MaxAnswers = 20
+	MaxOptions = 10
+	MaxParts   = 10
+	MaxPattern = 500
+	MaxText    = 1000
+)
+
+// Spec is a quest's solution. Only the fields of its Kind are used.
+type Spec struct {
+	Kind string `json:"kind" bson:"kind"`
+	// Answers are the accepted texts of a text check, compared after trimming spaces.
+	Answers       []string `json:"answers,omitempty" bson:"answers,omitempty"`
+	CaseSensitive bool     `json:"caseSensitive,omitempty" bson:"case_sensitive,omitempty"`
+	// Pattern is a regex check's RE2 expression; it must match the whole answer.
+	Pattern string `json:"pattern,omitempty" bson:"pattern,omitempty"`
+	// Value and Tolerance define a numeric check: answers within Tolerance of Value pass.
+	Value     float64 `json:"value,omitempty" bson:"value,omitempty"`
+	Tolerance float64 `json:"tolerance,omitempty" bson:"tolerance,omitempty"`
+	// Options are shown to students; Correct holds the indexes that must all be chosen.
+	Options []string `json:"options,omitempty" bson:"options,omitempty"`
+	Correct []int    `json:"correct,omitempty" bson:"correct,omitempty"`
+	// Parts are the questions of a multipart check, each answered separately.
+	Parts []Part `json:"parts,omitempty" bson:"parts,omitempty"`
+	// Feedback is shown with an incorrect answer, for example a pointer to the material.
+	Feedback string `json:"feedback,omitempty" bson:"feedback,omitempty"`
+}
+
+// Part is one question of a multipart check.
+type Part struct {
+	Label string `json:"label" bson:"label"`
+	Spec  `bson:",inline"`
+}
+
+// Submission is a student's answer: Text for text, regex and numeric checks, Choices for a
+// choice check, and one Submission per part, in order, for a multipart check.
+type Submission struct {
+	Text    string       `json:"text,omitempty" bson:"text,omitempty"`
+	Choices []int        `json:"choices,omitempty" bson:"choices,omitempty"`
+	Parts   []Submission `json:"parts,omitempty" bson:"parts,omitempty"`
+}
+
+// Result is the outcome of a check. Parts is set for multipart checks.
+type Result struct {
+	Correct bool   `json:"correct"`
+	Parts   []bool `json:"parts,omitempty"`
+}
+
+// Prompt is what students see of a Spec: the kind, and the options and part labels they
+// answer, without the solution.
+type Prompt struct {
+	Kind    string   `json:"kind"`
+	Label   string   `json:"label,omitempty"`
+	Options []string `json:"options,omitempty"`
+	Parts   []Prompt `json:"parts,omitempty"`
+}
+
+// Error rejects a Spec or a Submission. Field is a JSON path relative to the value checked.
+type Error struct {
+	Field   string
+	Message string
+}
+
+func (e *Error) Error() string {
+	if e.Field == "" {
+		return e.Message
+	}
+	return e.Field + ": " + e.Message
+}
+
+// Checker implements one kind of check.
+type Checker interface {
+	// Validate rejects a Spec that cannot be checked, such as a regex that does not compile.
+	Validate(s Spec) error
+	// Check compares sub with the solution in s. It returns an *Error when sub has the
+	// wrong shape, such as a choice that does not exist; a wrong answer is a Result.
+	Check(s Spec, sub Submission) (Result, error)
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Checker{}
+)
+
+// Register makes c available for Specs of kind, replacing any checker of that kind.
+func Register(kind string, c Checker) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[kind] = c
+}
+
+// Kinds lists the registered kinds in order.
+func Kinds() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return kindsLocked()
+}
+
+func lookup(kind string) (Checker, error) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	c, ok := registry[kind]
+	if !ok {
+		return nil, &Error{Field: "kind", Message: "must be one of: " + strings.Join(kindsLocked(), ", ")}
+	}
+	return c, nil
+}
+
+func kindsLocked() []string {
+	kinds := make([]string, 0, len(registry))
+	for k := range registry {
+		kinds = append(kinds, k)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+// Validate reports whether s can be checked.
+func Validate(s Spec) error {
+	c, err := lookup(s.Kind)
+	if err != nil {
+		return err
+	}
+	if len(s.Feedback) > MaxText {
+		return &Error{Field: "feedback", Message: fmt.Sprintf("must be at most %d characters", MaxText)}
+	}
+	return c.Validate(s)
+}
+
+// Check compares sub with the solution in s.
+func Check(s Spec, sub Submission) (Result, error) {
+	c, err := lookup(s.Kind)
+	if err != nil {
+		return Result{}, err
+	}
+	return c.Check(s, sub)
+}
+
+// PromptFor returns what students see of s, or nil for a nil Spec.
+func PromptFor(s *Spec) *Prompt {
+	if s == nil {
+		return nil
+	}
+	p := Prompt{Kind: s.Kind, Options: s.Options}
+	for _, part := range s.Parts {
+		sub := PromptFor(&part.Spec)
+		sub.Label = part.Label
+		p.Parts = append(p.Parts, *sub)
+	}
+	return &p
+}
+
+// prefix nests an *Error from a part under field.
+func prefix(field string, err error) error {
+	if e, ok := err.(*Error); ok {
+		if e.Field == "" {
+			return &Error{Field: field, Message: e.Message}
+		}
+		return &Error{Field: field + "." + e.Field, Message: e.Message}
+	}
+	return err
+}
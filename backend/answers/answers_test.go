@@ -0,0 +1,149 @@
+package answers
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	text := Spec{Kind: KindText, Answers: []string{"Alan Turing", " Turing "}}
+	regex := Spec{Kind: KindRegex, Pattern: `O\(n( log n)?\)`}
+	numeric := Spec{Kind: KindNumeric, Value: 3.14, Tolerance: 0.01}
+	choice := Spec{Kind: KindChoice, Options: []string{"TCP", "UDP", "HTTP"}, Correct: []int{0, 2}}
+	tests := []struct {
+		name string
+		spec Spec
+		sub  Submission
+		want bool
+	}{
+		{"text exact", text, Submission{Text: "Alan Turing"}, true},
+		{"text trims and ignores case", text, Submission{Text: "  turing "}, true},
+		{"text wrong", text, Submission{Text: "Ada Lovelace"}, false},
+		{"text case sensitive", Spec{Kind: KindText, Answers: []string{"Go"}, CaseSensitive: true}, Submission{Text: "go"}, false},
+		{"regex full match", regex, Submission{Text: "o(n log n)"}, true},
+		{"regex partial match", regex, Submission{Text: "O(n) time"}, false},
+		{"regex case sensitive", Spec{Kind: KindRegex, Pattern: "Go", CaseSensitive: true}, Submission{Text: "GO"}, false},
+		{"numeric within tolerance", numeric, Submission{Text: "3.145"}, true},
+		{"numeric outside tolerance", numeric, Submission{Text: "3.2"}, false},
+		{"numeric rounding", Spec{Kind: KindNumeric, Value: 0.3}, Submission{Text: "0.30000000000000004"}, true},
+		{"choice exact set", choice, Submission{Choices: []int{2, 0}}, true},
+		{"choice subset", choice, Submission{Choices: []int{0}}, false},
+		{"choice superset", choice, Submission{Choices: []int{0, 1, 2}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := Check(tt.spec, tt.sub)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Correct != tt.want {
+				t.Errorf("Correct = %v, want %v", res.Correct, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckMultipart(t *testing.T) {
+	spec := Spec{Kind: KindMultipart, Parts: []Part{
+		{Label: "Year", Spec: Spec{Kind: KindNumeric, Value: 1936}},
+		{Label: "Machine", Spec: Spec{Kind: KindText, Answers: []string{"Turing machine"}}},
+	}}
+	res, err := Check(spec, Submission{Parts: []Submission{{Text: "1936"}, {Text: "finite automaton"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (Result{Correct: false, Parts: []bool{true, false}}); !reflect.DeepEqual(res, want) {
+		t.Errorf("result = %+v, want %+v", res, want)
+	}
+	res, err = Check(spec, Submission{Parts: []Submission{{Text: "1936"}, {Text: "turing machine"}}})
+	if err != nil || !res.Correct {
+		t.Errorf("all parts right: %+v, %v", res, err)
+	}
+}
+
+func TestCheckRejectsMalformedSubmissions(t *testing.T) {
+	multipart := Spec{Kind: KindMultipart, Parts: []Part{{Label: "Year", Spec: Spec{Kind: KindNumeric, Value: 1936}}}}
+	tests := []struct {
+		name  string
+		spec  Spec
+		sub   Submission
+		field string
+	}{
+		{"blank text", Spec{Kind: KindText, Answers: []string{"a"}}, Submission{Text: "  "}, "text"},
+		{"text too long", Spec{Kind: KindText, Answers: []string{"a"}}, Submission{Text: strings.Repeat("a", MaxText+1)}, "text"},
+		{"not a number", Spec{Kind: KindNumeric}, Submission{Text: "pi"}, "text"},
+		{"infinite number", Spec{Kind: KindNumeric}, Submission{Text: "Inf"}, "text"},
+		{"no choices", Spec{Kind: KindChoice, Options: []string{"a", "b"}, Correct: []int{0}}, Submission{}, "choices"},
+		{"choice out of range", Spec{Kind: KindChoice, Options: []string{"a", "b"}, Correct: []int{0}}, Submission{Choices: []int{2}}, "choices[0]"},
+		{"choice repeated", Spec{Kind: KindChoice, Options: []string{"a", "b"}, Correct: []int{0}}, Submission{Choices: []int{1, 1}}, "choices[1]"},
+		{"missing parts", multipart, Submission{}, "parts"},
+		{"bad part", multipart, Submission{Parts: []Submission{{Text: "soon"}}}, "parts[0].text"},
+		{"unknown kind", Spec{Kind: "essay"}, Submission{Text: "a"}, "kind"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Check(tt.spec, tt.sub)
+			var e *Error
+			if !errors.As(err, &e) || e.Field != tt.field {
+				t.Errorf("err = %v, want an *Error on %q", err, tt.field)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		spec  Spec
+		field string
+	}{
+		{"text", Spec{Kind: KindText, Answers: []string{"a"}}, ""},
+		{"text without answers", Spec{Kind: KindText}, "answers"},
+		{"blank answer", Spec{Kind: KindText, Answers: []string{"a", " "}}, "answers[1]"},
+		{"regex", Spec{Kind: KindRegex, Pattern: "a+"}, ""},
+		{"invalid regex", Spec{Kind: KindRegex, Pattern: "a("}, "pattern"},
+		{"regex escaping the anchors", Spec{Kind: KindRegex, Pattern: "a)|(b"}, "pattern"},
+		{"negative tolerance", Spec{Kind: KindNumeric, Tolerance: -1}, "tolerance"},
+		{"one option", Spec{Kind: KindChoice, Options: []string{"a"}, Correct: []int{0}}, "options"},
+		{"no correct option", Spec{Kind: KindChoice, Options: []string{"a", "b"}}, "correct"},
+		{"correct out of range", Spec{Kind: KindChoice, Options: []string{"a", "b"}, Correct: []int{5}}, "correct[0]"},
+		{"nested multipart", Spec{Kind: KindMultipart, Parts: []Part{{Label: "x", Spec: Spec{Kind: KindMultipart}}}}, "parts[0].kind"},
+		{"unlabelled part", Spec{Kind: KindMultipart, Parts: []Part{{Spec: Spec{Kind: KindNumeric}}}}, "parts[0].label"},
+		{"invalid part", Spec{Kind: KindMultipart, Parts: []Part{{Label: "x", Spec: Spec{Kind: KindText}}}}, "parts[0].answers"},
+		{"long feedback", Spec{Kind: KindNumeric, Feedback: strings.Repeat("a", MaxText+1)}, "feedback"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.spec)
+			if tt.field == "" {
+				if err != nil {
+					t.Errorf("Validate = %v, want nil", err)
+				}
+				return
+			}
+			var e *Error
+			if !errors.As(err, &e) || e.Field != tt.field {
+				t.Errorf("err = %v, want an *Error on %q", err, tt.field)
+			}
+		})
+	}
+}
+
+func TestPromptHidesSolution(t *testing.T) {
+	spec := &Spec{Kind: KindMultipart, Feedback: "See chapter 2", Parts: []Part{
+		{Label: "Protocol", Spec: Spec{Kind: KindChoice, Options: []string{"TCP", "UDP"}, Correct: []int{0}}},
+		{Label: "Port", Spec: Spec{Kind: KindNumeric, Value: 443}},
+	}}
+	want := &Prompt{Kind: KindMultipart, Parts: []Prompt{
+		{Kind: KindChoice, Label: "Protocol", Options: []string{"TCP", "UDP"}},
+		{Kind: KindNumeric, Label: "Port"},
+	}}
+	if got := PromptFor(spec); !reflect.DeepEqual(got, want) {
+		t.Errorf("PromptFor = %+v, want %+v", got, want)
+	}
+	if PromptFor(nil) != nil {
+		t.Error("PromptFor(nil) is not nil")
+	}
+}
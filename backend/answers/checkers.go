@@ -0,0 +1,215 @@
+package answers
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	Register(KindText, textChecker{})
+	Register(KindRegex, regexChecker{})
+	Register(KindNumeric, numericChecker{})
+	Register(KindChoice, choiceChecker{})
+	Register(KindMultipart, multipartChecker{})
+}
+
+func checkText(sub Submission) (string, error) {
+	text := strings.TrimSpace(sub.Text)
+	if text == "" {
+		return "", &Error{Field: "text", Message: "is required"}
+	}
+	if len(text) > MaxText {
+		return "", &Error{Field: "text", Message: fmt.Sprintf("must be at most %d characters", MaxText)}
+	}
+	return text, nil
+}
+
+// textChecker accepts any of Answers, ignoring case unless CaseSensitive is set.
+type textChecker struct{}
+
+func (textChecker) Validate(s Spec) error {
+	if len(s.Answers) == 0 || len(s.Answers) > MaxAnswers {
+		return &Error{Field: "answers", Message: fmt.Sprintf("must contain 1 to %d answers", MaxAnswers)}
+	}
+	for i, a := range s.Answers {
+		if strings.TrimSpace(a) == "" {
+			return &Error{Field: fmt.Sprintf("answers[%d]", i), Message: "must not be blank"}
+		}
+	}
+	return nil
+}
+
+func (textChecker) Check(s Spec, sub Submission) (Result, error) {
+	text, err := checkText(sub)
+	if err != nil {
+		return Result{}, err
+	}
+	for _, a := range s.Answers {
+		a = strings.TrimSpace(a)
+		if a == text || (!s.CaseSensitive && strings.EqualFold(a, text)) {
+			return Result{Correct: true}, nil
+		}
+	}
+	return Result{}, nil
+}
+
+// regexChecker accepts answers Pattern matches in full. RE2 runs in linear time, so an
+// author's pattern cannot stall the server.
+type regexChecker struct{}
+
+func compilePattern(s Spec) (*regexp.Regexp, error) {
+	flags := ""
+	if !s.CaseSensitive {
+		flags = "(?i)"
+	}
+	// Compiling the pattern on its own first rejects one like "a)|(b" that would escape
+	// the anchors below.
+	if _, err := regexp.Compile(s.Pattern); err != nil {
+		return nil, err
+	}
+	return regexp.Compile(flags + `^(?:` + s.Pattern + `)$`)
+}
+
+func (regexChecker) Validate(s Spec) error {
+	if strings.TrimSpace(s.Pattern) == "" || len(s.Pattern) > MaxPattern {
+		return &Error{Field: "pattern", Message: fmt.Sprintf("must be 1 to %d characters", MaxPattern)}
+	}
+	if _, err := compilePattern(s); err != nil {
+		return &Error{Field: "pattern", Message: "must be a valid regular expression"}
+	}
+	return nil
+}
+
+func (regexChecker) Check(s Spec, sub Submission) (Result, error) {
+	text, err := checkText(sub)
+	if err != nil {
+		return Result{}, err
+	}
+	re, err := compilePattern(s)
+	if err != nil {
+		return Result{}, err
+	}
+	return Result{Correct: re.MatchString(text)}, nil
+}
+
+// numericChecker accepts numbers within Tolerance of Value.
+type numericChecker struct{}
+
+func (numericChecker) Validate(s Spec) error {
+	if math.IsNaN(s.Value) || math.IsInf(s.Value, 0) {
+		return &Error{Field: "value", Message: "must be a finite number"}
+	}
+	if s.Tolerance < 0 || math.IsNaN(s.Tolerance) || math.IsInf(s.Tolerance, 0) {
+		return &Error{Field: "tolerance", Message: "must be a finite number of at least 0"}
+	}
+	return nil
+}
+
+func (numericChecker) Check(s Spec, sub Submission) (Result, error) {
+	text, err := checkText(sub)
+	if err != nil {
+		return Result{}, err
+	}
+	v, err := strconv.ParseFloat(text, 64)
+	if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+		return Result{}, &Error{Field: "text", Message: "must be a number"}
+	}
+	// A small epsilon keeps answers such as 0.3 for 0.1+0.2 from failing on rounding.
+	return Result{Correct: math.Abs(v-s.Value) <= s.Tolerance+1e-9*math.Max(1, math.Abs(s.Value))}, nil
+}
+
+// choiceChecker accepts exactly the set of Correct options.
+type choiceChecker struct{}
+
+func (choiceChecker) Validate(s Spec) error {
+	if len(s.Options) < 2 || len(s.Options) > MaxOptions {
+		return &Error{Field: "options", Message: fmt.Sprintf("must contain 2 to %d options", MaxOptions)}
+	}
+	for i, o := range s.Options {
+		if strings.TrimSpace(o) == "" {
+			return &Error{Field: fmt.Sprintf("options[%d]", i), Message: "must not be blank"}
+		}
+	}
+	if len(s.Correct) == 0 {
+		return &Error{Field: "correct", Message: "must name at least one option"}
+	}
+	if _, err := choiceSet(s.Correct, len(s.Options), "correct"); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (choiceChecker) Check(s Spec, sub Submission) (Result, error) {
+	if len(sub.Choices) == 0 {
+		return Result{}, &Error{Field: "choices", Message: "is required"}
+	}
+	chosen, err := choiceSet(sub.Choices, len(s.Options), "choices")
+	if err != nil {
+		return Result{}, err
+	}
+	correct, _ := choiceSet(s.Correct, len(s.Options), "correct")
+	if len(chosen) != len(correct) {
+		return Result{}, nil
+	}
+	for i := range correct {
+		if !chosen[i] {
+			return Result{}, nil
+		}
+	}
+	return Result{Correct: true}, nil
+}
+
+func choiceSet(indexes []int, n int, field string) (map[int]bool, error) {
+	set := make(map[int]bool, len(indexes))
+	for i, idx := range indexes {
+		if idx < 0 || idx >= n {
+			return nil, &Error{Field: fmt.Sprintf("%s[%d]", field, i), Message: fmt.Sprintf("must be less than %d", n)}
+		}
+		if set[idx] {
+			return nil, &Error{Field: fmt.Sprintf("%s[%d]", field, i), Message: "must not repeat an option"}
+		}
+		set[idx] = true
+	}
+	return set, nil
+}
+
+// multipartChecker runs one check per part; the answer is correct when every part is.
+type multipartChecker struct{}
+
+func (multipartChecker) Validate(s Spec) error {
+	if len(s.Parts) == 0 || len(s.Parts) > MaxParts {
+		return &Error{Field: "parts", Message: fmt.Sprintf("must contain 1 to %d parts", MaxParts)}
+	}
+	for i, p := range s.Parts {
+		field := fmt.Sprintf("parts[%d]", i)
+		if strings.TrimSpace(p.Label) == "" {
+			return &Error{Field: field + ".label", Message: "is required"}
+		}
+		if p.Kind == KindMultipart {
+			return &Error{Field: field + ".kind", Message: "must not be multipart"}
+		}
+		if err := Validate(p.Spec); err != nil {
+			return prefix(field, err)
+		}
+	}
+	return nil
+}
+
+func (multipartChecker) Check(s Spec, sub Submission) (Result, error) {
+	if len(sub.Parts) != len(s.Parts) {
+		return Result{}, &Error{Field: "parts", Message: fmt.Sprintf("must contain %d answers", len(s.Parts))}
+	}
+	res := Result{Correct: true, Parts: make([]bool, len(s.Parts))}
+	for i, p := range s.Parts {
+		r, err := Check(p.Spec, sub.Parts[i])
+		if err != nil {
+			return Result{}, prefix(fmt.Sprintf("parts[%d]", i), err)
+		}
+		res.Parts[i] = r.Correct
+		res.Correct = res.Correct && r.Correct
+	}
+	return res, nil
+}
@@ -0,0 +1,258 @@
+package apiversion
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// Version describes one mounted API generation. A non-zero Deprecated time marks the version
+// as deprecated; responses then carry Deprecation, Sunset and successor Link headers.
+type Version struct {
+	Name        string
+	Prefix      string
+	Deprecated  time.Time
+	Sunset      time.Time
+	Successor   string
+	UnwrapLists bool
+}
+
+func (v Version) IsDeprecated() bool { return !v.Deprecated.IsZero() }
+
+// Middleware stamps version headers on every response and records usage for the version.
+func (v Version) Middleware() mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			h := w.Header()
+			h.Set("API-Version", v.Name)
+			if v.IsDeprecated() {
+				h.Set("Deprecation", "@"+strconv.FormatInt(v.Deprecated.Unix(), 10))
+				if !v.Sunset.IsZero() {
+					h.Set("Sunset", v.Sunset.UTC().Format(http.TimeFormat))
+				}
+				if v.Successor != "" {
+					h.Add("Link", fmt.Sprintf("<%s>; rel=\"successor-version\"", v.Successor))
+				}
+			}
+			route := r.URL.Path
+			if current := mux.CurrentRoute(r); current != nil {
+				if tmpl, err := current.GetPathTemplate(); err == nil {
+					route = tmpl
+				}
+			}
+			record(v.Name, r.Method+" "+route)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// --- response adapters ---
+
+// Adapter rewrites a successful JSON response body produced by the current handler into the
+// shape an older version promised. It may also adjust the response headers.
+type Adapter func(header http.Header, body []byte) ([]byte, error)
+
+// Adapt buffers the handler's response and passes 2xx JSON bodies through adapter. Errors and
+// non-JSON responses are forwarded unchanged.
+func Adapt(handler http.HandlerFunc, adapter Adapter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// Start from the headers set so far, so the handler sees e.g. Content-Language.
+		rec := &bufferedWriter{header: w.Header().Clone(), status: http.StatusOK}
+		handler(rec, r)
+		for k, vals := range rec.header {
+			w.Header()[k] = vals
+		}
+		body := rec.buf.Bytes()
+		if rec.status >= 200 && rec.status < 300 && len(body) > 0 {
+			if adapted, err := adapter(w.Header(), body); err == nil {
+				body = adapted
+				w.Header().Del("Content-Length")
+			}
+		}
+		w.WriteHeader(rec.status)
+		_, _ = w.Write(body)
+	}
+}
+
+// UnwrapItems turns the {"items": [...]} list envelope into the bare array returned by v1.
+// Page cursors from the envelope are surfaced as X-Next-Cursor / X-Prev-Cursor headers.
+func UnwrapItems(header http.Header, body []byte) ([]byte, error) {
+	var envelope struct {
+		Items json.RawMessage `json:"items"`
+		Next  string          `json:"next"`
+		Prev  string          `json:"prev"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return nil, err
+	}
+	if envelope.Next != "" {
+		header.Set("X-Next-Cursor", envelope.Next)
+	}
+	if envelope.Prev != "" {
+		header.Set("X-Prev-Cursor", envelope.Prev)
+	}
+	if len(envelope.Items) == 0 || bytes.Equal(envelope.Items, []byte("null")) {
+		return []byte("[]\n"), nil
+	}
+	return append(envelope.Items, '\n'), nil
+}
+
+type unwrappedKey struct{}
+
+// UnwrapList adapts a list handler to the bare arrays of older versions with UnwrapItems.
+// Those versions returned whole lists, so ListsUnwrapped tells the handler not to page by
+// default.
+func UnwrapList(handler http.HandlerFunc) http.HandlerFunc {
+	adapted := Adapt(handler, UnwrapItems)
+	return func(w http.ResponseWriter, r *http.Request) {
+		adapted(w, r.WithContext(context.WithValue(r.Context(), unwrappedKey{}, true)))
+	}
+}
+
+// ListsUnwrapped reports whether the list being served is sent as a bare array.
+func ListsUnwrapped(ctx context.Context) bool {
+	unwrapped, _ := ctx.Value(unwrappedKey{}).(bool)
+	return unwrapped
+}
+
+type bufferedWriter struct {
+	header http.Header
+	status int
+	buf    bytes.Buffer
+	wrote  bool
+}
+
+func (b *bufferedWriter) Header() http.Header { return b.header }
+
+func (b *bufferedWriter) WriteHeader(status int) {
+	if b.wrote {
+		return
+	}
+	b.status = status
+	b.wrote = true
+}
+
+func (b *bufferedWriter) Write(p []byte) (int, error) {
+	b.wrote = true
+	return b.buf.Write(p)
+}
+
+// --- usage metrics ---
+
+// RouteUsage counts requests served for one route of one version.
+type RouteUsage struct {
+	Route    string    `json:"route"`
+	Requests int64     `json:"requests"`
+	LastSeen time.Time `json:"lastSeen"`
+}
+
+// VersionUsage aggregates request counts for a version since process start.
+type VersionUsage struct {
+	Version    string       `json:"version"`
+	Deprecated bool         `json:"deprecated"`
+	Sunset     *time.Time   `json:"sunset,omitempty"`
+	Requests   int64        `json:"requests"`
+	LastSeen   time.Time    `json:"lastSeen"`
+	Routes     []RouteUsage `json:"routes"`
+}
+
+var (
+	usageMu  sync.Mutex
+	usage    = map[string]map[string]*RouteUsage{}
+	versions = map[string]Version{}
+)
+
+// Register makes v known to Usage even before it has served a request.
+func Register(v Version) {
+	usageMu.Lock()
+	defer usageMu.Unlock()
+	versions[v.Name] = v
+	if _, ok := usage[v.Name]; !ok {
+		usage[v.Name] = map[string]*RouteUsage{}
+	}
+}
+
+func record(version, route string) {
+	now := time.Now().UTC()
+	usageMu.Lock()
+	defer usageMu.Unlock()
+	routes, ok := usage[version]
+	if !ok {
+		routes = map[string]*RouteUsage{}
+		usage[version] = routes
+	}
+	ru, ok := routes[route]
+	if !ok {
+		ru = &RouteUsage{Route: route}
+		routes[route] = ru
+	}
+	ru.Requests++
+	ru.LastSeen = now
+}
+
+// Usage returns a per-version snapshot of request counts, newest version first.
+func Usage() []VersionUsage {
+	usageMu.Lock()
+	defer usageMu.Unlock()
+	out := make([]VersionUsage, 0, len(usage))
+	for name, routes := range usage {
+		vu := VersionUsage{Version: name, Routes: []RouteUsage{}}
+		if v, ok := versions[name]; ok {
+			vu.Deprecated = v.IsDeprecated()
+			if !v.Sunset.IsZero() {
+				sunset := v.Sunset.UTC()
+				vu.Sunset = &sunset
+			}
+		}
+		for _, ru := range routes {
+			vu.Requests += ru.Requests
+			if ru.LastSeen.After(vu.LastSeen) {
+				vu.LastSeen = ru.LastSeen
+			}
+			vu.Routes = append(vu.Routes, *ru)
+		}
+		sort.Slice(vu.Routes, func(i, j int) bool {
+			a, b := vu.Routes[i], vu.Routes[j]
+			if a.Requests != b.Requests {
+				return a.Requests > b.Requests
+			}
+			return a.Route < b.Route
+		})
+		out = append(out, vu)
+	}
+	sort.Slice(out, func(i, j int) bool { return newer(out[i].Version, out[j].Version) })
+	return out
+}
+
+// newer orders version names like "v10" after "v9" by their number. Names without one sort
+// after numbered versions, by name.
+func newer(a, b string) bool {
+	na, aok := versionNumber(a)
+	nb, bok := versionNumber(b)
+	switch {
+	case aok && bok && na != nb:
+		return na > nb
+	case aok != bok:
+		return aok
+	}
+	return a < b
+}
+
+func versionNumber(name string) (int, bool) {
+	n, err := strconv.Atoi(strings.TrimPrefix(name, "v"))
+	return n, err == nil
+}
+
+// UsageReport is the payload of the admin API usage endpoint.
+type UsageReport struct {
+	Versions []VersionUsage `json:"versions"`
+}
@@ -0,0 +1,172 @@
+package apiversion
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+func TestUnwrapItems(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		want       string
+		next, prev string
+	}{
+		{"items", `{"items":[{"id":1},{"id":2}]}`, "[{\"id\":1},{\"id\":2}]\n", "", ""},
+		{"cursors", `{"items":[1],"next":"n1","prev":"p1"}`, "[1]\n", "n1", "p1"},
+		{"empty items", `{"items":[]}`, "[]\n", "", ""},
+		{"null items", `{"items":null}`, "[]\n", "", ""},
+		{"no items", `{"total":0}`, "[]\n", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			got, err := UnwrapItems(header, []byte(tt.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("body = %q, want %q", got, tt.want)
+			}
+			if header.Get("X-Next-Cursor") != tt.next || header.Get("X-Prev-Cursor") != tt.prev {
+				t.Errorf("cursor headers = %v", header)
+			}
+		})
+	}
+	if _, err := UnwrapItems(http.Header{}, []byte("not json")); err == nil {
+		t.Error("UnwrapItems accepted a non-JSON body")
+	}
+}
+
+func TestAdapt(t *testing.T) {
+	replace := func(_ http.Header, body []byte) ([]byte, error) { return []byte("adapted"), nil }
+	failing := func(http.Header, []byte) ([]byte, error) { return nil, errors.New("not json") }
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		adapter Adapter
+		want    string
+	}{
+		{"success is adapted", http.StatusOK, "{}", replace, "adapted"},
+		{"created is adapted", http.StatusCreated, "{}", replace, "adapted"},
+		{"errors pass through", http.StatusNotFound, `{"error":"nope"}`, replace, `{"error":"nope"}`},
+		{"empty body passes through", http.StatusNoContent, "", replace, ""},
+		{"adapter failure passes through", http.StatusOK, "plain", failing, "plain"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := func(w http.ResponseWriter, r *http.Request) {
+				if w.Header().Get("Content-Language") != "ta" {
+					t.Error("handler did not see headers set before it")
+				}
+				w.Header().Set("Content-Length", "99")
+				w.WriteHeader(tt.status)
+				w.WriteHeader(http.StatusTeapot)
+				_, _ = w.Write([]byte(tt.body))
+			}
+			w := httptest.NewRecorder()
+			w.Header().Set("Content-Language", "ta")
+			Adapt(handler, tt.adapter)(w, httptest.NewRequest(http.MethodGet, "/", nil))
+			if w.Code != tt.status {
+				t.Errorf("status = %d, want %d", w.Code, tt.status)
+			}
+			if w.Body.String() != tt.want {
+				t.Errorf("body = %q, want %q", w.Body, tt.want)
+			}
+			if adapted := tt.want == "adapted"; adapted == (w.Header().Get("Content-Length") != "") {
+				t.Errorf("Content-Length = %q after adapting = %v", w.Header().Get("Content-Length"), adapted)
+			}
+		})
+	}
+}
+
+func TestUnwrapList(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		if !ListsUnwrapped(r.Context()) {
+			t.Error("ListsUnwrapped = false inside UnwrapList")
+		}
+		_, _ = w.Write([]byte(`{"items":[1,2],"next":"abc"}`))
+	}
+	w := httptest.NewRecorder()
+	UnwrapList(handler)(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Body.String() != "[1,2]\n" || w.Header().Get("X-Next-Cursor") != "abc" {
+		t.Errorf("got %q with headers %v", w.Body, w.Header())
+	}
+	if ListsUnwrapped(httptest.NewRequest(http.MethodGet, "/", nil).Context()) {
+		t.Error("ListsUnwrapped = true outside UnwrapList")
+	}
+}
+
+func TestMiddlewareHeaders(t *testing.T) {
+	resetUsage()
+	v := Version{Name: "v1", Prefix: "/api/v1", Deprecated: time.Unix(1700000000, 0), Sunset: time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC), Successor: "/api/v2"}
+	r := mux.NewRouter()
+	sub := r.PathPrefix(v.Prefix).Subrouter()
+	sub.Use(v.Middleware())
+	sub.HandleFunc("/quests/{id}", func(http.ResponseWriter, *http.Request) {})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/quests/3", nil))
+	want := map[string]string{
+		"API-Version": "v1",
+		"Deprecation": "@1700000000",
+		"Sunset":      "Fri, 01 Jan 2027 00:00:00 GMT",
+		"Link":        `</api/v2>; rel="successor-version"`,
+	}
+	for k, v := range want {
+		if got := w.Header().Get(k); got != v {
+			t.Errorf("%s = %q, want %q", k, got, v)
+		}
+	}
+	if u := Usage(); len(u) != 1 || len(u[0].Routes) != 1 || u[0].Routes[0].Route != "GET /api/v1/quests/{id}" {
+		t.Errorf("Usage = %+v, want the route template recorded", u)
+	}
+}
+
+func TestUsage(t *testing.T) {
+	resetUsage()
+	sunset := time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC)
+	for _, v := range []Version{{Name: "v2"}, {Name: "v1", Deprecated: time.Now(), Sunset: sunset}, {Name: "v10"}, {Name: "beta"}, {Name: "v9"}} {
+		Register(v)
+	}
+	record("v1", "GET /a")
+	record("v1", "GET /b")
+	record("v1", "GET /b")
+	record("v1", "GET /c")
+	record("v2", "GET /a")
+
+	got := Usage()
+	var names []string
+	for _, vu := range got {
+		names = append(names, vu.Version)
+	}
+	if want := []string{"v10", "v9", "v2", "v1", "beta"}; !slices.Equal(names, want) {
+		t.Fatalf("versions = %v, want %v", names, want)
+	}
+	v1 := got[3]
+	if v1.Requests != 4 || !v1.Deprecated || v1.Sunset == nil || !v1.Sunset.Equal(sunset) || v1.LastSeen.IsZero() {
+		t.Errorf("v1 usage = %+v", v1)
+	}
+	var routes []string
+	for _, ru := range v1.Routes {
+		routes = append(routes, ru.Route)
+	}
+	if want := []string{"GET /b", "GET /a", "GET /c"}; !slices.Equal(routes, want) {
+		t.Errorf("v1 routes = %v, want %v", routes, want)
+	}
+	if v10 := got[0]; v10.Requests != 0 || v10.Deprecated || v10.Sunset != nil || len(v10.Routes) != 0 {
+		t.Errorf("unused v10 usage = %+v", v10)
+	}
+}
+
+func resetUsage() {
+	usageMu.Lock()
+	usage, versions = map[string]map[string]*RouteUsage{}, map[string]Version{}
+	usageMu.Unlock()
+}
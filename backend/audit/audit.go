@@ -0,0 +1,161 @@
+// Package audit keeps an append-only trail of mutating API requests. Middleware records who
+// made each successful POST, PUT, PATCH or DELETE, for whom, against what, and with which
+// before/after changes. Every entry stores the hash of the previous entry of its tenant, so
+// editing or deleting a stored entry breaks the chain and Verify reports where.
+package audit
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/tenant"
+)
+
+// appendAttempts bounds retries when concurrent appends race for the same sequence number.
+const appendAttempts = 5
+
+// Entry is one audited request.
+type Entry struct {
+	ID           primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	TenantID     string             `bson:"tenant_id" json:"-"`
+	Seq          int64              `bson:"seq" json:"seq"`
+	At           time.Time          `bson:"at" json:"at"`
+	ActorID      int                `bson:"actor_id" json:"actorId"`
+	ActorRole    string             `bson:"actor_role" json:"actorRole"`
+	SubjectID    int                `bson:"subject_id" json:"subjectId"`
+	Action       string             `bson:"action" json:"action"`
+	Target       string             `bson:"target,omitempty" json:"target,omitempty"`
+	Status       int                `bson:"status" json:"status"`
+	Changes      []Change           `bson:"changes,omitempty" json:"changes,omitempty"`
+	RequestID    string             `bson:"request_id" json:"requestId"`
+	IP           string             `bson:"ip" json:"ip"`
+	ForwardedFor string             `bson:"forwarded_for,omitempty" json:"forwardedFor,omitempty"`
+	PrevHash     string             `bson:"prev_hash" json:"prevHash"`
+	Hash         string             `bson:"hash" json:"hash"`
+}
+
+// EntryList is a page of audit entries.
+type EntryList struct {
+	Items []Entry `json:"items"`
+	Next  string  `json:"next,omitempty"`
+	Prev  string  `json:"prev,omitempty"`
+}
+
+// Verification is the result of checking a tenant's hash chain.
+type Verification struct {
+	Valid    bool   `json:"valid"`
+	Checked  int    `json:"checked"`
+	BrokenAt int64  `json:"brokenAt,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+}
+
+// Log stores entries. Nothing in the application updates or deletes them; give the API's
+// database user insert-only access to the collection to make that hold for everyone else.
+type Log struct {
+	Col *tenant.Collection
+}
+
+// EnsureIndexes creates the per-tenant sequence index that serializes the chain.
+func (l *Log) EnsureIndexes(ctx context.Context) error {
+	_, err := l.Col.Raw().Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "tenant_id", Value: 1}, {Key: "seq", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "tenant_id", Value: 1}, {Key: "at", Value: -1}}},
+	})
+	return err
+}
+
+// Append chains e onto the log of the tenant in ctx and stores it.
+func (l *Log) Append(ctx context.Context, e Entry) (Entry, error) {
+	tenantID, ok := tenant.ID(ctx)
+	if !ok {
+		return e, tenant.ErrNoTenant
+	}
+	e.TenantID = tenantID
+	// Mongo keeps milliseconds; hash what will be read back.
+	e.At = time.Now().UTC().Truncate(time.Millisecond)
+	for attempt := 0; attempt < appendAttempts; attempt++ {
+		var last Entry
+		err := l.Col.FindOne(ctx, bson.M{}, options.FindOne().SetSort(bson.M{"seq": -1}).SetProjection(bson.M{"seq": 1, "hash": 1})).Decode(&last)
+		if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+			return e, err
+		}
+		e.ID = primitive.NewObjectID()
+		e.Seq, e.PrevHash = last.Seq+1, last.Hash
+		e.Hash = e.computeHash()
+		_, err = l.Col.InsertOne(ctx, e)
+		if mongo.IsDuplicateKeyError(err) {
+			continue
+		}
+		return e, err
+	}
+	return e, fmt.Errorf("audit: gave up appending after %d conflicting attempts", appendAttempts)
+}
+
+// Verify walks the chain of the tenant in ctx from the first entry and reports the first
+// entry whose sequence, link or hash does not match.
+func (l *Log) Verify(ctx context.Context) (Verification, error) {
+	cursor, err := l.Col.Find(ctx, bson.M{}, options.Find().SetSort(bson.M{"seq": 1}))
+	if err != nil {
+		return Verification{}, err
+	}
+	defer cursor.Close(ctx)
+	v := Verification{Valid: true}
+	prev := Entry{}
+	for cursor.Next(ctx) {
+		var e Entry
+		if err := cursor.Decode(&e); err != nil {
+			return v, err
+		}
+		v.Checked++
+		switch {
+		case e.Seq != prev.Seq+1:
+			v.Reason = fmt.Sprintf("expected entry %d, found %d", prev.Seq+1, e.Seq)
+		case e.PrevHash != prev.Hash:
+			v.Reason = "previous hash does not match the preceding entry"
+		case e.Hash != e.computeHash():
+			v.Reason = "entry hash does not match its contents"
+		}
+		if v.Reason != "" {
+			v.Valid, v.BrokenAt = false, e.Seq
+			return v, nil
+		}
+		prev = e
+	}
+	return v, cursor.Err()
+}
+
+// computeHash covers every stored field except the ID and the hash itself.
+func (e Entry) computeHash() string {
+	changes := e.Changes
+	if len(changes) == 0 {
+		changes = nil
+	}
+	payload, _ := json.Marshal(struct {
+		Tenant       string
+		Seq          int64
+		At           int64
+		ActorID      int
+		ActorRole    string
+		SubjectID    int
+		Action       string
+		Target       string
+		Status       int
+		Changes      []Change
+		RequestID    string
+		IP           string
+		ForwardedFor string
+		PrevHash     string
+	}{e.TenantID, e.Seq, e.At.UnixMilli(), e.ActorID, e.ActorRole, e.SubjectID, e.Action, e.Target, e.Status, changes, e.RequestID, e.IP, e.ForwardedFor, e.PrevHash})
+	sum := sha256.Sum256(payload)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,39 @@
+package audit
+
+import (
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestEntryHash(t *testing.T) {
+	base := Entry{TenantID: "campus", Seq: 3, At: time.Date(2026, time.March, 2, 9, 0, 0, 0, time.UTC), ActorID: 1, ActorRole: "admin", Action: "POST /api/v2/quests", Status: 201, PrevHash: "abc"}
+	hash := base.computeHash()
+
+	same := base
+	same.ID, same.Hash, same.Changes = primitive.NewObjectID(), "stored", []Change{}
+	if same.computeHash() != hash {
+		t.Error("hash depends on the ID, the stored hash or an empty change list")
+	}
+	// Mongo keeps milliseconds, so a reloaded entry must hash the same.
+	same.At = base.At.Add(300 * time.Microsecond)
+	if same.computeHash() != hash {
+		t.Error("hash depends on sub-millisecond time")
+	}
+
+	for name, edit := range map[string]func(*Entry){
+		"tenant":    func(e *Entry) { e.TenantID = "other" },
+		"seq":       func(e *Entry) { e.Seq++ },
+		"actor":     func(e *Entry) { e.ActorID = 2 },
+		"status":    func(e *Entry) { e.Status = 200 },
+		"changes":   func(e *Entry) { e.Changes = []Change{{Field: "coins", After: "5"}} },
+		"prev hash": func(e *Entry) { e.PrevHash = "abd" },
+	} {
+		e := base
+		edit(&e)
+		if e.computeHash() == hash {
+			t.Errorf("editing %s kept the hash", name)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+)
+
+// maxValueLength caps each recorded value so one large field cannot bloat the log.
+const maxValueLength = 2000
+
+// redacted fields never appear in a diff, only the fact that they changed.
+var redacted = map[string]bool{"secret": true, "password_hash": true, "password": true}
+
+// redactedValue stands in for a redacted field. While diffing it is followed by a digest
+// of the value so a change is still detected.
+const redactedValue = `"[redacted]"`
+
+// Change is one field that differs between the before and after state. Values are JSON;
+// an empty Before means the field was added and an empty After that it was removed.
+type Change struct {
+	Field  string `bson:"field" json:"field"`
+	Before string `bson:"before,omitempty" json:"before,omitempty"`
+	After  string `bson:"after,omitempty" json:"after,omitempty"`
+}
+
+// Diff compares two documents as they are stored in Mongo, field by field with dotted
+// paths for nested documents and arrays. Either side may be nil for a create or delete.
+func Diff(before, after interface{}) []Change {
+	old, cur := flatten(before), flatten(after)
+	fields := make([]string, 0, len(old)+len(cur))
+	for f := range old {
+		fields = append(fields, f)
+	}
+	for f := range cur {
+		if _, ok := old[f]; !ok {
+			fields = append(fields, f)
+		}
+	}
+	sort.Strings(fields)
+	changes := []Change{}
+	for _, f := range fields {
+		if old[f] != cur[f] {
+			changes = append(changes, Change{Field: f, Before: present(old[f]), After: present(cur[f])})
+		}
+	}
+	return changes
+}
+
+func present(v string) string {
+	if strings.HasPrefix(v, redactedValue) {
+		return redactedValue
+	}
+	return v
+}
+
+func flatten(doc interface{}) map[string]string {
+	out := map[string]string{}
+	if doc == nil {
+		return out
+	}
+	if v := reflect.ValueOf(doc); v.Kind() == reflect.Ptr && v.IsNil() {
+		return out
+	}
+	raw, err := bson.Marshal(doc)
+	if err != nil {
+		return out
+	}
+	flattenRaw(out, "", bson.Raw(raw))
+	return out
+}
+
+func flattenRaw(out map[string]string, prefix string, doc bson.Raw) {
+	elems, err := doc.Elements()
+	if err != nil {
+		return
+	}
+	for _, el := range elems {
+		key := el.Key()
+		path := prefix + key
+		val := el.Value()
+		if redacted[key] {
+			sum := sha256.Sum256(val.Value)
+			out[path] = redactedValue + hex.EncodeToString(sum[:8])
+			continue
+		}
+		switch val.Type {
+		case bsontype.EmbeddedDocument:
+			flattenRaw(out, path+".", val.Document())
+		case bsontype.Array:
+			values, _ := val.Array().Values()
+			if len(values) == 0 {
+				out[path] = "[]"
+			}
+			for i, item := range values {
+				itemPath := path + "." + strconv.Itoa(i)
+				if item.Type == bsontype.EmbeddedDocument {
+					flattenRaw(out, itemPath+".", item.Document())
+				} else {
+					out[itemPath] = jsonValue(item)
+				}
+			}
+		default:
+			out[path] = jsonValue(val)
+		}
+	}
+}
+
+func jsonValue(val bson.RawValue) string {
+	var v interface{}
+	if err := val.Unmarshal(&v); err != nil {
+		return val.String()
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return val.String()
+	}
+	if len(b) > maxValueLength {
+		return string(b[:maxValueLength]) + "…"
+	}
+	return string(b)
+}
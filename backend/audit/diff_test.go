@@ -0,0 +1,72 @@
+package audit
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestDiff(t *testing.T) {
+	type profile struct {
+		Name    string   `bson:"name"`
+		Courses []int    `bson:"courses"`
+		Address bson.M   `bson:"address,omitempty"`
+		Tags    []bson.M `bson:"tags,omitempty"`
+	}
+	tests := []struct {
+		name          string
+		before, after interface{}
+		want          []Change
+	}{
+		{"unchanged", bson.M{"coins": 5}, bson.M{"coins": 5}, []Change{}},
+		{"create", nil, bson.M{"coins": 5}, []Change{{Field: "coins", After: "5"}}},
+		{"delete", bson.M{"coins": 5}, (*profile)(nil), []Change{{Field: "coins", Before: "5"}}},
+		{"scalar", bson.M{"coins": 5, "name": "Ada"}, bson.M{"coins": 7, "name": "Ada"}, []Change{{Field: "coins", Before: "5", After: "7"}}},
+		{
+			"nested document",
+			profile{Name: "Ada", Address: bson.M{"city": "Chennai", "zip": "600001"}},
+			profile{Name: "Ada", Address: bson.M{"city": "Delhi", "zip": "600001"}},
+			[]Change{{Field: "address.city", Before: `"Chennai"`, After: `"Delhi"`}},
+		},
+		{
+			"array shrinks",
+			profile{Name: "Ada", Courses: []int{101, 102}},
+			profile{Name: "Ada", Courses: []int{101}},
+			[]Change{{Field: "courses.1", Before: "102"}},
+		},
+		{
+			"array emptied",
+			profile{Name: "Ada", Courses: []int{101}},
+			profile{Name: "Ada", Courses: []int{}},
+			[]Change{{Field: "courses", After: "[]"}, {Field: "courses.0", Before: "101"}},
+		},
+		{
+			"documents in arrays",
+			profile{Name: "Ada", Tags: []bson.M{{"label": "ai"}}},
+			profile{Name: "Ada", Tags: []bson.M{{"label": "ml"}}},
+			[]Change{{Field: "tags.0.label", Before: `"ai"`, After: `"ml"`}},
+		},
+		{"redacted change", bson.M{"password_hash": "a"}, bson.M{"password_hash": "b"}, []Change{{Field: "password_hash", Before: redactedValue, After: redactedValue}}},
+		{"redacted unchanged", bson.M{"secret": "a"}, bson.M{"secret": "a"}, []Change{}},
+		{"redacted nested", bson.M{"hook": bson.M{"secret": "a"}}, bson.M{"hook": bson.M{"secret": "b"}}, []Change{{Field: "hook.secret", Before: redactedValue, After: redactedValue}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Diff(tt.before, tt.after); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Diff = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiffTruncatesLongValues(t *testing.T) {
+	changes := Diff(nil, bson.M{"body": strings.Repeat("x", 3*maxValueLength)})
+	if len(changes) != 1 {
+		t.Fatalf("changes = %+v", changes)
+	}
+	if after := changes[0].After; len(after) > maxValueLength+len("…") || !strings.HasSuffix(after, "…") {
+		t.Errorf("After is %d bytes, want it cut at %d", len(after), maxValueLength)
+	}
+}
@@ -0,0 +1,152 @@
+package audit
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"backend/middleware"
+)
+
+// RequestIDHeader carries the request ID; a client-supplied value is kept, otherwise one is
+// generated. Either way it is echoed on the response and stored on the entry.
+const RequestIDHeader = "X-Request-ID"
+
+const maxRequestIDLength = 128
+
+var versionPrefix = regexp.MustCompile(`^/api(/v\d+)?`)
+
+type recorderKey struct{}
+
+// recorder collects what a handler knows about its mutation for the middleware to store.
+type recorder struct {
+	subjectID int
+	target    string
+	changes   []Change
+}
+
+func recorderFrom(ctx context.Context) *recorder {
+	rec, _ := ctx.Value(recorderKey{}).(*recorder)
+	return rec
+}
+
+// SetSubject records the user a request acted for when it differs from the caller, such as
+// an admin editing a faculty dashboard or completing a quest for a student.
+func SetSubject(ctx context.Context, userID int) {
+	if rec := recorderFrom(ctx); rec != nil {
+		rec.subjectID = userID
+	}
+}
+
+// SetTarget names the object a request changed, such as ("course", id). Without it the
+// route's {id} or {name} variable is used.
+func SetTarget(ctx context.Context, kind string, id interface{}) {
+	if rec := recorderFrom(ctx); rec != nil {
+		rec.target = fmt.Sprintf("%s:%v", kind, id)
+	}
+}
+
+// SetChange records the state of the target before and after the request; see Diff.
+func SetChange(ctx context.Context, before, after interface{}) {
+	if rec := recorderFrom(ctx); rec != nil {
+		rec.changes = Diff(before, after)
+	}
+}
+
+// Middleware appends an entry for every successful mutating request. Mount it inside the
+// auth middleware so the caller is known, and inside idempotency so replays are not logged
+// twice. A failure to write the entry is logged; the response has already been sent.
+func Middleware(l *Log) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodGet, http.MethodHead, http.MethodOptions:
+				next.ServeHTTP(w, r)
+				return
+			}
+			requestID := strings.TrimSpace(r.Header.Get(RequestIDHeader))
+			if requestID == "" || len(requestID) > maxRequestIDLength {
+				requestID = newRequestID()
+			}
+			w.Header().Set(RequestIDHeader, requestID)
+			rec := &recorder{}
+			sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(sw, r.WithContext(context.WithValue(r.Context(), recorderKey{}, rec)))
+			if sw.status >= http.StatusBadRequest || l == nil {
+				return
+			}
+
+			ctx := context.WithoutCancel(r.Context())
+			actorID, _ := middleware.UserIDFromContext(ctx)
+			e := Entry{ActorID: actorID, ActorRole: middleware.RoleFromContext(ctx), SubjectID: actorID, Action: action(r), Target: rec.target, Status: sw.status, Changes: rec.changes, RequestID: requestID, IP: remoteIP(r), ForwardedFor: r.Header.Get("X-Forwarded-For")}
+			if rec.subjectID != 0 {
+				e.SubjectID = rec.subjectID
+			}
+			if e.Target == "" {
+				vars := mux.Vars(r)
+				if id, ok := vars["id"]; ok {
+					e.Target = id
+				} else {
+					e.Target = vars["name"]
+				}
+			}
+			if _, err := l.Append(ctx, e); err != nil {
+				log.Printf("audit: %s %s (request %s): %v", r.Method, r.URL.Path, requestID, err)
+			}
+		})
+	}
+}
+
+// action is the method and route pattern without the API prefix, e.g.
+// "POST /faculty/dashboard/courses/{id}/status", so v1 and v2 calls read the same.
+func action(r *http.Request) string {
+	path := r.URL.Path
+	if route := mux.CurrentRoute(r); route != nil {
+		if tpl, err := route.GetPathTemplate(); err == nil {
+			path = tpl
+		}
+	}
+	return r.Method + " " + versionPrefix.ReplaceAllString(path, "")
+}
+
+// remoteIP is the address of the connecting peer. X-Forwarded-For is stored separately
+// because clients can set it to anything.
+func remoteIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func newRequestID() string {
+	buf := make([]byte, 16)
+	_, _ = rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
+
+type statusWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status, w.wroteHeader = status, true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	w.wroteHeader = true
+	return w.ResponseWriter.Write(b)
+}
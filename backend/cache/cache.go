@@ -0,0 +1,134 @@
+// Package cache keeps expensive computed values, such as the leaderboard and the faculty
+// course aggregates, for a short time. Values live in a Store: Memory for a single
+// instance, or Mongo so every instance shares one copy. Callers put the versions of the
+// data a value depends on into its key, so a change makes the old entry unreachable at
+// once; the TTL only bounds how long unreachable entries linger.
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// DefaultTTL is how long an entry is kept when no TTL is configured.
+const DefaultTTL = time.Minute
+
+// Store holds encoded values by key. Get reports a missing or expired key as ok == false.
+type Store interface {
+	Get(ctx context.Context, key string) (value []byte, ok bool, err error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+}
+
+// Cache memoizes values of type T in a Store and counts hits and misses.
+type Cache[T any] struct {
+	name  string
+	store Store
+	ttl   time.Duration
+	group singleflight.Group
+
+	hits, misses, errors atomic.Int64
+}
+
+// New returns a cache named name, listed in Snapshot under that name. A ttl of zero or less
+// uses DefaultTTL.
+func New[T any](name string, store Store, ttl time.Duration) *Cache[T] {
+	if ttl <= 0 {
+		ttl = DefaultTTL
+	}
+	c := &Cache[T]{name: name, store: store, ttl: ttl}
+	register(name, c)
+	return c
+}
+
+// Get returns the value stored under key, or calls load, stores its result and returns it.
+// Concurrent misses for one key share a single load. If the store fails the value is
+// loaded directly, so a cache outage only costs latency.
+func (c *Cache[T]) Get(ctx context.Context, key string, load func(context.Context) (T, error)) (T, error) {
+	if raw, ok, err := c.store.Get(ctx, key); err != nil {
+		c.errors.Add(1)
+		log.Printf("cache %s: get %s: %v", c.name, key, err)
+	} else if ok {
+		var v T
+		if err := json.Unmarshal(raw, &v); err == nil {
+			c.hits.Add(1)
+			return v, nil
+		}
+		c.errors.Add(1)
+	}
+	c.misses.Add(1)
+	v, err, _ := c.group.Do(key, func() (interface{}, error) {
+		// Other callers may be waiting on this load, so one caller going away must not fail it.
+		ctx := context.WithoutCancel(ctx)
+		v, err := load(ctx)
+		if err != nil {
+			return v, err
+		}
+		if raw, err := json.Marshal(v); err == nil {
+			if err := c.store.Set(ctx, key, raw, c.ttl); err != nil {
+				c.errors.Add(1)
+				log.Printf("cache %s: set %s: %v", c.name, key, err)
+			}
+		}
+		return v, nil
+	})
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return v.(T), nil
+}
+
+func (c *Cache[T]) stats() Stats {
+	s := Stats{Name: c.name, Hits: c.hits.Load(), Misses: c.misses.Load(), Errors: c.errors.Load(), TTLSeconds: int(c.ttl / time.Second)}
+	if total := s.Hits + s.Misses; total > 0 {
+		s.HitRate = float64(s.Hits) / float64(total)
+	}
+	return s
+}
+
+// Stats counts one cache's lookups since process start.
+type Stats struct {
+	Name       string  `json:"name"`
+	Hits       int64   `json:"hits"`
+	Misses     int64   `json:"misses"`
+	Errors     int64   `json:"errors"`
+	HitRate    float64 `json:"hitRate"`
+	TTLSeconds int     `json:"ttlSeconds"`
+}
+
+// StatsReport lists every cache of this instance.
+type StatsReport struct {
+	Caches []Stats `json:"caches"`
+}
+
+type statser interface{ stats() Stats }
+
+var (
+	registryMu sync.Mutex
+	registry   = map[string]statser{}
+)
+
+func register(name string, c statser) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[name] = c
+}
+
+// Snapshot returns the stats of every cache created with New, sorted by name.
+func Snapshot() []Stats {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	out := make([]Stats, 0, len(registry))
+	for _, c := range registry {
+		out = append(out, c.stats())
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
@@ -0,0 +1,185 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// brokenStore fails every call, like a cache database that is down.
+type brokenStore struct{}
+
+func (brokenStore) Get(context.Context, string) ([]byte, bool, error) {
+	return nil, false, errors.New("store down")
+}
+
+func (brokenStore) Set(context.Context, string, []byte, time.Duration) error {
+	return errors.New("store down")
+}
+
+func counter(v []int) (func(context.Context) ([]int, error), *atomic.Int64) {
+	var calls atomic.Int64
+	return func(context.Context) ([]int, error) {
+		calls.Add(1)
+		return v, nil
+	}, &calls
+}
+
+func TestGetCachesAndCounts(t *testing.T) {
+	c := New[[]int]("test_counts", NewMemory(0), 0)
+	load, calls := counter([]int{1, 2})
+	ctx := context.Background()
+	for i := 0; i < 4; i++ {
+		v, err := c.Get(ctx, "k", load)
+		if err != nil || len(v) != 2 || v[1] != 2 {
+			t.Fatalf("Get = %v, %v", v, err)
+		}
+	}
+	if _, err := c.Get(ctx, "other", load); err != nil {
+		t.Fatal(err)
+	}
+	if calls.Load() != 2 {
+		t.Errorf("load ran %d times, want once per key", calls.Load())
+	}
+	want := Stats{Name: "test_counts", Hits: 3, Misses: 2, HitRate: 0.6, TTLSeconds: 60}
+	if got := c.stats(); got != want {
+		t.Errorf("stats = %+v, want %+v", got, want)
+	}
+}
+
+func TestGetSharesConcurrentLoads(t *testing.T) {
+	c := New[[]int]("test_singleflight", NewMemory(0), time.Minute)
+	release := make(chan struct{})
+	var calls atomic.Int64
+	load := func(context.Context) ([]int, error) {
+		calls.Add(1)
+		<-release
+		return []int{7}, nil
+	}
+	const callers = 10
+	var wg sync.WaitGroup
+	results := make(chan []int, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := c.Get(context.Background(), "k", load)
+			if err != nil {
+				t.Error(err)
+			}
+			results <- v
+		}()
+	}
+	for c.misses.Load() < callers {
+		time.Sleep(time.Millisecond)
+	}
+	// Every caller has missed; give the last ones time to join the shared load.
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	close(results)
+	for v := range results {
+		if len(v) != 1 || v[0] != 7 {
+			t.Errorf("caller got %v", v)
+		}
+	}
+	if calls.Load() != 1 {
+		t.Errorf("load ran %d times for %d concurrent misses, want 1", calls.Load(), callers)
+	}
+}
+
+func TestGetLoadOutlivesCancelledCaller(t *testing.T) {
+	c := New[int]("test_cancel", NewMemory(0), time.Minute)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	v, err := c.Get(ctx, "k", func(ctx context.Context) (int, error) { return 3, ctx.Err() })
+	if err != nil || v != 3 {
+		t.Errorf("Get = %d, %v; want the load to run without the caller's cancellation", v, err)
+	}
+}
+
+func TestGetFallsBackWhenStoreFails(t *testing.T) {
+	c := New[[]int]("test_broken", brokenStore{}, time.Minute)
+	load, calls := counter([]int{1})
+	for i := 0; i < 3; i++ {
+		if v, err := c.Get(context.Background(), "k", load); err != nil || len(v) != 1 {
+			t.Fatalf("Get = %v, %v", v, err)
+		}
+	}
+	if calls.Load() != 3 {
+		t.Errorf("load ran %d times, want every call to load directly", calls.Load())
+	}
+	// Each call fails to read and then to write.
+	if s := c.stats(); s.Errors != 6 || s.Misses != 3 || s.Hits != 0 || s.HitRate != 0 {
+		t.Errorf("stats = %+v", s)
+	}
+}
+
+func TestGetReloadsCorruptEntries(t *testing.T) {
+	store := NewMemory(0)
+	_ = store.Set(context.Background(), "k", []byte("not json"), time.Minute)
+	c := New[[]int]("test_corrupt", store, time.Minute)
+	load, calls := counter([]int{5})
+	if v, err := c.Get(context.Background(), "k", load); err != nil || v[0] != 5 {
+		t.Fatalf("Get = %v, %v", v, err)
+	}
+	if v, _ := c.Get(context.Background(), "k", load); v[0] != 5 || calls.Load() != 1 {
+		t.Errorf("second Get = %v after %d loads, want the reloaded value cached", v, calls.Load())
+	}
+	if s := c.stats(); s.Errors != 1 || s.Hits != 1 || s.Misses != 1 {
+		t.Errorf("stats = %+v", s)
+	}
+}
+
+func TestGetDoesNotCacheErrors(t *testing.T) {
+	c := New[int]("test_errors", NewMemory(0), time.Minute)
+	failure := errors.New("database down")
+	if _, err := c.Get(context.Background(), "k", func(context.Context) (int, error) { return 1, failure }); !errors.Is(err, failure) {
+		t.Fatalf("err = %v, want the load error", err)
+	}
+	if v, err := c.Get(context.Background(), "k", func(context.Context) (int, error) { return 2, nil }); err != nil || v != 2 {
+		t.Errorf("Get after a failed load = %d, %v", v, err)
+	}
+}
+
+func TestSnapshot(t *testing.T) {
+	New[int]("test_snapshot_b", NewMemory(0), 0)
+	New[int]("test_snapshot_a", NewMemory(0), 5*time.Second)
+	var names []string
+	for _, s := range Snapshot() {
+		names = append(names, s.Name)
+		if s.Name == "test_snapshot_a" && s.TTLSeconds != 5 {
+			t.Errorf("TTLSeconds = %d, want 5", s.TTLSeconds)
+		}
+	}
+	for i := 1; i < len(names); i++ {
+		if names[i-1] >= names[i] {
+			t.Fatalf("Snapshot not sorted by name: %v", names)
+		}
+	}
+}
+
+func TestMemory(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory(2)
+	_ = m.Set(ctx, "expired", []byte("x"), -time.Second)
+	if _, ok, _ := m.Get(ctx, "expired"); ok {
+		t.Error("expired entry returned")
+	}
+	_ = m.Set(ctx, "a", []byte("1"), time.Minute)
+	_ = m.Set(ctx, "b", []byte("2"), time.Minute)
+	_ = m.Set(ctx, "a", []byte("3"), time.Minute)
+	if v, ok, _ := m.Get(ctx, "a"); !ok || string(v) != "3" {
+		t.Errorf("overwritten entry = %q, %v", v, ok)
+	}
+	_ = m.Set(ctx, "c", []byte("4"), time.Minute)
+	if len(m.entries) != 2 {
+		t.Errorf("store holds %d entries, want at most 2", len(m.entries))
+	}
+	if _, ok, _ := m.Get(ctx, "c"); !ok {
+		t.Error("newest entry was evicted")
+	}
+}
@@ -0,0 +1,67 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// DefaultMaxEntries bounds a Memory store created with NewMemory(0).
+const DefaultMaxEntries = 10000
+
+// Memory is a Store local to this process. Each instance keeps its own entries, which is
+// fine as long as keys carry data versions: another instance's change still changes the key.
+type Memory struct {
+	mu      sync.Mutex
+	entries map[string]memoryEntry
+	max     int
+}
+
+type memoryEntry struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+// NewMemory returns a Memory store holding at most maxEntries entries.
+func NewMemory(maxEntries int) *Memory {
+	if maxEntries <= 0 {
+		maxEntries = DefaultMaxEntries
+	}
+	return &Memory{entries: map[string]memoryEntry{}, max: maxEntries}
+}
+
+func (m *Memory) Get(_ context.Context, key string) ([]byte, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	if time.Now().After(e.expiresAt) {
+		delete(m.entries, key)
+		return nil, false, nil
+	}
+	return e.value, true, nil
+}
+
+func (m *Memory) Set(_ context.Context, key string, value []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	now := time.Now()
+	if _, exists := m.entries[key]; !exists && len(m.entries) >= m.max {
+		for k, e := range m.entries {
+			if now.After(e.expiresAt) {
+				delete(m.entries, k)
+			}
+		}
+		// Still full: evict an arbitrary entry rather than grow without bound.
+		for k := range m.entries {
+			if len(m.entries) < m.max {
+				break
+			}
+			delete(m.entries, k)
+		}
+	}
+	m.entries[key] = memoryEntry{value: value, expiresAt: now.Add(ttl)}
+	return nil
+}
@@ -0,0 +1,47 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Mongo is a Store shared by every instance, kept in a collection with a TTL index.
+type Mongo struct {
+	Col *mongo.Collection
+}
+
+type mongoEntry struct {
+	Key       string    `bson:"_id"`
+	Value     []byte    `bson:"value"`
+	ExpiresAt time.Time `bson:"expires_at"`
+}
+
+// EnsureIndexes creates the TTL index that removes expired entries.
+func (m Mongo) EnsureIndexes(ctx context.Context) error {
+	_, err := m.Col.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)})
+	return err
+}
+
+func (m Mongo) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	var e mongoEntry
+	// The TTL monitor runs about once a minute, so expired entries are filtered here too.
+	err := m.Col.FindOne(ctx, bson.M{"_id": key, "expires_at": bson.M{"$gt": time.Now().UTC()}}).Decode(&e)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return e.Value, true, nil
+}
+
+func (m Mongo) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	e := mongoEntry{Key: key, Value: value, ExpiresAt: time.Now().UTC().Add(ttl)}
+	_, err := m.Col.ReplaceOne(ctx, bson.M{"_id": key}, e, options.Replace().SetUpsert(true))
+	return err
+}
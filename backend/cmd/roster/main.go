@@ -0,0 +1,125 @@
+// Command roster imports users from a CSV roster into one campus, the same way as
+// POST /api/v2/admin/users/import.
+//
+//	go run ./cmd/roster -file fall-2026.csv -tenant srm -dry-run
+//
+// It reads MONGODB_URI, DEFAULT_TENANT, INVITE_URL and the SMTP_* settings from the
+// environment (or .env). The exit status is 1 when any row is invalid.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/joho/godotenv"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/httpcache"
+	"backend/mailer"
+	"backend/roster"
+	"backend/tenant"
+)
+
+func main() {
+	godotenv.Load()
+	file := flag.String("file", "", "CSV roster with name, email, role and optional courses columns")
+	tenantID := flag.String("tenant", os.Getenv("DEFAULT_TENANT"), "campus to import into")
+	dryRun := flag.Bool("dry-run", false, "validate and report without writing")
+	invite := flag.Bool("invite", true, "email new users an invitation link")
+	asJSON := flag.Bool("json", false, "print the report as JSON")
+	flag.Parse()
+
+	if *file == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *tenantID == "" {
+		*tenantID = "srm"
+	}
+	mongoURI := os.Getenv("MONGODB_URI")
+	if mongoURI == "" {
+		log.Fatal("MONGODB_URI environment variable not set")
+	}
+	inviteURL := strings.TrimSpace(os.Getenv("INVITE_URL"))
+	if inviteURL == "" {
+		inviteURL = "http://localhost:5173/accept-invite"
+	}
+
+	f, err := os.Open(*file)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer f.Close()
+	entries, err := roster.Parse(f)
+	if err != nil {
+		log.Fatalf("%s: %v", *file, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(mongoURI))
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer client.Disconnect(context.Background())
+	database := client.Database("LearnOnline")
+
+	registry := tenant.NewRegistry(database.Collection("tenants"), *tenantID)
+	campus, err := registry.Get(ctx, *tenantID)
+	if err != nil {
+		log.Fatalf("tenant %q: %v", *tenantID, err)
+	}
+	ctx = tenant.WithID(ctx, campus.ID)
+
+	invites := &roster.Invitations{Col: tenant.Wrap(database.Collection("invitations")), BaseURL: inviteURL}
+	versions := &httpcache.Versions{Col: tenant.Wrap(database.Collection("cache_versions"))}
+	importer := &roster.Importer{Users: tenant.Wrap(database.Collection("users")), Counters: database.Collection("counters"), Invites: invites, Mailer: mailer.FromEnv(), Versions: versions}
+	report, err := importer.Import(ctx, entries, roster.Options{DryRun: *dryRun, Invite: *invite, Campus: campus.Branding.DisplayName})
+	if err != nil && !errors.Is(err, roster.ErrInvalidRows) {
+		log.Fatal(err)
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(report)
+	} else {
+		printReport(report)
+	}
+	if report.Invalid > 0 {
+		os.Exit(1)
+	}
+}
+
+func printReport(report roster.Report) {
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "LINE\tEMAIL\tACTION\tUSER ID\tERRORS")
+	for _, row := range report.Rows {
+		problems := make([]string, 0, len(row.Errors))
+		for _, e := range row.Errors {
+			problems = append(problems, e.Field+" "+e.Message)
+		}
+		userID := ""
+		if row.UserID > 0 {
+			userID = fmt.Sprint(row.UserID)
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\n", row.Line, row.Email, row.Action, userID, strings.Join(problems, "; "))
+	}
+	tw.Flush()
+	mode := "applied"
+	if report.DryRun {
+		mode = "dry run"
+	} else if report.Invalid > 0 {
+		mode = "rejected, nothing written"
+	}
+	fmt.Printf("\n%d rows (%s): %d create, %d update, %d invalid, %d invited, %d invite failures\n", report.Total, mode, report.Created, report.Updated, report.Invalid, report.Invited, report.InviteFailed)
+}
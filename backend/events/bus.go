@@ -0,0 +1,168 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"backend/tenant"
+)
+
+const (
+	defaultMaxAttempts  = 10
+	defaultPollInterval = 2 * time.Second
+	processingLease     = time.Minute
+	maxRetryDelay       = 10 * time.Minute
+)
+
+// Handler processes one event. Returning an error schedules a retry for this subscriber only.
+type Handler func(ctx context.Context, env Envelope) error
+
+type subscription struct {
+	name    string
+	types   map[string]bool
+	handler Handler
+}
+
+// Bus dispatches committed outbox events to in-process subscribers.
+type Bus struct {
+	Outbox       *Outbox
+	MaxAttempts  int
+	PollInterval time.Duration
+
+	mu   sync.RWMutex
+	subs []subscription
+	wake chan struct{}
+}
+
+// NewBus returns a Bus reading from outbox.
+func NewBus(outbox *Outbox) *Bus {
+	return &Bus{Outbox: outbox, wake: make(chan struct{}, 1)}
+}
+
+// Subscribe registers h under a unique name for the given event types (all types when
+// none are given). The name is persisted per event to track which subscribers are done.
+func (b *Bus) Subscribe(name string, h Handler, types ...string) {
+	set := map[string]bool{}
+	for _, t := range types {
+		set[t] = true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, s := range b.subs {
+		if s.name == name {
+			panic("events: duplicate subscriber " + name)
+		}
+	}
+	b.subs = append(b.subs, subscription{name: name, types: set, handler: h})
+}
+
+// Notify wakes the dispatcher after a transaction commits so events go out immediately
+// rather than on the next poll.
+func (b *Bus) Notify() {
+	if b == nil {
+		return
+	}
+	select {
+	case b.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run dispatches events until ctx is cancelled.
+func (b *Bus) Run(ctx context.Context) {
+	interval := b.PollInterval
+	if interval <= 0 {
+		interval = defaultPollInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := b.Drain(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("events: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-b.wake:
+		}
+	}
+}
+
+// Drain dispatches every event that is currently due.
+func (b *Bus) Drain(ctx context.Context) error {
+	for ctx.Err() == nil {
+		rec, err := b.Outbox.claim(ctx, processingLease)
+		if err != nil {
+			return fmt.Errorf("claim event: %w", err)
+		}
+		if rec == nil {
+			return nil
+		}
+		if err := b.dispatch(ctx, rec); err != nil {
+			return fmt.Errorf("record dispatch of %s: %w", rec.ID.Hex(), err)
+		}
+	}
+	return ctx.Err()
+}
+
+func (b *Bus) dispatch(ctx context.Context, rec *Record) error {
+	ev, err := decode(rec.Type, rec.Payload)
+	if err != nil {
+		return b.Outbox.retry(ctx, rec, nil, err, time.Now().UTC(), true)
+	}
+	env := Envelope{ID: rec.ID, Tenant: rec.Tenant, OccurredAt: rec.OccurredAt, Event: ev}
+	// Subscribers act on behalf of the tenant that produced the event.
+	if rec.Tenant != "" {
+		ctx = tenant.WithID(ctx, rec.Tenant)
+	}
+	already := map[string]bool{}
+	for _, name := range rec.Done {
+		already[name] = true
+	}
+	b.mu.RLock()
+	subs := append([]subscription(nil), b.subs...)
+	b.mu.RUnlock()
+
+	done := []string{}
+	var failures []string
+	for _, s := range subs {
+		if already[s.name] || (len(s.types) > 0 && !s.types[rec.Type]) {
+			continue
+		}
+		if err := call(ctx, s.handler, env); err != nil {
+			failures = append(failures, s.name+": "+err.Error())
+			continue
+		}
+		done = append(done, s.name)
+	}
+	if len(failures) == 0 {
+		return b.Outbox.finish(ctx, rec, done)
+	}
+	maxAttempts := b.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultMaxAttempts
+	}
+	attempts := rec.Attempts + 1
+	delay := time.Second << uint(attempts)
+	if delay > maxRetryDelay || delay <= 0 {
+		delay = maxRetryDelay
+	}
+	cause := errors.New(strings.Join(failures, "; "))
+	return b.Outbox.retry(ctx, rec, done, cause, time.Now().UTC().Add(delay), attempts >= maxAttempts)
+}
+
+// call runs h, turning a panic into an error so one bad subscriber cannot stop dispatch.
+func call(ctx context.Context, h Handler, env Envelope) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+	return h(ctx, env)
+}
@@ -0,0 +1,132 @@
+// Package events defines the platform's domain events and the transactional outbox that
+// carries them. Handlers record events with Outbox.Record inside the same Mongo transaction
+// as the state change; a Bus then reads committed events from the outbox and hands them to
+// in-process subscribers (webhooks today; notifications, badges and audit later). Delivery
+// is at-least-once, so subscribers must tolerate seeing an event twice.
+package events
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Event is a typed domain event. Type is the stable name stored in the outbox.
+type Event interface {
+	Type() string
+}
+
+const (
+	TypeQuestCompleted       = "quest.completed"
+	TypeVoteCast             = "vote.cast"
+	TypeResearchPostCreated  = "research_post.created"
+	TypeMenteeAdded          = "mentee.added"
+	TypeAISuggestionReviewed = "ai_suggestion.reviewed"
+	TypeFacultyDashboardEdit = "faculty_dashboard.edited"
+	TypeLevelUp              = "user.leveled_up"
+)
+
+type QuestCompleted struct {
+	UserID      int       `bson:"user_id" json:"userId"`
+	QuestID     int       `bson:"quest_id" json:"questId"`
+	Coins       int       `bson:"coins" json:"coins"`
+	CompletedAt time.Time `bson:"completed_at" json:"completedAt"`
+}
+
+type VoteCast struct {
+	UserID      int       `bson:"user_id" json:"userId"`
+	PollID      int       `bson:"poll_id" json:"pollId"`
+	OptionIndex int       `bson:"option_index" json:"optionIndex"`
+	VotedAt     time.Time `bson:"voted_at" json:"votedAt"`
+}
+
+type ResearchPostCreated struct {
+	PostID    primitive.ObjectID `bson:"post_id" json:"postId"`
+	AuthorID  int                `bson:"author_id" json:"authorId"`
+	Title     string             `bson:"title" json:"title"`
+	Category  string             `bson:"category" json:"category"`
+	Tags      []string           `bson:"tags" json:"tags"`
+	CreatedAt time.Time          `bson:"created_at" json:"createdAt"`
+}
+
+type MenteeAdded struct {
+	FacultyID int                `bson:"faculty_id" json:"facultyId"`
+	MenteeID  primitive.ObjectID `bson:"mentee_id" json:"menteeId"`
+	Name      string             `bson:"name" json:"name"`
+	Status    string             `bson:"status" json:"status"`
+	AddedBy   int                `bson:"added_by" json:"addedBy"`
+	AddedAt   time.Time          `bson:"added_at" json:"addedAt"`
+}
+
+type AISuggestionReviewed struct {
+	FacultyID    int                `bson:"faculty_id" json:"facultyId"`
+	SuggestionID primitive.ObjectID `bson:"suggestion_id" json:"suggestionId"`
+	Status       string             `bson:"status" json:"status"`
+	ReviewedBy   int                `bson:"reviewed_by" json:"reviewedBy"`
+	ReviewedAt   time.Time          `bson:"reviewed_at" json:"reviewedAt"`
+}
+
+// FacultyDashboardEdited covers course and mentee edits that have no dedicated event.
+// Section is "courses" or "mentorship".
+type FacultyDashboardEdited struct {
+	FacultyID int                `bson:"faculty_id" json:"facultyId"`
+	Section   string             `bson:"section" json:"section"`
+	ItemID    primitive.ObjectID `bson:"item_id" json:"itemId"`
+	ActorID   int                `bson:"actor_id" json:"actorId"`
+	EditedAt  time.Time          `bson:"edited_at" json:"editedAt"`
+}
+
+// LevelUp is raised when an XP award takes a user from level From to level To. Coins is the
+// level reward paid with it.
+type LevelUp struct {
+	UserID int       `bson:"user_id" json:"userId"`
+	From   int       `bson:"from" json:"from"`
+	To     int       `bson:"to" json:"to"`
+	XP     int       `bson:"xp" json:"xp"`
+	Coins  int       `bson:"coins" json:"coins"`
+	At     time.Time `bson:"at" json:"at"`
+}
+
+func (QuestCompleted) Type() string         { return TypeQuestCompleted }
+func (VoteCast) Type() string               { return TypeVoteCast }
+func (ResearchPostCreated) Type() string    { return TypeResearchPostCreated }
+func (MenteeAdded) Type() string            { return TypeMenteeAdded }
+func (AISuggestionReviewed) Type() string   { return TypeAISuggestionReviewed }
+func (FacultyDashboardEdited) Type() string { return TypeFacultyDashboardEdit }
+func (LevelUp) Type() string                { return TypeLevelUp }
+
+// registry maps stored type names back to their Go types for decoding.
+var registry = map[string]func() Event{
+	TypeQuestCompleted:       func() Event { return &QuestCompleted{} },
+	TypeVoteCast:             func() Event { return &VoteCast{} },
+	TypeResearchPostCreated:  func() Event { return &ResearchPostCreated{} },
+	TypeMenteeAdded:          func() Event { return &MenteeAdded{} },
+	TypeAISuggestionReviewed: func() Event { return &AISuggestionReviewed{} },
+	TypeFacultyDashboardEdit: func() Event { return &FacultyDashboardEdited{} },
+	TypeLevelUp:              func() Event { return &LevelUp{} },
+}
+
+// decode rebuilds the typed event stored under eventType. The returned value is the
+// event struct itself (not a pointer), matching what was recorded.
+func decode(eventType string, payload bson.Raw) (Event, error) {
+	factory, ok := registry[eventType]
+	if !ok {
+		return nil, fmt.Errorf("unknown event type %q", eventType)
+	}
+	ptr := factory()
+	if err := bson.Unmarshal(payload, ptr); err != nil {
+		return nil, fmt.Errorf("decode %s: %w", eventType, err)
+	}
+	return reflect.ValueOf(ptr).Elem().Interface().(Event), nil
+}
+
+// Envelope is what subscribers receive: the decoded event plus its outbox identity.
+type Envelope struct {
+	ID         primitive.ObjectID
+	Tenant     string
+	OccurredAt time.Time
+	Event      Event
+}
@@ -0,0 +1,112 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/tenant"
+)
+
+// Outbox record statuses.
+const (
+	StatusPending    = "pending"
+	StatusProcessing = "processing"
+	StatusDispatched = "dispatched"
+	StatusFailed     = "failed"
+)
+
+// Record is one stored event in the event_outbox collection. Done lists the subscribers
+// that already handled it, so a retry only re-runs the ones that failed.
+type Record struct {
+	ID            primitive.ObjectID `bson:"_id"`
+	Type          string             `bson:"type"`
+	Tenant        string             `bson:"tenant_id,omitempty"`
+	Payload       bson.Raw           `bson:"payload"`
+	OccurredAt    time.Time          `bson:"occurred_at"`
+	Status        string             `bson:"status"`
+	Attempts      int                `bson:"attempts"`
+	Done          []string           `bson:"done"`
+	NextAttemptAt time.Time          `bson:"next_attempt_at"`
+	LastError     string             `bson:"last_error,omitempty"`
+	DispatchedAt  *time.Time         `bson:"dispatched_at,omitempty"`
+}
+
+// Outbox stores events alongside the state changes that produced them.
+type Outbox struct {
+	Col *mongo.Collection
+}
+
+// EnsureIndexes creates the index the dispatcher polls on.
+func (o *Outbox) EnsureIndexes(ctx context.Context) error {
+	_, err := o.Col.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.D{{Key: "status", Value: 1}, {Key: "next_attempt_at", Value: 1}}})
+	return err
+}
+
+// Record inserts evs into the outbox, tagged with the tenant in ctx. Pass the transaction's
+// session context so the events commit or roll back together with the change they describe.
+func (o *Outbox) Record(ctx context.Context, evs ...Event) error {
+	if len(evs) == 0 {
+		return nil
+	}
+	now := time.Now().UTC()
+	tenantID, _ := tenant.ID(ctx)
+	docs := make([]interface{}, 0, len(evs))
+	for _, ev := range evs {
+		payload, err := bson.Marshal(ev)
+		if err != nil {
+			return fmt.Errorf("encode %s: %w", ev.Type(), err)
+		}
+		docs = append(docs, Record{ID: primitive.NewObjectID(), Type: ev.Type(), Tenant: tenantID, Payload: payload, OccurredAt: now, Status: StatusPending, Done: []string{}, NextAttemptAt: now})
+	}
+	_, err := o.Col.InsertMany(ctx, docs)
+	return err
+}
+
+// claim atomically takes the oldest due event. Events stuck in processing past their
+// lease (a crashed dispatcher) become claimable again.
+func (o *Outbox) claim(ctx context.Context, lease time.Duration) (*Record, error) {
+	now := time.Now().UTC()
+	filter := bson.M{"status": bson.M{"$in": bson.A{StatusPending, StatusProcessing}}, "next_attempt_at": bson.M{"$lte": now}}
+	update := bson.M{"$set": bson.M{"status": StatusProcessing, "next_attempt_at": now.Add(lease)}}
+	opts := options.FindOneAndUpdate().SetSort(bson.D{{Key: "next_attempt_at", Value: 1}, {Key: "_id", Value: 1}}).SetReturnDocument(options.After)
+	var rec Record
+	err := o.Col.FindOneAndUpdate(ctx, filter, update, opts).Decode(&rec)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
+
+func (o *Outbox) finish(ctx context.Context, rec *Record, done []string) error {
+	now := time.Now().UTC()
+	_, err := o.Col.UpdateOne(ctx, bson.M{"_id": rec.ID}, bson.M{
+		"$set":      bson.M{"status": StatusDispatched, "dispatched_at": now},
+		"$unset":    bson.M{"last_error": ""},
+		"$addToSet": bson.M{"done": bson.M{"$each": done}},
+		"$inc":      bson.M{"attempts": 1},
+	})
+	return err
+}
+
+func (o *Outbox) retry(ctx context.Context, rec *Record, done []string, cause error, next time.Time, failed bool) error {
+	status := StatusPending
+	if failed {
+		status = StatusFailed
+	}
+	_, err := o.Col.UpdateOne(ctx, bson.M{"_id": rec.ID}, bson.M{
+		"$set":      bson.M{"status": status, "last_error": cause.Error(), "next_attempt_at": next},
+		"$addToSet": bson.M{"done": bson.M{"$each": done}},
+		"$inc":      bson.M{"attempts": 1},
+	})
+	return err
+}
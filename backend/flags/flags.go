@@ -0,0 +1,249 @@
+// Package flags gates features behind platform-wide feature flags. A flag can be switched
+// on for everyone, for chosen users, or for a stable percentage of users per role, and can
+// be limited to some tenants. Handlers ask Store.Enabled; clients read their evaluated
+// flags from GET /me/flags.
+package flags
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"regexp"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/middleware"
+	"backend/tenant"
+)
+
+const cacheTTL = 30 * time.Second
+
+var (
+	ErrNotFound = errors.New("flag not found")
+	ErrExists   = errors.New("flag already exists")
+)
+
+// KeyPattern is what a flag key must look like, e.g. "ai_advisor" or "quests.riddles".
+var KeyPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{0,63}$`)
+
+// Flag is one feature flag. A disabled flag is off for everyone. Otherwise it is on for
+// the listed Users, and for anyone else the first Rule matching their role decides; with
+// no matching rule it is off. Tenants, when set, limit the flag to those campuses.
+type Flag struct {
+	Key         string    `bson:"_id" json:"key"`
+	Description string    `bson:"description" json:"description"`
+	Enabled     bool      `bson:"enabled" json:"enabled"`
+	Tenants     []string  `bson:"tenants" json:"tenants"`
+	Users       []int     `bson:"users" json:"users"`
+	Rules       []Rule    `bson:"rules" json:"rules"`
+	UpdatedBy   int       `bson:"updated_by" json:"updatedBy"`
+	CreatedAt   time.Time `bson:"created_at" json:"createdAt"`
+	UpdatedAt   time.Time `bson:"updated_at" json:"updatedAt"`
+}
+
+// Rule rolls a flag out to Percentage (0-100) of the users with one of Roles, or of all
+// users when Roles is empty. Users keep their bucket as the percentage grows, so raising
+// it only adds users.
+type Rule struct {
+	Roles      []string `bson:"roles" json:"roles"`
+	Percentage int      `bson:"percentage" json:"percentage"`
+}
+
+// FlagList is the admin listing.
+type FlagList struct {
+	Items []Flag `json:"items"`
+}
+
+// Evaluation is the flag state for one user.
+type Evaluation struct {
+	Flags map[string]bool `json:"flags"`
+}
+
+// Subject is who a flag is evaluated for.
+type Subject struct {
+	UserID int
+	Role   string
+	Tenant string
+}
+
+// SubjectFrom reads the caller and tenant from a request context.
+func SubjectFrom(ctx context.Context) Subject {
+	userID, _ := middleware.UserIDFromContext(ctx)
+	tenantID, _ := tenant.ID(ctx)
+	return Subject{UserID: userID, Role: middleware.RoleFromContext(ctx), Tenant: tenantID}
+}
+
+// On reports whether f is on for s.
+func (f Flag) On(s Subject) bool {
+	if !f.Enabled {
+		return false
+	}
+	if len(f.Tenants) > 0 && !contains(f.Tenants, s.Tenant) {
+		return false
+	}
+	for _, id := range f.Users {
+		if id == s.UserID && s.UserID != 0 {
+			return true
+		}
+	}
+	for _, rule := range f.Rules {
+		if len(rule.Roles) == 0 || contains(rule.Roles, s.Role) {
+			return bucket(f.Key, s.UserID) < rule.Percentage
+		}
+	}
+	return false
+}
+
+// bucket places a user in 0-99 for key. Hashing the key too spreads different flags'
+// rollouts over different users.
+func bucket(key string, userID int) int {
+	h := fnv.New32a()
+	h.Write([]byte(key + ":" + strconv.Itoa(userID)))
+	return int(h.Sum32() % 100)
+}
+
+func contains(list []string, v string) bool {
+	for _, item := range list {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
+
+// Store keeps flags in Mongo and serves evaluations from a cache refreshed at most every
+// 30 seconds, so a change reaches other instances within that time.
+type Store struct {
+	Col *mongo.Collection
+
+	mu       sync.RWMutex
+	byKey    map[string]Flag
+	loadedAt time.Time
+}
+
+// NewStore returns a store over col.
+func NewStore(col *mongo.Collection) *Store {
+	return &Store{Col: col}
+}
+
+func (s *Store) invalidate() {
+	s.mu.Lock()
+	s.loadedAt = time.Time{}
+	s.mu.Unlock()
+}
+
+func (s *Store) load(ctx context.Context) error {
+	s.mu.RLock()
+	fresh := time.Since(s.loadedAt) < cacheTTL
+	s.mu.RUnlock()
+	if fresh {
+		return nil
+	}
+	cursor, err := s.Col.Find(ctx, bson.M{})
+	if err != nil {
+		return err
+	}
+	var all []Flag
+	if err := cursor.All(ctx, &all); err != nil {
+		return err
+	}
+	byKey := make(map[string]Flag, len(all))
+	for _, f := range all {
+		byKey[f.Key] = f
+	}
+	s.mu.Lock()
+	s.byKey, s.loadedAt = byKey, time.Now()
+	s.mu.Unlock()
+	return nil
+}
+
+// Enabled reports whether the flag key is on for the caller in ctx. Unknown flags, and
+// every flag while the store cannot be read, are off.
+func (s *Store) Enabled(ctx context.Context, key string) bool {
+	if s == nil || s.load(ctx) != nil {
+		return false
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	f, ok := s.byKey[key]
+	return ok && f.On(SubjectFrom(ctx))
+}
+
+// Evaluate returns every flag's state for sub.
+func (s *Store) Evaluate(ctx context.Context, sub Subject) (map[string]bool, error) {
+	if err := s.load(ctx); err != nil {
+		return nil, err
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make(map[string]bool, len(s.byKey))
+	for key, f := range s.byKey {
+		out[key] = f.On(sub)
+	}
+	return out, nil
+}
+
+// List returns every flag, read from Mongo rather than the cache.
+func (s *Store) List(ctx context.Context) ([]Flag, error) {
+	cursor, err := s.Col.Find(ctx, bson.M{}, options.Find().SetSort(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	out := []Flag{}
+	if err := cursor.All(ctx, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Get returns the flag with key.
+func (s *Store) Get(ctx context.Context, key string) (Flag, error) {
+	var f Flag
+	err := s.Col.FindOne(ctx, bson.M{"_id": key}).Decode(&f)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return f, ErrNotFound
+	}
+	return f, err
+}
+
+// Create stores a new flag.
+func (s *Store) Create(ctx context.Context, f Flag) (Flag, error) {
+	now := time.Now().UTC()
+	f.CreatedAt, f.UpdatedAt = now, now
+	_, err := s.Col.InsertOne(ctx, f)
+	if mongo.IsDuplicateKeyError(err) {
+		return f, ErrExists
+	}
+	s.invalidate()
+	return f, err
+}
+
+// Save replaces an existing flag.
+func (s *Store) Save(ctx context.Context, f Flag) (Flag, error) {
+	f.UpdatedAt = time.Now().UTC()
+	res, err := s.Col.ReplaceOne(ctx, bson.M{"_id": f.Key}, f)
+	if err != nil {
+		return f, err
+	}
+	if res.MatchedCount == 0 {
+		return f, ErrNotFound
+	}
+	s.invalidate()
+	return f, nil
+}
+
+// Delete removes a flag and returns it as it was.
+func (s *Store) Delete(ctx context.Context, key string) (Flag, error) {
+	var f Flag
+	err := s.Col.FindOneAndDelete(ctx, bson.M{"_id": key}).Decode(&f)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return f, ErrNotFound
+	}
+	s.invalidate()
+	return f, err
+}
@@ -0,0 +1,107 @@
+package flags
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRolloutOnlyAddsUsersAsPercentageRises(t *testing.T) {
+	const users = 2000
+	f := Flag{Key: "ai_advisor", Enabled: true, Rules: []Rule{{}}}
+	prev := map[int]bool{}
+	for pct := 0; pct <= 100; pct++ {
+		f.Rules[0].Percentage = pct
+		on := map[int]bool{}
+		for id := 1; id <= users; id++ {
+			if f.On(Subject{UserID: id, Role: "student"}) {
+				on[id] = true
+			}
+		}
+		for id := range prev {
+			if !on[id] {
+				t.Fatalf("user %d lost the flag when the rollout rose to %d%%", id, pct)
+			}
+		}
+		// The buckets should be close to uniform.
+		if want := users * pct / 100; len(on) < want-users/20 || len(on) > want+users/20 {
+			t.Errorf("%d%% rollout reached %d of %d users", pct, len(on), users)
+		}
+		prev = on
+	}
+	if len(prev) != users {
+		t.Errorf("100%% rollout reached %d of %d users", len(prev), users)
+	}
+}
+
+func TestBucketDependsOnKey(t *testing.T) {
+	same := 0
+	for id := 1; id <= 1000; id++ {
+		if bucket("ai_advisor", id) == bucket("quests.riddles", id) {
+			same++
+		}
+	}
+	if same > 50 {
+		t.Errorf("%d of 1000 users share a bucket across two flags", same)
+	}
+	if bucket("ai_advisor", 42) != bucket("ai_advisor", 42) {
+		t.Error("bucket is not stable")
+	}
+}
+
+func TestFlagOn(t *testing.T) {
+	student := Subject{UserID: 7, Role: "student", Tenant: "default"}
+	faculty := Subject{UserID: 7, Role: "faculty", Tenant: "default"}
+	tests := []struct {
+		name string
+		flag Flag
+		sub  Subject
+		want bool
+	}{
+		{"disabled", Flag{Rules: []Rule{{Percentage: 100}}}, student, false},
+		{"disabled ignores users", Flag{Users: []int{7}}, student, false},
+		{"no rules", Flag{Enabled: true}, student, false},
+		{"rule for everyone", Flag{Enabled: true, Rules: []Rule{{Percentage: 100}}}, student, true},
+		{"zero percent", Flag{Enabled: true, Rules: []Rule{{Percentage: 0}}}, student, false},
+		{"role targeted", Flag{Enabled: true, Rules: []Rule{{Roles: []string{"faculty"}, Percentage: 100}}}, faculty, true},
+		{"other role", Flag{Enabled: true, Rules: []Rule{{Roles: []string{"faculty"}, Percentage: 100}}}, student, false},
+		{"first matching rule decides", Flag{Enabled: true, Rules: []Rule{{Roles: []string{"student"}, Percentage: 0}, {Percentage: 100}}}, student, false},
+		{"later rule for other roles", Flag{Enabled: true, Rules: []Rule{{Roles: []string{"student"}, Percentage: 0}, {Percentage: 100}}}, faculty, true},
+		{"listed user", Flag{Enabled: true, Users: []int{3, 7}}, student, true},
+		{"listed user beats rules", Flag{Enabled: true, Users: []int{7}, Rules: []Rule{{Percentage: 0}}}, student, true},
+		{"unlisted user", Flag{Enabled: true, Users: []int{3}}, student, false},
+		{"anonymous never listed", Flag{Enabled: true, Users: []int{0}}, Subject{Tenant: "default"}, false},
+		{"tenant allowed", Flag{Enabled: true, Tenants: []string{"default"}, Rules: []Rule{{Percentage: 100}}}, student, true},
+		{"tenant excluded", Flag{Enabled: true, Tenants: []string{"other"}, Rules: []Rule{{Percentage: 100}}}, student, false},
+		{"tenant excludes listed users", Flag{Enabled: true, Tenants: []string{"other"}, Users: []int{7}}, student, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.flag.Key = "ai_advisor"
+			if got := tt.flag.On(tt.sub); got != tt.want {
+				t.Errorf("On = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvaluateUsesCache(t *testing.T) {
+	s := NewStore(nil)
+	s.byKey = map[string]Flag{
+		"on":  {Key: "on", Enabled: true, Rules: []Rule{{Percentage: 100}}},
+		"off": {Key: "off", Enabled: true, Rules: []Rule{{Roles: []string{"admin"}, Percentage: 100}}},
+	}
+	s.loadedAt = time.Now()
+	got, err := s.Evaluate(context.Background(), Subject{UserID: 1, Role: "student"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := map[string]bool{"on": true, "off": false}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Evaluate = %v, want %v", got, want)
+	}
+	var nilStore *Store
+	if nilStore.Enabled(context.Background(), "on") {
+		t.Error("a nil store enabled a flag")
+	}
+}
@@ -0,0 +1,464 @@
+// Package account serves data-subject requests: a ZIP export of everything tied to the
+// signed-in user, and account deletion with a grace period during which it can be cancelled.
+package account
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/handlers/common"
+	"backend/mailer"
+	"backend/models"
+)
+
+type exportManifest struct {
+	GeneratedAt time.Time `json:"generatedAt"`
+	UserID      int       `json:"userId"`
+	Campus      string    `json:"campus"`
+	Files       []string  `json:"files"`
+}
+
+type exportProfile struct {
+	models.PublicUser
+	Campus               string     `json:"campus"`
+	DeletionRequestedAt  *time.Time `json:"deletionRequestedAt,omitempty"`
+	DeletionScheduledFor *time.Time `json:"deletionScheduledFor,omitempty"`
+}
+
+type exportCompletion struct {
+	QuestID     int        `json:"questId"`
+	Title       string     `json:"title"`
+	Completed   bool       `json:"completed"`
+	CompletedAt *time.Time `json:"completedAt,omitempty"`
+}
+
+type exportCoin struct {
+	Coins   int       `json:"coins" bson:"coins"`
+	Reason  string    `json:"reason" bson:"reason"`
+	QuestID int       `json:"questId,omitempty" bson:"quest_id"`
+	At      time.Time `json:"at" bson:"at"`
+}
+
+type exportVote struct {
+	PollID      int        `json:"pollId"`
+	Question    string     `json:"question"`
+	OptionIndex int        `json:"optionIndex"`
+	Option      string     `json:"option"`
+	VotedAt     *time.Time `json:"votedAt,omitempty"`
+}
+
+type exportPost struct {
+	ID              string    `json:"id"`
+	Title           string    `json:"title"`
+	Summary         string    `json:"summary"`
+	Body            string    `json:"body,omitempty"`
+	Category        string    `json:"category"`
+	Tags            []string  `json:"tags"`
+	Image           string    `json:"image,omitempty"`
+	Link            string    `json:"link,omitempty"`
+	Likes           int       `json:"likes"`
+	Comments        int       `json:"comments"`
+	Collaborations  int       `json:"collaborations"`
+	IsCollaboration bool      `json:"isCollaboration"`
+	CreatedAt       time.Time `json:"createdAt"`
+	UpdatedAt       time.Time `json:"updatedAt"`
+}
+
+type exportMentee struct {
+	FacultyID   int       `json:"facultyId"`
+	RecordID    string    `json:"recordId"`
+	Name        string    `json:"name"`
+	Status      string    `json:"status"`
+	NextSession string    `json:"nextSession,omitempty"`
+	Note        string    `json:"note,omitempty"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+type menteeDoc struct {
+	ID          primitive.ObjectID `bson:"_id"`
+	StudentID   int                `bson:"student_id,omitempty"`
+	Name        string             `bson:"name"`
+	Status      string             `bson:"status"`
+	NextSession string             `bson:"next_session,omitempty"`
+	Note        string             `bson:"note,omitempty"`
+	CreatedAt   time.Time          `bson:"created_at"`
+	UpdatedAt   time.Time          `bson:"updated_at"`
+}
+
+type dashboardMentees struct {
+	FacultyID  int `bson:"faculty_id"`
+	Mentorship struct {
+		Mentees []menteeDoc `bson:"mentees"`
+	} `bson:"mentorship"`
+}
+
+// ExportData streams a ZIP of JSON files with the profile, quest completions and answers,
+// coin and XP history, votes, research posts and mentee records of the signed-in user, plus
+// their own dashboard for faculty.
+func ExportData(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+	campus := common.TenantConfig(ctx)
+	files := map[string]interface{}{}
+	var names []string
+	add := func(name string, v interface{}) { files[name] = v; names = append(names, name) }
+
+	add("profile.json", exportProfile{PublicUser: common.SanitizeUser(&user), Campus: campus.ID, DeletionRequestedAt: user.DeletionRequestedAt, DeletionScheduledFor: user.DeletionScheduledFor})
+	completions, err := exportCompletions(ctx, user.UserID)
+	if err != nil {
+		writeExportError(w, "quest completions", err)
+		return
+	}
+	add("quest_completions.json", completions)
+	var coins []exportCoin
+	cursor, err := common.CoinLedgerCol.Find(ctx, bson.M{"user_id": user.UserID}, options.Find().SetSort(bson.M{"at": 1}))
+	if err == nil {
+		err = cursor.All(ctx, &coins)
+	}
+	if err != nil {
+		writeExportError(w, "coin history", err)
+		return
+	}
+	add("coin_history.json", coins)
+	xp := []models.XPEntry{}
+	cursor, err = common.XPLedgerCol.Find(ctx, bson.M{"user_id": user.UserID}, options.Find().SetSort(bson.M{"at": 1}))
+	if err == nil {
+		err = cursor.All(ctx, &xp)
+	}
+	if err != nil {
+		writeExportError(w, "xp history", err)
+		return
+	}
+	add("xp_history.json", xp)
+	attempts := []models.QuestAttempt{}
+	cursor, err = common.QuestAttemptsCol.Find(ctx, bson.M{"user_id": user.UserID}, options.Find().SetSort(bson.D{{Key: "at", Value: 1}, {Key: "number", Value: 1}}))
+	if err == nil {
+		err = cursor.All(ctx, &attempts)
+	}
+	if err != nil {
+		writeExportError(w, "quest attempts", err)
+		return
+	}
+	add("quest_attempts.json", attempts)
+	assignments := []models.QuestAssignment{}
+	cursor, err = common.QuestAssignmentsCol.Find(ctx, bson.M{"user_id": user.UserID}, options.Find().SetSort(bson.D{{Key: "starts_at", Value: 1}, {Key: "cadence", Value: 1}}))
+	if err == nil {
+		err = cursor.All(ctx, &assignments)
+	}
+	if err != nil {
+		writeExportError(w, "quest assignments", err)
+		return
+	}
+	add("quest_assignments.json", assignments)
+	votes, err := exportVotes(ctx, user.UserID)
+	if err != nil {
+		writeExportError(w, "votes", err)
+		return
+	}
+	add("votes.json", votes)
+	posts, err := exportPosts(ctx, user.UserID)
+	if err != nil {
+		writeExportError(w, "research posts", err)
+		return
+	}
+	add("research_posts.json", posts)
+	mentees, err := exportMentees(ctx, user)
+	if err != nil {
+		writeExportError(w, "mentee records", err)
+		return
+	}
+	add("mentee_records.json", mentees)
+	if user.Role == common.RoleFaculty {
+		raw, err := common.FacultyDashboardsCol.FindOne(ctx, bson.M{"faculty_id": user.UserID}).Raw()
+		if err == nil {
+			ext, err := bson.MarshalExtJSON(raw, false, false)
+			if err != nil {
+				writeExportError(w, "faculty dashboard", err)
+				return
+			}
+			add("faculty_dashboard.json", json.RawMessage(ext))
+		}
+	}
+
+	now := time.Now().UTC()
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s-export-%d-%s.zip"`, campus.ID, user.UserID, now.Format("20060102")))
+	w.WriteHeader(http.StatusOK)
+	zw := zip.NewWriter(w)
+	if err := writeEntry(zw, "manifest.json", now, exportManifest{GeneratedAt: now, UserID: user.UserID, Campus: campus.ID, Files: names}); err != nil {
+		log.Printf("account export %d: %v", user.UserID, err)
+		return
+	}
+	for _, name := range names {
+		if err := writeEntry(zw, name, now, files[name]); err != nil {
+			log.Printf("account export %d: %v", user.UserID, err)
+			return
+		}
+	}
+	if err := zw.Close(); err != nil {
+		log.Printf("account export %d: %v", user.UserID, err)
+	}
+}
+
+func writeEntry(zw *zip.Writer, name string, modified time.Time, v interface{}) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	var indented bytes.Buffer
+	if err := json.Indent(&indented, body, "", "  "); err != nil {
+		return fmt.Errorf("%s: %w", name, err)
+	}
+	f, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: modified})
+	if err != nil {
+		return err
+	}
+	_, err = indented.WriteTo(f)
+	return err
+}
+
+func writeExportError(w http.ResponseWriter, what string, err error) {
+	log.Printf("account export: load %s: %v", what, err)
+	common.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to export " + what})
+}
+
+func exportCompletions(ctx context.Context, userID int) ([]exportCompletion, error) {
+	var done []struct {
+		QuestID     int        `bson:"quest_id"`
+		Completed   bool       `bson:"completed"`
+		CompletedAt *time.Time `bson:"completed_at"`
+	}
+	cursor, err := common.UserQuestsCol.Find(ctx, bson.M{"user_id": userID})
+	if err != nil {
+		return nil, err
+	}
+	if err := cursor.All(ctx, &done); err != nil {
+		return nil, err
+	}
+	titles := map[int]string{}
+	questCursor, err := common.QuestsCol.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	var quests []models.Quest
+	if err := questCursor.All(ctx, &quests); err != nil {
+		return nil, err
+	}
+	for _, q := range quests {
+		titles[q.QuestID] = q.Title
+	}
+	out := make([]exportCompletion, 0, len(done))
+	for _, d := range done {
+		out = append(out, exportCompletion{QuestID: d.QuestID, Title: titles[d.QuestID], Completed: d.Completed, CompletedAt: d.CompletedAt})
+	}
+	return out, nil
+}
+
+func exportVotes(ctx context.Context, userID int) ([]exportVote, error) {
+	var votes []struct {
+		PollID      int        `bson:"poll_id"`
+		OptionIndex int        `bson:"option_index"`
+		VotedAt     *time.Time `bson:"voted_at"`
+	}
+	cursor, err := common.VotesCol.Find(ctx, bson.M{"user_id": userID})
+	if err != nil {
+		return nil, err
+	}
+	if err := cursor.All(ctx, &votes); err != nil {
+		return nil, err
+	}
+	out := make([]exportVote, 0, len(votes))
+	for _, v := range votes {
+		entry := exportVote{PollID: v.PollID, OptionIndex: v.OptionIndex, VotedAt: v.VotedAt}
+		var poll models.Poll
+		if err := common.PollsCol.FindOne(ctx, bson.M{"poll_id": v.PollID}).Decode(&poll); err == nil {
+			entry.Question = poll.Question
+			if v.OptionIndex >= 0 && v.OptionIndex < len(poll.Options) {
+				entry.Option = poll.Options[v.OptionIndex].Text
+			}
+		}
+		out = append(out, entry)
+	}
+	return out, nil
+}
+
+func exportPosts(ctx context.Context, userID int) ([]exportPost, error) {
+	cursor, err := common.ResearchPostsCol.Find(ctx, bson.M{"author_id": userID})
+	if err != nil {
+		return nil, err
+	}
+	var posts []models.ResearchPost
+	if err := cursor.All(ctx, &posts); err != nil {
+		return nil, err
+	}
+	out := make([]exportPost, 0, len(posts))
+	for _, p := range posts {
+		tags := p.Tags
+		if tags == nil {
+			tags = []string{}
+		}
+		out = append(out, exportPost{ID: p.ID.Hex(), Title: p.Title, Summary: p.Summary, Body: p.Body, Category: p.Category, Tags: tags, Image: p.ImageURL, Link: p.Link, Likes: p.Likes, Comments: p.Comments, Collaborations: p.Collaborations, IsCollaboration: p.IsCollaboration, CreatedAt: p.CreatedAt, UpdatedAt: p.UpdatedAt})
+	}
+	return out, nil
+}
+
+func exportMentees(ctx context.Context, user models.User) ([]exportMentee, error) {
+	match, err := newMenteeMatcher(ctx, user)
+	if err != nil {
+		return nil, err
+	}
+	cursor, err := common.FacultyDashboardsCol.Find(ctx, bson.M{"mentorship.mentees": bson.M{"$elemMatch": match.filter("")}})
+	if err != nil {
+		return nil, err
+	}
+	var dashboards []dashboardMentees
+	if err := cursor.All(ctx, &dashboards); err != nil {
+		return nil, err
+	}
+	out := []exportMentee{}
+	for _, d := range dashboards {
+		for _, m := range d.Mentorship.Mentees {
+			if match.matches(m) {
+				out = append(out, exportMentee{FacultyID: d.FacultyID, RecordID: m.ID.Hex(), Name: m.Name, Status: m.Status, NextSession: m.NextSession, Note: m.Note, CreatedAt: m.CreatedAt, UpdatedAt: m.UpdatedAt})
+			}
+		}
+	}
+	return out, nil
+}
+
+// menteeMatcher finds the faculty mentee records about a user. Records are linked by
+// student_id; older records only carry a name, so those match by name as long as no other
+// user on the campus shares it.
+type menteeMatcher struct {
+	userID int
+	name   string
+}
+
+func newMenteeMatcher(ctx context.Context, user models.User) (menteeMatcher, error) {
+	m := menteeMatcher{userID: user.UserID}
+	name := strings.TrimSpace(user.Name)
+	if name == "" {
+		return m, nil
+	}
+	namesakes, err := common.UsersCol.CountDocuments(ctx, bson.M{"name": common.CaseInsensitive(name)})
+	if err != nil {
+		return m, err
+	}
+	if namesakes <= 1 {
+		m.name = name
+	}
+	return m, nil
+}
+
+// filter matches a mentee record; prefix is the array filter identifier ("m.") or empty
+// inside $elemMatch.
+func (m menteeMatcher) filter(prefix string) bson.M {
+	linked := bson.M{prefix + "student_id": m.userID}
+	if m.name == "" {
+		return linked
+	}
+	return bson.M{"$or": bson.A{linked, bson.M{prefix + "student_id": bson.M{"$exists": false}, prefix + "name": common.CaseInsensitive(m.name)}}}
+}
+
+func (m menteeMatcher) matches(doc menteeDoc) bool {
+	if doc.StudentID != 0 {
+		return doc.StudentID == m.userID
+	}
+	return m.name != "" && strings.EqualFold(strings.TrimSpace(doc.Name), m.name)
+}
+
+// GetDeletion reports whether the signed-in user's account is scheduled for deletion.
+func GetDeletion(w http.ResponseWriter, r *http.Request) {
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+	common.WriteJSON(w, http.StatusOK, deletionStatus(user))
+}
+
+// RequestDeletion schedules the signed-in user's account for deletion once the grace
+// period ends. Signing in keeps working until then so the request can be cancelled.
+func RequestDeletion(w http.ResponseWriter, r *http.Request) {
+	var req models.RequestAccountDeletionRequest
+	if !common.BindJSON(w, r, &req) {
+		return
+	}
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+	if err := common.VerifyPassword(user.PasswordHash, req.Password); err != nil {
+		common.WriteJSON(w, http.StatusForbidden, map[string]string{"error": "password is incorrect"})
+		return
+	}
+	if user.DeletionScheduledFor != nil {
+		common.WriteJSON(w, http.StatusAccepted, deletionStatus(user))
+		return
+	}
+	ctx := r.Context()
+	now := time.Now().UTC()
+	scheduled := now.Add(common.DeletionGrace)
+	if _, err := common.UsersCol.UpdateOne(ctx, bson.M{"user_id": user.UserID}, bson.M{"$set": bson.M{"deletion_requested_at": now, "deletion_scheduled_for": scheduled}}); err != nil {
+		common.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to schedule deletion"})
+		return
+	}
+	user.DeletionRequestedAt, user.DeletionScheduledFor = &now, &scheduled
+	if user.Email != "" {
+		campus := common.TenantConfig(ctx).Branding.DisplayName
+		body := fmt.Sprintf("Hi %s,\n\nYour %s account is scheduled for deletion on %s. Sign in and cancel the request before then if you change your mind.\n", user.Name, campus, scheduled.Format("2 January 2006"))
+		if err := common.Mailer.Send(ctx, mailer.Message{To: user.Email, Subject: "Your " + campus + " account will be deleted", Body: body}); err != nil {
+			log.Printf("account deletion notice %d: %v", user.UserID, err)
+		}
+	}
+	common.WriteJSON(w, http.StatusAccepted, deletionStatus(user))
+}
+
+// CancelDeletion withdraws a pending deletion request.
+func CancelDeletion(w http.ResponseWriter, r *http.Request) {
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+	if _, err := common.UsersCol.UpdateOne(r.Context(), bson.M{"user_id": user.UserID}, bson.M{"$unset": bson.M{"deletion_requested_at": "", "deletion_scheduled_for": ""}}); err != nil {
+		common.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to cancel deletion"})
+		return
+	}
+	user.DeletionRequestedAt, user.DeletionScheduledFor = nil, nil
+	common.WriteJSON(w, http.StatusOK, deletionStatus(user))
+}
+
+func deletionStatus(user models.User) models.AccountDeletionResponse {
+	if user.DeletionScheduledFor == nil {
+		return models.AccountDeletionResponse{Status: "none"}
+	}
+	return models.AccountDeletionResponse{Status: "scheduled", RequestedAt: user.DeletionRequestedAt, ScheduledFor: user.DeletionScheduledFor}
+}
+
+func currentUser(w http.ResponseWriter, r *http.Request) (models.User, bool) {
+	var user models.User
+	userID, ok := common.UserIDFromContext(r.Context())
+	if !ok {
+		common.WriteJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
+		return user, false
+	}
+	if err := common.UsersCol.FindOne(r.Context(), bson.M{"user_id": userID}).Decode(&user); err != nil {
+		common.WriteJSON(w, http.StatusNotFound, map[string]string{"error": "user not found"})
+		return user, false
+	}
+	return user, true
+}
@@ -0,0 +1,91 @@
+package account
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"backend/handlers/common"
+	"backend/models"
+)
+
+func TestMenteeMatcher(t *testing.T) {
+	named := menteeMatcher{userID: 7, name: "Ada Lovelace"}
+	unnamed := menteeMatcher{userID: 7}
+	tests := []struct {
+		name    string
+		matcher menteeMatcher
+		doc     menteeDoc
+		want    bool
+	}{
+		{"linked", named, menteeDoc{StudentID: 7, Name: "Someone else"}, true},
+		{"linked to another student", named, menteeDoc{StudentID: 8, Name: "Ada Lovelace"}, false},
+		{"legacy record by name", named, menteeDoc{Name: " ada lovelace "}, true},
+		{"legacy record with another name", named, menteeDoc{Name: "Grace Hopper"}, false},
+		{"legacy record when the name is shared", unnamed, menteeDoc{Name: "Ada Lovelace"}, false},
+		{"linked when the name is shared", unnamed, menteeDoc{StudentID: 7}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.matcher.matches(tt.doc); got != tt.want {
+				t.Errorf("matches = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if got, want := unnamed.filter("m."), (bson.M{"m.student_id": 7}); !reflect.DeepEqual(got, want) {
+		t.Errorf("filter without a name = %v, want %v", got, want)
+	}
+	want := bson.M{"$or": bson.A{
+		bson.M{"student_id": 7},
+		bson.M{"student_id": bson.M{"$exists": false}, "name": common.CaseInsensitive("Ada Lovelace")},
+	}}
+	if got := named.filter(""); !reflect.DeepEqual(got, want) {
+		t.Errorf("filter with a name = %v, want %v", got, want)
+	}
+}
+
+func TestDeletionStatus(t *testing.T) {
+	if got := deletionStatus(models.User{}); got != (models.AccountDeletionResponse{Status: "none"}) {
+		t.Errorf("no request: %+v", got)
+	}
+	requested := time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC)
+	scheduled := requested.Add(common.DefaultDeletionGrace)
+	got := deletionStatus(models.User{DeletionRequestedAt: &requested, DeletionScheduledFor: &scheduled})
+	if got.Status != "scheduled" || got.RequestedAt != &requested || got.ScheduledFor != &scheduled {
+		t.Errorf("pending request: %+v", got)
+	}
+}
+
+func TestWriteEntry(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	modified := time.Date(2026, time.October, 18, 9, 0, 0, 0, time.UTC)
+	if err := writeEntry(zw, "votes.json", modified, []exportVote{{PollID: 1, Option: "Yes"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeEntry(zw, "bad.json", modified, func() {}); err == nil {
+		t.Error("writeEntry encoded a func")
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(zr.File) != 1 || zr.File[0].Name != "votes.json" || !zr.File[0].Modified.Equal(modified) {
+		t.Fatalf("zip entries = %+v", zr.File)
+	}
+	f, _ := zr.File[0].Open()
+	body, _ := io.ReadAll(f)
+	want := "[\n  {\n    \"pollId\": 1,\n    \"question\": \"\",\n    \"optionIndex\": 0,\n    \"option\": \"Yes\"\n  }\n]"
+	if string(body) != want {
+		t.Errorf("votes.json = %s, want %s", body, want)
+	}
+}
@@ -0,0 +1,141 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/handlers/common"
+	"backend/httpcache"
+	"backend/models"
+)
+
+// Placeholders left behind on content that outlives a deleted account.
+const (
+	deletedAuthorName = "Deleted user"
+	formerMenteeName  = "Former student"
+)
+
+var errDeletionCancelled = errors.New("deletion cancelled")
+
+// PurgeDeletedAccounts deletes the accounts of the tenant in ctx whose grace period has
+// ended.
+func PurgeDeletedAccounts(ctx context.Context) error {
+	now := time.Now().UTC()
+	cursor, err := common.UsersCol.Find(ctx, bson.M{"deletion_scheduled_for": bson.M{"$lte": now}})
+	if err != nil {
+		return err
+	}
+	var due []models.User
+	if err := cursor.All(ctx, &due); err != nil {
+		return err
+	}
+	if len(due) > 0 {
+		// Purges touch users, completions, posts and other faculty's mentee records.
+		defer common.Versions.Bump(ctx, httpcache.ScopeUsers, httpcache.ScopeQuests, httpcache.ScopeResearch, httpcache.ScopeFaculty)
+	}
+	failed := 0
+	for _, user := range due {
+		if err := purgeAccount(ctx, user, now); err != nil {
+			log.Printf("account purge %d: %v", user.UserID, err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d account deletions failed", failed, len(due))
+	}
+	return nil
+}
+
+// purgeAccount removes user in one transaction. Research posts stay up under a placeholder
+// author, votes are removed together with their poll tallies, quest completions and
+// invitations are deleted, and mentee records about the user keep their status but lose
+// the name and notes, so poll results and mentee counts still add up.
+func purgeAccount(ctx context.Context, user models.User, now time.Time) error {
+	mentees, err := newMenteeMatcher(ctx, user)
+	if err != nil {
+		return err
+	}
+	err = common.WithTransaction(ctx, func(sc mongo.SessionContext) error {
+		// A cancellation that races the job wins: the user is only deleted while still due.
+		res, err := common.UsersCol.DeleteOne(sc, bson.M{"user_id": user.UserID, "deletion_scheduled_for": bson.M{"$lte": now}})
+		if err != nil {
+			return err
+		}
+		if res.DeletedCount == 0 {
+			return errDeletionCancelled
+		}
+		if _, err := common.ResearchPostsCol.UpdateMany(sc, bson.M{"author_id": user.UserID}, bson.M{"$set": bson.M{"author_id": 0, "author_name": deletedAuthorName}, "$unset": bson.M{"author_role": ""}}); err != nil {
+			return fmt.Errorf("anonymize research posts: %w", err)
+		}
+		if err := removeVotes(sc, user.UserID); err != nil {
+			return fmt.Errorf("remove votes: %w", err)
+		}
+		if _, err := common.UserQuestsCol.DeleteMany(sc, bson.M{"user_id": user.UserID}); err != nil {
+			return fmt.Errorf("remove quest completions: %w", err)
+		}
+		if _, err := common.CoinLedgerCol.DeleteMany(sc, bson.M{"user_id": user.UserID}); err != nil {
+			return fmt.Errorf("remove coin history: %w", err)
+		}
+		if _, err := common.XPLedgerCol.DeleteMany(sc, bson.M{"user_id": user.UserID}); err != nil {
+			return fmt.Errorf("remove xp history: %w", err)
+		}
+		if _, err := common.QuestAttemptsCol.DeleteMany(sc, bson.M{"user_id": user.UserID}); err != nil {
+			return fmt.Errorf("remove quest attempts: %w", err)
+		}
+		if _, err := common.QuestAssignmentsCol.DeleteMany(sc, bson.M{"user_id": user.UserID}); err != nil {
+			return fmt.Errorf("remove quest assignments: %w", err)
+		}
+		// Past standings keep the place but not the person.
+		standing := options.Update().SetArrayFilters(options.ArrayFilters{Filters: []interface{}{bson.M{"e.user_id": user.UserID}}})
+		if _, err := common.SnapshotsCol.UpdateMany(sc, bson.M{"entries.user_id": user.UserID}, bson.M{"$set": bson.M{"entries.$[e].user_id": 0, "entries.$[e].name": deletedAuthorName}}, standing); err != nil {
+			return fmt.Errorf("anonymize leaderboard snapshots: %w", err)
+		}
+		entry := "mentorship.mentees.$[m]."
+		update := bson.M{"$set": bson.M{entry + "name": formerMenteeName, entry + "updated_at": now}, "$unset": bson.M{entry + "student_id": "", entry + "note": "", entry + "next_session": ""}}
+		opts := options.Update().SetArrayFilters(options.ArrayFilters{Filters: []interface{}{mentees.filter("m.")}})
+		if _, err := common.FacultyDashboardsCol.UpdateMany(sc, bson.M{"mentorship.mentees": bson.M{"$elemMatch": mentees.filter("")}}, update, opts); err != nil {
+			return fmt.Errorf("anonymize mentee records: %w", err)
+		}
+		if user.Role == common.RoleFaculty {
+			if _, err := common.FacultyDashboardsCol.DeleteMany(sc, bson.M{"faculty_id": user.UserID}); err != nil {
+				return fmt.Errorf("remove faculty dashboard: %w", err)
+			}
+		}
+		_, err = common.InvitationsCol.DeleteMany(sc, bson.M{"user_id": user.UserID})
+		return err
+	})
+	if errors.Is(err, errDeletionCancelled) {
+		return nil
+	}
+	return err
+}
+
+// removeVotes deletes the user's votes and takes each one back off its poll option tally.
+func removeVotes(sc mongo.SessionContext, userID int) error {
+	var votes []struct {
+		PollID      int `bson:"poll_id"`
+		OptionIndex int `bson:"option_index"`
+	}
+	cursor, err := common.VotesCol.Find(sc, bson.M{"user_id": userID})
+	if err != nil {
+		return err
+	}
+	if err := cursor.All(sc, &votes); err != nil {
+		return err
+	}
+	for _, v := range votes {
+		field := fmt.Sprintf("options.%d.votes", v.OptionIndex)
+		if _, err := common.PollsCol.UpdateOne(sc, bson.M{"poll_id": v.PollID, field: bson.M{"$gt": 0}}, bson.M{"$inc": bson.M{field: -1}}); err != nil {
+			return err
+		}
+	}
+	_, err = common.VotesCol.DeleteMany(sc, bson.M{"user_id": userID})
+	return err
+}
@@ -6,8 +6,11 @@ import (
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo/options"
 
+	"backend/apiversion"
+	"backend/cache"
 	"backend/handlers/common"
 	"backend/models"
 )
@@ -21,7 +24,7 @@ type (
 
 func GetOverview(w http.ResponseWriter, r *http.Request) {
     if !common.HasRole(r.Context(), common.RoleAdmin) { common.WriteJSON(w,http.StatusForbidden,map[string]string{"error":"admin access required"}); return }
-    ctx := context.Background()
+    ctx := r.Context()
     totalUsers, _ := common.UsersCol.CountDocuments(ctx, bson.M{})
     studentCount, _ := common.UsersCol.CountDocuments(ctx, bson.M{"role": common.RoleStudent})
     facultyCount, _ := common.UsersCol.CountDocuments(ctx, bson.M{"role": common.RoleFaculty})
@@ -31,7 +34,7 @@ func GetOverview(w http.ResponseWriter, r *http.Request) {
     defer cursor.Close(ctx)
     var coinSum int
     for cursor.Next(ctx) { var user User; if err := cursor.Decode(&user); err != nil { continue }; coinSum += user.Coins }
-    leaders, err := common.CollectLeaderboard(5); if err != nil { common.WriteJSON(w,http.StatusInternalServerError,map[string]string{"error": err.Error()}); return }
+    leaders, err := common.CollectLeaderboard(r.Context(), 5); if err != nil { common.WriteJSON(w,http.StatusInternalServerError,map[string]string{"error": err.Error()}); return }
     activity, err := collectRecentActivity(ctx, 5); if err != nil { common.WriteJSON(w,http.StatusInternalServerError,map[string]string{"error": err.Error()}); return }
     var avgCoins float64; if totalUsers>0 { avgCoins = float64(coinSum)/float64(totalUsers) }
     resp := AdminOverviewResponse{ AverageCoins: avgCoins, Leaderboard: leaders, RecentActivity: activity }
@@ -39,20 +42,61 @@ func GetOverview(w http.ResponseWriter, r *http.Request) {
     common.WriteJSON(w, http.StatusOK, resp)
 }
 
+type activityRecord struct {
+    ID          primitive.ObjectID `bson:"_id"`
+    UserID      int                `bson:"user_id"`
+    QuestID     int                `bson:"quest_id"`
+    CompletedAt time.Time          `bson:"completed_at"`
+}
+
+var activityListSpec = common.ListSpec{Sorts: map[string]string{"completed_at": "completed_at"}, DefaultSort: "completed_at", DefaultDesc: true, TieBreaker: "_id", DefaultLimit: 20, MaxLimit: 100}
+
+// GET /admin/activity
+func GetActivity(w http.ResponseWriter, r *http.Request) {
+    page, err := common.ParsePage(r, activityListSpec)
+    if err != nil { common.WriteValidationError(w, err); return }
+    filter := bson.M{}
+    if id := common.QueryInt(r, "user_id"); id > 0 { filter["user_id"] = id }
+    if id := common.QueryInt(r, "quest_id"); id > 0 { filter["quest_id"] = id }
+    ctx := r.Context()
+    records, links, err := common.FindPage[activityRecord](ctx, common.UserQuestsCol, filter, page)
+    if err != nil { common.WriteJSON(w,http.StatusInternalServerError,map[string]string{"error":"failed to load activity"}); return }
+    common.WriteJSON(w, http.StatusOK, models.AdminActivityListResponse{Items: describeActivity(ctx, records), Next: links.Next, Prev: links.Prev})
+}
+
 func collectRecentActivity(ctx context.Context, limit int64) ([]AdminActivity, error) {
     findOpts := options.Find().SetSort(bson.M{"completed_at": -1}).SetLimit(limit)
     cursor, err := common.UserQuestsCol.Find(ctx, bson.M{}, findOpts)
     if err != nil { return nil, err }
     defer cursor.Close(ctx)
+    records := []activityRecord{}
+    for cursor.Next(ctx) {
+        var record activityRecord
+        if err := cursor.Decode(&record); err != nil { continue }
+        records = append(records, record)
+    }
+    return describeActivity(ctx, records), nil
+}
+
+// describeActivity resolves user and quest names for completion records.
+func describeActivity(ctx context.Context, records []activityRecord) []AdminActivity {
     activities := []AdminActivity{}
     userCache := map[int]string{}
     questCache := map[int]string{}
-    for cursor.Next(ctx) {
-        var record struct { UserID int `bson:"user_id"`; QuestID int `bson:"quest_id"`; CompletedAt time.Time `bson:"completed_at"` }
-        if err := cursor.Decode(&record); err != nil { continue }
+    for _, record := range records {
         if _, ok := userCache[record.UserID]; !ok { var user User; if err := common.UsersCol.FindOne(ctx, bson.M{"user_id": record.UserID}).Decode(&user); err == nil { userCache[record.UserID] = user.Name } }
         if _, ok := questCache[record.QuestID]; !ok { var quest Quest; if err := common.QuestsCol.FindOne(ctx, bson.M{"quest_id": record.QuestID}).Decode(&quest); err == nil { questCache[record.QuestID] = quest.Title } }
         activities = append(activities, AdminActivity{ UserName: userCache[record.UserID], QuestTitle: questCache[record.QuestID], CompletedAt: record.CompletedAt })
     }
-    return activities, nil
+    return activities
+}
+
+// GET /admin/api-usage
+func GetAPIUsage(w http.ResponseWriter, r *http.Request) {
+    common.WriteJSON(w, http.StatusOK, apiversion.UsageReport{Versions: apiversion.Usage()})
+}
+
+// GET /admin/cache
+func GetCacheStats(w http.ResponseWriter, r *http.Request) {
+    common.WriteJSON(w, http.StatusOK, cache.StatsReport{Caches: cache.Snapshot()})
 }
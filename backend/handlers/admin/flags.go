@@ -0,0 +1,219 @@
+package admin
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+
+	"backend/audit"
+	"backend/flags"
+	"backend/handlers/common"
+	"backend/models"
+	"backend/tenant"
+)
+
+var flagRoles = []string{common.RoleStudent, common.RoleFaculty, common.RoleAdmin}
+
+// GET /admin/flags
+func ListFlags(w http.ResponseWriter, r *http.Request) {
+	if !platformAdmin(w, r) {
+		return
+	}
+	all, err := common.Flags.List(r.Context())
+	if err != nil {
+		common.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to load flags"})
+		return
+	}
+	common.WriteJSON(w, http.StatusOK, flags.FlagList{Items: all})
+}
+
+// GET /admin/flags/{key}
+func GetFlag(w http.ResponseWriter, r *http.Request) {
+	if !platformAdmin(w, r) {
+		return
+	}
+	f, err := common.Flags.Get(r.Context(), mux.Vars(r)["key"])
+	if errors.Is(err, flags.ErrNotFound) {
+		common.WriteJSON(w, http.StatusNotFound, map[string]string{"error": "flag not found"})
+		return
+	} else if err != nil {
+		common.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to load flag"})
+		return
+	}
+	common.WriteJSON(w, http.StatusOK, f)
+}
+
+// POST /admin/flags creates a flag, switched off unless enabled is true.
+func CreateFlag(w http.ResponseWriter, r *http.Request) {
+	if !platformAdmin(w, r) {
+		return
+	}
+	var req models.CreateFlagRequest
+	if !common.BindJSON(w, r, &req) {
+		return
+	}
+	ctx := r.Context()
+	actorID, _ := common.UserIDFromContext(ctx)
+	f := flags.Flag{Key: strings.TrimSpace(req.Key), Description: strings.TrimSpace(req.Description), Enabled: req.Enabled != nil && *req.Enabled, Tenants: req.Tenants, Users: req.Users, UpdatedBy: actorID}
+	f.Rules = flagRules(req.Rules)
+	errs := checkFlag(r, &f)
+	if !flags.KeyPattern.MatchString(f.Key) {
+		errs = append(common.ValidationErrors{{Field: "key", Rule: "pattern", Message: "must be lowercase letters, digits, '.', '_' or '-'"}}, errs...)
+	}
+	if len(errs) > 0 {
+		common.WriteValidationError(w, errs)
+		return
+	}
+	created, err := common.Flags.Create(ctx, f)
+	if errors.Is(err, flags.ErrExists) {
+		common.WriteJSON(w, http.StatusConflict, map[string]string{"error": "flag already exists"})
+		return
+	} else if err != nil {
+		common.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to save flag"})
+		return
+	}
+	audit.SetTarget(ctx, "flag", created.Key)
+	audit.SetChange(ctx, nil, created)
+	common.WriteJSON(w, http.StatusCreated, created)
+}
+
+// POST /admin/flags/{key}
+func UpdateFlag(w http.ResponseWriter, r *http.Request) {
+	if !platformAdmin(w, r) {
+		return
+	}
+	var req models.UpdateFlagRequest
+	if !common.BindJSON(w, r, &req) {
+		return
+	}
+	ctx := r.Context()
+	before, err := common.Flags.Get(ctx, mux.Vars(r)["key"])
+	if errors.Is(err, flags.ErrNotFound) {
+		common.WriteJSON(w, http.StatusNotFound, map[string]string{"error": "flag not found"})
+		return
+	} else if err != nil {
+		common.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to load flag"})
+		return
+	}
+	f := before
+	f.UpdatedBy, _ = common.UserIDFromContext(ctx)
+	if req.Description != nil {
+		f.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.Enabled != nil {
+		f.Enabled = *req.Enabled
+	}
+	if req.Tenants != nil {
+		f.Tenants = *req.Tenants
+	}
+	if req.Users != nil {
+		f.Users = *req.Users
+	}
+	if req.Rules != nil {
+		f.Rules = flagRules(*req.Rules)
+	}
+	if errs := checkFlag(r, &f); len(errs) > 0 {
+		common.WriteValidationError(w, errs)
+		return
+	}
+	saved, err := common.Flags.Save(ctx, f)
+	if errors.Is(err, flags.ErrNotFound) {
+		common.WriteJSON(w, http.StatusNotFound, map[string]string{"error": "flag not found"})
+		return
+	} else if err != nil {
+		common.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to save flag"})
+		return
+	}
+	audit.SetTarget(ctx, "flag", saved.Key)
+	audit.SetChange(ctx, before, saved)
+	common.WriteJSON(w, http.StatusOK, saved)
+}
+
+// DELETE /admin/flags/{key}
+func DeleteFlag(w http.ResponseWriter, r *http.Request) {
+	if !platformAdmin(w, r) {
+		return
+	}
+	ctx := r.Context()
+	f, err := common.Flags.Delete(ctx, mux.Vars(r)["key"])
+	if errors.Is(err, flags.ErrNotFound) {
+		common.WriteJSON(w, http.StatusNotFound, map[string]string{"error": "flag not found"})
+		return
+	} else if err != nil {
+		common.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to delete flag"})
+		return
+	}
+	audit.SetTarget(ctx, "flag", f.Key)
+	audit.SetChange(ctx, f, nil)
+	common.WriteJSON(w, http.StatusOK, models.SuccessResponse{Success: true})
+}
+
+// platformAdmin allows only admins of the default campus: feature flags and recurring jobs
+// apply to every campus, so one campus's admins must not see or change them for the others.
+func platformAdmin(w http.ResponseWriter, r *http.Request) bool {
+	if id, _ := tenant.ID(r.Context()); id != common.Tenants.Default {
+		common.WriteJSON(w, http.StatusForbidden, map[string]string{"error": "platform settings are managed from the " + common.Tenants.Default + " campus"})
+		return false
+	}
+	return true
+}
+
+func flagRules(reqs []models.FlagRuleRequest) []flags.Rule {
+	rules := make([]flags.Rule, 0, len(reqs))
+	for _, req := range reqs {
+		roles := []string{}
+		for _, role := range req.Roles {
+			roles = append(roles, strings.ToLower(strings.TrimSpace(role)))
+		}
+		rules = append(rules, flags.Rule{Roles: roles, Percentage: req.Percentage})
+	}
+	return rules
+}
+
+// checkFlag validates what the struct tags cannot and normalizes empty lists.
+func checkFlag(r *http.Request, f *flags.Flag) common.ValidationErrors {
+	var errs common.ValidationErrors
+	if f.Tenants == nil {
+		f.Tenants = []string{}
+	}
+	if f.Users == nil {
+		f.Users = []int{}
+	}
+	if f.Rules == nil {
+		f.Rules = []flags.Rule{}
+	}
+	for i, id := range f.Tenants {
+		if _, err := common.Tenants.Get(r.Context(), id); err != nil {
+			errs = append(errs, common.FieldError{Field: fmt.Sprintf("tenants[%d]", i), Rule: "oneof", Message: "is not a known campus"})
+		}
+	}
+	for i, id := range f.Users {
+		if id <= 0 {
+			errs = append(errs, common.FieldError{Field: fmt.Sprintf("users[%d]", i), Rule: "min", Message: "must be a user ID"})
+		}
+	}
+	for i, rule := range f.Rules {
+		if rule.Percentage < 0 || rule.Percentage > 100 {
+			errs = append(errs, common.FieldError{Field: fmt.Sprintf("rules[%d].percentage", i), Rule: "max", Message: "must be between 0 and 100"})
+		}
+		for _, role := range rule.Roles {
+			if !containsString(flagRoles, role) {
+				errs = append(errs, common.FieldError{Field: fmt.Sprintf("rules[%d].roles", i), Rule: "oneof", Message: "must be one of: " + strings.Join(flagRoles, ", ")})
+				break
+			}
+		}
+	}
+	return errs
+}
+
+func containsString(list []string, v string) bool {
+	for _, item := range list {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,92 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"backend/handlers/common"
+	"backend/jobs"
+)
+
+var jobRunListSpec = common.ListSpec{Sorts: map[string]string{"started_at": "started_at"}, DefaultSort: "started_at", DefaultDesc: true, TieBreaker: "_id", DefaultLimit: 20, MaxLimit: 100}
+
+// GET /admin/jobs. Jobs run for every campus, so only admins of the default campus may list,
+// run, pause or resume them.
+func ListJobs(w http.ResponseWriter, r *http.Request) {
+	if !platformAdmin(w, r) {
+		return
+	}
+	states, err := common.Jobs.List(context.Background())
+	if err != nil {
+		common.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to load jobs"})
+		return
+	}
+	common.WriteJSON(w, http.StatusOK, jobs.JobList{Items: states})
+}
+
+// GET /admin/jobs/{name}/runs
+func ListJobRuns(w http.ResponseWriter, r *http.Request) {
+	if !platformAdmin(w, r) {
+		return
+	}
+	name := mux.Vars(r)["name"]
+	if !common.Jobs.Has(name) {
+		common.WriteJSON(w, http.StatusNotFound, map[string]string{"error": "job not found"})
+		return
+	}
+	page, err := common.ParsePage(r, jobRunListSpec)
+	if err != nil {
+		common.WriteValidationError(w, err)
+		return
+	}
+	runs, links, err := common.FindPage[jobs.Run](context.Background(), common.JobRunsCol, bson.M{"job": name}, page)
+	if err != nil {
+		common.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to load job runs"})
+		return
+	}
+	common.WriteJSON(w, http.StatusOK, jobs.RunList{Items: runs, Next: links.Next, Prev: links.Prev})
+}
+
+// POST /admin/jobs/{name}/run
+func TriggerJob(w http.ResponseWriter, r *http.Request) {
+	if !platformAdmin(w, r) {
+		return
+	}
+	run, err := common.Jobs.Trigger(context.Background(), mux.Vars(r)["name"])
+	switch {
+	case errors.Is(err, jobs.ErrUnknownJob):
+		common.WriteJSON(w, http.StatusNotFound, map[string]string{"error": "job not found"})
+	case errors.Is(err, jobs.ErrJobRunning):
+		common.WriteJSON(w, http.StatusConflict, map[string]string{"error": "job is already running"})
+	case err != nil:
+		common.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to start job"})
+	default:
+		common.WriteJSON(w, http.StatusAccepted, run)
+	}
+}
+
+// POST /admin/jobs/{name}/pause
+func PauseJob(w http.ResponseWriter, r *http.Request) { setJobPaused(w, r, true) }
+
+// POST /admin/jobs/{name}/resume
+func ResumeJob(w http.ResponseWriter, r *http.Request) { setJobPaused(w, r, false) }
+
+func setJobPaused(w http.ResponseWriter, r *http.Request, paused bool) {
+	if !platformAdmin(w, r) {
+		return
+	}
+	state, err := common.Jobs.SetPaused(context.Background(), mux.Vars(r)["name"], paused)
+	switch {
+	case errors.Is(err, jobs.ErrUnknownJob), errors.Is(err, mongo.ErrNoDocuments):
+		common.WriteJSON(w, http.StatusNotFound, map[string]string{"error": "job not found"})
+	case err != nil:
+		common.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to update job"})
+	default:
+		common.WriteJSON(w, http.StatusOK, state)
+	}
+}
@@ -0,0 +1,54 @@
+package admin
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"backend/handlers/common"
+	"backend/roster"
+)
+
+// POST /admin/users/import?dry_run=true&invite=false
+//
+// The roster is either the raw request body (text/csv) or the "file" part of a multipart
+// upload.
+func ImportRoster(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, roster.MaxBytes)
+	var src io.Reader = r.Body
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			common.WriteValidationError(w, common.ValidationErrors{{Field: "file", Rule: "required", Message: "is required"}})
+			return
+		}
+		defer file.Close()
+		src = file
+	}
+	entries, err := roster.Parse(src)
+	if err != nil {
+		common.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	ctx := r.Context()
+	opts := roster.Options{DryRun: queryBool(r, "dry_run", false), Invite: queryBool(r, "invite", true), Campus: common.TenantConfig(ctx).Branding.DisplayName}
+	report, err := common.Roster.Import(ctx, entries, opts)
+	if errors.Is(err, roster.ErrInvalidRows) {
+		common.WriteJSON(w, http.StatusUnprocessableEntity, report)
+		return
+	} else if err != nil {
+		common.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to import roster"})
+		return
+	}
+	common.WriteJSON(w, http.StatusOK, report)
+}
+
+func queryBool(r *http.Request, name string, fallback bool) bool {
+	v, err := strconv.ParseBool(r.URL.Query().Get(name))
+	if err != nil {
+		return fallback
+	}
+	return v
+}
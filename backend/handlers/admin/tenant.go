@@ -0,0 +1,181 @@
+package admin
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"backend/audit"
+	"backend/handlers/common"
+	"backend/httpcache"
+	"backend/models"
+	"backend/tenant"
+)
+
+// GET /admin/tenant
+func GetTenant(w http.ResponseWriter, r *http.Request) {
+	id, _ := tenant.ID(r.Context())
+	t, err := common.Tenants.Get(r.Context(), id)
+	if errors.Is(err, tenant.ErrUnknownTenant) {
+		common.WriteJSON(w, http.StatusNotFound, map[string]string{"error": "tenant not found"})
+		return
+	} else if err != nil {
+		common.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to load tenant"})
+		return
+	}
+	common.WriteJSON(w, http.StatusOK, t)
+}
+
+// POST /admin/tenant updates the calling admin's campus; omitted fields keep their value.
+func UpdateTenant(w http.ResponseWriter, r *http.Request) {
+	var req models.UpdateTenantRequest
+	if !common.BindJSON(w, r, &req) {
+		return
+	}
+	ctx := r.Context()
+	id, _ := tenant.ID(ctx)
+	t, err := common.Tenants.Get(ctx, id)
+	if err != nil {
+		common.WriteJSON(w, http.StatusNotFound, map[string]string{"error": "tenant not found"})
+		return
+	}
+	branding, ai, gamification := t.Branding, t.AI, t.Gamification
+	if b := req.Branding; b != nil {
+		setString(&branding.DisplayName, b.DisplayName)
+		setString(&branding.LogoURL, b.LogoURL)
+		setString(&branding.PrimaryColor, b.PrimaryColor)
+		setString(&branding.AccentColor, b.AccentColor)
+	}
+	if a := req.AI; a != nil {
+		if a.Enabled != nil {
+			ai.Enabled = *a.Enabled
+		}
+		setString(&ai.Model, a.Model)
+	}
+	if g := req.Gamification; g != nil {
+		if g.CoinMultiplier != nil {
+			gamification.CoinMultiplier = *g.CoinMultiplier
+		}
+		if g.LeaderboardEnabled != nil {
+			gamification.LeaderboardEnabled = *g.LeaderboardEnabled
+		}
+		if g.Terms != nil {
+			terms, errs := parseTerms(g.Terms)
+			if len(errs) > 0 {
+				common.WriteValidationError(w, errs)
+				return
+			}
+			gamification.Terms = terms
+		}
+		if g.Timezone != nil {
+			tz := strings.TrimSpace(*g.Timezone)
+			if !common.ValidTimezone(tz) {
+				common.WriteValidationError(w, common.ValidationErrors{{Field: "gamification.timezone", Rule: "timezone", Message: "must be an IANA timezone such as Asia/Kolkata"}})
+				return
+			}
+			gamification.Timezone = tz
+		}
+		if rot := g.Rotation; rot != nil {
+			if rot.Daily != nil {
+				gamification.Rotation.Daily = *rot.Daily
+			}
+			if rot.Weekly != nil {
+				gamification.Rotation.Weekly = *rot.Weekly
+			}
+		}
+		if st := g.Streaks; st != nil {
+			if st.Actions != nil {
+				for i, action := range *st.Actions {
+					if !slices.Contains(common.Activities, action) {
+						common.WriteValidationError(w, common.ValidationErrors{{Field: fmt.Sprintf("gamification.streaks.actions[%d]", i), Rule: "oneof", Message: "must be one of: " + strings.Join(common.Activities, ", ")}})
+						return
+					}
+				}
+				gamification.Streaks.Actions = *st.Actions
+				if len(gamification.Streaks.Actions) == 0 {
+					gamification.Streaks.Actions = nil
+				}
+			}
+			if st.FreezeCost != nil {
+				gamification.Streaks.FreezeCost = *st.FreezeCost
+			}
+			if st.MaxFreezes != nil {
+				gamification.Streaks.MaxFreezes = st.MaxFreezes
+			}
+		}
+		if lv := g.Levels; lv != nil {
+			if lv.Base != nil {
+				gamification.Levels.Base = *lv.Base
+			}
+			if lv.Exponent != nil {
+				gamification.Levels.Exponent = *lv.Exponent
+			}
+			if lv.Thresholds != nil {
+				for i, xp := range *lv.Thresholds {
+					if xp <= 0 || (i > 0 && xp <= (*lv.Thresholds)[i-1]) {
+						common.WriteValidationError(w, common.ValidationErrors{{Field: fmt.Sprintf("gamification.levels.thresholds[%d]", i), Rule: "increasing", Message: "must be more than 0 and more than the previous threshold"}})
+						return
+					}
+				}
+				gamification.Levels.Thresholds = *lv.Thresholds
+				if len(gamification.Levels.Thresholds) == 0 {
+					gamification.Levels.Thresholds = nil
+				}
+			}
+			if lv.Reward != nil {
+				gamification.Levels.Reward = lv.Reward
+			}
+		}
+	}
+	updated, err := common.Tenants.Update(ctx, branding, ai, gamification)
+	if err != nil {
+		common.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to update tenant"})
+		return
+	}
+	audit.SetTarget(ctx, "tenant", id)
+	audit.SetChange(ctx, t, updated)
+	if !reflect.DeepEqual(t.Gamification.Levels, updated.Gamification.Levels) {
+		// Stored levels follow the new curve; nobody is paid for levels it hands out.
+		if _, err := common.Relevel(ctx, updated.Gamification.Levels); err != nil {
+			log.Printf("failed to relevel users of %s: %v", id, err)
+		}
+		common.Versions.Bump(ctx, httpcache.ScopeUsers)
+	}
+	common.WriteJSON(w, http.StatusOK, updated)
+}
+
+// parseTerms checks each term has a name and ends after it starts, and sorts them by start.
+func parseTerms(reqs []models.TenantTermRequest) ([]tenant.Term, common.ValidationErrors) {
+	var errs common.ValidationErrors
+	terms := make([]tenant.Term, 0, len(reqs))
+	for i, t := range reqs {
+		field := fmt.Sprintf("gamification.terms[%d].", i)
+		name := strings.TrimSpace(t.Name)
+		switch {
+		case name == "":
+			errs = append(errs, common.FieldError{Field: field + "name", Rule: "required", Message: "is required"})
+		case utf8.RuneCountInString(name) > 60:
+			errs = append(errs, common.FieldError{Field: field + "name", Rule: "max", Message: "must be at most 60 characters"})
+		}
+		if t.Start.IsZero() {
+			errs = append(errs, common.FieldError{Field: field + "start", Rule: "required", Message: "is required"})
+		} else if !t.End.After(t.Start) {
+			errs = append(errs, common.FieldError{Field: field + "end", Rule: "after", Message: "must be after start"})
+		}
+		terms = append(terms, tenant.Term{Name: name, Start: t.Start.UTC(), End: t.End.UTC()})
+	}
+	sort.Slice(terms, func(i, j int) bool { return terms[i].Start.Before(terms[j].Start) })
+	return terms, errs
+}
+
+func setString(dst *string, src *string) {
+	if src != nil {
+		*dst = strings.TrimSpace(*src)
+	}
+}
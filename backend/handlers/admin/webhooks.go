@@ -0,0 +1,228 @@
+package admin
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/audit"
+	"backend/handlers/common"
+	"backend/models"
+	"backend/webhooks"
+)
+
+var deliveryListSpec = common.ListSpec{Sorts: map[string]string{"created_at": "created_at", "next_attempt_at": "next_attempt_at"}, DefaultSort: "created_at", DefaultDesc: true, TieBreaker: "_id", DefaultLimit: 20, MaxLimit: 100}
+
+// GET /admin/webhooks
+func ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	cursor, err := common.WebhookSubsCol.Find(ctx, bson.M{}, options.Find().SetSort(bson.M{"created_at": -1}))
+	if err != nil {
+		common.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to load webhooks"})
+		return
+	}
+	subs := []webhooks.Subscription{}
+	if err := cursor.All(ctx, &subs); err != nil {
+		common.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to load webhooks"})
+		return
+	}
+	common.WriteJSON(w, http.StatusOK, webhooks.SubscriptionList{Items: subs})
+}
+
+// POST /admin/webhooks
+func CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateWebhookRequest
+	if !common.BindJSON(w, r, &req) {
+		return
+	}
+	events, errs := checkWebhookTarget(req.URL, req.Events)
+	if len(errs) > 0 {
+		common.WriteValidationError(w, errs)
+		return
+	}
+	secret := strings.TrimSpace(req.Secret)
+	if secret == "" {
+		generated, err := webhooks.NewSecret()
+		if err != nil {
+			common.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to generate secret"})
+			return
+		}
+		secret = generated
+	}
+	actorID, _ := common.UserIDFromContext(r.Context())
+	sub := webhooks.Subscription{ID: primitive.NewObjectID(), URL: strings.TrimSpace(req.URL), Events: events, Secret: secret, Active: req.Active == nil || *req.Active, CreatedBy: actorID, CreatedAt: time.Now().UTC()}
+	if _, err := common.WebhookSubsCol.InsertOne(r.Context(), sub); err != nil {
+		common.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to save webhook"})
+		return
+	}
+	common.WriteJSON(w, http.StatusCreated, webhooks.CreatedSubscription{ID: sub.ID, URL: sub.URL, Events: sub.Events, Active: sub.Active, Secret: sub.Secret, CreatedAt: sub.CreatedAt})
+}
+
+// POST /admin/webhooks/{id}
+func UpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	id, ok := objectIDVar(w, r)
+	if !ok {
+		return
+	}
+	var req models.UpdateWebhookRequest
+	if !common.BindJSON(w, r, &req) {
+		return
+	}
+	ctx := r.Context()
+	sub, err := common.Webhooks.Subscription(ctx, id)
+	if errors.Is(err, webhooks.ErrNotFound) {
+		common.WriteJSON(w, http.StatusNotFound, map[string]string{"error": "webhook not found"})
+		return
+	} else if err != nil {
+		common.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to load webhook"})
+		return
+	}
+	before := sub
+	target, events := sub.URL, sub.Events
+	if req.URL != nil {
+		target = strings.TrimSpace(*req.URL)
+	}
+	if req.Events != nil {
+		events = req.Events
+	}
+	events, errs := checkWebhookTarget(target, events)
+	if len(errs) > 0 {
+		common.WriteValidationError(w, errs)
+		return
+	}
+	set := bson.M{"url": target, "events": events}
+	if req.Active != nil {
+		set["active"] = *req.Active
+		sub.Active = *req.Active
+	}
+	if _, err := common.WebhookSubsCol.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": set}); err != nil {
+		common.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to update webhook"})
+		return
+	}
+	sub.URL, sub.Events = target, events
+	audit.SetTarget(ctx, "webhook", id.Hex())
+	audit.SetChange(ctx, before, sub)
+	common.WriteJSON(w, http.StatusOK, sub)
+}
+
+// DELETE /admin/webhooks/{id}
+func DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	id, ok := objectIDVar(w, r)
+	if !ok {
+		return
+	}
+	var sub webhooks.Subscription
+	err := common.WebhookSubsCol.FindOneAndDelete(r.Context(), bson.M{"_id": id}).Decode(&sub)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		common.WriteJSON(w, http.StatusNotFound, map[string]string{"error": "webhook not found"})
+		return
+	} else if err != nil {
+		common.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to delete webhook"})
+		return
+	}
+	audit.SetTarget(r.Context(), "webhook", id.Hex())
+	audit.SetChange(r.Context(), sub, nil)
+	common.WriteJSON(w, http.StatusOK, models.SuccessResponse{Success: true})
+}
+
+// GET /admin/webhooks/deliveries
+func ListWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	writeDeliveries(w, r, strings.ToLower(strings.TrimSpace(r.URL.Query().Get("status"))))
+}
+
+// GET /admin/webhooks/dead-letters
+func ListDeadLetters(w http.ResponseWriter, r *http.Request) {
+	writeDeliveries(w, r, webhooks.StatusDead)
+}
+
+func writeDeliveries(w http.ResponseWriter, r *http.Request, status string) {
+	page, err := common.ParsePage(r, deliveryListSpec)
+	if err != nil {
+		common.WriteValidationError(w, err)
+		return
+	}
+	filter := bson.M{}
+	if status != "" {
+		filter["status"] = status
+	}
+	if event := strings.TrimSpace(r.URL.Query().Get("event")); event != "" {
+		filter["event"] = event
+	}
+	if raw := strings.TrimSpace(r.URL.Query().Get("subscription_id")); raw != "" {
+		id, err := primitive.ObjectIDFromHex(raw)
+		if err != nil {
+			common.WriteValidationError(w, common.ValidationErrors{{Field: "subscription_id", Rule: "format", Message: "is not a valid id"}})
+			return
+		}
+		filter["subscription_id"] = id
+	}
+	items, links, err := common.FindPage[webhooks.Delivery](r.Context(), common.WebhookDeliveriesCol, filter, page)
+	if err != nil {
+		common.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to load deliveries"})
+		return
+	}
+	common.WriteJSON(w, http.StatusOK, webhooks.DeliveryList{Items: items, Next: links.Next, Prev: links.Prev})
+}
+
+// POST /admin/webhooks/deliveries/{id}/replay
+func ReplayWebhookDelivery(w http.ResponseWriter, r *http.Request) {
+	id, ok := objectIDVar(w, r)
+	if !ok {
+		return
+	}
+	d, err := common.Webhooks.Replay(r.Context(), id)
+	if errors.Is(err, webhooks.ErrNotFound) {
+		common.WriteJSON(w, http.StatusNotFound, map[string]string{"error": "delivery not found or currently sending"})
+		return
+	} else if err != nil {
+		common.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to replay delivery"})
+		return
+	}
+	common.WriteJSON(w, http.StatusAccepted, d)
+}
+
+// checkWebhookTarget validates a receiver URL and event list, returning the de-duplicated events.
+func checkWebhookTarget(target string, events []string) ([]string, common.ValidationErrors) {
+	var errs common.ValidationErrors
+	if u, err := url.Parse(strings.TrimSpace(target)); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errs = append(errs, common.FieldError{Field: "url", Rule: "url", Message: "must be an absolute http(s) URL"})
+	}
+	known := map[string]bool{webhooks.EventAll: true}
+	for _, e := range webhooks.EventTypes {
+		known[e] = true
+	}
+	seen := map[string]bool{}
+	cleaned := []string{}
+	for _, e := range events {
+		e = strings.ToLower(strings.TrimSpace(e))
+		if !known[e] {
+			errs = append(errs, common.FieldError{Field: "events", Rule: "oneof", Message: "must only contain: *, " + strings.Join(webhooks.EventTypes, ", ")})
+			break
+		}
+		if !seen[e] {
+			seen[e] = true
+			cleaned = append(cleaned, e)
+		}
+	}
+	if len(events) == 0 {
+		errs = append(errs, common.FieldError{Field: "events", Rule: "required", Message: "is required"})
+	}
+	return cleaned, errs
+}
+
+func objectIDVar(w http.ResponseWriter, r *http.Request) (primitive.ObjectID, bool) {
+	id, err := primitive.ObjectIDFromHex(mux.Vars(r)["id"])
+	if err != nil {
+		common.WriteJSON(w, http.StatusNotFound, map[string]string{"error": "not found"})
+		return id, false
+	}
+	return id, true
+}
@@ -1,7 +1,6 @@
 package common
 
 import (
-	"context"
 	"encoding/json"
 	"errors"
 	"log"
@@ -9,27 +8,27 @@ import (
 	"strings"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
 
+	"backend/flags"
+	"backend/httpcache"
+	"backend/i18n"
+	"backend/middleware"
 	"backend/models"
+	"backend/roster"
+	"backend/tenant"
 )
 
 type publicUser = models.PublicUser
 
-type loginRequest struct {
-	Email    string `json:"email"`
-	Password string `json:"password"`
-}
+type loginRequest = models.LoginRequest
 
 func sanitizeUser(u *models.User) publicUser {
 	if u == nil { return publicUser{} }
-	return publicUser{ID: u.UserID, Name: u.Name, Email: u.Email, Coins: u.Coins, Streak: u.Streak, Role: u.Role, AcademicStanding: u.AcademicStanding, GamificationLevel: u.GamificationLevel, CourseProgress: u.CourseProgress, ActiveCourses: u.ActiveCourses}
+	return publicUser{ID: u.UserID, Name: u.Name, Email: u.Email, Coins: u.Coins, Streak: u.Streak, LongestStreak: u.LongestStreak, Role: u.Role, AcademicStanding: u.AcademicStanding, GamificationLevel: u.GamificationLevel, CourseProgress: u.CourseProgress, ActiveCourses: u.ActiveCourses, Cohort: u.Cohort, Locale: u.Locale, Timezone: u.Timezone}
 }
 
-func writeJSON(w http.ResponseWriter, status int, payload interface{}) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
-	if payload != nil { _ = json.NewEncoder(w).Encode(payload) }
-}
+func writeJSON(w http.ResponseWriter, status int, payload interface{}) { WriteJSON(w, status, payload) }
 
 func decodeJSON(r *http.Request, dst interface{}) error {
 	dec := json.NewDecoder(r.Body); dec.DisallowUnknownFields(); return dec.Decode(dst)
@@ -38,25 +37,82 @@ func decodeJSON(r *http.Request, dst interface{}) error {
 func LoginHandler(w http.ResponseWriter, r *http.Request) {
 	if UsersCol == nil { log.Println("LoginHandler: users collection not configured"); writeJSON(w, http.StatusInternalServerError, map[string]string{"error":"service unavailable"}); return }
 	var req loginRequest
-	if err := decodeJSON(r, &req); err != nil { writeJSON(w, http.StatusBadRequest, map[string]string{"error":"invalid payload"}); return }
-	ctx := context.Background()
+	if !BindJSON(w, r, &req) { return }
+	ctx := r.Context()
+	if req.Tenant != "" {
+		// Shared hosts name the campus in the body; a campus host only signs in its own users.
+		current, _ := tenant.ID(ctx)
+		if _, err := Tenants.Get(ctx, req.Tenant); err != nil || (tenant.FromHost(ctx) && req.Tenant != current) { WriteValidationError(w, ValidationErrors{{Field: "tenant", Rule: "oneof", Message: "is not a known campus for this host"}}); return }
+		ctx = tenant.WithID(ctx, req.Tenant)
+	}
 	var user models.User
 	filter := bson.M{"email": strings.ToLower(strings.TrimSpace(req.Email))}
 	if err := UsersCol.FindOne(ctx, filter).Decode(&user); err != nil { writeJSON(w, http.StatusUnauthorized, map[string]string{"error":"invalid credentials"}); return }
 	if err := VerifyPassword(user.PasswordHash, req.Password); err != nil { writeJSON(w, http.StatusUnauthorized, map[string]string{"error":"invalid credentials"}); return }
 	token, expires, err := GenerateToken(&user)
 	if err != nil { writeJSON(w, http.StatusInternalServerError, map[string]string{"error":"failed to generate token"}); return }
-	writeJSON(w, http.StatusOK, map[string]interface{}{"token": token, "expiresAt": expires.UTC(), "user": sanitizeUser(&user)})
+	writeJSON(w, http.StatusOK, models.LoginResponse{Token: token, ExpiresAt: expires.UTC(), User: sanitizeUser(&user)})
+}
+
+// AcceptInvitationHandler sets the password of an invited user and signs them in.
+func AcceptInvitationHandler(w http.ResponseWriter, r *http.Request) {
+	var req models.AcceptInvitationRequest
+	if !BindJSON(w, r, &req) { return }
+	inv, err := Invitations.Accept(r.Context(), strings.TrimSpace(req.Token))
+	if errors.Is(err, roster.ErrInvalidInvite) { writeJSON(w, http.StatusGone, map[string]string{"error": err.Error()}); return }
+	if err != nil { writeJSON(w, http.StatusInternalServerError, map[string]string{"error":"failed to accept invitation"}); return }
+	hash, err := middleware.HashPassword(req.Password)
+	if err != nil { writeJSON(w, http.StatusInternalServerError, map[string]string{"error":"failed to set password"}); return }
+	ctx := tenant.WithID(r.Context(), inv.TenantID)
+	var user models.User
+	if err := UsersCol.FindOneAndUpdate(ctx, bson.M{"user_id": inv.UserID}, bson.M{"$set": bson.M{"password_hash": hash}}, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&user); err != nil { writeJSON(w, http.StatusNotFound, map[string]string{"error":"user not found"}); return }
+	token, expires, err := GenerateToken(&user)
+	if err != nil { writeJSON(w, http.StatusInternalServerError, map[string]string{"error":"failed to generate token"}); return }
+	writeJSON(w, http.StatusOK, models.LoginResponse{Token: token, ExpiresAt: expires.UTC(), User: sanitizeUser(&user)})
 }
 
 func GetMeHandler(w http.ResponseWriter, r *http.Request) {
 	userID, ok := UserIDFromContext(r.Context()); if !ok { writeJSON(w, http.StatusUnauthorized, map[string]string{"error":"unauthorized"}); return }
-	ctx := context.Background()
+	ctx := r.Context()
 	var user models.User
 	if err := UsersCol.FindOne(ctx, bson.M{"user_id": userID}).Decode(&user); err != nil { writeJSON(w, http.StatusNotFound, map[string]string{"error":"user not found"}); return }
 	writeJSON(w, http.StatusOK, sanitizeUser(&user))
 }
 
+// UpdatePreferencesHandler saves the caller's language and returns a fresh token carrying
+// it, so later requests are localized without another lookup.
+func UpdatePreferencesHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := UserIDFromContext(r.Context()); if !ok { writeJSON(w, http.StatusUnauthorized, map[string]string{"error":"unauthorized"}); return }
+	var req models.UpdatePreferencesRequest
+	if !BindJSON(w, r, &req) { return }
+	set, unset := bson.M{}, bson.M{}
+	if req.Locale != nil { if locale := strings.ToLower(strings.TrimSpace(*req.Locale)); locale != "" { set["locale"] = locale } else { unset["locale"] = "" } }
+	if req.Timezone != nil {
+		tz := strings.TrimSpace(*req.Timezone)
+		if !ValidTimezone(tz) { WriteValidationError(w, ValidationErrors{{Field: "timezone", Rule: "timezone", Message: "must be an IANA timezone such as Asia/Kolkata"}}); return }
+		if tz != "" { set["timezone"] = tz } else { unset["timezone"] = "" }
+	}
+	update := bson.M{}
+	if len(set) > 0 { update["$set"] = set }
+	if len(unset) > 0 { update["$unset"] = unset }
+	var user models.User
+	res := UsersCol.FindOne(r.Context(), bson.M{"user_id": userID})
+	if len(update) > 0 { res = UsersCol.FindOneAndUpdate(r.Context(), bson.M{"user_id": userID}, update, options.FindOneAndUpdate().SetReturnDocument(options.After)) }
+	if err := res.Decode(&user); err != nil { writeJSON(w, http.StatusNotFound, map[string]string{"error":"user not found"}); return }
+	Versions.Bump(r.Context(), httpcache.ScopeUsers)
+	token, expires, err := GenerateToken(&user)
+	if err != nil { writeJSON(w, http.StatusInternalServerError, map[string]string{"error":"failed to generate token"}); return }
+	w.Header().Set("Content-Language", i18n.Negotiate(user.Locale, r.Header.Get("Accept-Language")))
+	writeJSON(w, http.StatusOK, models.LoginResponse{Token: token, ExpiresAt: expires.UTC(), User: sanitizeUser(&user)})
+}
+
+// GetMyFlagsHandler returns every feature flag evaluated for the caller.
+func GetMyFlagsHandler(w http.ResponseWriter, r *http.Request) {
+	evaluated, err := Flags.Evaluate(r.Context(), flags.SubjectFrom(r.Context()))
+	if err != nil { writeJSON(w, http.StatusInternalServerError, map[string]string{"error":"failed to load flags"}); return }
+	writeJSON(w, http.StatusOK, flags.Evaluation{Flags: evaluated})
+}
+
 func WithRoles(handler http.HandlerFunc, allowed ...string) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if !HasRole(r.Context(), allowed...) { http.Error(w, "forbidden", http.StatusForbidden); return }
@@ -65,3 +121,9 @@ func WithRoles(handler http.HandlerFunc, allowed ...string) http.HandlerFunc {
 }
 
 var ErrForbidden = errors.New("forbidden")
+
+// GetTenantBranding serves the campus branding for the request's host (or the default
+// campus) so clients can theme the login screen before anyone signs in.
+func GetTenantBranding(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, TenantConfig(r.Context()).Public())
+}
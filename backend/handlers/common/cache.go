@@ -0,0 +1,80 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"backend/httpcache"
+	"backend/models"
+	"backend/tenant"
+)
+
+// ETag derives the ETag of a dashboard built for subject from the version counters of
+// scopes, without loading the dashboard. Relative labels such as "5 minutes ago" change by
+// the minute, so the current minute is part of it. ok is false when the counters cannot be
+// read; serve the response without an ETag then.
+func ETag(r *http.Request, subject int, scopes ...string) (string, bool) {
+	if Versions == nil || Versions.Col == nil {
+		return "", false
+	}
+	ctx := r.Context()
+	versions, err := Versions.Get(ctx, scopes...)
+	if err != nil {
+		return "", false
+	}
+	tenantID, _ := tenant.ID(ctx)
+	return httpcache.ETag(r.URL.Path, r.URL.RawQuery, tenantID, subject, Locale(r), scopes, versions, time.Now().Unix()/60), true
+}
+
+// FacultyAggregateSet is the cached result of CollectFacultyAggregates.
+type FacultyAggregateSet struct {
+	Courses []models.FacultyCourse    `json:"courses"`
+	Leaders []models.LeaderboardEntry `json:"leaders"`
+}
+
+// aggregateKey names a cached aggregate for the campus in ctx at the current versions of
+// the users and quests scopes. Completing a quest, importing a roster or changing an
+// enrollment bumps one of them, so the next read misses and recomputes. ok is false when
+// the counters cannot be read; compute the aggregate directly then.
+func aggregateKey(ctx context.Context, name string) (string, bool) {
+	if Versions == nil || Versions.Col == nil {
+		return "", false
+	}
+	versions, err := Versions.Get(ctx, httpcache.ScopeUsers, httpcache.ScopeQuests)
+	if err != nil {
+		return "", false
+	}
+	tenantID, _ := tenant.ID(ctx)
+	return fmt.Sprintf("%s:%s:%d.%d", name, tenantID, versions[0], versions[1]), true
+}
+
+// CollectLeaderboard returns the top limit users by coins, from the cache when the data
+// has not changed since it was computed.
+func CollectLeaderboard(ctx context.Context, limit int64) ([]models.LeaderboardEntry, error) {
+	key, ok := aggregateKey(ctx, fmt.Sprintf("leaderboard:%d", limit))
+	if !ok || Leaderboards == nil {
+		return loadLeaderboard(ctx, limit)
+	}
+	return Leaderboards.Get(ctx, key, func(ctx context.Context) ([]models.LeaderboardEntry, error) {
+		return loadLeaderboard(ctx, limit)
+	})
+}
+
+// CollectFacultyAggregates returns the per-course progress averages and the top five
+// students, from the cache when the data has not changed since they were computed.
+func CollectFacultyAggregates(ctx context.Context) ([]models.FacultyCourse, []models.LeaderboardEntry, error) {
+	key, ok := aggregateKey(ctx, "faculty")
+	if !ok || FacultyAggregates == nil {
+		return loadFacultyAggregates(ctx)
+	}
+	set, err := FacultyAggregates.Get(ctx, key, func(ctx context.Context) (FacultyAggregateSet, error) {
+		courses, leaders, err := loadFacultyAggregates(ctx)
+		return FacultyAggregateSet{Courses: courses, Leaders: leaders}, err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return set.Courses, set.Leaders, nil
+}
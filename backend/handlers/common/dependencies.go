@@ -7,11 +7,23 @@ import (
 
 	"go.mongodb.org/mongo-driver/mongo"
 
+	"backend/audit"
+	"backend/cache"
+	"backend/events"
+	"backend/flags"
+	"backend/httpcache"
+	"backend/jobs"
+	"backend/mailer"
 	"backend/middleware"
 	"backend/models"
+	"backend/realtime"
+	"backend/roster"
+	"backend/tenant"
+	"backend/webhooks"
 )
 
 type Dependencies struct {
+	Client               *mongo.Client
 	UsersCol             *mongo.Collection
 	QuestsCol            *mongo.Collection
 	PollsCol             *mongo.Collection
@@ -19,24 +31,84 @@ type Dependencies struct {
 	UserQuestsCol        *mongo.Collection
 	ResearchPostsCol     *mongo.Collection
 	FacultyDashboardsCol *mongo.Collection
+	IdempotencyKeysCol   *mongo.Collection
+	WebhookSubsCol       *mongo.Collection
+	WebhookDeliveriesCol *mongo.Collection
+	EventOutboxCol       *mongo.Collection
+	LiveEventsCol        *mongo.Collection
+	JobsCol              *mongo.Collection
+	JobRunsCol           *mongo.Collection
+	TenantsCol           *mongo.Collection
+	CountersCol          *mongo.Collection
+	InvitationsCol       *mongo.Collection
+	AuditLogCol          *mongo.Collection
+	FlagsCol             *mongo.Collection
+	CacheVersionsCol     *mongo.Collection
+	CoinLedgerCol        *mongo.Collection
+	SnapshotsCol         *mongo.Collection
+	QuestAttemptsCol     *mongo.Collection
+	QuestAssignmentsCol  *mongo.Collection
+	XPLedgerCol          *mongo.Collection
+	CacheStore           cache.Store
+	CacheTTL             time.Duration
+	DefaultTenant        string
+	InviteURL            string
+	DeletionGrace        time.Duration
 	GeminiAPIKey         string
 	GeminiModel          string
 	JWTSecret            string
 	TokenTTL             time.Duration
+	IdempotencyTTL       time.Duration
+	Mailer               mailer.Mailer
 }
 
 var (
-	UsersCol             *mongo.Collection
-	QuestsCol            *mongo.Collection
-	PollsCol             *mongo.Collection
-	VotesCol             *mongo.Collection
-	UserQuestsCol        *mongo.Collection
-	ResearchPostsCol     *mongo.Collection
-	FacultyDashboardsCol *mongo.Collection
+	Client               *mongo.Client
+	UsersCol             *tenant.Collection
+	QuestsCol            *tenant.Collection
+	PollsCol             *tenant.Collection
+	VotesCol             *tenant.Collection
+	UserQuestsCol        *tenant.Collection
+	ResearchPostsCol     *tenant.Collection
+	FacultyDashboardsCol *tenant.Collection
+	IdempotencyKeysCol   *mongo.Collection
+	WebhookSubsCol       *tenant.Collection
+	WebhookDeliveriesCol *tenant.Collection
+	EventOutboxCol       *mongo.Collection
+	LiveEventsCol        *mongo.Collection
+	JobsCol              *mongo.Collection
+	JobRunsCol           *mongo.Collection
+	TenantsCol           *mongo.Collection
+	CountersCol          *mongo.Collection
+	InvitationsCol       *tenant.Collection
+	AuditLogCol          *tenant.Collection
+	FlagsCol             *mongo.Collection
+	CacheVersionsCol     *tenant.Collection
+	CoinLedgerCol        *tenant.Collection
+	SnapshotsCol         *tenant.Collection
+	QuestAttemptsCol     *tenant.Collection
+	QuestAssignmentsCol  *tenant.Collection
+	XPLedgerCol          *tenant.Collection
 	GeminiAPIKey         string
 	GeminiModel          string
 	JWTSecret            string
 	TokenTTL             time.Duration
+	IdempotencyTTL       time.Duration
+	DeletionGrace        time.Duration
+	Webhooks             *webhooks.Store
+	Outbox               *events.Outbox
+	Events               *events.Bus
+	Live                 *realtime.Broker
+	Jobs                 *jobs.Scheduler
+	Tenants              *tenant.Registry
+	Invitations          *roster.Invitations
+	Roster               *roster.Importer
+	Audit                *audit.Log
+	Flags                *flags.Store
+	Versions             *httpcache.Versions
+	Leaderboards         *cache.Cache[[]models.LeaderboardEntry]
+	FacultyAggregates    *cache.Cache[FacultyAggregateSet]
+	Mailer               mailer.Mailer
 )
 
 const (
@@ -45,19 +117,75 @@ const (
 	RoleStudent = "student"
 )
 
+// DefaultDeletionGrace is how long a requested account deletion can still be cancelled.
+const DefaultDeletionGrace = 30 * 24 * time.Hour
+
 func Configure(deps Dependencies) {
-	UsersCol = deps.UsersCol
-	QuestsCol = deps.QuestsCol
-	PollsCol = deps.PollsCol
-	VotesCol = deps.VotesCol
-	UserQuestsCol = deps.UserQuestsCol
-	ResearchPostsCol = deps.ResearchPostsCol
-	FacultyDashboardsCol = deps.FacultyDashboardsCol
+	Client = deps.Client
+	UsersCol = tenant.Wrap(deps.UsersCol)
+	QuestsCol = tenant.Wrap(deps.QuestsCol)
+	PollsCol = tenant.Wrap(deps.PollsCol)
+	VotesCol = tenant.Wrap(deps.VotesCol)
+	UserQuestsCol = tenant.Wrap(deps.UserQuestsCol)
+	ResearchPostsCol = tenant.Wrap(deps.ResearchPostsCol)
+	FacultyDashboardsCol = tenant.Wrap(deps.FacultyDashboardsCol)
+	IdempotencyKeysCol = deps.IdempotencyKeysCol
+	WebhookSubsCol = tenant.Wrap(deps.WebhookSubsCol)
+	WebhookDeliveriesCol = tenant.Wrap(deps.WebhookDeliveriesCol)
+	Webhooks = &webhooks.Store{Subscriptions: WebhookSubsCol, Deliveries: WebhookDeliveriesCol}
+	EventOutboxCol = deps.EventOutboxCol
+	Outbox = &events.Outbox{Col: EventOutboxCol}
+	Events = events.NewBus(Outbox)
+	LiveEventsCol = deps.LiveEventsCol
+	Live = realtime.NewBroker(LiveEventsCol)
+	JobsCol = deps.JobsCol
+	JobRunsCol = deps.JobRunsCol
+	Jobs = jobs.NewScheduler(JobsCol, JobRunsCol)
+	TenantsCol = deps.TenantsCol
+	Tenants = tenant.NewRegistry(TenantsCol, deps.DefaultTenant)
+	Mailer = deps.Mailer
+	if Mailer == nil { Mailer = mailer.LogMailer{} }
+	CountersCol = deps.CountersCol
+	InvitationsCol = tenant.Wrap(deps.InvitationsCol)
+	Invitations = &roster.Invitations{Col: InvitationsCol, BaseURL: deps.InviteURL}
+	CacheVersionsCol = tenant.Wrap(deps.CacheVersionsCol)
+	Versions = &httpcache.Versions{Col: CacheVersionsCol}
+	CoinLedgerCol = tenant.Wrap(deps.CoinLedgerCol)
+	SnapshotsCol = tenant.Wrap(deps.SnapshotsCol)
+	QuestAttemptsCol = tenant.Wrap(deps.QuestAttemptsCol)
+	QuestAssignmentsCol = tenant.Wrap(deps.QuestAssignmentsCol)
+	XPLedgerCol = tenant.Wrap(deps.XPLedgerCol)
+	store := deps.CacheStore
+	if store == nil { store = cache.NewMemory(0) }
+	Leaderboards = cache.New[[]models.LeaderboardEntry]("leaderboard", store, deps.CacheTTL)
+	FacultyAggregates = cache.New[FacultyAggregateSet]("faculty_aggregates", store, deps.CacheTTL)
+	Roster = &roster.Importer{Users: UsersCol, Counters: CountersCol, Invites: Invitations, Mailer: Mailer, Versions: Versions}
+	AuditLogCol = tenant.Wrap(deps.AuditLogCol)
+	Audit = &audit.Log{Col: AuditLogCol}
+	FlagsCol = deps.FlagsCol
+	Flags = flags.NewStore(FlagsCol)
 	GeminiAPIKey = deps.GeminiAPIKey
 	GeminiModel = deps.GeminiModel
 	JWTSecret = deps.JWTSecret
 	TokenTTL = deps.TokenTTL
 	if TokenTTL <= 0 { TokenTTL = middleware.DefaultTokenTTL }
+	IdempotencyTTL = deps.IdempotencyTTL
+	if IdempotencyTTL <= 0 { IdempotencyTTL = middleware.DefaultIdempotencyTTL }
+	DeletionGrace = deps.DeletionGrace
+	if DeletionGrace <= 0 { DeletionGrace = DefaultDeletionGrace }
+}
+
+// WithTransaction runs fn in a Mongo transaction. Use the session context for every write,
+// including Outbox.Record, so state changes and their events commit together; the event bus
+// is woken once the transaction commits.
+func WithTransaction(ctx context.Context, fn func(sc mongo.SessionContext) error) error {
+	if Client == nil { return errors.New("mongo client not configured") }
+	session, err := Client.StartSession()
+	if err != nil { return err }
+	defer session.EndSession(ctx)
+	if _, err := session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) { return nil, fn(sc) }); err != nil { return err }
+	Events.Notify()
+	return nil
 }
 
 func VerifyPassword(hash, raw string) error { return middleware.VerifyPassword(hash, raw) }
@@ -67,6 +195,12 @@ func GenerateToken(user *models.User) (string, time.Time, error) {
 	return middleware.GenerateToken(user, JWTSecret, TokenTTL)
 }
 
+// TenantConfig returns the configuration of the campus the request belongs to.
+func TenantConfig(ctx context.Context) tenant.Tenant { return Tenants.Current(ctx) }
+
+// FlagEnabled reports whether the feature flag key is on for the caller in ctx.
+func FlagEnabled(ctx context.Context, key string) bool { return Flags.Enabled(ctx, key) }
+
 func UserIDFromContext(ctx context.Context) (int, bool) { return middleware.UserIDFromContext(ctx) }
 
 func RoleFromContext(ctx context.Context) string { return middleware.RoleFromContext(ctx) }
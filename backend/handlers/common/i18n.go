@@ -0,0 +1,48 @@
+package common
+
+import (
+	"net/http"
+
+	"backend/i18n"
+)
+
+// Locale returns the language negotiated for the request.
+func Locale(r *http.Request) string { return i18n.FromContext(r.Context()) }
+
+// localizeErrors translates the message of an error payload, and the field messages of a
+// validation error, into loc. Other payloads are returned as they are; handlers localize
+// their own labels.
+func localizeErrors(loc string, payload interface{}) interface{} {
+	if loc == i18n.English {
+		return payload
+	}
+	switch p := payload.(type) {
+	case map[string]string:
+		if msg, ok := p["error"]; ok {
+			out := make(map[string]string, len(p))
+			for k, v := range p {
+				out[k] = v
+			}
+			out["error"] = i18n.Translate(loc, msg)
+			return out
+		}
+	case map[string]interface{}:
+		if msg, ok := p["error"].(string); ok {
+			out := make(map[string]interface{}, len(p))
+			for k, v := range p {
+				out[k] = v
+			}
+			out["error"] = i18n.Translate(loc, msg)
+			if fields, ok := p["fields"].(ValidationErrors); ok {
+				localized := make(ValidationErrors, len(fields))
+				for i, fe := range fields {
+					fe.Message = i18n.Translate(loc, fe.Message)
+					localized[i] = fe
+				}
+				out["fields"] = localized
+			}
+			return out
+		}
+	}
+	return payload
+}
@@ -0,0 +1,75 @@
+package common
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/middleware"
+)
+
+// MongoIdempotencyStore keeps idempotency keys in the idempotency_keys collection. The
+// scoped key is the document _id, so the unique index makes Reserve atomic; a TTL index on
+// expires_at drops keys once the replay window has passed.
+type MongoIdempotencyStore struct {
+	Col *mongo.Collection
+}
+
+type idempotencyDoc struct {
+	Key         string              `bson:"_id"`
+	Fingerprint string              `bson:"fingerprint"`
+	Completed   bool                `bson:"completed"`
+	Status      int                 `bson:"status,omitempty"`
+	Header      map[string][]string `bson:"header,omitempty"`
+	Body        []byte              `bson:"body,omitempty"`
+	CreatedAt   time.Time           `bson:"created_at"`
+	ExpiresAt   time.Time           `bson:"expires_at"`
+}
+
+// EnsureIndexes creates the TTL index used to expire keys.
+func (s MongoIdempotencyStore) EnsureIndexes(ctx context.Context) error {
+	_, err := s.Col.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.M{"expires_at": 1}, Options: options.Index().SetExpireAfterSeconds(0)})
+	return err
+}
+
+// Reserve takes over a key whose record has expired but not yet been removed by the TTL
+// monitor, which only runs about once a minute.
+func (s MongoIdempotencyStore) Reserve(ctx context.Context, key, fingerprint string, ttl time.Duration) error {
+	now := time.Now().UTC()
+	doc := idempotencyDoc{Key: key, Fingerprint: fingerprint, CreatedAt: now, ExpiresAt: now.Add(ttl)}
+	_, err := s.Col.InsertOne(ctx, doc)
+	if !mongo.IsDuplicateKeyError(err) {
+		return err
+	}
+	res, err := s.Col.ReplaceOne(ctx, bson.M{"_id": key, "expires_at": bson.M{"$lte": now}}, doc)
+	if err != nil {
+		return err
+	}
+	if res.MatchedCount == 0 {
+		return middleware.ErrIdempotencyKeyExists
+	}
+	return nil
+}
+
+// Load ignores expired records, as if the TTL monitor had already removed them.
+func (s MongoIdempotencyStore) Load(ctx context.Context, key string) (*middleware.IdempotencyRecord, error) {
+	var doc idempotencyDoc
+	if err := s.Col.FindOne(ctx, bson.M{"_id": key, "expires_at": bson.M{"$gt": time.Now().UTC()}}).Decode(&doc); err != nil {
+		return nil, err
+	}
+	return &middleware.IdempotencyRecord{Fingerprint: doc.Fingerprint, Completed: doc.Completed, Status: doc.Status, Header: http.Header(doc.Header), Body: doc.Body}, nil
+}
+
+func (s MongoIdempotencyStore) Complete(ctx context.Context, key string, record middleware.IdempotencyRecord) error {
+	_, err := s.Col.UpdateOne(ctx, bson.M{"_id": key}, bson.M{"$set": bson.M{"completed": true, "status": record.Status, "header": map[string][]string(record.Header), "body": record.Body}})
+	return err
+}
+
+func (s MongoIdempotencyStore) Release(ctx context.Context, key string) error {
+	_, err := s.Col.DeleteOne(ctx, bson.M{"_id": key})
+	return err
+}
@@ -0,0 +1,369 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/models"
+	"backend/tenant"
+)
+
+// The leaderboard ranks users by coins with a dense rank: users with equal coins share a
+// rank and the next distinct total takes the following one. Within a rank, the user who
+// joined first (lowest user ID) is listed first.
+
+// Leaderboard windows. WindowAll ranks lifetime coins from the users collection; the others
+// rank the coins recorded in the coin ledger during the current week (from Monday, UTC),
+// calendar month (UTC) or campus term.
+const (
+	WindowAll   = "all"
+	WindowWeek  = "week"
+	WindowMonth = "month"
+	WindowTerm  = "term"
+)
+
+// Windows lists the leaderboard windows in the order clients should offer them.
+var Windows = []string{WindowAll, WindowWeek, WindowMonth, WindowTerm}
+
+// ScopeCampus is the leaderboard of every matching user on the campus; "course:{id}" and
+// "cohort:{name}" narrow it to one course's students or one cohort.
+const ScopeCampus = "campus"
+
+// LedgerQuest is the coin ledger reason for a completed quest.
+const LedgerQuest = "quest"
+
+// SnapshotSize is how many entries a leaderboard snapshot keeps.
+const SnapshotSize = 100
+
+// ErrNoTerm is returned for the term window when the campus has no term in progress.
+var ErrNoTerm = errors.New("no term is in progress")
+
+// Board selects whose coins are ranked and over which period. Filter applies to user
+// fields; a nil Period ranks lifetime coins.
+type Board struct {
+	Filter bson.M
+	Period *models.LeaderboardPeriod
+}
+
+// RecordCoins appends a dated coin award to the ledger the windowed leaderboards are
+// computed from. Call it in the transaction that changes the user's coins.
+func RecordCoins(ctx context.Context, userID, coins int, reason string, questID int, at time.Time) error {
+	_, err := CoinLedgerCol.InsertOne(ctx, bson.M{"user_id": userID, "coins": coins, "reason": reason, "quest_id": questID, "at": at})
+	return err
+}
+
+// EnsureLeaderboardIndexes indexes the coin ledger by time and user and makes snapshots
+// unique per scope and period.
+func EnsureLeaderboardIndexes(ctx context.Context) error {
+	if _, err := CoinLedgerCol.Raw().Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: tenant.Field, Value: 1}, {Key: "at", Value: 1}}},
+		{Keys: bson.D{{Key: tenant.Field, Value: 1}, {Key: "user_id", Value: 1}}},
+	}); err != nil {
+		return err
+	}
+	_, err := SnapshotsCol.Raw().Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: tenant.Field, Value: 1}, {Key: "scope", Value: 1}, {Key: "period.window", Value: 1}, {Key: "period.start", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	return err
+}
+
+// ParseScope turns a ?scope= value into its canonical form and the user filter it adds.
+func ParseScope(raw string) (string, bson.M, bool) {
+	raw = strings.TrimSpace(raw)
+	kind, value, _ := strings.Cut(raw, ":")
+	switch strings.ToLower(kind) {
+	case "", ScopeCampus:
+		if value != "" {
+			return "", nil, false
+		}
+		return ScopeCampus, bson.M{}, true
+	case "course":
+		id, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || id <= 0 {
+			return "", nil, false
+		}
+		return fmt.Sprintf("course:%d", id), bson.M{"active_courses.course_id": id}, true
+	case "cohort":
+		value = strings.TrimSpace(value)
+		if value == "" {
+			return "", nil, false
+		}
+		return "cohort:" + value, bson.M{"cohort": value}, true
+	}
+	return "", nil, false
+}
+
+// CurrentPeriod returns the period of window containing at, or nil for WindowAll. It
+// returns ErrNoTerm for WindowTerm when no term of g is in progress.
+func CurrentPeriod(window string, at time.Time, g tenant.Gamification) (*models.LeaderboardPeriod, error) {
+	at = at.UTC()
+	switch window {
+	case WindowWeek:
+		start := time.Date(at.Year(), at.Month(), at.Day()-(int(at.Weekday())+6)%7, 0, 0, 0, 0, time.UTC)
+		return weekPeriod(start), nil
+	case WindowMonth:
+		return monthPeriod(time.Date(at.Year(), at.Month(), 1, 0, 0, 0, 0, time.UTC)), nil
+	case WindowTerm:
+		term, ok := g.TermAt(at)
+		if !ok {
+			return nil, ErrNoTerm
+		}
+		return &models.LeaderboardPeriod{Window: WindowTerm, Label: term.Name, Start: term.Start, End: term.End}, nil
+	}
+	return nil, nil
+}
+
+// PreviousPeriod returns the latest period of window that has closed by at. ok is false
+// for WindowAll and when no term of g has ended yet.
+func PreviousPeriod(window string, at time.Time, g tenant.Gamification) (*models.LeaderboardPeriod, bool) {
+	switch window {
+	case WindowWeek, WindowMonth:
+		current, _ := CurrentPeriod(window, at, g)
+		if window == WindowWeek {
+			return weekPeriod(current.Start.AddDate(0, 0, -7)), true
+		}
+		return monthPeriod(current.Start.AddDate(0, -1, 0)), true
+	case WindowTerm:
+		term, ok := g.LastTermBefore(at.UTC())
+		if !ok {
+			return nil, false
+		}
+		return &models.LeaderboardPeriod{Window: WindowTerm, Label: term.Name, Start: term.Start, End: term.End}, true
+	}
+	return nil, false
+}
+
+func weekPeriod(start time.Time) *models.LeaderboardPeriod {
+	year, week := start.ISOWeek()
+	return &models.LeaderboardPeriod{Window: WindowWeek, Label: fmt.Sprintf("%d-W%02d", year, week), Start: start, End: start.AddDate(0, 0, 7)}
+}
+
+func monthPeriod(start time.Time) *models.LeaderboardPeriod {
+	return &models.LeaderboardPeriod{Window: WindowMonth, Label: start.Format("2006-01"), Start: start, End: start.AddDate(0, 1, 0)}
+}
+
+// StudentsOnly is the leaderboard filter unless the caller asks for another role.
+func StudentsOnly() bson.M { return bson.M{"role": RoleStudent} }
+
+// source is the collection the board's pipeline runs on.
+func (b Board) source() Aggregator {
+	if b.Period != nil {
+		return CoinLedgerCol
+	}
+	return UsersCol
+}
+
+func (b Board) filter() bson.M {
+	if b.Filter == nil {
+		return bson.M{}
+	}
+	return b.Filter
+}
+
+// rankStages selects the users on the board and adds their rank. A windowed board sums the
+// period's ledger entries per user and keeps the users matching the filter.
+func (b Board) rankStages() bson.A {
+	stages := bson.A{bson.M{"$match": b.filter()}}
+	if p := b.Period; p != nil {
+		stages = bson.A{
+			bson.M{"$match": bson.M{"at": bson.M{"$gte": p.Start, "$lt": p.End}}},
+			bson.M{"$group": bson.M{
+				"_id":              "$user_id",
+				"coins":            bson.M{"$sum": "$coins"},
+				"completed_quests": bson.M{"$sum": bson.M{"$cond": bson.A{bson.M{"$eq": bson.A{"$reason", LedgerQuest}}, 1, 0}}},
+				"tid":              bson.M{"$first": "$" + tenant.Field},
+			}},
+			bson.M{"$lookup": bson.M{
+				"from": UsersCol.Raw().Name(),
+				"let":  bson.M{"uid": "$_id", "tid": "$tid"},
+				"pipeline": bson.A{
+					bson.M{"$match": bson.M{"$and": bson.A{sameUser(), b.filter()}}},
+					bson.M{"$project": bson.M{"name": 1, "streak": 1}},
+				},
+				"as": "user",
+			}},
+			bson.M{"$unwind": "$user"},
+			bson.M{"$project": bson.M{"_id": 0, "user_id": "$_id", "name": "$user.name", "streak": "$user.streak", "coins": 1, "completed_quests": 1, tenant.Field: "$tid"}},
+		}
+	}
+	return append(stages, bson.M{"$setWindowFields": bson.M{
+		"sortBy": bson.M{"coins": -1},
+		"output": bson.M{"rank": bson.M{"$denseRank": bson.M{}}},
+	}})
+}
+
+// sameUser matches the documents of $$uid in campus $$tid inside a $lookup pipeline.
+func sameUser() bson.M {
+	return bson.M{"$expr": bson.M{"$and": bson.A{
+		bson.M{"$eq": bson.A{"$user_id", "$$uid"}},
+		bson.M{"$eq": bson.A{"$" + tenant.Field, "$$tid"}},
+	}}}
+}
+
+// rankOrder is the display order of ranked users.
+var rankOrder = bson.D{{Key: "rank", Value: 1}, {Key: "user_id", Value: 1}}
+
+// entryStages turns ranked users into leaderboard entries. Lifetime boards count each
+// user's completed quests in the same campus; windowed boards counted them from the ledger.
+func (b Board) entryStages() bson.A {
+	if b.Period != nil {
+		return bson.A{bson.M{"$project": bson.M{"_id": 0, tenant.Field: 0}}}
+	}
+	return bson.A{
+		bson.M{"$lookup": bson.M{
+			"from": UserQuestsCol.Raw().Name(),
+			"let":  bson.M{"uid": "$user_id", "tid": "$" + tenant.Field},
+			"pipeline": bson.A{
+				bson.M{"$match": bson.M{"$and": bson.A{sameUser(), bson.M{"completed": true}}}},
+				bson.M{"$count": "n"},
+			},
+			"as": "completed",
+		}},
+		bson.M{"$project": bson.M{
+			"_id":              0,
+			"rank":             1,
+			"user_id":          1,
+			"name":             1,
+			"streak":           1,
+			"coins":            1,
+			"completed_quests": bson.M{"$ifNull": bson.A{bson.M{"$first": "$completed.n"}, 0}},
+		}},
+	}
+}
+
+// Top returns the limit best-ranked entries of b, or all of them when limit is 0.
+func (b Board) Top(ctx context.Context, limit int64) ([]models.LeaderboardEntry, error) {
+	stages := append(b.rankStages(), bson.M{"$sort": rankOrder})
+	if limit > 0 {
+		stages = append(stages, bson.M{"$limit": limit})
+	}
+	stages = append(stages, b.entryStages()...)
+	cursor, err := b.source().Aggregate(ctx, stages)
+	if err != nil {
+		return nil, err
+	}
+	leaders := []models.LeaderboardEntry{}
+	if err := cursor.All(ctx, &leaders); err != nil {
+		return nil, err
+	}
+	return leaders, nil
+}
+
+// Page returns one page of b in rank order.
+func (b Board) Page(ctx context.Context, p Page) ([]models.LeaderboardEntry, PageLinks, error) {
+	return AggregatePage[models.LeaderboardEntry](ctx, b.source(), b.rankStages(), b.entryStages(), p)
+}
+
+// Around returns userID's entry on b with the entries just above and below it in rank
+// order. me is nil when userID is not on the board, for example because they earned no
+// coins in the period.
+func (b Board) Around(ctx context.Context, userID int) (me *models.LeaderboardEntry, around []models.LeaderboardEntry, err error) {
+	stages := append(b.rankStages(),
+		bson.M{"$setWindowFields": bson.M{
+			"sortBy": rankOrder,
+			"output": bson.M{"around": bson.M{
+				"$push":  bson.M{"rank": "$rank", "user_id": "$user_id", "name": "$name", "streak": "$streak", "coins": "$coins", "completed_quests": "$completed_quests", tenant.Field: "$" + tenant.Field},
+				"window": bson.M{"documents": bson.A{-1, 1}},
+			}},
+		}},
+		bson.M{"$match": bson.M{"user_id": userID}},
+		bson.M{"$unwind": "$around"},
+		bson.M{"$replaceWith": "$around"},
+	)
+	stages = append(stages, b.entryStages()...)
+	cursor, err := b.source().Aggregate(ctx, stages)
+	if err != nil {
+		return nil, nil, err
+	}
+	around = []models.LeaderboardEntry{}
+	if err := cursor.All(ctx, &around); err != nil {
+		return nil, nil, err
+	}
+	for i := range around {
+		if around[i].UserID == userID {
+			entry := around[i]
+			return &entry, around, nil
+		}
+	}
+	return nil, nil, nil
+}
+
+// loadLeaderboard returns the limit best-ranked students of all time; CollectLeaderboard
+// serves it from the cache.
+func loadLeaderboard(ctx context.Context, limit int64) ([]models.LeaderboardEntry, error) {
+	return Board{Filter: StudentsOnly()}.Top(ctx, limit)
+}
+
+// LeaderboardScopes lists the scopes worth snapshotting on the campus in ctx: the campus
+// itself, every course a student is enrolled in and every student cohort.
+func LeaderboardScopes(ctx context.Context) ([]string, error) {
+	scopes := []string{ScopeCampus}
+	courses, err := UsersCol.Distinct(ctx, "active_courses.course_id", StudentsOnly())
+	if err != nil {
+		return nil, err
+	}
+	ids := []int{}
+	for _, c := range courses {
+		switch id := c.(type) {
+		case int32:
+			ids = append(ids, int(id))
+		case int64:
+			ids = append(ids, int(id))
+		}
+	}
+	sort.Ints(ids)
+	for _, id := range ids {
+		scopes = append(scopes, fmt.Sprintf("course:%d", id))
+	}
+	cohorts, err := UsersCol.Distinct(ctx, "cohort", StudentsOnly())
+	if err != nil {
+		return nil, err
+	}
+	names := []string{}
+	for _, c := range cohorts {
+		if name, ok := c.(string); ok && name != "" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		scopes = append(scopes, "cohort:"+name)
+	}
+	return scopes, nil
+}
+
+// SnapshotLeaderboard stores the top SnapshotSize students of scope over period unless a
+// snapshot of them already exists, so re-running it is harmless.
+func SnapshotLeaderboard(ctx context.Context, raw string, period models.LeaderboardPeriod) error {
+	scope, filter, ok := ParseScope(raw)
+	if !ok {
+		return fmt.Errorf("invalid leaderboard scope %q", raw)
+	}
+	key := bson.M{"scope": scope, "period.window": period.Window, "period.start": period.Start}
+	if n, err := SnapshotsCol.CountDocuments(ctx, key); err != nil || n > 0 {
+		return err
+	}
+	for k, v := range StudentsOnly() {
+		filter[k] = v
+	}
+	entries, err := Board{Filter: filter, Period: &period}.Top(ctx, SnapshotSize)
+	if err != nil {
+		return err
+	}
+	// The unique index settles a race with another run.
+	_, err = SnapshotsCol.InsertOne(ctx, models.LeaderboardSnapshot{Scope: scope, Period: period, Entries: entries, TakenAt: time.Now().UTC()})
+	if mongo.IsDuplicateKeyError(err) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,112 @@
+package common
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"backend/models"
+	"backend/tenant"
+)
+
+func TestParseScope(t *testing.T) {
+	tests := []struct {
+		raw    string
+		scope  string
+		filter bson.M
+		ok     bool
+	}{
+		{"", ScopeCampus, bson.M{}, true},
+		{" Campus ", ScopeCampus, bson.M{}, true},
+		{"course: 12", "course:12", bson.M{"active_courses.course_id": 12}, true},
+		{"COURSE:7", "course:7", bson.M{"active_courses.course_id": 7}, true},
+		{"cohort: 2026 A ", "cohort:2026 A", bson.M{"cohort": "2026 A"}, true},
+		{"campus:main", "", nil, false},
+		{"course:0", "", nil, false},
+		{"course:x", "", nil, false},
+		{"cohort:", "", nil, false},
+		{"school:1", "", nil, false},
+	}
+	for _, tt := range tests {
+		scope, filter, ok := ParseScope(tt.raw)
+		if scope != tt.scope || ok != tt.ok || !reflect.DeepEqual(filter, tt.filter) {
+			t.Errorf("ParseScope(%q) = %q, %v, %v; want %q, %v, %v", tt.raw, scope, filter, ok, tt.scope, tt.filter, tt.ok)
+		}
+	}
+}
+
+func TestLeaderboardPeriods(t *testing.T) {
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+	g := tenant.Gamification{Terms: []tenant.Term{
+		{Name: "Spring 2026", Start: day(2026, time.January, 5), End: day(2026, time.May, 1)},
+		{Name: "Autumn 2026", Start: day(2026, time.August, 3), End: day(2026, time.December, 1)},
+	}}
+	// Sunday evening in Kolkata is still Sunday in UTC; Monday morning there is not.
+	sunday := time.Date(2026, time.October, 18, 23, 0, 0, 0, time.UTC)
+	mondayIST := time.Date(2026, time.October, 19, 2, 0, 0, 0, time.FixedZone("IST", 5*3600+1800))
+
+	tests := []struct {
+		name   string
+		window string
+		at     time.Time
+		want   *models.LeaderboardPeriod
+	}{
+		{"all time", WindowAll, sunday, nil},
+		{"week from Monday", WindowWeek, sunday, &models.LeaderboardPeriod{Window: WindowWeek, Label: "2026-W42", Start: day(2026, time.October, 12), End: day(2026, time.October, 19)}},
+		{"week in UTC", WindowWeek, mondayIST, &models.LeaderboardPeriod{Window: WindowWeek, Label: "2026-W42", Start: day(2026, time.October, 12), End: day(2026, time.October, 19)}},
+		{"ISO week of the previous year", WindowWeek, day(2027, time.January, 1), &models.LeaderboardPeriod{Window: WindowWeek, Label: "2026-W53", Start: day(2026, time.December, 28), End: day(2027, time.January, 4)}},
+		{"month", WindowMonth, sunday, &models.LeaderboardPeriod{Window: WindowMonth, Label: "2026-10", Start: day(2026, time.October, 1), End: day(2026, time.November, 1)}},
+		{"term", WindowTerm, sunday, &models.LeaderboardPeriod{Window: WindowTerm, Label: "Autumn 2026", Start: day(2026, time.August, 3), End: day(2026, time.December, 1)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CurrentPeriod(tt.window, tt.at, g)
+			if err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CurrentPeriod = %+v, %v; want %+v", got, err, tt.want)
+			}
+		})
+	}
+	if _, err := CurrentPeriod(WindowTerm, day(2026, time.June, 1), g); !errors.Is(err, ErrNoTerm) {
+		t.Errorf("CurrentPeriod between terms: err = %v, want ErrNoTerm", err)
+	}
+
+	previous := []struct {
+		window string
+		at     time.Time
+		label  string
+		ok     bool
+	}{
+		{WindowWeek, sunday, "2026-W41", true},
+		{WindowMonth, day(2026, time.January, 15), "2025-12", true},
+		{WindowTerm, sunday, "Spring 2026", true},
+		{WindowTerm, day(2026, time.December, 1), "Autumn 2026", true},
+		{WindowTerm, day(2026, time.April, 1), "", false},
+		{WindowAll, sunday, "", false},
+	}
+	for _, tt := range previous {
+		p, ok := PreviousPeriod(tt.window, tt.at, g)
+		if ok != tt.ok || (ok && p.Label != tt.label) {
+			t.Errorf("PreviousPeriod(%s, %v) = %+v, %v; want %q, %v", tt.window, tt.at, p, ok, tt.label, tt.ok)
+		}
+	}
+}
+
+// The lifetime board filters users and then dense-ranks them by coins.
+func TestLifetimeRankStages(t *testing.T) {
+	want := bson.A{
+		bson.M{"$match": bson.M{"role": RoleStudent}},
+		bson.M{"$setWindowFields": bson.M{
+			"sortBy": bson.M{"coins": -1},
+			"output": bson.M{"rank": bson.M{"$denseRank": bson.M{}}},
+		}},
+	}
+	if got := (Board{Filter: StudentsOnly()}).rankStages(); !reflect.DeepEqual(got, want) {
+		t.Errorf("rankStages = %v, want %v", got, want)
+	}
+	if got := (Board{}).rankStages()[0]; !reflect.DeepEqual(got, bson.M{"$match": bson.M{}}) {
+		t.Errorf("unfiltered board starts with %v", got)
+	}
+}
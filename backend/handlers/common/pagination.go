@@ -0,0 +1,355 @@
+package common
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/apiversion"
+)
+
+// ListSpec declares how a list endpoint may be sorted and paged. Sorts maps the public
+// `sort` query value to a BSON field; TieBreaker must be a unique field so keyset pages are
+// stable when several documents share a sort value.
+type ListSpec struct {
+	Sorts        map[string]string
+	DefaultSort  string
+	DefaultDesc  bool
+	TieBreaker   string
+	DefaultLimit int
+	MaxLimit     int
+}
+
+// ErrInvalidCursor is returned by ParsePage for a cursor this server did not issue, or one
+// issued for a different list, filter or sort. WriteValidationError renders it as a 400.
+var ErrInvalidCursor = errors.New("cursor is not valid")
+
+// Page is a parsed `limit`/`sort`/`order`/`cursor` query for one list request. A zero Limit
+// reads the whole list.
+type Page struct {
+	Sort   string
+	Field  string
+	Desc   bool
+	Limit  int
+	tie    string
+	list   []byte
+	cursor *pageCursor
+}
+
+// PageLinks holds the opaque cursors for the neighbouring pages; empty means none.
+type PageLinks struct {
+	Next string `json:"next,omitempty"`
+	Prev string `json:"prev,omitempty"`
+}
+
+type pageCursor struct {
+	Sort  string      `bson:"s"`
+	Desc  bool        `bson:"d"`
+	Value interface{} `bson:"v"`
+	Tie   interface{} `bson:"t"`
+	Prev  bool        `bson:"p"`
+	List  []byte      `bson:"l"`
+}
+
+// ParsePage reads paging parameters from r. A cursor carries its own sort and order, so
+// clients only need to echo `cursor` (plus the same filters) to move between pages. It is
+// only valid for the path, filters and sort it was issued for. Lists sent as bare arrays
+// predate paging and return everything unless the client sets `limit`.
+func ParsePage(r *http.Request, spec ListSpec) (Page, error) {
+	q := r.URL.Query()
+	page := Page{Sort: spec.DefaultSort, Desc: spec.DefaultDesc, Limit: spec.DefaultLimit, tie: spec.TieBreaker}
+	whole := apiversion.ListsUnwrapped(r.Context())
+	if whole {
+		page.Limit = 0
+	}
+	var errs ValidationErrors
+	if raw := strings.TrimSpace(q.Get("limit")); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			errs = append(errs, FieldError{Field: "limit", Rule: "min", Message: "must be a positive integer"})
+		} else {
+			page.Limit = n
+		}
+	}
+	if spec.MaxLimit > 0 && page.Limit > spec.MaxLimit && !whole {
+		page.Limit = spec.MaxLimit
+	}
+	if raw := strings.ToLower(strings.TrimSpace(q.Get("sort"))); raw != "" {
+		if _, ok := spec.Sorts[raw]; !ok {
+			allowed := make([]string, 0, len(spec.Sorts))
+			for k := range spec.Sorts {
+				allowed = append(allowed, k)
+			}
+			sort.Strings(allowed)
+			errs = append(errs, FieldError{Field: "sort", Rule: "oneof", Message: "must be one of: " + strings.Join(allowed, ", ")})
+		} else {
+			page.Sort = raw
+		}
+	}
+	order := strings.ToLower(strings.TrimSpace(q.Get("order")))
+	switch order {
+	case "":
+	case "asc":
+		page.Desc = false
+	case "desc":
+		page.Desc = true
+	default:
+		errs = append(errs, FieldError{Field: "order", Rule: "oneof", Message: "must be one of: asc, desc"})
+	}
+	if raw := strings.TrimSpace(q.Get("cursor")); raw != "" {
+		c, err := decodeCursor(raw)
+		if err != nil || spec.Sorts[c.Sort] == "" || !hmac.Equal(c.List, listKey(r, c.Sort, c.Desc)) {
+			return page, ErrInvalidCursor
+		}
+		// A sort or order sent alongside the cursor must be the one it was issued for.
+		if (q.Has("sort") && page.Sort != c.Sort) || (order != "" && page.Desc != c.Desc) {
+			return page, ErrInvalidCursor
+		}
+		page.cursor = c
+		page.Sort = c.Sort
+		page.Desc = c.Desc
+	}
+	if len(errs) > 0 {
+		return page, errs
+	}
+	page.Field = spec.Sorts[page.Sort]
+	page.list = listKey(r, page.Sort, page.Desc)
+	return page, nil
+}
+
+// pagingParams are the query parameters that may change between pages of one list.
+var pagingParams = map[string]bool{"cursor": true, "limit": true, "sort": true, "order": true}
+
+// listKey hashes the path, the filter parameters and the sort of a list request. Cursors
+// carry it so that one issued for a list cannot page through another: the keyset position
+// means nothing under a different filter or order. Filter values are trimmed and empty ones
+// dropped, matching how handlers read them.
+func listKey(r *http.Request, sortName string, desc bool) []byte {
+	q := r.URL.Query()
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		if !pagingParams[k] {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	h := sha256.New()
+	h.Write([]byte(r.URL.Path + "\x00" + sortName + "\x00" + strconv.FormatBool(desc)))
+	for _, k := range keys {
+		values := []string{}
+		for _, v := range q[k] {
+			if v = strings.TrimSpace(v); v != "" {
+				values = append(values, v)
+			}
+		}
+		if len(values) == 0 {
+			continue
+		}
+		sort.Strings(values)
+		h.Write([]byte("\x00" + k + "=" + strings.Join(values, "\x01")))
+	}
+	return h.Sum(nil)[:16]
+}
+
+// scanDesc is the direction the collection is actually read in; walking backwards from a
+// prev cursor flips it and the results are reversed afterwards.
+func (p Page) scanDesc() bool {
+	if p.cursor != nil && p.cursor.Prev {
+		return !p.Desc
+	}
+	return p.Desc
+}
+
+// Query merges the keyset condition for the cursor into filter and returns matching find
+// options. One extra document is requested to detect whether another page exists.
+func (p Page) Query(filter bson.M) (bson.M, *options.FindOptions) {
+	dir := 1
+	op := "$gt"
+	if p.scanDesc() {
+		dir = -1
+		op = "$lt"
+	}
+	order := bson.D{{Key: p.Field, Value: dir}}
+	if p.tie != p.Field {
+		order = append(order, bson.E{Key: p.tie, Value: dir})
+	}
+	opts := options.Find().SetSort(order)
+	if p.Limit > 0 {
+		opts.SetLimit(int64(p.Limit + 1))
+	}
+	if p.cursor == nil {
+		return filter, opts
+	}
+	var keyset bson.M
+	if p.tie == p.Field {
+		keyset = bson.M{p.Field: bson.M{op: p.cursor.Value}}
+	} else {
+		keyset = bson.M{"$or": bson.A{
+			bson.M{p.Field: bson.M{op: p.cursor.Value}},
+			bson.M{p.Field: p.cursor.Value, p.tie: bson.M{op: p.cursor.Tie}},
+		}}
+	}
+	if len(filter) == 0 {
+		return keyset, opts
+	}
+	return bson.M{"$and": bson.A{filter, keyset}}, opts
+}
+
+// Finder is the query side of a collection, scoped or raw.
+type Finder interface {
+	Find(ctx context.Context, filter interface{}, opts ...*options.FindOptions) (*mongo.Cursor, error)
+}
+
+// Aggregator is the aggregation side of a collection, scoped or raw.
+type Aggregator interface {
+	Aggregate(ctx context.Context, pipeline interface{}, opts ...*options.AggregateOptions) (*mongo.Cursor, error)
+}
+
+// FindPage runs the paged query against col and decodes one page of T in display order.
+func FindPage[T any](ctx context.Context, col Finder, filter bson.M, p Page) ([]T, PageLinks, error) {
+	query, opts := p.Query(filter)
+	cursor, err := col.Find(ctx, query, opts)
+	if err != nil {
+		return nil, PageLinks{}, err
+	}
+	return readPage[T](ctx, cursor, p)
+}
+
+// AggregatePage runs pipeline and pages through its output like FindPage, for lists whose
+// sort field is computed by the pipeline. The stages in decorate run on the cut page only,
+// so per-item joins are not paid for documents outside it; they must keep the order.
+func AggregatePage[T any](ctx context.Context, col Aggregator, pipeline, decorate bson.A, p Page) ([]T, PageLinks, error) {
+	query, opts := p.Query(bson.M{})
+	stages := append(bson.A{}, pipeline...)
+	if len(query) > 0 {
+		stages = append(stages, bson.M{"$match": query})
+	}
+	stages = append(stages, bson.M{"$sort": opts.Sort})
+	if opts.Limit != nil {
+		stages = append(stages, bson.M{"$limit": *opts.Limit})
+	}
+	stages = append(stages, decorate...)
+	cursor, err := col.Aggregate(ctx, stages)
+	if err != nil {
+		return nil, PageLinks{}, err
+	}
+	return readPage[T](ctx, cursor, p)
+}
+
+// readPage decodes a cursor opened with p's query into one page of T in display order.
+func readPage[T any](ctx context.Context, cursor *mongo.Cursor, p Page) ([]T, PageLinks, error) {
+	defer cursor.Close(ctx)
+	raws := []bson.Raw{}
+	for cursor.Next(ctx) {
+		raws = append(raws, append(bson.Raw(nil), cursor.Current...))
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, PageLinks{}, err
+	}
+	hasMore := p.Limit > 0 && len(raws) > p.Limit
+	if hasMore {
+		raws = raws[:p.Limit]
+	}
+	backwards := p.cursor != nil && p.cursor.Prev
+	if backwards {
+		for i, j := 0, len(raws)-1; i < j; i, j = i+1, j-1 {
+			raws[i], raws[j] = raws[j], raws[i]
+		}
+	}
+	items := make([]T, 0, len(raws))
+	for _, raw := range raws {
+		var item T
+		if err := bson.Unmarshal(raw, &item); err != nil {
+			continue
+		}
+		items = append(items, item)
+	}
+	var links PageLinks
+	if len(raws) == 0 {
+		return items, links, nil
+	}
+	if (!backwards && hasMore) || (backwards && p.cursor != nil) {
+		links.Next = p.cursorFor(raws[len(raws)-1], false)
+	}
+	if (!backwards && p.cursor != nil) || (backwards && hasMore) {
+		links.Prev = p.cursorFor(raws[0], true)
+	}
+	return items, links, nil
+}
+
+func (p Page) cursorFor(raw bson.Raw, prev bool) string {
+	c := pageCursor{Sort: p.Sort, Desc: p.Desc, Prev: prev, List: p.list}
+	if v, err := raw.LookupErr(strings.Split(p.Field, ".")...); err == nil {
+		_ = v.Unmarshal(&c.Value)
+	}
+	if v, err := raw.LookupErr(strings.Split(p.tie, ".")...); err == nil {
+		_ = v.Unmarshal(&c.Tie)
+	}
+	return encodeCursor(c)
+}
+
+// Cursors are signed, because their values go into the query: an edited cursor could
+// otherwise carry an operator document such as {"$ne": null} into the keyset filter.
+
+func encodeCursor(c pageCursor) string {
+	data, err := bson.Marshal(c)
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(data) + "." + base64.RawURLEncoding.EncodeToString(cursorMAC(data))
+}
+
+func decodeCursor(raw string) (*pageCursor, error) {
+	payload, sig, ok := strings.Cut(raw, ".")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(sig)
+	if err != nil || !hmac.Equal(mac, cursorMAC(data)) {
+		return nil, ErrInvalidCursor
+	}
+	var c pageCursor
+	if err := bson.Unmarshal(data, &c); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	// Sort values are scalars; a document or array is never issued, signed or not.
+	for _, v := range []interface{}{c.Value, c.Tie} {
+		switch v.(type) {
+		case bson.D, bson.M, bson.A, []interface{}, map[string]interface{}:
+			return nil, ErrInvalidCursor
+		}
+	}
+	return &c, nil
+}
+
+func cursorMAC(data []byte) []byte {
+	mac := hmac.New(sha256.New, []byte("cursor:"+JWTSecret))
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+// CaseInsensitive matches a field equal to value ignoring case, for list filters.
+func CaseInsensitive(value string) primitive.Regex {
+	return primitive.Regex{Pattern: "^" + regexp.QuoteMeta(value) + "$", Options: "i"}
+}
+
+// ContainsText matches a field containing value ignoring case, for list filters.
+func ContainsText(value string) primitive.Regex {
+	return primitive.Regex{Pattern: regexp.QuoteMeta(value), Options: "i"}
+}
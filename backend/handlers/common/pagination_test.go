@@ -0,0 +1,226 @@
+package common
+
+import (
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"backend/apiversion"
+)
+
+var testListSpec = ListSpec{Sorts: map[string]string{"id": "poll_id", "question": "question"}, DefaultSort: "id", TieBreaker: "poll_id", DefaultLimit: 20, MaxLimit: 100}
+
+func withSecret(t *testing.T, secret string) {
+	t.Helper()
+	old := JWTSecret
+	JWTSecret = secret
+	t.Cleanup(func() { JWTSecret = old })
+}
+
+func TestCursorRoundTrip(t *testing.T) {
+	withSecret(t, "secret")
+	at := primitive.NewDateTimeFromTime(time.Date(2026, time.March, 2, 9, 0, 0, 0, time.UTC))
+	id := primitive.NewObjectID()
+	tests := []pageCursor{
+		{Sort: "id", Value: int32(7), Tie: int32(7)},
+		{Sort: "question", Desc: true, Value: "Which?", Tie: int32(3), Prev: true},
+		{Sort: "at", Desc: true, Value: at, Tie: id},
+	}
+	for _, want := range tests {
+		got, err := decodeCursor(encodeCursor(want))
+		if err != nil {
+			t.Fatalf("decode %+v: %v", want, err)
+		}
+		if !reflect.DeepEqual(*got, want) {
+			t.Errorf("round trip = %+v, want %+v", *got, want)
+		}
+	}
+}
+
+func TestCursorRejectsTampering(t *testing.T) {
+	withSecret(t, "secret")
+	valid := encodeCursor(pageCursor{Sort: "id", Value: int32(7), Tie: int32(7)})
+	payload, sig, _ := strings.Cut(valid, ".")
+	forged, _ := bson.Marshal(pageCursor{Sort: "id", Value: bson.M{"$ne": nil}, Tie: int32(0)})
+	forgedPayload := base64.RawURLEncoding.EncodeToString(forged)
+
+	otherSecret := func() string {
+		JWTSecret = "other"
+		defer func() { JWTSecret = "secret" }()
+		return encodeCursor(pageCursor{Sort: "id", Value: int32(7), Tie: int32(7)})
+	}()
+	tests := map[string]string{
+		"unsigned":            payload,
+		"not base64":          "!!!." + sig,
+		"payload swapped":     forgedPayload + "." + sig,
+		"signature truncated": payload + "." + sig[:10],
+		"other secret":        otherSecret,
+	}
+	for name, raw := range tests {
+		if _, err := decodeCursor(raw); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("%s: err = %v, want ErrInvalidCursor", name, err)
+		}
+	}
+}
+
+// Even a correctly signed cursor may not carry a document or array into the keyset filter.
+func TestCursorRejectsOperatorValues(t *testing.T) {
+	withSecret(t, "secret")
+	for _, v := range []interface{}{bson.M{"$ne": nil}, bson.D{{Key: "$gt", Value: ""}}, bson.A{1, 2}} {
+		raw := encodeCursor(pageCursor{Sort: "id", Value: v, Tie: int32(1)})
+		if _, err := decodeCursor(raw); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("value %v: err = %v, want ErrInvalidCursor", v, err)
+		}
+		raw = encodeCursor(pageCursor{Sort: "id", Value: int32(1), Tie: v})
+		if _, err := decodeCursor(raw); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("tie %v: err = %v, want ErrInvalidCursor", v, err)
+		}
+	}
+}
+
+func TestParsePage(t *testing.T) {
+	withSecret(t, "secret")
+	cursor := issueCursor("/polls", pageCursor{Sort: "question", Desc: true, Value: "Which?", Tie: int32(3)})
+	foreign := issueCursor("/polls", pageCursor{Sort: "rank", Value: int32(1), Tie: int32(1)})
+	tests := []struct {
+		name      string
+		query     string
+		unwrapped bool
+		wantLimit int
+		wantSort  string
+		wantDesc  bool
+		wantErr   error
+	}{
+		{"defaults", "", false, 20, "id", false, nil},
+		{"limit and order", "limit=5&sort=question&order=desc", false, 5, "question", true, nil},
+		{"limit capped", "limit=500", false, 100, "id", false, nil},
+		{"cursor sets sort", "cursor=" + cursor, false, 20, "question", true, nil},
+		{"bare array lists everything", "", true, 0, "id", false, nil},
+		{"bare array keeps an explicit limit", "limit=500", true, 500, "id", false, nil},
+		{"tampered cursor", "cursor=" + cursor + "x", false, 0, "", false, ErrInvalidCursor},
+		{"cursor from another list", "cursor=" + foreign, false, 0, "", false, ErrInvalidCursor},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var page Page
+			var err error
+			h := func(w http.ResponseWriter, r *http.Request) { page, err = ParsePage(r, testListSpec) }
+			if tt.unwrapped {
+				h = apiversion.UnwrapList(h)
+			}
+			h(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/polls?"+tt.query, nil))
+			if tt.wantErr != nil || err != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("err = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if page.Limit != tt.wantLimit || page.Sort != tt.wantSort || page.Desc != tt.wantDesc {
+				t.Errorf("page = limit %d, sort %q, desc %v; want %d, %q, %v", page.Limit, page.Sort, page.Desc, tt.wantLimit, tt.wantSort, tt.wantDesc)
+			}
+		})
+	}
+}
+
+// issueCursor signs c as ParsePage would have issued it for target.
+func issueCursor(target string, c pageCursor) string {
+	c.List = listKey(httptest.NewRequest(http.MethodGet, target, nil), c.Sort, c.Desc)
+	return encodeCursor(c)
+}
+
+func TestParsePageBindsCursorToList(t *testing.T) {
+	withSecret(t, "secret")
+	byQuestion := pageCursor{Sort: "question", Desc: true, Value: "Which?", Tie: int32(3)}
+	filtered := issueCursor("/polls?q=which&closed=false&limit=5", byQuestion)
+	unbound := encodeCursor(byQuestion)
+	tests := []struct {
+		name   string
+		target string
+		valid  bool
+	}{
+		{"same filters", "/polls?q=which&closed=false&cursor=" + filtered, true},
+		{"filters reordered and padded", "/polls?closed=+false+&q=which&tag=&cursor=" + filtered, true},
+		{"other limit", "/polls?q=which&closed=false&limit=50&cursor=" + filtered, true},
+		{"sort and order echoed", "/polls?q=which&closed=false&sort=question&order=desc&cursor=" + filtered, true},
+		{"filter changed", "/polls?q=what&closed=false&cursor=" + filtered, false},
+		{"filter dropped", "/polls?q=which&cursor=" + filtered, false},
+		{"filter added", "/polls?q=which&closed=false&tag=ai&cursor=" + filtered, false},
+		{"other list", "/research/posts?q=which&closed=false&cursor=" + filtered, false},
+		{"other sort", "/polls?q=which&closed=false&sort=id&cursor=" + filtered, false},
+		{"other order", "/polls?q=which&closed=false&order=asc&cursor=" + filtered, false},
+		{"no list hash", "/polls?cursor=" + unbound, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			page, err := ParsePage(httptest.NewRequest(http.MethodGet, tt.target, nil), testListSpec)
+			if tt.valid && (err != nil || page.cursor == nil) {
+				t.Errorf("err = %v, want the cursor accepted", err)
+			}
+			if !tt.valid && !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("err = %v, want ErrInvalidCursor", err)
+			}
+		})
+	}
+}
+
+func TestPageCursorsCarryListKey(t *testing.T) {
+	withSecret(t, "secret")
+	page, err := ParsePage(httptest.NewRequest(http.MethodGet, "/polls?q=which&sort=question", nil), testListSpec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw, _ := bson.Marshal(bson.M{"question": "Which?", "poll_id": int32(3)})
+	next := page.cursorFor(raw, false)
+	if _, err := ParsePage(httptest.NewRequest(http.MethodGet, "/polls?q=which&cursor="+next, nil), testListSpec); err != nil {
+		t.Errorf("follow-up page: %v", err)
+	}
+	if _, err := ParsePage(httptest.NewRequest(http.MethodGet, "/polls?cursor="+next, nil), testListSpec); !errors.Is(err, ErrInvalidCursor) {
+		t.Errorf("follow-up page without the filter: err = %v, want ErrInvalidCursor", err)
+	}
+}
+
+func TestParsePageValidation(t *testing.T) {
+	_, err := ParsePage(httptest.NewRequest(http.MethodGet, "/polls?limit=0&sort=votes&order=up", nil), testListSpec)
+	var verrs ValidationErrors
+	if !errors.As(err, &verrs) || len(verrs) != 3 {
+		t.Fatalf("err = %v, want three field errors", err)
+	}
+}
+
+func TestPageQuery(t *testing.T) {
+	filter := bson.M{"closed": false}
+	page := Page{Sort: "question", Field: "question", Desc: true, Limit: 10, tie: "poll_id"}
+	got, opts := page.Query(filter)
+	if !reflect.DeepEqual(got, filter) || *opts.Limit != 11 {
+		t.Errorf("first page: filter %v, limit %d", got, *opts.Limit)
+	}
+	if want := (bson.D{{Key: "question", Value: -1}, {Key: "poll_id", Value: -1}}); !reflect.DeepEqual(opts.Sort, want) {
+		t.Errorf("sort = %v, want %v", opts.Sort, want)
+	}
+
+	page.cursor = &pageCursor{Sort: "question", Desc: true, Value: "M", Tie: int32(4), Prev: true}
+	got, opts = page.Query(filter)
+	want := bson.M{"$and": bson.A{filter, bson.M{"$or": bson.A{
+		bson.M{"question": bson.M{"$gt": "M"}},
+		bson.M{"question": "M", "poll_id": bson.M{"$gt": int32(4)}},
+	}}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("prev page filter = %v, want %v", got, want)
+	}
+	if want := (bson.D{{Key: "question", Value: 1}, {Key: "poll_id", Value: 1}}); !reflect.DeepEqual(opts.Sort, want) {
+		t.Errorf("prev page sort = %v, want %v", opts.Sort, want)
+	}
+
+	whole := Page{Sort: "id", Field: "poll_id", tie: "poll_id"}
+	if _, opts := whole.Query(nil); opts.Limit != nil {
+		t.Errorf("whole list limit = %d, want none", *opts.Limit)
+	}
+}
@@ -0,0 +1,83 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/tenant"
+)
+
+// ErrAlreadyVoted is returned by InsertVote when the user already voted on the poll.
+var ErrAlreadyVoted = errors.New("already voted on this poll")
+
+// EnsureVoteIndexes makes votes unique per user and poll, which is what keeps InsertVote
+// from counting a vote twice. Duplicate votes left by older versions are removed first,
+// keeping the earliest of each, and taken off their poll's tally.
+func EnsureVoteIndexes(ctx context.Context) error {
+	raw := VotesCol.Raw()
+	cursor, err := raw.Aggregate(ctx, bson.A{
+		bson.M{"$sort": bson.D{{Key: "voted_at", Value: 1}, {Key: "_id", Value: 1}}},
+		bson.M{"$group": bson.M{
+			"_id":   bson.M{"tenant": "$" + tenant.Field, "user": "$user_id", "poll": "$poll_id"},
+			"votes": bson.M{"$push": bson.M{"id": "$_id", "option": "$option_index"}},
+		}},
+		bson.M{"$match": bson.M{"votes.1": bson.M{"$exists": true}}},
+	})
+	if err != nil {
+		return err
+	}
+	var groups []struct {
+		Key struct {
+			Tenant string `bson:"tenant"`
+			Poll   int    `bson:"poll"`
+		} `bson:"_id"`
+		Votes []struct {
+			ID     primitive.ObjectID `bson:"id"`
+			Option int                `bson:"option"`
+		} `bson:"votes"`
+	}
+	if err := cursor.All(ctx, &groups); err != nil {
+		return err
+	}
+	for _, g := range groups {
+		ids, tally := bson.A{}, bson.M{}
+		for _, v := range g.Votes[1:] {
+			ids = append(ids, v.ID)
+			key := fmt.Sprintf("options.%d.votes", v.Option)
+			n, _ := tally[key].(int)
+			tally[key] = n - 1
+		}
+		res, err := raw.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}})
+		if err != nil {
+			return err
+		}
+		if _, err := PollsCol.Raw().UpdateOne(ctx, bson.M{tenant.Field: g.Key.Tenant, "poll_id": g.Key.Poll}, bson.M{"$inc": tally}); err != nil {
+			return err
+		}
+		log.Printf("polls: removed %d duplicate votes", res.DeletedCount)
+	}
+	_, err = raw.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: tenant.Field, Value: 1}, {Key: "user_id", Value: 1}, {Key: "poll_id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	return err
+}
+
+// InsertVote stores userID's vote inside the transaction sc. A vote that already exists,
+// including one committed a moment ago by a concurrent request, yields ErrAlreadyVoted, and
+// the caller's transaction must then count nothing.
+func InsertVote(sc mongo.SessionContext, userID, pollID, optionIndex int, at time.Time) error {
+	_, err := VotesCol.InsertOne(sc, bson.M{"user_id": userID, "poll_id": pollID, "option_index": optionIndex, "voted_at": at})
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrAlreadyVoted
+	}
+	return err
+}
@@ -0,0 +1,190 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/answers"
+	"backend/models"
+	"backend/tenant"
+)
+
+// Quest statuses. Only active quests are shown to students; drafts are still being written
+// and archived quests are retired but keep their completions.
+const (
+	QuestDraft    = "draft"
+	QuestActive   = "active"
+	QuestArchived = "archived"
+)
+
+// QuestStatuses lists every quest status.
+var QuestStatuses = []string{QuestDraft, QuestActive, QuestArchived}
+
+// VisibleQuests returns the filter matching the quests user may see and complete at now:
+// active, inside their visibility window, and aimed at the user's role and one of their
+// courses, or at everyone. Missing fields count as open, so quests stored before authoring
+// existed stay visible.
+func VisibleQuests(user models.User, now time.Time) bson.M {
+	courses := bson.A{nil, bson.A{}}
+	for _, c := range user.ActiveCourses {
+		courses = append(courses, c.CourseID)
+	}
+	return bson.M{
+		"status":        bson.M{"$nin": bson.A{QuestDraft, QuestArchived}},
+		"visible_from":  bson.M{"$not": bson.M{"$gt": now}},
+		"visible_until": bson.M{"$not": bson.M{"$lte": now}},
+		"roles":         bson.M{"$in": bson.A{nil, bson.A{}, user.Role}},
+		"course_ids":    bson.M{"$in": courses},
+	}
+}
+
+// QuestsWithStatus returns the filter matching quests in status, counting quests without
+// one as active.
+func QuestsWithStatus(status string) bson.M {
+	if status == QuestActive {
+		return bson.M{"status": bson.M{"$in": bson.A{nil, QuestActive}}}
+	}
+	return bson.M{"status": status}
+}
+
+// NextQuestID reserves a quest ID. Like user IDs they are unique across tenants, and the
+// counter starts above the highest quest_id already stored, which covers the seeded quests.
+func NextQuestID(ctx context.Context) (int, error) {
+	var highest struct {
+		QuestID int `bson:"quest_id"`
+	}
+	err := QuestsCol.Raw().FindOne(ctx, bson.M{}, options.FindOne().SetSort(bson.M{"quest_id": -1}).SetProjection(bson.M{"quest_id": 1})).Decode(&highest)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return 0, err
+	}
+	if _, err := CountersCol.UpdateOne(ctx, bson.M{"_id": "quest_id"}, bson.M{"$max": bson.M{"seq": highest.QuestID}}, options.Update().SetUpsert(true)); err != nil {
+		return 0, err
+	}
+	var counter struct {
+		Seq int `bson:"seq"`
+	}
+	err = CountersCol.FindOneAndUpdate(ctx, bson.M{"_id": "quest_id"}, bson.M{"$inc": bson.M{"seq": 1}}, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&counter)
+	return counter.Seq, err
+}
+
+var (
+	// ErrQuestCompleted is returned by CompleteQuestOnce when the user already completed the quest.
+	ErrQuestCompleted = errors.New("quest already completed")
+	// ErrAttemptTaken is returned by RecordAttempt when a concurrent request stored an
+	// attempt with the same number first.
+	ErrAttemptTaken = errors.New("another attempt was submitted at the same time")
+)
+
+// EnsureQuestIndexes makes quest completions unique per user and quest, which is what
+// keeps CompleteQuestOnce from awarding coins twice. Duplicate completions left by older
+// versions are removed first, keeping the earliest completed one of each, so a record that
+// was only started never replaces the completion the user was paid for.
+func EnsureQuestIndexes(ctx context.Context) error {
+	raw := UserQuestsCol.Raw()
+	cursor, err := raw.Aggregate(ctx, bson.A{
+		bson.M{"$sort": bson.D{{Key: "completed", Value: -1}, {Key: "completed_at", Value: 1}, {Key: "_id", Value: 1}}},
+		bson.M{"$group": bson.M{
+			"_id": bson.M{"tenant": "$" + tenant.Field, "user": "$user_id", "quest": "$quest_id"},
+			"ids": bson.M{"$push": "$_id"},
+		}},
+		bson.M{"$match": bson.M{"ids.1": bson.M{"$exists": true}}},
+	})
+	if err != nil {
+		return err
+	}
+	var groups []struct {
+		IDs []primitive.ObjectID `bson:"ids"`
+	}
+	if err := cursor.All(ctx, &groups); err != nil {
+		return err
+	}
+	for _, g := range groups {
+		res, err := raw.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": g.IDs[1:]}})
+		if err != nil {
+			return err
+		}
+		log.Printf("quests: removed %d duplicate completions", res.DeletedCount)
+	}
+	_, err = raw.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: tenant.Field, Value: 1}, {Key: "user_id", Value: 1}, {Key: "quest_id", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return err
+	}
+	// Attempt numbers are unique per user and quest, so two answers sent at once cannot
+	// both slip under an attempt limit.
+	_, err = QuestAttemptsCol.Raw().Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: tenant.Field, Value: 1}, {Key: "user_id", Value: 1}, {Key: "quest_id", Value: 1}, {Key: "number", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return err
+	}
+	// One rotation set per student, pool and period, so concurrent first requests of a day
+	// cannot hand a student two different sets.
+	_, err = QuestAssignmentsCol.Raw().Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: tenant.Field, Value: 1}, {Key: "user_id", Value: 1}, {Key: "cadence", Value: 1}, {Key: "period", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	return err
+}
+
+// QuestCheck returns the answer check of q, or nil when completing q needs no answer.
+// Quests written before checks existed keep their plain answer, compared as text in any case.
+func QuestCheck(q models.Quest) *answers.Spec {
+	if q.Check != nil {
+		return q.Check
+	}
+	if strings.TrimSpace(q.Answer) != "" {
+		return &answers.Spec{Kind: answers.KindText, Answers: []string{q.Answer}}
+	}
+	return nil
+}
+
+// LastAttempt returns the latest answer userID submitted for questID, or nil if there is none.
+func LastAttempt(ctx context.Context, userID, questID int) (*models.QuestAttempt, error) {
+	var a models.QuestAttempt
+	err := QuestAttemptsCol.FindOne(ctx, bson.M{"user_id": userID, "quest_id": questID}, options.FindOne().SetSort(bson.M{"number": -1})).Decode(&a)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// RecordAttempt stores a. Its number must follow the last attempt's; if a concurrent request
+// took that number first, the result is ErrAttemptTaken.
+func RecordAttempt(ctx context.Context, a models.QuestAttempt) error {
+	_, err := QuestAttemptsCol.InsertOne(ctx, a)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrAttemptTaken
+	}
+	return err
+}
+
+// CompleteQuestOnce marks questID completed for userID inside the transaction sc. A
+// completion that already exists, including one committed a moment ago by a concurrent
+// request, yields ErrQuestCompleted, and the caller's transaction must then award nothing.
+func CompleteQuestOnce(sc mongo.SessionContext, userID, questID int, at time.Time) error {
+	// A stored but not completed record is completed in place; otherwise the upsert inserts,
+	// and the unique index rejects a second completion.
+	_, err := UserQuestsCol.UpdateOne(sc,
+		bson.M{"user_id": userID, "quest_id": questID, "completed": bson.M{"$ne": true}},
+		bson.M{"$set": bson.M{"completed": true, "completed_at": at}},
+		options.Update().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrQuestCompleted
+	}
+	return err
+}
@@ -0,0 +1,174 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/models"
+	"backend/tenant"
+)
+
+// Quest pools. A pooled quest is not listed on its own; each student is handed a few
+// quests of every pool per period, drawn by EnsureAssignment.
+const (
+	PoolDaily  = "daily"
+	PoolWeekly = "weekly"
+)
+
+// Pools lists every quest pool.
+var Pools = []string{PoolDaily, PoolWeekly}
+
+// difficultyOrder is the order difficulties take turns in when a set is drawn; others follow.
+var difficultyOrder = []string{"easy", "medium", "hard"}
+
+// ValidTimezone reports whether tz names an IANA timezone. The empty string is valid and
+// means the default.
+func ValidTimezone(tz string) bool {
+	if tz == "" {
+		return true
+	}
+	if tz == "Local" {
+		return false
+	}
+	_, err := time.LoadLocation(tz)
+	return err == nil
+}
+
+// UserLocation returns the timezone whose midnight ends user's quest day: their own, else
+// the campus's, else UTC.
+func UserLocation(user models.User, g tenant.Gamification) *time.Location {
+	if user.Timezone != "" {
+		if loc, err := time.LoadLocation(user.Timezone); err == nil {
+			return loc
+		}
+	}
+	return g.Location()
+}
+
+// RotationPeriod returns the local day or week of pool containing at in loc: its label
+// ("2026-10-18" or "2026-W42") and its bounds, from local midnight to local midnight.
+// Weeks start on Monday.
+func RotationPeriod(pool string, at time.Time, loc *time.Location) (label string, start, end time.Time) {
+	at = at.In(loc)
+	if pool == PoolWeekly {
+		start = time.Date(at.Year(), at.Month(), at.Day()-(int(at.Weekday())+6)%7, 0, 0, 0, 0, loc)
+		year, week := start.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week), start.UTC(), start.AddDate(0, 0, 7).UTC()
+	}
+	start = time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, loc)
+	return start.Format("2006-01-02"), start.UTC(), start.AddDate(0, 0, 1).UTC()
+}
+
+// EnsureAssignment returns user's set of pool quests for the period containing now,
+// drawing and storing it on first use. Once stored a set does not change, even when
+// quests are added to the pool later in the period.
+func EnsureAssignment(ctx context.Context, user models.User, pool string, now time.Time) (models.QuestAssignment, error) {
+	campus := TenantConfig(ctx)
+	loc := UserLocation(user, campus.Gamification)
+	label, start, end := RotationPeriod(pool, now, loc)
+	filter := bson.M{"user_id": user.UserID, "cadence": pool, "period": label}
+	var a models.QuestAssignment
+	err := QuestAssignmentsCol.FindOne(ctx, filter).Decode(&a)
+	if err == nil || !errors.Is(err, mongo.ErrNoDocuments) {
+		return a, err
+	}
+	size := campus.Gamification.Rotation.DailyQuests()
+	if pool == PoolWeekly {
+		size = campus.Gamification.Rotation.WeeklyQuests()
+	}
+	candidates, err := poolCandidates(ctx, user, pool, now)
+	if err != nil {
+		return a, err
+	}
+	seed := fmt.Sprintf("%s/%d/%s/%s", campus.ID, user.UserID, pool, label)
+	a = models.QuestAssignment{UserID: user.UserID, Cadence: pool, Period: label, Timezone: loc.String(), QuestIDs: DrawQuests(candidates, size, seed), StartsAt: start, ExpiresAt: end, AssignedAt: now.UTC()}
+	if _, err := QuestAssignmentsCol.InsertOne(ctx, a); mongo.IsDuplicateKeyError(err) {
+		// A concurrent request drew the set first; both callers must see the same one.
+		err = QuestAssignmentsCol.FindOne(ctx, filter).Decode(&a)
+		return a, err
+	} else if err != nil {
+		return a, err
+	}
+	return a, nil
+}
+
+// poolCandidates returns the quests of pool user may be handed at now: visible to them and
+// not completed yet, ordered by ID so a draw depends only on its seed.
+func poolCandidates(ctx context.Context, user models.User, pool string, now time.Time) ([]models.Quest, error) {
+	done, err := UserQuestsCol.Distinct(ctx, "quest_id", bson.M{"user_id": user.UserID, "completed": true})
+	if err != nil {
+		return nil, err
+	}
+	filter := VisibleQuests(user, now)
+	filter["pool"] = pool
+	filter["quest_id"] = bson.M{"$nin": done}
+	cursor, err := QuestsCol.Find(ctx, filter, options.Find().SetSort(bson.M{"quest_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+	quests := []models.Quest{}
+	err = cursor.All(ctx, &quests)
+	return quests, err
+}
+
+// DrawQuests picks up to n of candidates, the same ones for the same seed. Difficulties
+// take turns, easy first, so a set mixes them as far as the pool allows, and within each
+// difficulty quests aimed at the student's courses come before quests for everyone.
+func DrawQuests(candidates []models.Quest, n int, seed string) []int {
+	h := fnv.New64a()
+	h.Write([]byte(seed))
+	rng := rand.New(rand.NewSource(int64(h.Sum64())))
+	var keys []string
+	buckets := map[string][]models.Quest{}
+	for _, q := range candidates {
+		d := strings.ToLower(q.Difficulty)
+		if _, ok := buckets[d]; !ok {
+			keys = append(keys, d)
+		}
+		buckets[d] = append(buckets[d], q)
+	}
+	sort.SliceStable(keys, func(i, j int) bool { return difficultyRank(keys[i]) < difficultyRank(keys[j]) })
+	for _, d := range keys {
+		b := buckets[d]
+		rng.Shuffle(len(b), func(i, j int) { b[i], b[j] = b[j], b[i] })
+		// Every quest with courses is aimed at one of the student's, or they could not see it.
+		sort.SliceStable(b, func(i, j int) bool { return len(b[i].CourseIDs) > 0 && len(b[j].CourseIDs) == 0 })
+	}
+	ids := []int{}
+	for round := 0; len(ids) < n && len(ids) < len(candidates); round++ {
+		for _, d := range keys {
+			if round < len(buckets[d]) && len(ids) < n {
+				ids = append(ids, buckets[d][round].QuestID)
+			}
+		}
+	}
+	return ids
+}
+
+func difficultyRank(d string) int {
+	if i := slices.Index(difficultyOrder, d); i >= 0 {
+		return i
+	}
+	return len(difficultyOrder)
+}
+
+// IsAssigned reports whether questID is in user's current set of pool quests, drawing the
+// set if needed.
+func IsAssigned(ctx context.Context, user models.User, pool string, questID int, now time.Time) (bool, error) {
+	a, err := EnsureAssignment(ctx, user, pool, now)
+	if err != nil {
+		return false, err
+	}
+	return slices.Contains(a.QuestIDs, questID), nil
+}
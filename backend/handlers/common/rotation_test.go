@@ -0,0 +1,115 @@
+package common
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"backend/models"
+)
+
+func mustLoad(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+	return loc
+}
+
+func TestRotationPeriod(t *testing.T) {
+	kolkata := mustLoad(t, "Asia/Kolkata")
+	newYork := mustLoad(t, "America/New_York")
+	utc := func(s string) time.Time {
+		at, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return at
+	}
+	tests := []struct {
+		name       string
+		pool       string
+		at         string
+		loc        *time.Location
+		label      string
+		start, end string
+	}{
+		// 20:00 UTC is already 01:30 the next day in Kolkata.
+		{"daily after local midnight", PoolDaily, "2026-10-17T20:00:00Z", kolkata, "2026-10-18", "2026-10-17T18:30:00Z", "2026-10-18T18:30:00Z"},
+		{"daily before local midnight", PoolDaily, "2026-10-17T18:00:00Z", kolkata, "2026-10-17", "2026-10-16T18:30:00Z", "2026-10-17T18:30:00Z"},
+		{"daily across a DST change", PoolDaily, "2026-03-08T12:00:00Z", newYork, "2026-03-08", "2026-03-08T05:00:00Z", "2026-03-09T04:00:00Z"},
+		{"weekly from Monday", PoolWeekly, "2026-10-18T12:00:00Z", time.UTC, "2026-W42", "2026-10-12T00:00:00Z", "2026-10-19T00:00:00Z"},
+		{"weekly on Monday", PoolWeekly, "2026-10-19T00:00:00Z", time.UTC, "2026-W43", "2026-10-19T00:00:00Z", "2026-10-26T00:00:00Z"},
+		{"weekly across the year end", PoolWeekly, "2027-01-01T12:00:00Z", time.UTC, "2026-W53", "2026-12-28T00:00:00Z", "2027-01-04T00:00:00Z"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			label, start, end := RotationPeriod(tt.pool, utc(tt.at), tt.loc)
+			if label != tt.label || !start.Equal(utc(tt.start)) || !end.Equal(utc(tt.end)) {
+				t.Errorf("RotationPeriod = %s [%s, %s), want %s [%s, %s)", label, start.Format(time.RFC3339), end.Format(time.RFC3339), tt.label, tt.start, tt.end)
+			}
+		})
+	}
+}
+
+func TestValidTimezone(t *testing.T) {
+	mustLoad(t, "Asia/Kolkata")
+	for tz, want := range map[string]bool{"": true, "Asia/Kolkata": true, "UTC": true, "Local": false, "Mars/Olympus": false} {
+		if got := ValidTimezone(tz); got != want {
+			t.Errorf("ValidTimezone(%q) = %v, want %v", tz, got, want)
+		}
+	}
+}
+
+func TestDrawQuests(t *testing.T) {
+	var pool []models.Quest
+	for i, d := range []string{"Hard", "Easy", "Medium", "Easy", "Hard", "Medium", "Easy", "Unrated"} {
+		pool = append(pool, models.Quest{QuestID: i + 1, Difficulty: d})
+	}
+	difficulty := map[int]string{}
+	for _, q := range pool {
+		difficulty[q.QuestID] = q.Difficulty
+	}
+
+	first := DrawQuests(pool, 4, "campus/1/daily/2026-10-18")
+	if again := DrawQuests(pool, 4, "campus/1/daily/2026-10-18"); !reflect.DeepEqual(first, again) {
+		t.Errorf("same seed drew %v then %v", first, again)
+	}
+	var got []string
+	for _, id := range first {
+		got = append(got, difficulty[id])
+	}
+	if want := []string{"Easy", "Medium", "Hard", "Unrated"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("difficulties = %v, want them taking turns %v", got, want)
+	}
+
+	differs := false
+	for day := 1; day <= 10 && !differs; day++ {
+		differs = !reflect.DeepEqual(first, DrawQuests(pool, 4, fmt.Sprintf("campus/1/daily/2026-11-%02d", day)))
+	}
+	if !differs {
+		t.Error("ten different seeds all drew the same set")
+	}
+
+	if all := DrawQuests(pool, 20, "seed"); len(all) != len(pool) {
+		t.Errorf("drew %d of %d quests, want all of them", len(all), len(pool))
+	}
+	if none := DrawQuests(nil, 3, "seed"); len(none) != 0 {
+		t.Errorf("empty pool drew %v", none)
+	}
+}
+
+func TestDrawQuestsPrefersCourseQuests(t *testing.T) {
+	pool := []models.Quest{
+		{QuestID: 1, Difficulty: "easy"},
+		{QuestID: 2, Difficulty: "easy", CourseIDs: []int{101}},
+		{QuestID: 3, Difficulty: "easy"},
+	}
+	for _, seed := range []string{"a", "b", "c", "d"} {
+		if ids := DrawQuests(pool, 1, seed); !reflect.DeepEqual(ids, []int{2}) {
+			t.Errorf("seed %q drew %v, want the course quest first", seed, ids)
+		}
+	}
+}
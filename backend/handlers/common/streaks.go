@@ -0,0 +1,233 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/models"
+	"backend/tenant"
+)
+
+// Kinds of activity that can keep a streak going. Campuses pick theirs in
+// gamification.streaks.actions; by default all of them count.
+const (
+	ActivityQuest = "quest"
+	ActivityVote  = "vote"
+	ActivityPost  = "post"
+)
+
+// Activities lists every kind of streak activity.
+var Activities = []string{ActivityQuest, ActivityVote, ActivityPost}
+
+// LedgerStreakFreeze is the coin ledger reason for a bought streak freeze.
+const LedgerStreakFreeze = "streak_freeze"
+
+const dayLayout = "2006-01-02"
+
+// streakState is the part of a user document the streak engine reads. Day is nil until the
+// streak has been computed from the user's activity.
+type streakState struct {
+	UserID        int     `bson:"user_id"`
+	Timezone      string  `bson:"timezone"`
+	Streak        int     `bson:"streak"`
+	LongestStreak int     `bson:"longest_streak"`
+	Day           *string `bson:"streak_day"`
+	Freezes       int     `bson:"streak_freezes"`
+}
+
+// dayNumber counts the days from 1970-01-01 to the local date of t.
+func dayNumber(t time.Time) int {
+	return int(time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC).Unix() / 86400)
+}
+
+// parseDay returns the day number of a "2006-01-02" label.
+func parseDay(label string) (int, bool) {
+	t, err := time.Parse(dayLayout, label)
+	if err != nil {
+		return 0, false
+	}
+	return dayNumber(t), true
+}
+
+func dayLabel(n int) string {
+	return time.Unix(int64(n)*86400, 0).UTC().Format(dayLayout)
+}
+
+// missedDays returns how many whole local days passed without activity between the last
+// active day and today. Today itself is not missed yet.
+func missedDays(lastDay string, today int) int {
+	last, ok := parseDay(lastDay)
+	if !ok {
+		return 0
+	}
+	if missed := today - last - 1; missed > 0 {
+		return missed
+	}
+	return 0
+}
+
+// CurrentStreak returns user's streak as of now. The stored streak is only broken by the
+// nightly job, so until it runs a streak with more missed days than freezes reads as 0.
+func CurrentStreak(user models.User, g tenant.Gamification, now time.Time) int {
+	if user.StreakDay == "" {
+		return user.Streak
+	}
+	today := dayNumber(now.In(UserLocation(user, g)))
+	if missed := missedDays(user.StreakDay, today); missed > user.StreakFreezes {
+		return 0
+	}
+	return user.Streak
+}
+
+// RecordActivity counts activity of kind action by userID at at toward their streak. Call
+// it in the transaction that stores the activity. The first activity of a local day extends
+// the streak, spending freezes on the days missed since the last one if there are enough;
+// otherwise the streak starts again at 1. A user whose streak was never computed gets it
+// rebuilt from their history, which by then includes this activity.
+func RecordActivity(ctx context.Context, userID int, action string, at time.Time) error {
+	g := TenantConfig(ctx).Gamification
+	if !g.Streaks.Qualifies(action) {
+		return nil
+	}
+	var s streakState
+	err := UsersCol.FindOne(ctx, bson.M{"user_id": userID}).Decode(&s)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if s.Day == nil {
+		return rebuildStreak(ctx, s, g, at)
+	}
+	today := dayNumber(at.In(UserLocation(models.User{Timezone: s.Timezone}, g)))
+	last, ok := parseDay(*s.Day)
+	if ok && today <= last {
+		return nil
+	}
+	streak, used := 1, 0
+	if missed := missedDays(*s.Day, today); ok && missed <= s.Freezes {
+		streak, used = s.Streak+1, missed
+	}
+	// Matching on the stored day keeps two activities at once from both extending the streak.
+	_, err = UsersCol.UpdateOne(ctx,
+		bson.M{"user_id": userID, "streak_day": *s.Day},
+		bson.M{"$set": bson.M{"streak": streak, "streak_day": dayLabel(today)}, "$max": bson.M{"longest_streak": streak}, "$inc": bson.M{"streak_freezes": -used}})
+	return err
+}
+
+// RebuildStreaks brings the streaks of the tenant in ctx up to date at now. Streaks never
+// computed are rebuilt from activity history. Streaks with days missed since the last
+// activity spend a freeze per missed day, or break when there are too few.
+func RebuildStreaks(ctx context.Context, now time.Time) (changed int, err error) {
+	g := TenantConfig(ctx).Gamification
+	cursor, err := UsersCol.Find(ctx, bson.M{"$or": bson.A{bson.M{"streak": bson.M{"$gt": 0}}, bson.M{"streak_day": bson.M{"$exists": false}}}})
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+	for cursor.Next(ctx) {
+		var s streakState
+		if err := cursor.Decode(&s); err != nil {
+			continue
+		}
+		if s.Day == nil {
+			if err := rebuildStreak(ctx, s, g, now); err != nil {
+				return changed, err
+			}
+			changed++
+			continue
+		}
+		today := dayNumber(now.In(UserLocation(models.User{Timezone: s.Timezone}, g)))
+		missed := missedDays(*s.Day, today)
+		if missed == 0 {
+			continue
+		}
+		update := bson.M{"$set": bson.M{"streak": 0}}
+		if missed <= s.Freezes {
+			// The frozen days count as active without extending the streak.
+			update = bson.M{"$set": bson.M{"streak_day": dayLabel(today - 1)}, "$inc": bson.M{"streak_freezes": -missed}}
+		}
+		res, err := UsersCol.UpdateOne(ctx, bson.M{"user_id": s.UserID, "streak_day": *s.Day}, update)
+		if err != nil {
+			return changed, err
+		}
+		changed += int(res.ModifiedCount)
+	}
+	return changed, cursor.Err()
+}
+
+// rebuildStreak computes s's streak from the dated activity the campus counts: quest
+// completions, poll votes and research posts.
+func rebuildStreak(ctx context.Context, s streakState, g tenant.Gamification, now time.Time) error {
+	loc := UserLocation(models.User{Timezone: s.Timezone}, g)
+	days := map[int]bool{}
+	sources := []struct {
+		action string
+		col    *tenant.Collection
+		filter bson.M
+		field  string
+	}{
+		{ActivityQuest, UserQuestsCol, bson.M{"user_id": s.UserID, "completed": true}, "completed_at"},
+		{ActivityVote, VotesCol, bson.M{"user_id": s.UserID}, "voted_at"},
+		{ActivityPost, ResearchPostsCol, bson.M{"author_id": s.UserID}, "created_at"},
+	}
+	for _, src := range sources {
+		if !g.Streaks.Qualifies(src.action) {
+			continue
+		}
+		src.filter[src.field] = bson.M{"$type": "date"}
+		cursor, err := src.col.Find(ctx, src.filter, options.Find().SetProjection(bson.M{src.field: 1}))
+		if err != nil {
+			return err
+		}
+		var docs []bson.M
+		if err := cursor.All(ctx, &docs); err != nil {
+			return err
+		}
+		for _, doc := range docs {
+			if at, ok := doc[src.field].(time.Time); ok {
+				days[dayNumber(at.In(loc))] = true
+			}
+		}
+	}
+	streak, longest, last := streakRuns(days, dayNumber(now.In(loc)))
+	lastDay := ""
+	if last > 0 {
+		lastDay = dayLabel(last)
+	}
+	_, err := UsersCol.UpdateOne(ctx, bson.M{"user_id": s.UserID}, bson.M{"$set": bson.M{"streak": streak, "streak_day": lastDay}, "$max": bson.M{"longest_streak": longest}})
+	return err
+}
+
+// streakRuns returns the run of consecutive active days that is still going at today (it
+// may end yesterday, since today is not over), the longest run, and the last active day.
+func streakRuns(days map[int]bool, today int) (current, longest, last int) {
+	sorted := make([]int, 0, len(days))
+	for d := range days {
+		if d <= today {
+			sorted = append(sorted, d)
+		}
+	}
+	sort.Ints(sorted)
+	run := 0
+	for i, d := range sorted {
+		if i > 0 && d == sorted[i-1]+1 {
+			run++
+		} else {
+			run = 1
+		}
+		longest = max(longest, run)
+		last = d
+	}
+	if len(sorted) > 0 && last >= today-1 {
+		current = run
+	}
+	return current, longest, last
+}
@@ -0,0 +1,109 @@
+package common
+
+import (
+	"testing"
+	"time"
+
+	"backend/models"
+	"backend/tenant"
+)
+
+func TestDayLabels(t *testing.T) {
+	for _, label := range []string{"1970-01-01", "2024-02-29", "2026-10-18"} {
+		n, ok := parseDay(label)
+		if !ok || dayLabel(n) != label {
+			t.Errorf("parseDay(%q) = %d, %v; dayLabel gives %q", label, n, ok, dayLabel(n))
+		}
+	}
+	if n, _ := parseDay("1970-01-02"); n != 1 {
+		t.Errorf("parseDay(1970-01-02) = %d, want 1", n)
+	}
+	if _, ok := parseDay("18/10/2026"); ok {
+		t.Error("parseDay accepted a malformed label")
+	}
+	kolkata := mustLoad(t, "Asia/Kolkata")
+	at := time.Date(2026, time.October, 17, 20, 0, 0, 0, time.UTC)
+	if got, want := dayNumber(at.In(kolkata)), dayNumber(at)+1; got != want {
+		t.Errorf("dayNumber in Kolkata = %d, want the next UTC day %d", got, want)
+	}
+}
+
+func TestMissedDays(t *testing.T) {
+	today, _ := parseDay("2026-10-18")
+	tests := []struct {
+		last string
+		want int
+	}{
+		{"2026-10-18", 0},
+		{"2026-10-17", 0},
+		{"2026-10-15", 2},
+		{"2026-10-19", 0},
+		{"", 0},
+		{"yesterday", 0},
+	}
+	for _, tt := range tests {
+		if got := missedDays(tt.last, today); got != tt.want {
+			t.Errorf("missedDays(%q) = %d, want %d", tt.last, got, tt.want)
+		}
+	}
+}
+
+func TestStreakRuns(t *testing.T) {
+	const today = 100
+	days := func(ns ...int) map[int]bool {
+		m := map[int]bool{}
+		for _, n := range ns {
+			m[n] = true
+		}
+		return m
+	}
+	tests := []struct {
+		name                   string
+		days                   map[int]bool
+		current, longest, last int
+	}{
+		{"no activity", days(), 0, 0, 0},
+		{"run ending today", days(98, 99, 100), 3, 3, 100},
+		{"run ending yesterday", days(98, 99), 2, 2, 99},
+		{"run that ended", days(97, 98), 0, 2, 98},
+		{"longest before a gap", days(90, 91, 92, 93, 99, 100), 2, 4, 100},
+		{"future days ignored", days(99, 100, 101, 102), 2, 2, 100},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			current, longest, last := streakRuns(tt.days, today)
+			if current != tt.current || longest != tt.longest || last != tt.last {
+				t.Errorf("streakRuns = %d, %d, %d; want %d, %d, %d", current, longest, last, tt.current, tt.longest, tt.last)
+			}
+		})
+	}
+}
+
+func TestCurrentStreak(t *testing.T) {
+	mustLoad(t, "Asia/Kolkata")
+	// 20:00 UTC on the 17th is already the 18th in Kolkata.
+	now := time.Date(2026, time.October, 17, 20, 0, 0, 0, time.UTC)
+	utc := tenant.Gamification{}
+	tests := []struct {
+		name string
+		user models.User
+		g    tenant.Gamification
+		want int
+	}{
+		{"never computed", models.User{Streak: 4}, utc, 4},
+		{"active today", models.User{Streak: 4, StreakDay: "2026-10-17"}, utc, 4},
+		{"active yesterday", models.User{Streak: 4, StreakDay: "2026-10-16"}, utc, 4},
+		{"missed a day", models.User{Streak: 4, StreakDay: "2026-10-15"}, utc, 0},
+		{"missed days covered by freezes", models.User{Streak: 4, StreakDay: "2026-10-14", StreakFreezes: 2}, utc, 4},
+		{"more missed days than freezes", models.User{Streak: 4, StreakDay: "2026-10-13", StreakFreezes: 2}, utc, 0},
+		{"campus timezone", models.User{Streak: 4, StreakDay: "2026-10-16"}, tenant.Gamification{Timezone: "Asia/Kolkata"}, 0},
+		{"user timezone wins", models.User{Streak: 4, StreakDay: "2026-10-16", Timezone: "UTC"}, tenant.Gamification{Timezone: "Asia/Kolkata"}, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := CurrentStreak(tt.user, tt.g, now); got != tt.want {
+				t.Errorf("CurrentStreak = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
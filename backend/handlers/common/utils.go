@@ -10,14 +10,17 @@ import (
 	"strings"
 
 	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/mongo/options"
 
+	"backend/i18n"
 	"backend/models"
 )
 
 var hashtagRegex = regexp.MustCompile(`#([A-Za-z0-9_-]+)`)
 
+// WriteJSON writes payload as JSON. Error messages are translated into the language the
+// i18n middleware negotiated, read back from the response's Content-Language header.
 func WriteJSON(w http.ResponseWriter, status int, payload interface{}) {
+	if status >= http.StatusBadRequest { payload = localizeErrors(i18n.FromHeader(w.Header()), payload) }
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
 	if payload != nil { _ = json.NewEncoder(w).Encode(payload) }
@@ -25,29 +28,10 @@ func WriteJSON(w http.ResponseWriter, status int, payload interface{}) {
 
 func DecodeJSON(r *http.Request, dst interface{}) error { dec := json.NewDecoder(r.Body); dec.DisallowUnknownFields(); return dec.Decode(dst) }
 
-func SanitizeUser(u *models.User) models.PublicUser { if u==nil { return models.PublicUser{} }; return models.PublicUser{ID:u.UserID,Name:u.Name,Email:u.Email,Coins:u.Coins,Streak:u.Streak,Role:u.Role,AcademicStanding:u.AcademicStanding,GamificationLevel:u.GamificationLevel,CourseProgress:u.CourseProgress,ActiveCourses:u.ActiveCourses} }
+func SanitizeUser(u *models.User) models.PublicUser { if u==nil { return models.PublicUser{} }; return models.PublicUser{ID:u.UserID,Name:u.Name,Email:u.Email,Coins:u.Coins,Streak:u.Streak,LongestStreak:u.LongestStreak,Role:u.Role,AcademicStanding:u.AcademicStanding,GamificationLevel:u.GamificationLevel,CourseProgress:u.CourseProgress,ActiveCourses:u.ActiveCourses,Cohort:u.Cohort,Locale:u.Locale,Timezone:u.Timezone} }
 
-// Shared student/faculty leaderboard aggregation (reused by multiple packages)
-func CollectLeaderboard(limit int64) ([]models.LeaderboardEntry, error) {
-    findOpts := options.Find().SetSort(bson.M{"coins": -1})
-    if limit > 0 { findOpts.SetLimit(limit) }
-    ctx := context.Background()
-    cursor, err := UsersCol.Find(ctx, bson.M{}, findOpts)
-    if err != nil { return nil, err }
-    defer cursor.Close(ctx)
-    leaders := []models.LeaderboardEntry{}
-    for cursor.Next(ctx) {
-        var user models.User
-        if err := cursor.Decode(&user); err != nil { continue }
-        count, _ := UserQuestsCol.CountDocuments(ctx, bson.M{"user_id": user.UserID, "completed": true})
-        leaders = append(leaders, models.LeaderboardEntry{UserID:user.UserID,Name:user.Name,CompletedQuests:int(count),Streak:user.Streak,Coins:user.Coins})
-    }
-    return leaders, nil
-}
-
-// Helper used by faculty aggregation
-func CollectFacultyAggregates() ([]models.FacultyCourse, []models.LeaderboardEntry, error) {
-    ctx := context.Background()
+// Helper used by faculty aggregation; CollectFacultyAggregates serves it from the cache
+func loadFacultyAggregates(ctx context.Context) ([]models.FacultyCourse, []models.LeaderboardEntry, error) {
     cursor, err := UsersCol.Find(ctx, bson.M{"role": RoleStudent})
     if err != nil { return nil, nil, err }
     defer cursor.Close(ctx)
@@ -66,14 +50,11 @@ func CollectFacultyAggregates() ([]models.FacultyCourse, []models.LeaderboardEnt
     courses := make([]models.FacultyCourse,0,len(courseMap))
     for id,data := range courseMap { avg := 0.0; if data.students>0 { avg = float64(data.totalProgress)/float64(data.students) }; courses = append(courses, models.FacultyCourse{CourseID:id,Title:courseTitles[id],AverageProgress:avg,Students:data.students,DueNext:data.lastDue}) }
     sort.Slice(courses, func(i,j int) bool { return courses[i].AverageProgress > courses[j].AverageProgress })
-    leaders, err := CollectLeaderboard(5)
+    leaders, err := loadLeaderboard(ctx, 5)
     if err != nil { return courses, nil, err }
     return courses, leaders, nil
 }
 
-// Minimal background context helper (can be swapped for request-scoped later)
-// Removed invalid custom background abstraction; use context.Background() directly above.
-
 // Tag utilities reused by research (kept here for now)
 func SanitizeTagList(tags []string) []string {
 	seen := map[string]bool{}
@@ -0,0 +1,257 @@
+package common
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// FieldError describes a single rejected field in a request payload.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// ValidationErrors is returned by Validate when one or more fields fail their rules.
+type ValidationErrors []FieldError
+
+func (v ValidationErrors) Error() string {
+	parts := make([]string, 0, len(v))
+	for _, fe := range v {
+		parts = append(parts, fe.Field+": "+fe.Message)
+	}
+	return "validation failed: " + strings.Join(parts, "; ")
+}
+
+// Validate checks the `validate` struct tags on dst and returns ValidationErrors when
+// any field fails. Supported rules: required, min, max (string length, slice length or
+// numeric range), oneof (space separated, case-insensitive), url and notblank. Nil pointer
+// fields are only checked for required; everything else is skipped so partial updates work.
+func Validate(dst interface{}) error {
+	v := reflect.ValueOf(dst)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	var errs ValidationErrors
+	validateStruct(v, "", &errs)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+func validateStruct(v reflect.Value, prefix string, errs *ValidationErrors) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		name := prefix + jsonFieldName(sf)
+		fv := v.Field(i)
+		tag := sf.Tag.Get("validate")
+		if tag == "-" {
+			continue
+		}
+		rules := parseRules(tag)
+		if fv.Kind() == reflect.Ptr {
+			if fv.IsNil() {
+				if _, ok := rules["required"]; ok {
+					*errs = append(*errs, FieldError{Field: name, Rule: "required", Message: "is required"})
+				}
+				continue
+			}
+			fv = fv.Elem()
+		}
+		if fv.Kind() == reflect.Struct && tag == "" {
+			validateStruct(fv, name+".", errs)
+			continue
+		}
+		if len(rules) == 0 {
+			continue
+		}
+		if fe, ok := checkField(name, fv, rules); !ok {
+			*errs = append(*errs, fe)
+		}
+	}
+}
+
+type ruleSet map[string]string
+
+func parseRules(tag string) ruleSet {
+	rules := ruleSet{}
+	if strings.TrimSpace(tag) == "" {
+		return rules
+	}
+	for _, part := range strings.Split(tag, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key, arg, _ := strings.Cut(part, "=")
+		rules[key] = arg
+	}
+	return rules
+}
+
+// checkField applies rules in a fixed order so the first failure is the reported one.
+func checkField(name string, fv reflect.Value, rules ruleSet) (FieldError, bool) {
+	empty := isEmptyValue(fv)
+	if _, ok := rules["required"]; ok && empty {
+		return FieldError{Field: name, Rule: "required", Message: "is required"}, false
+	}
+	if _, ok := rules["notblank"]; ok && empty {
+		return FieldError{Field: name, Rule: "notblank", Message: "must not be blank"}, false
+	}
+	if empty {
+		return FieldError{}, true
+	}
+	switch fv.Kind() {
+	case reflect.String:
+		s := strings.TrimSpace(fv.String())
+		n := utf8.RuneCountInString(s)
+		if arg, ok := rules["min"]; ok {
+			if limit, err := strconv.Atoi(arg); err == nil && n < limit {
+				return FieldError{Field: name, Rule: "min", Message: fmt.Sprintf("must be at least %d characters", limit)}, false
+			}
+		}
+		if arg, ok := rules["max"]; ok {
+			if limit, err := strconv.Atoi(arg); err == nil && n > limit {
+				return FieldError{Field: name, Rule: "max", Message: fmt.Sprintf("must be at most %d characters", limit)}, false
+			}
+		}
+		if arg, ok := rules["oneof"]; ok {
+			allowed := strings.Fields(arg)
+			if !containsFold(allowed, s) {
+				return FieldError{Field: name, Rule: "oneof", Message: "must be one of: " + strings.Join(allowed, ", ")}, false
+			}
+		}
+		if _, ok := rules["url"]; ok && !isValidURL(s) {
+			return FieldError{Field: name, Rule: "url", Message: "must be a valid http(s) URL"}, false
+		}
+	case reflect.Slice, reflect.Array, reflect.Map:
+		n := fv.Len()
+		if arg, ok := rules["min"]; ok {
+			if limit, err := strconv.Atoi(arg); err == nil && n < limit {
+				return FieldError{Field: name, Rule: "min", Message: fmt.Sprintf("must contain at least %d items", limit)}, false
+			}
+		}
+		if arg, ok := rules["max"]; ok {
+			if limit, err := strconv.Atoi(arg); err == nil && n > limit {
+				return FieldError{Field: name, Rule: "max", Message: fmt.Sprintf("must contain at most %d items", limit)}, false
+			}
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Float32, reflect.Float64:
+		var n float64
+		if fv.Kind() == reflect.Float32 || fv.Kind() == reflect.Float64 {
+			n = fv.Float()
+		} else {
+			n = float64(fv.Int())
+		}
+		if arg, ok := rules["min"]; ok {
+			if limit, err := strconv.ParseFloat(arg, 64); err == nil && n < limit {
+				return FieldError{Field: name, Rule: "min", Message: "must be at least " + arg}, false
+			}
+		}
+		if arg, ok := rules["max"]; ok {
+			if limit, err := strconv.ParseFloat(arg, 64); err == nil && n > limit {
+				return FieldError{Field: name, Rule: "max", Message: "must be at most " + arg}, false
+			}
+		}
+	}
+	return FieldError{}, true
+}
+
+// isEmptyValue treats whitespace-only strings as empty; numbers are never empty so that
+// zero can still be range-checked (e.g. option_index=0).
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.String:
+		return strings.TrimSpace(v.String()) == ""
+	case reflect.Slice, reflect.Map, reflect.Array:
+		return v.Len() == 0
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil()
+	default:
+		return false
+	}
+}
+
+func isValidURL(raw string) bool {
+	candidate := EnsureURLHasScheme(raw)
+	if strings.HasPrefix(strings.ToLower(candidate), "data:") {
+		return true
+	}
+	if strings.HasPrefix(candidate, "//") {
+		candidate = "https:" + candidate
+	}
+	u, err := url.ParseRequestURI(candidate)
+	if err != nil {
+		return false
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return false
+	}
+	host := u.Hostname()
+	return host != "" && (strings.Contains(host, ".") || host == "localhost")
+}
+
+func containsFold(values []string, candidate string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, candidate) {
+			return true
+		}
+	}
+	return false
+}
+
+func jsonFieldName(sf reflect.StructField) string {
+	tag := sf.Tag.Get("json")
+	if name, _, _ := strings.Cut(tag, ","); name != "" && name != "-" {
+		return name
+	}
+	return sf.Name
+}
+
+// BindJSON decodes the request body into dst and validates it. An empty body is treated
+// as an empty object so required-field rules report what is missing. On failure the
+// response has already been written (400 for malformed JSON, 422 for rule violations).
+func BindJSON(w http.ResponseWriter, r *http.Request, dst interface{}) bool {
+	if err := DecodeJSON(r, dst); err != nil && !errors.Is(err, io.EOF) {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid payload"})
+		return false
+	}
+	if err := Validate(dst); err != nil {
+		WriteValidationError(w, err)
+		return false
+	}
+	return true
+}
+
+// WriteValidationError renders ValidationErrors as a 422 with per-field details, and
+// ErrInvalidCursor as a 400.
+func WriteValidationError(w http.ResponseWriter, err error) {
+	if errors.Is(err, ErrInvalidCursor) {
+		WriteJSON(w, http.StatusBadRequest, map[string]string{"error": err.Error()})
+		return
+	}
+	var verrs ValidationErrors
+	if !errors.As(err, &verrs) {
+		WriteJSON(w, http.StatusUnprocessableEntity, map[string]string{"error": err.Error()})
+		return
+	}
+	WriteJSON(w, http.StatusUnprocessableEntity, map[string]interface{}{"error": "validation failed", "fields": verrs})
+}
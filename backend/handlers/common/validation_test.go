@@ -0,0 +1,166 @@
+package common
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type testAddress struct {
+	City string `json:"city" validate:"required,max=5"`
+}
+
+type testRequest struct {
+	Name     string       `json:"name" validate:"required,min=2,max=5"`
+	Role     string       `json:"role" validate:"oneof=student faculty"`
+	Site     string       `json:"site" validate:"url"`
+	Tags     []string     `json:"tags" validate:"min=1,max=2"`
+	Age      int          `json:"age" validate:"min=0,max=120"`
+	Score    float64      `json:"score" validate:"max=1.5"`
+	Nick     *string      `json:"nick" validate:"notblank,max=3"`
+	Token    *string      `json:"token" validate:"required"`
+	Address  testAddress  `json:"address"`
+	Shipping *testAddress `json:"shipping"`
+	Ignored  string       `json:"ignored" validate:"-"`
+	NoTag    string       `validate:"max=1"`
+	internal string       `validate:"required"`
+}
+
+// validRequest passes every rule; each test case breaks one.
+func validRequest() testRequest {
+	token := "t"
+	return testRequest{Name: "Ada", Tags: []string{"ai"}, Token: &token, Address: testAddress{City: "Pune"}}
+}
+
+func TestValidate(t *testing.T) {
+	str := func(s string) *string { return &s }
+	tests := []struct {
+		name   string
+		change func(*testRequest)
+		want   []FieldError
+	}{
+		{"valid", func(*testRequest) {}, nil},
+		{"required missing", func(r *testRequest) { r.Name = "" }, []FieldError{{"name", "required", "is required"}}},
+		{"required whitespace", func(r *testRequest) { r.Name = "   " }, []FieldError{{"name", "required", "is required"}}},
+		{"string min", func(r *testRequest) { r.Name = "A" }, []FieldError{{"name", "min", "must be at least 2 characters"}}},
+		{"string max counts runes", func(r *testRequest) { r.Name = "ஆதிரா" }, nil},
+		{"string max", func(r *testRequest) { r.Name = "Adalovelace" }, []FieldError{{"name", "max", "must be at most 5 characters"}}},
+		{"string trimmed before length", func(r *testRequest) { r.Name = "  Ada  " }, nil},
+		{"oneof ignores case", func(r *testRequest) { r.Role = "Faculty" }, nil},
+		{"oneof", func(r *testRequest) { r.Role = "dean" }, []FieldError{{"role", "oneof", "must be one of: student, faculty"}}},
+		{"empty optional skipped", func(r *testRequest) { r.Role, r.Site = "", "" }, nil},
+		{"url", func(r *testRequest) { r.Site = "https://example.edu/a" }, nil},
+		{"url without scheme", func(r *testRequest) { r.Site = "example.edu" }, nil},
+		{"url localhost", func(r *testRequest) { r.Site = "http://localhost:5173" }, nil},
+		{"url without a host", func(r *testRequest) { r.Site = "not a url" }, []FieldError{{"site", "url", "must be a valid http(s) URL"}}},
+		{"url other scheme", func(r *testRequest) { r.Site = "ftp://example.edu" }, []FieldError{{"site", "url", "must be a valid http(s) URL"}}},
+		{"empty slice skips min", func(r *testRequest) { r.Tags = []string{} }, nil},
+		{"slice max", func(r *testRequest) { r.Tags = []string{"a", "b", "c"} }, []FieldError{{"tags", "max", "must contain at most 2 items"}}},
+		{"int zero is checked", func(r *testRequest) { r.Age = -1 }, []FieldError{{"age", "min", "must be at least 0"}}},
+		{"int max", func(r *testRequest) { r.Age = 121 }, []FieldError{{"age", "max", "must be at most 120"}}},
+		{"float max", func(r *testRequest) { r.Score = 1.6 }, []FieldError{{"score", "max", "must be at most 1.5"}}},
+		{"nil pointer skips other rules", func(r *testRequest) { r.Nick = nil }, nil},
+		{"pointer notblank", func(r *testRequest) { r.Nick = str(" ") }, []FieldError{{"nick", "notblank", "must not be blank"}}},
+		{"pointer max", func(r *testRequest) { r.Nick = str("abcd") }, []FieldError{{"nick", "max", "must be at most 3 characters"}}},
+		{"nil pointer required", func(r *testRequest) { r.Token = nil }, []FieldError{{"token", "required", "is required"}}},
+		{"nested struct", func(r *testRequest) { r.Address.City = "" }, []FieldError{{"address.city", "required", "is required"}}},
+		{"nested pointer", func(r *testRequest) { r.Shipping = &testAddress{City: "Chennai"} }, []FieldError{{"shipping.city", "max", "must be at most 5 characters"}}},
+		{"skipped fields", func(r *testRequest) { r.Ignored, r.internal = "anything", "" }, nil},
+		{"go name without json tag", func(r *testRequest) { r.NoTag = "ab" }, []FieldError{{"NoTag", "max", "must be at most 1 characters"}}},
+		{"every failure reported", func(r *testRequest) { r.Name, r.Age = "", 200 }, []FieldError{{"name", "required", "is required"}, {"age", "max", "must be at most 120"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := validRequest()
+			tt.change(&req)
+			err := Validate(&req)
+			if tt.want == nil {
+				if err != nil {
+					t.Errorf("Validate = %v, want nil", err)
+				}
+				return
+			}
+			var verrs ValidationErrors
+			if !errors.As(err, &verrs) || !reflect.DeepEqual([]FieldError(verrs), tt.want) {
+				t.Errorf("Validate = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateIgnoresNonStructs(t *testing.T) {
+	var nilReq *testRequest
+	for _, v := range []interface{}{nil, nilReq, "text", 3} {
+		if err := Validate(v); err != nil {
+			t.Errorf("Validate(%#v) = %v, want nil", v, err)
+		}
+	}
+}
+
+func TestBindJSON(t *testing.T) {
+	tests := []struct {
+		name   string
+		body   string
+		status int
+		fields []string
+	}{
+		{"valid", `{"name":"Ada","tags":["ai"],"token":"t","address":{"city":"Pune"}}`, http.StatusOK, nil},
+		{"malformed", `{"name":`, http.StatusBadRequest, nil},
+		{"unknown field", `{"name":"Ada","admin":true}`, http.StatusBadRequest, nil},
+		{"wrong type", `{"age":"old"}`, http.StatusBadRequest, nil},
+		{"empty body reports required fields", ``, http.StatusUnprocessableEntity, []string{"name", "token", "address.city"}},
+		{"rule violations", `{"name":"A","tags":["ai"],"token":"t","address":{"city":"Pune"}}`, http.StatusUnprocessableEntity, []string{"name"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			var req testRequest
+			ok := BindJSON(w, r, &req)
+			if ok != (tt.status == http.StatusOK) {
+				t.Fatalf("BindJSON = %v, status %d: %s", ok, w.Code, w.Body)
+			}
+			if ok {
+				return
+			}
+			if w.Code != tt.status {
+				t.Errorf("status = %d, want %d: %s", w.Code, tt.status, w.Body)
+			}
+			var resp struct {
+				Fields []FieldError `json:"fields"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatal(err)
+			}
+			var fields []string
+			for _, f := range resp.Fields {
+				fields = append(fields, f.Field)
+			}
+			if !reflect.DeepEqual(fields, tt.fields) {
+				t.Errorf("fields = %v, want %v", fields, tt.fields)
+			}
+		})
+	}
+}
+
+func TestWriteValidationError(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+	}{
+		{ValidationErrors{{Field: "name", Rule: "required", Message: "is required"}}, http.StatusUnprocessableEntity},
+		{errors.New("bad sort"), http.StatusUnprocessableEntity},
+		{ErrInvalidCursor, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		WriteValidationError(w, tt.err)
+		if w.Code != tt.status {
+			t.Errorf("WriteValidationError(%v): status %d, want %d", tt.err, w.Code, tt.status)
+		}
+	}
+}
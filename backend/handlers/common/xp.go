@@ -0,0 +1,202 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/events"
+	"backend/models"
+	"backend/tenant"
+)
+
+// XP is earned alongside coins but kept apart from them: coins are spent, XP only grows. A
+// user's lifetime XP is the sum of their XP ledger, and their level follows from it and the
+// campus level curve.
+
+// XPQuest is the XP ledger reason for a completed quest.
+const XPQuest = "quest"
+
+// LedgerLevelUp is the coin ledger reason for a level reward.
+const LedgerLevelUp = "level_up"
+
+// difficultyXP is the XP a quest without its own earns, by difficulty.
+var difficultyXP = map[string]int{"easy": 10, "medium": 25, "hard": 50}
+
+const defaultQuestXP = 10
+
+// QuestXP returns the XP completing q earns.
+func QuestXP(q models.Quest) int {
+	if q.XP > 0 {
+		return q.XP
+	}
+	if xp, ok := difficultyXP[strings.ToLower(q.Difficulty)]; ok {
+		return xp
+	}
+	return defaultQuestXP
+}
+
+// Progress places xp on curve.
+func Progress(xp int, curve tenant.LevelCurve) models.ProgressResponse {
+	level := curve.Level(xp)
+	start, _ := curve.Threshold(level)
+	p := models.ProgressResponse{XP: xp, Level: level, LevelXP: start, XPIntoLevel: xp - start, LevelReward: curve.LevelReward()}
+	next, ok := curve.Threshold(level + 1)
+	if !ok {
+		p.MaxLevel, p.Percent = true, 100
+		return p
+	}
+	p.NextLevelXP, p.XPToNext = next, next-xp
+	p.Percent = p.XPIntoLevel * 100 / (next - start)
+	return p
+}
+
+// EnsureXP computes userID's lifetime XP from their completed quests if it never was,
+// writing an XP ledger entry per quest inside the transaction sc, so the total and the
+// ledger are stored together or not at all. Levels reached this way pay no reward.
+func EnsureXP(sc mongo.SessionContext, userID int) error {
+	if missing, err := xpMissing(sc, userID); err != nil || !missing {
+		return err
+	}
+	cursor, err := UserQuestsCol.Find(sc, bson.M{"user_id": userID, "completed": true})
+	if err != nil {
+		return err
+	}
+	var done []struct {
+		QuestID     int       `bson:"quest_id"`
+		CompletedAt time.Time `bson:"completed_at"`
+	}
+	if err := cursor.All(sc, &done); err != nil {
+		return err
+	}
+	ids := make(bson.A, 0, len(done))
+	for _, d := range done {
+		ids = append(ids, d.QuestID)
+	}
+	cursor, err = QuestsCol.Find(sc, bson.M{"quest_id": bson.M{"$in": ids}})
+	if err != nil {
+		return err
+	}
+	var quests []models.Quest
+	if err := cursor.All(sc, &quests); err != nil {
+		return err
+	}
+	questXP := make(map[int]int, len(quests))
+	for _, q := range quests {
+		questXP[q.QuestID] = QuestXP(q)
+	}
+	total, entries := 0, []interface{}{}
+	for _, d := range done {
+		xp, ok := questXP[d.QuestID]
+		if !ok {
+			continue
+		}
+		total += xp
+		entries = append(entries, models.XPEntry{UserID: userID, XP: xp, Reason: XPQuest, QuestID: d.QuestID, At: d.CompletedAt})
+	}
+	level := TenantConfig(sc).Gamification.Levels.Level(total)
+	// Matching on the missing field makes a concurrent backfill conflict with this one, and
+	// the retried transaction then finds the XP set and writes nothing.
+	res, err := UsersCol.UpdateOne(sc, bson.M{"user_id": userID, "xp": bson.M{"$exists": false}}, bson.M{"$set": bson.M{"xp": total, "gamification_level": level}})
+	if err != nil || res.ModifiedCount == 0 || len(entries) == 0 {
+		return err
+	}
+	_, err = XPLedgerCol.InsertMany(sc, entries)
+	return err
+}
+
+// LoadXP runs EnsureXP in a transaction of its own, for requests that only read progress.
+func LoadXP(ctx context.Context, userID int) error {
+	if missing, err := xpMissing(ctx, userID); err != nil || !missing {
+		return err
+	}
+	return WithTransaction(ctx, func(sc mongo.SessionContext) error { return EnsureXP(sc, userID) })
+}
+
+// xpMissing reports whether userID's lifetime XP was never computed.
+func xpMissing(ctx context.Context, userID int) (bool, error) {
+	err := UsersCol.FindOne(ctx, bson.M{"user_id": userID, "xp": bson.M{"$exists": false}}, options.FindOne().SetProjection(bson.M{"_id": 1})).Err()
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// AwardXP adds xp to userID's lifetime XP and XP ledger. Call it in the transaction that
+// stores what earned it. Each level reached pays the campus level reward in coins, entered
+// in the coin ledger, and a LevelUp event is recorded. It returns nil when no level was
+// reached. Call EnsureXP before storing what earned the XP, or a user whose XP was never
+// computed would have it counted twice.
+func AwardXP(ctx context.Context, userID, xp int, reason string, questID int, at time.Time) (*models.LevelUp, error) {
+	if xp <= 0 {
+		return nil, nil
+	}
+	var user struct {
+		XP int `bson:"xp"`
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After).SetProjection(bson.M{"xp": 1})
+	if err := UsersCol.FindOneAndUpdate(ctx, bson.M{"user_id": userID}, bson.M{"$inc": bson.M{"xp": xp}}, opts).Decode(&user); err != nil {
+		return nil, err
+	}
+	if _, err := XPLedgerCol.InsertOne(ctx, models.XPEntry{UserID: userID, XP: xp, Reason: reason, QuestID: questID, At: at}); err != nil {
+		return nil, err
+	}
+	curve := TenantConfig(ctx).Gamification.Levels
+	from, to := curve.Level(user.XP-xp), curve.Level(user.XP)
+	update := bson.M{"$set": bson.M{"gamification_level": to}}
+	if to <= from {
+		_, err := UsersCol.UpdateOne(ctx, bson.M{"user_id": userID}, update)
+		return nil, err
+	}
+	up := &models.LevelUp{From: from, To: to, Coins: curve.LevelReward() * (to - from)}
+	if up.Coins > 0 {
+		update["$inc"] = bson.M{"coins": up.Coins}
+	}
+	if _, err := UsersCol.UpdateOne(ctx, bson.M{"user_id": userID}, update); err != nil {
+		return nil, err
+	}
+	if up.Coins > 0 {
+		if err := RecordCoins(ctx, userID, up.Coins, LedgerLevelUp, 0, at); err != nil {
+			return nil, err
+		}
+	}
+	return up, Outbox.Record(ctx, events.LevelUp{UserID: userID, From: from, To: to, XP: user.XP, Coins: up.Coins, At: at})
+}
+
+// Relevel brings the stored levels of the tenant in ctx in line with curve, after the
+// campus changed it. Levels gained this way pay no reward.
+func Relevel(ctx context.Context, curve tenant.LevelCurve) (changed int, err error) {
+	cursor, err := UsersCol.Find(ctx, bson.M{"xp": bson.M{"$exists": true}}, options.Find().SetProjection(bson.M{"user_id": 1, "xp": 1, "gamification_level": 1}))
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+	for cursor.Next(ctx) {
+		var u struct {
+			UserID int `bson:"user_id"`
+			XP     int `bson:"xp"`
+			Level  int `bson:"gamification_level"`
+		}
+		if err := cursor.Decode(&u); err != nil {
+			continue
+		}
+		if level := curve.Level(u.XP); level != u.Level {
+			if _, err := UsersCol.UpdateOne(ctx, bson.M{"user_id": u.UserID}, bson.M{"$set": bson.M{"gamification_level": level}}); err != nil {
+				return changed, err
+			}
+			changed++
+		}
+	}
+	return changed, cursor.Err()
+}
+
+// EnsureXPIndexes indexes the XP ledger by user and time.
+func EnsureXPIndexes(ctx context.Context) error {
+	_, err := XPLedgerCol.Raw().Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.D{{Key: tenant.Field, Value: 1}, {Key: "user_id", Value: 1}, {Key: "at", Value: -1}}})
+	return err
+}
@@ -0,0 +1,49 @@
+package common
+
+import (
+	"testing"
+
+	"backend/models"
+	"backend/tenant"
+)
+
+func TestQuestXP(t *testing.T) {
+	tests := []struct {
+		quest models.Quest
+		want  int
+	}{
+		{models.Quest{XP: 70, Difficulty: "easy"}, 70},
+		{models.Quest{Difficulty: "Easy"}, 10},
+		{models.Quest{Difficulty: "medium"}, 25},
+		{models.Quest{Difficulty: "HARD"}, 50},
+		{models.Quest{Difficulty: "legendary"}, defaultQuestXP},
+		{models.Quest{}, defaultQuestXP},
+	}
+	for _, tt := range tests {
+		if got := QuestXP(tt.quest); got != tt.want {
+			t.Errorf("QuestXP(xp %d, %q) = %d, want %d", tt.quest.XP, tt.quest.Difficulty, got, tt.want)
+		}
+	}
+}
+
+func TestProgress(t *testing.T) {
+	reward := 5
+	curve := tenant.LevelCurve{Thresholds: []int{100, 300}, Reward: &reward}
+	tests := []struct {
+		name string
+		xp   int
+		want models.ProgressResponse
+	}{
+		{"new user", 0, models.ProgressResponse{Level: 1, NextLevelXP: 100, XPToNext: 100, LevelReward: 5}},
+		{"inside a level", 150, models.ProgressResponse{XP: 150, Level: 2, LevelXP: 100, XPIntoLevel: 50, NextLevelXP: 300, XPToNext: 150, Percent: 25, LevelReward: 5}},
+		{"just below the next level", 299, models.ProgressResponse{XP: 299, Level: 2, LevelXP: 100, XPIntoLevel: 199, NextLevelXP: 300, XPToNext: 1, Percent: 99, LevelReward: 5}},
+		{"top level", 450, models.ProgressResponse{XP: 450, Level: 3, LevelXP: 300, XPIntoLevel: 150, Percent: 100, MaxLevel: true, LevelReward: 5}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Progress(tt.xp, curve); got != tt.want {
+				t.Errorf("Progress = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -3,7 +3,6 @@ package faculty
 import (
 	"context"
 	"errors"
-	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
@@ -15,7 +14,11 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 
+	"backend/audit"
+	"backend/events"
 	"backend/handlers/common"
+	"backend/httpcache"
+	"backend/i18n"
 	"backend/models"
 )
 
@@ -70,6 +73,7 @@ type facultyMentorshipDoc struct {
 
 type facultyMenteeDoc struct {
 	ID          primitive.ObjectID `bson:"_id,omitempty"`
+	StudentID   int                `bson:"student_id,omitempty"`
 	Name        string             `bson:"name"`
 	Status      string             `bson:"status"`
 	NextSession string             `bson:"next_session,omitempty"`
@@ -97,7 +101,10 @@ func GetOverview(w http.ResponseWriter, r *http.Request) {
 	if !ok {
 		return
 	}
-	ctx := context.Background()
+	ctx := r.Context()
+	if etag, ok := common.ETag(r, targetID, httpcache.ScopeUsers, httpcache.ScopeFaculty, httpcache.FacultyScope(targetID)); ok && httpcache.NotModified(w, r, etag) {
+		return
+	}
 	doc, err := loadFacultyDashboard(ctx, targetID)
 	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
 		common.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to load faculty dashboard"})
@@ -127,6 +134,7 @@ func getFacultyTarget(w http.ResponseWriter, r *http.Request) (int, bool) {
 			targetID = override
 		}
 	}
+	audit.SetSubject(r.Context(), targetID)
 	return targetID, true
 }
 
@@ -143,12 +151,12 @@ func respondFacultyDashboard(w http.ResponseWriter, ctx context.Context, doc *fa
 	if doc != nil {
 		recomputeFacultyPending(ctx, doc)
 	}
-	courses, leaders, err := common.CollectFacultyAggregates()
+	courses, leaders, err := common.CollectFacultyAggregates(ctx)
 	if err != nil {
 		common.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
 		return false
 	}
-	resp := prepareFacultyOverviewResponse(doc, courses, leaders)
+	resp := prepareFacultyOverviewResponse(i18n.FromContext(ctx), doc, courses, leaders)
 	common.WriteJSON(w, http.StatusOK, resp)
 	return true
 }
@@ -169,12 +177,12 @@ func recomputeFacultyPending(ctx context.Context, doc *facultyDashboardDoc) {
 	}
 }
 
-func prepareFacultyOverviewResponse(doc *facultyDashboardDoc, courses []models.FacultyCourse, leaders []models.LeaderboardEntry) FacultyOverviewResponse {
+func prepareFacultyOverviewResponse(loc string, doc *facultyDashboardDoc, courses []models.FacultyCourse, leaders []models.LeaderboardEntry) FacultyOverviewResponse {
 	var resp FacultyOverviewResponse
 	if doc != nil {
-		resp = mapFacultyDashboard(doc)
+		resp = mapFacultyDashboard(loc, doc)
 	} else {
-		resp = FacultyOverviewResponse{Overview: FacultyOverviewStats{}, AIGrading: FacultyAIGrading{Suggestions: []FacultyAISuggestion{}, PendingCount: 0, LastUpdated: formatRelativeTimestamp(time.Time{})}, Mentorship: FacultyMentorship{Mentees: []FacultyMentee{}, ActiveCount: 0, LastUpdated: formatRelativeTimestamp(time.Time{})}, Courses: []FacultyCourseCard{}, Analytics: FacultyAnalytics{Labels: []string{}, Students: []int{}, AvgGrade: []int{}}}
+		resp = FacultyOverviewResponse{Overview: FacultyOverviewStats{}, AIGrading: FacultyAIGrading{Suggestions: []FacultyAISuggestion{}, PendingCount: 0, LastUpdated: i18n.RelativeTime(loc, time.Time{})}, Mentorship: FacultyMentorship{Mentees: []FacultyMentee{}, ActiveCount: 0, LastUpdated: i18n.RelativeTime(loc, time.Time{})}, Courses: []FacultyCourseCard{}, Analytics: FacultyAnalytics{Labels: []string{}, Students: []int{}, AvgGrade: []int{}}}
 	}
 	if resp.AIGrading.Suggestions == nil {
 		resp.AIGrading.Suggestions = []FacultyAISuggestion{}
@@ -199,7 +207,8 @@ func prepareFacultyOverviewResponse(doc *facultyDashboardDoc, courses []models.F
 	return resp
 }
 
-func mapFacultyDashboard(doc *facultyDashboardDoc) FacultyOverviewResponse {
+// mapFacultyDashboard renders doc with its labels in loc.
+func mapFacultyDashboard(loc string, doc *facultyDashboardDoc) FacultyOverviewResponse {
 	if doc == nil {
 		return FacultyOverviewResponse{}
 	}
@@ -212,7 +221,7 @@ func mapFacultyDashboard(doc *facultyDashboardDoc) FacultyOverviewResponse {
 		}
 		suggestions = append(suggestions, FacultyAISuggestion{ID: s.ID.Hex(), Title: s.Title, Course: s.Course, Summary: s.Summary, Recommendation: s.Recommendation, GradeSuggestion: s.GradeSuggestion, Status: s.Status, CreatedAt: s.CreatedAt.UTC().Format(time.RFC3339), UpdatedAt: s.UpdatedAt.UTC().Format(time.RFC3339)})
 	}
-	ai := FacultyAIGrading{Suggestions: suggestions, PendingCount: pendingCount, LastUpdated: formatRelativeTimestamp(doc.UpdatedAt)}
+	ai := FacultyAIGrading{Suggestions: suggestions, PendingCount: pendingCount, LastUpdated: i18n.RelativeTime(loc, doc.UpdatedAt), LastUpdatedAt: isoTime(doc.UpdatedAt)}
 	overview.PendingReviews = pendingCount
 	mentees := make([]FacultyMentee, 0, len(doc.Mentorship.Mentees))
 	activeCount := 0
@@ -221,12 +230,12 @@ func mapFacultyDashboard(doc *facultyDashboardDoc) FacultyOverviewResponse {
 		if status != "archived" {
 			activeCount++
 		}
-		mentees = append(mentees, FacultyMentee{ID: m.ID.Hex(), Name: m.Name, Status: status, NextSession: m.NextSession, Note: m.Note, UpdatedAt: m.UpdatedAt.UTC().Format(time.RFC3339)})
+		mentees = append(mentees, FacultyMentee{ID: m.ID.Hex(), StudentID: m.StudentID, Name: m.Name, Status: status, NextSession: m.NextSession, Note: m.Note, UpdatedAt: m.UpdatedAt.UTC().Format(time.RFC3339)})
 	}
-	mentorship := FacultyMentorship{Mentees: mentees, ActiveCount: activeCount, LastUpdated: formatRelativeTimestamp(doc.Mentorship.LastUpdated)}
+	mentorship := FacultyMentorship{Mentees: mentees, ActiveCount: activeCount, LastUpdated: i18n.RelativeTime(loc, doc.Mentorship.LastUpdated), LastUpdatedAt: isoTime(doc.Mentorship.LastUpdated)}
 	cards := make([]FacultyCourseCard, 0, len(doc.Courses))
 	for _, c := range doc.Courses {
-		label, tone := courseStatusMeta(c.Status)
+		label, tone := courseStatusMeta(loc, c.Status)
 		cards = append(cards, FacultyCourseCard{ID: c.ID.Hex(), Title: c.Title, Status: c.Status, StatusLabel: label, StatusTone: tone, Code: c.Code, LastUpdated: c.LastUpdated.UTC().Format(time.RFC3339)})
 	}
 	analytics := FacultyAnalytics{Labels: doc.Analytics.Labels, Students: doc.Analytics.Students, AvgGrade: doc.Analytics.AvgGrade}
@@ -234,16 +243,16 @@ func mapFacultyDashboard(doc *facultyDashboardDoc) FacultyOverviewResponse {
 }
 
 // status helpers
-func courseStatusMeta(status string) (string, string) {
+func courseStatusMeta(loc, status string) (string, string) {
 	switch strings.ToLower(status) {
 	case "published":
-		return "Published", "emerald"
+		return i18n.T(loc, "Published"), "emerald"
 	case "draft":
-		return "Draft", "amber"
+		return i18n.T(loc, "Draft"), "amber"
 	case "archived":
-		return "Archived", "slate"
+		return i18n.T(loc, "Archived"), "slate"
 	case "meeting_soon":
-		return "Meeting Soon", "amber"
+		return i18n.T(loc, "Meeting Soon"), "amber"
 	default:
 		return strings.Title(status), "indigo"
 	}
@@ -268,6 +277,7 @@ func normalizeAISuggestionStatus(s string) string {
 		return "reviewed"
 	}
 }
+// normalizeCourseStatus lowercases a validated status and defaults empty input to draft.
 func normalizeCourseStatus(s string) string {
 	s = strings.ToLower(strings.TrimSpace(s))
 	switch s {
@@ -291,13 +301,8 @@ func ReviewAISuggestion(w http.ResponseWriter, r *http.Request) {
 		common.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid suggestion id"})
 		return
 	}
-	var req struct {
-		Status          string `json:"status"`
-		Recommendation  string `json:"recommendation"`
-		GradeSuggestion string `json:"gradeSuggestion"`
-	}
-	if err := common.DecodeJSON(r, &req); err != nil {
-		common.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid payload"})
+	var req models.ReviewAISuggestionRequest
+	if !common.BindJSON(w, r, &req) {
 		return
 	}
 	status := normalizeAISuggestionStatus(req.Status)
@@ -309,10 +314,20 @@ func ReviewAISuggestion(w http.ResponseWriter, r *http.Request) {
 	if strings.TrimSpace(req.GradeSuggestion) != "" {
 		setFields["ai_suggestions.$.grade_suggestion"] = strings.TrimSpace(req.GradeSuggestion)
 	}
-	ctx := context.Background()
+	ctx := r.Context()
+	actorID, _ := common.UserIDFromContext(r.Context())
 	findOpts := options.FindOneAndUpdate().SetReturnDocument(options.After)
-	var doc facultyDashboardDoc
-	err = common.FacultyDashboardsCol.FindOneAndUpdate(ctx, bson.M{"faculty_id": targetID, "ai_suggestions._id": suggestionID}, bson.M{"$set": setFields, "$currentDate": bson.M{"updated_at": true}}, findOpts).Decode(&doc)
+	var before, doc facultyDashboardDoc
+	filter := bson.M{"faculty_id": targetID, "ai_suggestions._id": suggestionID}
+	err = common.WithTransaction(ctx, func(sc mongo.SessionContext) error {
+		if err := common.FacultyDashboardsCol.FindOne(sc, filter).Decode(&before); err != nil {
+			return err
+		}
+		if err := common.FacultyDashboardsCol.FindOneAndUpdate(sc, filter, bson.M{"$set": setFields, "$currentDate": bson.M{"updated_at": true}}, findOpts).Decode(&doc); err != nil {
+			return err
+		}
+		return common.Outbox.Record(sc, events.AISuggestionReviewed{FacultyID: targetID, SuggestionID: suggestionID, Status: status, ReviewedBy: actorID, ReviewedAt: now})
+	})
 	if errors.Is(err, mongo.ErrNoDocuments) {
 		common.WriteJSON(w, http.StatusNotFound, map[string]string{"error": "suggestion not found"})
 		return
@@ -321,6 +336,9 @@ func ReviewAISuggestion(w http.ResponseWriter, r *http.Request) {
 		common.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to update suggestion"})
 		return
 	}
+	audit.SetTarget(ctx, "ai_suggestion", suggestionHex)
+	audit.SetChange(ctx, findSuggestion(&before, suggestionID), findSuggestion(&doc, suggestionID))
+	common.Versions.Bump(ctx, httpcache.FacultyScope(targetID))
 	respondFacultyDashboard(w, ctx, &doc)
 }
 
@@ -329,37 +347,43 @@ func AddMentee(w http.ResponseWriter, r *http.Request) {
 	if !ok {
 		return
 	}
-	var req struct{ Name, Status, NextSession, Note string }
-	if err := common.DecodeJSON(r, &req); err != nil {
-		common.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid payload"})
+	var req models.AddMenteeRequest
+	if !common.BindJSON(w, r, &req) {
 		return
 	}
 	name := strings.TrimSpace(req.Name)
-	if name == "" {
-		common.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "name is required"})
-		return
+	ctx := r.Context()
+	if req.StudentID > 0 {
+		// Linking the record to the student's account lets it follow their data export and deletion.
+		if n, err := common.UsersCol.CountDocuments(ctx, bson.M{"user_id": req.StudentID, "role": common.RoleStudent}); err != nil || n == 0 {
+			common.WriteValidationError(w, common.ValidationErrors{{Field: "studentId", Rule: "oneof", Message: "is not a student on this campus"}})
+			return
+		}
 	}
 	now := time.Now().UTC()
-	mentee := facultyMenteeDoc{ID: primitive.NewObjectID(), Name: name, Status: normalizeMenteeStatus(req.Status), NextSession: strings.TrimSpace(req.NextSession), Note: strings.TrimSpace(req.Note), CreatedAt: now, UpdatedAt: now}
-	ctx := context.Background()
+	mentee := facultyMenteeDoc{ID: primitive.NewObjectID(), StudentID: req.StudentID, Name: name, Status: normalizeMenteeStatus(req.Status), NextSession: strings.TrimSpace(req.NextSession), Note: strings.TrimSpace(req.Note), CreatedAt: now, UpdatedAt: now}
+	actorID, _ := common.UserIDFromContext(r.Context())
 	findOpts := options.FindOneAndUpdate().SetReturnDocument(options.After)
 	var doc facultyDashboardDoc
-	err := common.FacultyDashboardsCol.FindOneAndUpdate(ctx, bson.M{"faculty_id": targetID}, bson.M{"$push": bson.M{"mentorship.mentees": mentee}, "$set": bson.M{"mentorship.last_updated": now, "updated_at": now}, "$inc": bson.M{"overview.students_mentored": 1}}, findOpts).Decode(&doc)
-	if errors.Is(err, mongo.ErrNoDocuments) {
-		newDoc := facultyDashboardDoc{FacultyID: targetID, Overview: facultyOverviewDoc{CoursesTaught: 0, StudentsMentored: 1, AverageGrade: 0, PendingReviews: 0}, AISuggestions: []facultyAISuggestionDoc{}, Mentorship: facultyMentorshipDoc{Mentees: []facultyMenteeDoc{mentee}, LastUpdated: now}, Courses: []facultyCourseDoc{}, Analytics: facultyAnalyticsDoc{Labels: []string{}, Students: []int{}, AvgGrade: []int{}}, CreatedAt: now, UpdatedAt: now}
-		res, insertErr := common.FacultyDashboardsCol.InsertOne(ctx, newDoc)
-		if insertErr != nil {
-			common.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to create dashboard"})
-			return
+	// The mentee push, the students_mentored bump and the MenteeAdded event commit together.
+	err := common.WithTransaction(ctx, func(sc mongo.SessionContext) error {
+		err := common.FacultyDashboardsCol.FindOneAndUpdate(sc, bson.M{"faculty_id": targetID}, bson.M{"$push": bson.M{"mentorship.mentees": mentee}, "$set": bson.M{"mentorship.last_updated": now, "updated_at": now}, "$inc": bson.M{"overview.students_mentored": 1}}, findOpts).Decode(&doc)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			doc = facultyDashboardDoc{ID: primitive.NewObjectID(), FacultyID: targetID, Overview: facultyOverviewDoc{CoursesTaught: 0, StudentsMentored: 1, AverageGrade: 0, PendingReviews: 0}, AISuggestions: []facultyAISuggestionDoc{}, Mentorship: facultyMentorshipDoc{Mentees: []facultyMenteeDoc{mentee}, LastUpdated: now}, Courses: []facultyCourseDoc{}, Analytics: facultyAnalyticsDoc{Labels: []string{}, Students: []int{}, AvgGrade: []int{}}, CreatedAt: now, UpdatedAt: now}
+			_, err = common.FacultyDashboardsCol.InsertOne(sc, doc)
 		}
-		if oid, ok := res.InsertedID.(primitive.ObjectID); ok {
-			newDoc.ID = oid
+		if err != nil {
+			return err
 		}
-		doc = newDoc
-	} else if err != nil {
+		return common.Outbox.Record(sc, events.MenteeAdded{FacultyID: targetID, MenteeID: mentee.ID, Name: mentee.Name, Status: mentee.Status, AddedBy: actorID, AddedAt: now})
+	})
+	if err != nil {
 		common.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to add mentee"})
 		return
 	}
+	audit.SetTarget(ctx, "mentee", mentee.ID.Hex())
+	audit.SetChange(ctx, nil, mentee)
+	common.Versions.Bump(ctx, httpcache.FacultyScope(targetID))
 	respondFacultyDashboard(w, ctx, &doc)
 }
 
@@ -375,9 +399,8 @@ func UpdateMenteeStatus(w http.ResponseWriter, r *http.Request) {
 		common.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid mentee id"})
 		return
 	}
-	var req struct{ Status, NextSession, Note *string }
-	if err := common.DecodeJSON(r, &req); err != nil {
-		common.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid payload"})
+	var req models.UpdateMenteeRequest
+	if !common.BindJSON(w, r, &req) {
 		return
 	}
 	now := time.Now().UTC()
@@ -391,10 +414,10 @@ func UpdateMenteeStatus(w http.ResponseWriter, r *http.Request) {
 	if req.Note != nil {
 		setFields["mentorship.mentees.$.note"] = strings.TrimSpace(*req.Note)
 	}
-	ctx := context.Background()
+	ctx := r.Context()
 	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
-	var doc facultyDashboardDoc
-	err = common.FacultyDashboardsCol.FindOneAndUpdate(ctx, bson.M{"faculty_id": targetID, "mentorship.mentees._id": menteeID}, bson.M{"$set": setFields, "$currentDate": bson.M{"updated_at": true}}, opts).Decode(&doc)
+	var before, doc facultyDashboardDoc
+	err = updateDashboard(ctx, r, bson.M{"faculty_id": targetID, "mentorship.mentees._id": menteeID}, bson.M{"$set": setFields, "$currentDate": bson.M{"updated_at": true}}, opts, &before, &doc, events.FacultyDashboardEdited{FacultyID: targetID, Section: "mentorship", ItemID: menteeID, EditedAt: now})
 	if errors.Is(err, mongo.ErrNoDocuments) {
 		common.WriteJSON(w, http.StatusNotFound, map[string]string{"error": "mentee not found"})
 		return
@@ -403,6 +426,9 @@ func UpdateMenteeStatus(w http.ResponseWriter, r *http.Request) {
 		common.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to update mentee"})
 		return
 	}
+	audit.SetTarget(ctx, "mentee", menteeHex)
+	audit.SetChange(ctx, findMentee(&before, menteeID), findMentee(&doc, menteeID))
+	common.Versions.Bump(ctx, httpcache.FacultyScope(targetID))
 	respondFacultyDashboard(w, ctx, &doc)
 }
 
@@ -411,37 +437,35 @@ func AddCourse(w http.ResponseWriter, r *http.Request) {
 	if !ok {
 		return
 	}
-	var req struct{ Title, Status, Code string }
-	if err := common.DecodeJSON(r, &req); err != nil {
-		common.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid payload"})
+	var req models.AddCourseRequest
+	if !common.BindJSON(w, r, &req) {
 		return
 	}
 	title := strings.TrimSpace(req.Title)
-	if title == "" {
-		common.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "title is required"})
-		return
-	}
 	now := time.Now().UTC()
 	course := facultyCourseDoc{ID: primitive.NewObjectID(), Title: title, Status: normalizeCourseStatus(req.Status), Code: strings.TrimSpace(req.Code), LastUpdated: now}
-	ctx := context.Background()
+	ctx := r.Context()
+	actorID, _ := common.UserIDFromContext(r.Context())
 	findOpts := options.FindOneAndUpdate().SetReturnDocument(options.After)
 	var doc facultyDashboardDoc
-	err := common.FacultyDashboardsCol.FindOneAndUpdate(ctx, bson.M{"faculty_id": targetID}, bson.M{"$push": bson.M{"courses": course}, "$set": bson.M{"updated_at": now}, "$inc": bson.M{"overview.courses_taught": 1}}, findOpts).Decode(&doc)
-	if errors.Is(err, mongo.ErrNoDocuments) {
-		newDoc := facultyDashboardDoc{FacultyID: targetID, Overview: facultyOverviewDoc{CoursesTaught: 1, StudentsMentored: 0, AverageGrade: 0, PendingReviews: 0}, AISuggestions: []facultyAISuggestionDoc{}, Mentorship: facultyMentorshipDoc{Mentees: []facultyMenteeDoc{}, LastUpdated: now}, Courses: []facultyCourseDoc{course}, Analytics: facultyAnalyticsDoc{Labels: []string{}, Students: []int{}, AvgGrade: []int{}}, CreatedAt: now, UpdatedAt: now}
-		res, insertErr := common.FacultyDashboardsCol.InsertOne(ctx, newDoc)
-		if insertErr != nil {
-			common.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to create dashboard"})
-			return
+	err := common.WithTransaction(ctx, func(sc mongo.SessionContext) error {
+		err := common.FacultyDashboardsCol.FindOneAndUpdate(sc, bson.M{"faculty_id": targetID}, bson.M{"$push": bson.M{"courses": course}, "$set": bson.M{"updated_at": now}, "$inc": bson.M{"overview.courses_taught": 1}}, findOpts).Decode(&doc)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			doc = facultyDashboardDoc{ID: primitive.NewObjectID(), FacultyID: targetID, Overview: facultyOverviewDoc{CoursesTaught: 1, StudentsMentored: 0, AverageGrade: 0, PendingReviews: 0}, AISuggestions: []facultyAISuggestionDoc{}, Mentorship: facultyMentorshipDoc{Mentees: []facultyMenteeDoc{}, LastUpdated: now}, Courses: []facultyCourseDoc{course}, Analytics: facultyAnalyticsDoc{Labels: []string{}, Students: []int{}, AvgGrade: []int{}}, CreatedAt: now, UpdatedAt: now}
+			_, err = common.FacultyDashboardsCol.InsertOne(sc, doc)
 		}
-		if oid, ok := res.InsertedID.(primitive.ObjectID); ok {
-			newDoc.ID = oid
+		if err != nil {
+			return err
 		}
-		doc = newDoc
-	} else if err != nil {
+		return common.Outbox.Record(sc, events.FacultyDashboardEdited{FacultyID: targetID, Section: "courses", ItemID: course.ID, ActorID: actorID, EditedAt: now})
+	})
+	if err != nil {
 		common.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to add course"})
 		return
 	}
+	audit.SetTarget(ctx, "course", course.ID.Hex())
+	audit.SetChange(ctx, nil, course)
+	common.Versions.Bump(ctx, httpcache.FacultyScope(targetID))
 	respondFacultyDashboard(w, ctx, &doc)
 }
 
@@ -457,9 +481,8 @@ func UpdateCourseStatus(w http.ResponseWriter, r *http.Request) {
 		common.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid course id"})
 		return
 	}
-	var req struct{ Status, Title, Code *string }
-	if err := common.DecodeJSON(r, &req); err != nil {
-		common.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid payload"})
+	var req models.UpdateCourseRequest
+	if !common.BindJSON(w, r, &req) {
 		return
 	}
 	now := time.Now().UTC()
@@ -473,10 +496,10 @@ func UpdateCourseStatus(w http.ResponseWriter, r *http.Request) {
 	if req.Code != nil {
 		setFields["courses.$.code"] = strings.TrimSpace(*req.Code)
 	}
-	ctx := context.Background()
+	ctx := r.Context()
 	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
-	var doc facultyDashboardDoc
-	err = common.FacultyDashboardsCol.FindOneAndUpdate(ctx, bson.M{"faculty_id": targetID, "courses._id": courseID}, bson.M{"$set": setFields, "$currentDate": bson.M{"updated_at": true}}, opts).Decode(&doc)
+	var before, doc facultyDashboardDoc
+	err = updateDashboard(ctx, r, bson.M{"faculty_id": targetID, "courses._id": courseID}, bson.M{"$set": setFields, "$currentDate": bson.M{"updated_at": true}}, opts, &before, &doc, events.FacultyDashboardEdited{FacultyID: targetID, Section: "courses", ItemID: courseID, EditedAt: now})
 	if errors.Is(err, mongo.ErrNoDocuments) {
 		common.WriteJSON(w, http.StatusNotFound, map[string]string{"error": "course not found"})
 		return
@@ -485,45 +508,61 @@ func UpdateCourseStatus(w http.ResponseWriter, r *http.Request) {
 		common.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to update course"})
 		return
 	}
+	audit.SetTarget(ctx, "course", courseHex)
+	audit.SetChange(ctx, findCourse(&before, courseID), findCourse(&doc, courseID))
+	common.Versions.Bump(ctx, httpcache.FacultyScope(targetID))
 	respondFacultyDashboard(w, ctx, &doc)
 }
 
-// --- formatting/time helpers copied ---
-func formatRelativeTimestamp(t time.Time) string {
-	if t.IsZero() {
-		return "Recently"
-	}
-	diff := time.Since(t)
-	if diff < time.Minute {
-		return "Just now"
-	}
-	if diff < time.Hour {
-		m := int(diff / time.Minute)
-		if m <= 1 {
-			return "1 minute ago"
+// updateDashboard applies update to the matching dashboard and records ev (stamped with
+// the acting user) in the same transaction. before receives the dashboard as it was, for
+// the audit log. Returns mongo.ErrNoDocuments when nothing matched.
+func updateDashboard(ctx context.Context, r *http.Request, filter, update bson.M, opts *options.FindOneAndUpdateOptions, before, doc *facultyDashboardDoc, ev events.FacultyDashboardEdited) error {
+	ev.ActorID, _ = common.UserIDFromContext(r.Context())
+	return common.WithTransaction(ctx, func(sc mongo.SessionContext) error {
+		if err := common.FacultyDashboardsCol.FindOne(sc, filter).Decode(before); err != nil {
+			return err
 		}
-		return fmt.Sprintf("%d minutes ago", m)
-	}
-	if diff < 24*time.Hour {
-		h := int(diff / time.Hour)
-		if h <= 1 {
-			return "1 hour ago"
+		if err := common.FacultyDashboardsCol.FindOneAndUpdate(sc, filter, update, opts).Decode(doc); err != nil {
+			return err
+		}
+		return common.Outbox.Record(sc, ev)
+	})
+}
+
+func findSuggestion(doc *facultyDashboardDoc, id primitive.ObjectID) *facultyAISuggestionDoc {
+	for i := range doc.AISuggestions {
+		if doc.AISuggestions[i].ID == id {
+			return &doc.AISuggestions[i]
 		}
-		return fmt.Sprintf("%d hours ago", h)
 	}
-	if diff < 7*24*time.Hour {
-		d := int(diff / (24 * time.Hour))
-		if d <= 1 {
-			return "1 day ago"
+	return nil
+}
+
+func findMentee(doc *facultyDashboardDoc, id primitive.ObjectID) *facultyMenteeDoc {
+	for i := range doc.Mentorship.Mentees {
+		if doc.Mentorship.Mentees[i].ID == id {
+			return &doc.Mentorship.Mentees[i]
 		}
-		return fmt.Sprintf("%d days ago", d)
 	}
-	if diff < 30*24*time.Hour {
-		w := int(diff / (7 * 24 * time.Hour))
-		if w <= 1 {
-			return "1 week ago"
+	return nil
+}
+
+func findCourse(doc *facultyDashboardDoc, id primitive.ObjectID) *facultyCourseDoc {
+	for i := range doc.Courses {
+		if doc.Courses[i].ID == id {
+			return &doc.Courses[i]
 		}
-		return fmt.Sprintf("%d weeks ago", w)
 	}
-	return t.Format("Jan 2, 2006")
+	return nil
+}
+
+// --- formatting/time helpers ---
+
+// isoTime formats t as RFC 3339, or "" when it is unset.
+func isoTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
 }
@@ -0,0 +1,84 @@
+package faculty
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"backend/handlers/common"
+	"backend/httpcache"
+)
+
+const analyticsMonths = 6
+
+// RecomputeAnalytics rebuilds every faculty dashboard's monthly analytics series from
+// quest activity: active students per month and their average academic standing. It also
+// refreshes the pending review counts.
+func RecomputeAnalytics(ctx context.Context) error {
+	analytics, err := monthlyActivity(ctx, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	cursor, err := common.FacultyDashboardsCol.Find(ctx, bson.M{})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+	for cursor.Next(ctx) {
+		var doc facultyDashboardDoc
+		if err := cursor.Decode(&doc); err != nil {
+			continue
+		}
+		recomputeFacultyPending(ctx, &doc)
+		if _, err := common.FacultyDashboardsCol.UpdateOne(ctx, bson.M{"_id": doc.ID}, bson.M{"$set": bson.M{"analytics": analytics}}); err != nil {
+			return err
+		}
+	}
+	common.Versions.Bump(ctx, httpcache.ScopeFaculty)
+	return cursor.Err()
+}
+
+func monthlyActivity(ctx context.Context, now time.Time) (facultyAnalyticsDoc, error) {
+	start := time.Date(now.Year(), now.Month()-analyticsMonths+1, 1, 0, 0, 0, 0, time.UTC)
+	out := facultyAnalyticsDoc{Labels: []string{}, Students: []int{}, AvgGrade: []int{}}
+	for i := 0; i < analyticsMonths; i++ {
+		from := start.AddDate(0, i, 0)
+		to := from.AddDate(0, 1, 0)
+		ids, err := common.UserQuestsCol.Distinct(ctx, "user_id", bson.M{"completed": true, "completed_at": bson.M{"$gte": from, "$lt": to}})
+		if err != nil {
+			return out, err
+		}
+		students, avg, err := studentStanding(ctx, ids)
+		if err != nil {
+			return out, err
+		}
+		out.Labels = append(out.Labels, from.Format("Jan"))
+		out.Students = append(out.Students, students)
+		out.AvgGrade = append(out.AvgGrade, avg)
+	}
+	return out, nil
+}
+
+func studentStanding(ctx context.Context, userIDs []interface{}) (int, int, error) {
+	if len(userIDs) == 0 {
+		return 0, 0, nil
+	}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"user_id": bson.M{"$in": userIDs}, "role": common.RoleStudent}}},
+		{{Key: "$group", Value: bson.M{"_id": nil, "count": bson.M{"$sum": 1}, "avg": bson.M{"$avg": "$academic_standing"}}}},
+	}
+	cursor, err := common.UsersCol.Aggregate(ctx, pipeline)
+	if err != nil {
+		return 0, 0, err
+	}
+	var rows []struct {
+		Count int     `bson:"count"`
+		Avg   float64 `bson:"avg"`
+	}
+	if err := cursor.All(ctx, &rows); err != nil || len(rows) == 0 {
+		return 0, 0, err
+	}
+	return rows[0].Count, int(rows[0].Avg + 0.5), nil
+}
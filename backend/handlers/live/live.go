@@ -0,0 +1,45 @@
+package live
+
+import (
+	"net/http"
+	"strings"
+
+	"backend/handlers/common"
+	"backend/realtime"
+)
+
+const maxTopics = 20
+
+// GET /stream?topics=poll:1,leaderboard
+func Stream(w http.ResponseWriter, r *http.Request) {
+	topics := []string{}
+	for _, raw := range r.URL.Query()["topics"] {
+		for _, t := range strings.Split(raw, ",") {
+			if t = strings.ToLower(strings.TrimSpace(t)); t != "" {
+				topics = append(topics, t)
+			}
+		}
+	}
+	if len(topics) == 0 {
+		common.WriteValidationError(w, common.ValidationErrors{{Field: "topics", Rule: "required", Message: "is required"}})
+		return
+	}
+	if len(topics) > maxTopics {
+		common.WriteValidationError(w, common.ValidationErrors{{Field: "topics", Rule: "max", Message: "must contain at most 20 items"}})
+		return
+	}
+	actorID, _ := common.UserIDFromContext(r.Context())
+	role := common.RoleFromContext(r.Context())
+	for _, t := range topics {
+		kind, id, err := realtime.ParseTopic(t)
+		if err != nil {
+			common.WriteValidationError(w, common.ValidationErrors{{Field: "topics", Rule: "oneof", Message: "must be leaderboard, research, poll:{id} or faculty:{id}"}})
+			return
+		}
+		if kind == "faculty" && role != common.RoleAdmin && !(role == common.RoleFaculty && id == actorID) {
+			common.WriteJSON(w, http.StatusForbidden, map[string]string{"error": "cannot subscribe to another faculty member's dashboard"})
+			return
+		}
+	}
+	common.Live.Serve(w, r, topics)
+}
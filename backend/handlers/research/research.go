@@ -1,7 +1,6 @@
 package research
 
 import (
-	"context"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -10,9 +9,12 @@ import (
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo"
 
+	"backend/events"
 	"backend/handlers/common"
+	"backend/httpcache"
+	"backend/i18n"
 	"backend/models"
 )
 
@@ -22,6 +24,15 @@ const (
 	researchMaxTags      = 6
 )
 
+var postListSpec = common.ListSpec{
+	Sorts:        map[string]string{"created_at": "created_at", "likes": "likes", "comments": "comments"},
+	DefaultSort:  "created_at",
+	DefaultDesc:  true,
+	TieBreaker:   "_id",
+	DefaultLimit: 20,
+	MaxLimit:     100,
+}
+
 // GET /research/posts
 func GetPosts(w http.ResponseWriter, r *http.Request) {
 	viewerID, ok := common.UserIDFromContext(r.Context())
@@ -29,19 +40,51 @@ func GetPosts(w http.ResponseWriter, r *http.Request) {
 		common.WriteJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
 		return
 	}
-	var limit int64
-	if raw := strings.TrimSpace(r.URL.Query().Get("limit")); raw != "" {
-		if parsed, err := strconv.Atoi(raw); err == nil && parsed > 0 {
-			limit = int64(parsed)
-		}
+	page, err := common.ParsePage(r, postListSpec)
+	if err != nil {
+		common.WriteValidationError(w, err)
+		return
+	}
+	filter, err := researchFilter(r)
+	if err != nil {
+		common.WriteValidationError(w, err)
+		return
 	}
-	ctx := context.Background()
-	feed, err := collectResearchFeed(ctx, viewerID, limit)
+	ctx := r.Context()
+	posts, links, err := common.FindPage[models.ResearchPost](ctx, common.ResearchPostsCol, filter, page)
 	if err != nil {
-		common.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		common.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to load research posts"})
 		return
 	}
-	common.WriteJSON(w, http.StatusOK, map[string]interface{}{"items": feed})
+	feed := make([]models.ResearchPostResponse, 0, len(posts))
+	for _, post := range posts {
+		feed = append(feed, buildResearchPostResponse(common.Locale(r), post, viewerID))
+	}
+	common.WriteJSON(w, http.StatusOK, models.ResearchFeedResponse{Items: feed, Next: links.Next, Prev: links.Prev})
+}
+
+// researchFilter builds the Mongo filter for the tag, category and author query parameters.
+// author accepts a numeric user ID or a case-insensitive name fragment.
+func researchFilter(r *http.Request) (bson.M, error) {
+	q := r.URL.Query()
+	filter := bson.M{}
+	if tag := strings.TrimSpace(strings.TrimLeft(q.Get("tag"), "#")); tag != "" {
+		filter["tags"] = common.CaseInsensitive(tag)
+	}
+	if category := strings.TrimSpace(q.Get("category")); category != "" {
+		filter["category"] = common.CaseInsensitive(normalizeResearchCategory(category, false))
+	}
+	if author := strings.TrimSpace(q.Get("author")); author != "" {
+		if id, err := strconv.Atoi(author); err == nil {
+			if id <= 0 {
+				return nil, common.ValidationErrors{{Field: "author", Rule: "min", Message: "must be a positive user ID or a name"}}
+			}
+			filter["author_id"] = id
+		} else {
+			filter["author_name"] = common.ContainsText(author)
+		}
+	}
+	return filter, nil
 }
 
 // POST /research/posts
@@ -51,19 +94,14 @@ func CreatePost(w http.ResponseWriter, r *http.Request) {
 		common.WriteJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized"})
 		return
 	}
-	ctx := context.Background()
+	ctx := r.Context()
 	var user models.User
 	if err := common.UsersCol.FindOne(ctx, bson.M{"user_id": authorID}).Decode(&user); err != nil {
 		common.WriteJSON(w, http.StatusNotFound, map[string]string{"error": "user not found"})
 		return
 	}
-	var req struct {
-		Title, Summary, Body, Category, Link, Image, AuthorRole string
-		Tags                                                    []string
-		IsCollaboration                                         *bool `json:"isCollaboration"`
-	}
-	if err := common.DecodeJSON(r, &req); err != nil {
-		common.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid payload"})
+	var req models.CreateResearchPostRequest
+	if !common.BindJSON(w, r, &req) {
 		return
 	}
 	body := strings.TrimSpace(req.Body)
@@ -76,7 +114,7 @@ func CreatePost(w http.ResponseWriter, r *http.Request) {
 		body = title
 	}
 	if body == "" {
-		common.WriteJSON(w, http.StatusBadRequest, map[string]string{"error": "content is required"})
+		common.WriteValidationError(w, common.ValidationErrors{{Field: "body", Rule: "required", Message: "one of body, summary or title is required"}})
 		return
 	}
 	if summary == "" {
@@ -102,41 +140,30 @@ func CreatePost(w http.ResponseWriter, r *http.Request) {
 	authorRole := deriveAuthorRoleLabel(user, req.AuthorRole)
 	now := time.Now().UTC()
 	post := models.ResearchPost{AuthorID: authorID, AuthorName: user.Name, AuthorRole: authorRole, Title: title, Summary: summary, Body: body, Category: category, Tags: tags, ImageURL: image, Link: link, Likes: 0, Comments: 0, Collaborations: 0, IsCollaboration: isCollab, CreatedAt: now, UpdatedAt: now}
-	res, err := common.ResearchPostsCol.InsertOne(ctx, post)
+	post.ID = primitive.NewObjectID()
+	err := common.WithTransaction(ctx, func(sc mongo.SessionContext) error {
+		if _, err := common.ResearchPostsCol.InsertOne(sc, post); err != nil {
+			return err
+		}
+		if err := common.RecordActivity(sc, authorID, common.ActivityPost, now); err != nil {
+			return err
+		}
+		return common.Outbox.Record(sc, events.ResearchPostCreated{PostID: post.ID, AuthorID: authorID, Title: post.Title, Category: post.Category, Tags: post.Tags, CreatedAt: now})
+	})
 	if err != nil {
 		common.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to save post"})
 		return
 	}
-	if oid, ok := res.InsertedID.(primitive.ObjectID); ok {
-		post.ID = oid
-	}
-	response := buildResearchPostResponse(post, authorID)
+	common.Versions.Bump(ctx, httpcache.ScopeResearch, httpcache.ScopeUsers)
+	response := buildResearchPostResponse(common.Locale(r), post, authorID)
 	common.WriteJSON(w, http.StatusCreated, response)
 }
 
 // -------- internal helpers (adapted from original) ---------
-func collectResearchFeed(ctx context.Context, viewerID int, limit int64) ([]models.ResearchPostResponse, error) {
-	findOpts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
-	if limit > 0 {
-		findOpts.SetLimit(limit)
-	}
-	cursor, err := common.ResearchPostsCol.Find(ctx, bson.M{}, findOpts)
-	if err != nil {
-		return nil, fmt.Errorf("failed to load research posts: %w", err)
-	}
-	defer cursor.Close(ctx)
-	feed := []models.ResearchPostResponse{}
-	for cursor.Next(ctx) {
-		var post models.ResearchPost
-		if err := cursor.Decode(&post); err != nil {
-			continue
-		}
-		feed = append(feed, buildResearchPostResponse(post, viewerID))
-	}
-	return feed, nil
-}
 
-func buildResearchPostResponse(post models.ResearchPost, viewerID int) models.ResearchPostResponse {
+// buildResearchPostResponse renders post for viewerID with labels in loc. Author roles are
+// stored in English and translated here, so older posts are localized too.
+func buildResearchPostResponse(loc string, post models.ResearchPost, viewerID int) models.ResearchPostResponse {
 	id := ""
 	if !post.ID.IsZero() {
 		id = post.ID.Hex()
@@ -152,16 +179,16 @@ func buildResearchPostResponse(post models.ResearchPost, viewerID int) models.Re
 		summary = common.TruncateText(post.Body, researchSummaryLimit)
 	}
 	if summary == "" {
-		summary = "An exciting research update from our community."
+		summary = i18n.T(loc, "An exciting research update from our community.")
 	}
 	category := normalizeResearchCategory(post.Category, post.IsCollaboration)
 	tags := common.SanitizeTagList(post.Tags)
-	timestamp := formatRelativeTimestamp(post.CreatedAt)
+	timestamp := i18n.RelativeTime(loc, post.CreatedAt)
 	createdAt := ""
 	if !post.CreatedAt.IsZero() {
 		createdAt = post.CreatedAt.UTC().Format(time.RFC3339)
 	}
-	return models.ResearchPostResponse{ID: id, Title: title, Summary: summary, Category: category, Tags: tags, Author: models.ResearchPostAuthor{Name: post.AuthorName, Role: post.AuthorRole}, Timestamp: timestamp, CreatedAt: createdAt, Image: strings.TrimSpace(post.ImageURL), Link: strings.TrimSpace(post.Link), Stats: models.ResearchPostStats{Likes: post.Likes, Comments: post.Comments, Collaborations: post.Collaborations}, IsCollaboration: post.IsCollaboration, IsMine: post.AuthorID == viewerID, Trending: isResearchPostTrending(post)}
+	return models.ResearchPostResponse{ID: id, Title: title, Summary: summary, Category: category, Tags: tags, Author: models.ResearchPostAuthor{Name: post.AuthorName, Role: i18n.Translate(loc, post.AuthorRole)}, Timestamp: timestamp, CreatedAt: createdAt, Image: strings.TrimSpace(post.ImageURL), Link: strings.TrimSpace(post.Link), Stats: models.ResearchPostStats{Likes: post.Likes, Comments: post.Comments, Collaborations: post.Collaborations}, IsCollaboration: post.IsCollaboration, IsMine: post.AuthorID == viewerID, Trending: isResearchPostTrending(post)}
 }
 
 func isResearchPostTrending(post models.ResearchPost) bool {
@@ -175,45 +202,6 @@ func isResearchPostTrending(post models.ResearchPost) bool {
 	return false
 }
 
-func formatRelativeTimestamp(t time.Time) string {
-	if t.IsZero() {
-		return "Recently"
-	}
-	diff := time.Since(t)
-	if diff < time.Minute {
-		return "Just now"
-	}
-	if diff < time.Hour {
-		m := int(diff / time.Minute)
-		if m <= 1 {
-			return "1 minute ago"
-		}
-		return fmt.Sprintf("%d minutes ago", m)
-	}
-	if diff < 24*time.Hour {
-		h := int(diff / time.Hour)
-		if h <= 1 {
-			return "1 hour ago"
-		}
-		return fmt.Sprintf("%d hours ago", h)
-	}
-	if diff < 7*24*time.Hour {
-		d := int(diff / (24 * time.Hour))
-		if d <= 1 {
-			return "1 day ago"
-		}
-		return fmt.Sprintf("%d days ago", d)
-	}
-	if diff < 30*24*time.Hour {
-		w := int(diff / (7 * 24 * time.Hour))
-		if w <= 1 {
-			return "1 week ago"
-		}
-		return fmt.Sprintf("%d weeks ago", w)
-	}
-	return t.Format("Jan 2, 2006")
-}
-
 func normalizeResearchCategory(raw string, isCollaboration bool) string {
 	category := strings.TrimSpace(raw)
 	if category == "" {
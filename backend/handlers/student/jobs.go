@@ -0,0 +1,211 @@
+package student
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"backend/handlers/common"
+	"backend/httpcache"
+	"backend/mailer"
+	"backend/models"
+)
+
+var legacyTimeLeft = regexp.MustCompile(`(?i)^\s*(\d+)\s+(day|hour)s?\s+left`)
+
+// ExpirePolls closes polls whose expires_at has passed and refreshes the time_left label
+// of the open ones. Polls seeded before expires_at existed get one derived from their
+// "N days left" label the first time this runs.
+func ExpirePolls(ctx context.Context) error {
+	now := time.Now().UTC()
+	cursor, err := common.PollsCol.Find(ctx, bson.M{"closed": bson.M{"$ne": true}})
+	if err != nil {
+		return err
+	}
+	var polls []models.Poll
+	if err := cursor.All(ctx, &polls); err != nil {
+		return err
+	}
+	for _, p := range polls {
+		set := bson.M{}
+		if p.ExpiresAt == nil {
+			m := legacyTimeLeft.FindStringSubmatch(p.TimeLeft)
+			if m == nil {
+				continue
+			}
+			n, _ := strconv.Atoi(m[1])
+			unit := 24 * time.Hour
+			if strings.EqualFold(m[2], "hour") {
+				unit = time.Hour
+			}
+			expires := now.Add(time.Duration(n) * unit)
+			p.ExpiresAt = &expires
+			set["expires_at"] = expires
+		}
+		if !now.Before(*p.ExpiresAt) {
+			set["closed"] = true
+		}
+		set["time_left"] = pollTimeLeft(p, now)
+		if _, err := common.PollsCol.UpdateOne(ctx, bson.M{"poll_id": p.PollID}, bson.M{"$set": set}); err != nil {
+			return fmt.Errorf("poll %d: %w", p.PollID, err)
+		}
+	}
+	return nil
+}
+
+// pollTimeLeft renders the time_left label from expires_at; polls without one keep theirs.
+func pollTimeLeft(p models.Poll, now time.Time) string {
+	if p.Closed {
+		return "Closed"
+	}
+	if p.ExpiresAt == nil {
+		return p.TimeLeft
+	}
+	left := p.ExpiresAt.Sub(now)
+	switch {
+	case left <= 0:
+		return "Closed"
+	case left < time.Hour:
+		return "Closing soon"
+	case left < 24*time.Hour:
+		if h := int(left / time.Hour); h > 1 {
+			return fmt.Sprintf("%d hours left", h)
+		}
+		return "1 hour left"
+	default:
+		if d := int(left / (24 * time.Hour)); d > 1 {
+			return fmt.Sprintf("%d days left", d)
+		}
+		return "1 day left"
+	}
+}
+
+func pollIsClosed(p models.Poll, now time.Time) bool {
+	return p.Closed || (p.ExpiresAt != nil && !now.Before(*p.ExpiresAt))
+}
+
+// RotateQuests draws the current daily and weekly quests of every student of the tenant in
+// ctx who has none yet. Sets are also drawn on first request, so this only keeps the
+// assignment history complete for students who do not sign in; running it every half hour
+// catches each timezone's midnight soon after it passes.
+func RotateQuests(ctx context.Context) error {
+	now := time.Now().UTC()
+	cursor, err := common.UsersCol.Find(ctx, bson.M{"role": common.RoleStudent})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+	for cursor.Next(ctx) {
+		var user models.User
+		if err := cursor.Decode(&user); err != nil {
+			continue
+		}
+		for _, pool := range common.Pools {
+			if _, err := common.EnsureAssignment(ctx, user, pool, now); err != nil {
+				return fmt.Errorf("user %d %s quests: %w", user.UserID, pool, err)
+			}
+		}
+	}
+	return cursor.Err()
+}
+
+// BreakStreaks brings every streak of the tenant in ctx up to date: a streak with days
+// missed since the user's last activity, in their timezone, spends a freeze per missed day
+// or breaks. Users whose streak was never computed get it rebuilt from their history.
+func BreakStreaks(ctx context.Context) error {
+	changed, err := common.RebuildStreaks(ctx, time.Now().UTC())
+	if changed > 0 {
+		common.Versions.Bump(ctx, httpcache.ScopeUsers)
+	}
+	return err
+}
+
+// SnapshotLeaderboards stores the final standings of the last closed week, month and term
+// for the campus and each of its courses and cohorts. Periods already snapshotted are
+// skipped, so a missed day is caught up by the next run.
+func SnapshotLeaderboards(ctx context.Context) error {
+	campus := common.TenantConfig(ctx)
+	scopes, err := common.LeaderboardScopes(ctx)
+	if err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	for _, window := range common.Windows {
+		period, ok := common.PreviousPeriod(window, now, campus.Gamification)
+		if !ok {
+			continue
+		}
+		for _, scope := range scopes {
+			if err := common.SnapshotLeaderboard(ctx, scope, *period); err != nil {
+				return fmt.Errorf("%s %s %s: %w", window, period.Label, scope, err)
+			}
+		}
+	}
+	return nil
+}
+
+// SendWeeklyDigests emails each student of the tenant in ctx a summary of their last seven days.
+func SendWeeklyDigests(ctx context.Context) error {
+	campus := common.TenantConfig(ctx)
+	now := time.Now().UTC()
+	since := now.AddDate(0, 0, -7)
+	newPosts, err := common.ResearchPostsCol.CountDocuments(ctx, bson.M{"created_at": bson.M{"$gte": since}})
+	if err != nil {
+		return err
+	}
+	openPolls, err := common.PollsCol.CountDocuments(ctx, bson.M{"closed": bson.M{"$ne": true}})
+	if err != nil {
+		return err
+	}
+	coinsByQuest := map[int]int{}
+	questCursor, err := common.QuestsCol.Find(ctx, bson.M{})
+	if err != nil {
+		return err
+	}
+	var quests []models.Quest
+	if err := questCursor.All(ctx, &quests); err != nil {
+		return err
+	}
+	for _, q := range quests {
+		coinsByQuest[q.QuestID] = campus.Gamification.Coins(q.Coins)
+	}
+	cursor, err := common.UsersCol.Find(ctx, bson.M{"role": common.RoleStudent, "email": bson.M{"$ne": ""}})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+	failed := 0
+	for cursor.Next(ctx) {
+		var user models.User
+		if err := cursor.Decode(&user); err != nil {
+			continue
+		}
+		var done []struct {
+			QuestID int `bson:"quest_id"`
+		}
+		completions, err := common.UserQuestsCol.Find(ctx, bson.M{"user_id": user.UserID, "completed": true, "completed_at": bson.M{"$gte": since}})
+		if err != nil {
+			return err
+		}
+		if err := completions.All(ctx, &done); err != nil {
+			return err
+		}
+		earned := 0
+		for _, d := range done {
+			earned += coinsByQuest[d.QuestID]
+		}
+		body := fmt.Sprintf("Hi %s,\n\nYour week on campus:\n- Quests completed: %d\n- Coins earned: %d\n- Current streak: %d days\n- New research posts: %d\n- Open polls: %d\n\nSee you on the portal!\n", user.Name, len(done), earned, common.CurrentStreak(user, campus.Gamification, now), newPosts, openPolls)
+		if err := common.Mailer.Send(ctx, mailer.Message{To: user.Email, Subject: "Your weekly " + campus.Branding.DisplayName + " digest", Body: body}); err != nil {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d digest emails failed", failed)
+	}
+	return cursor.Err()
+}
@@ -0,0 +1,49 @@
+package student
+
+import (
+	"net/http"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"backend/handlers/common"
+	"backend/models"
+)
+
+// GET /me/progress places the caller's lifetime XP on the campus level curve.
+func GetProgress(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, _ := common.UserIDFromContext(ctx)
+	if err := common.LoadXP(ctx, userID); err != nil {
+		common.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to load progress"})
+		return
+	}
+	var user User
+	if err := common.UsersCol.FindOne(ctx, bson.M{"user_id": userID}).Decode(&user); err != nil {
+		common.WriteJSON(w, http.StatusNotFound, map[string]string{"error": "user not found"})
+		return
+	}
+	common.WriteJSON(w, http.StatusOK, common.Progress(user.XP, common.TenantConfig(ctx).Gamification.Levels))
+}
+
+var xpListSpec = common.ListSpec{Sorts: map[string]string{"at": "at"}, DefaultSort: "at", DefaultDesc: true, TieBreaker: "_id", DefaultLimit: 20, MaxLimit: 100}
+
+// GET /me/xp pages through the caller's XP ledger, newest first.
+func GetXPLedger(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	page, verr := common.ParsePage(r, xpListSpec)
+	if verr != nil {
+		common.WriteValidationError(w, verr)
+		return
+	}
+	userID, _ := common.UserIDFromContext(ctx)
+	if err := common.LoadXP(ctx, userID); err != nil {
+		common.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to load progress"})
+		return
+	}
+	items, links, err := common.FindPage[models.XPEntry](ctx, common.XPLedgerCol, bson.M{"user_id": userID}, page)
+	if err != nil {
+		common.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to load progress"})
+		return
+	}
+	common.WriteJSON(w, http.StatusOK, models.XPEntryListResponse{Items: items, Next: links.Next, Prev: links.Prev})
+}
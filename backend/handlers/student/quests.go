@@ -0,0 +1,480 @@
+package student
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"backend/answers"
+	"backend/audit"
+	"backend/handlers/common"
+	"backend/httpcache"
+	"backend/i18n"
+	"backend/models"
+)
+
+var questRoles = []string{common.RoleStudent, common.RoleFaculty, common.RoleAdmin}
+
+// POST /quests creates a quest. It starts as a draft unless status says otherwise.
+func CreateQuest(w http.ResponseWriter, r *http.Request) {
+	var req models.CreateQuestRequest
+	if !common.BindJSON(w, r, &req) {
+		return
+	}
+	ctx := r.Context()
+	authorID, _ := common.UserIDFromContext(ctx)
+	now := timeNow().UTC()
+	q := Quest{Title: strings.TrimSpace(req.Title), Question: strings.TrimSpace(req.Question), Description: strings.TrimSpace(req.Description), Icon: strings.TrimSpace(req.Icon), Difficulty: questDifficulty(req.Difficulty), Coins: req.Coins, XP: req.XP, Status: strings.ToLower(req.Status), Pool: strings.ToLower(req.Pool), Roles: req.Roles, CourseIDs: req.CourseIDs, Check: req.Check, MaxAttempts: req.MaxAttempts, Cooldown: req.Cooldown, CreatedBy: authorID, CreatedAt: &now, UpdatedAt: &now}
+	if q.Status == "" {
+		q.Status = common.QuestDraft
+	}
+	if req.Visibility != nil {
+		q.VisibleFrom, q.VisibleUntil = req.Visibility.From, req.Visibility.Until
+	}
+	if errs := checkQuest(&q); len(errs) > 0 {
+		common.WriteValidationError(w, errs)
+		return
+	}
+	id, err := common.NextQuestID(ctx)
+	if err != nil {
+		common.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to save quest"})
+		return
+	}
+	q.QuestID = id
+	if _, err := common.QuestsCol.InsertOne(ctx, q); err != nil {
+		common.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to save quest"})
+		return
+	}
+	audit.SetTarget(ctx, "quest", q.QuestID)
+	audit.SetChange(ctx, nil, q)
+	common.Versions.Bump(ctx, httpcache.ScopeQuests)
+	common.WriteJSON(w, http.StatusCreated, authoredQuest(q))
+}
+
+// GET /quests/{id}. Students get 404 for a quest they cannot see; faculty and admins get
+// any quest of their campus, with its answer check when they may edit it.
+func GetQuest(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	actorID, _ := common.UserIDFromContext(ctx)
+	questID, _ := strconv.Atoi(mux.Vars(r)["id"])
+	filter := bson.M{"quest_id": questID}
+	staff := false
+	if role := common.RoleFromContext(ctx); role == common.RoleFaculty || role == common.RoleAdmin {
+		staff = true
+	} else {
+		var user User
+		if err := common.UsersCol.FindOne(ctx, bson.M{"user_id": actorID}).Decode(&user); err != nil {
+			common.WriteJSON(w, http.StatusNotFound, map[string]string{"error": "user not found"})
+			return
+		}
+		filter = common.VisibleQuests(user, timeNow().UTC())
+		filter["quest_id"] = questID
+	}
+	var q Quest
+	if err := common.QuestsCol.FindOne(ctx, filter).Decode(&q); errors.Is(err, mongo.ErrNoDocuments) {
+		common.WriteJSON(w, http.StatusNotFound, map[string]string{"error": "quest not found"})
+		return
+	} else if err != nil {
+		common.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to load quest"})
+		return
+	}
+	var userQuest struct {
+		Completed bool `bson:"completed"`
+	}
+	_ = common.UserQuestsCol.FindOne(ctx, bson.M{"user_id": actorID, "quest_id": q.QuestID}).Decode(&userQuest)
+	q.Completed = userQuest.Completed
+	if staff && canEditQuest(ctx, q) {
+		common.WriteJSON(w, http.StatusOK, authoredQuest(q))
+		return
+	}
+	q.Prompt, q.XP = answers.PromptFor(common.QuestCheck(q)), common.QuestXP(q)
+	common.WriteJSON(w, http.StatusOK, q)
+}
+
+// GET /quests/today returns the caller's daily and weekly quests, drawing them on the first
+// request of the period. Admins may pass user_id to see a student's.
+func GetTodayQuests(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	targetID, _ := common.UserIDFromContext(ctx)
+	if common.RoleFromContext(ctx) == common.RoleAdmin {
+		if override, err := strconv.Atoi(r.URL.Query().Get("user_id")); err == nil && override > 0 {
+			targetID = override
+		}
+	}
+	var user User
+	if err := common.UsersCol.FindOne(ctx, bson.M{"user_id": targetID}).Decode(&user); err != nil {
+		common.WriteJSON(w, http.StatusNotFound, map[string]string{"error": "user not found"})
+		return
+	}
+	now := timeNow().UTC()
+	daily, err := assignedQuests(ctx, user, common.PoolDaily, now)
+	if err != nil {
+		common.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to load assigned quests"})
+		return
+	}
+	weekly, err := assignedQuests(ctx, user, common.PoolWeekly, now)
+	if err != nil {
+		common.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to load assigned quests"})
+		return
+	}
+	common.WriteJSON(w, http.StatusOK, models.TodayQuestsResponse{Daily: daily, Weekly: weekly})
+}
+
+var assignmentListSpec = common.ListSpec{Sorts: map[string]string{"start": "starts_at"}, DefaultSort: "start", DefaultDesc: true, TieBreaker: "_id", DefaultLimit: 20, MaxLimit: 100}
+
+// GET /quests/assignments pages through the daily and weekly sets a student was handed,
+// newest first. Faculty and admins may pass user_id to see a student's.
+func GetQuestAssignments(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	page, verr := common.ParsePage(r, assignmentListSpec)
+	if verr != nil {
+		common.WriteValidationError(w, verr)
+		return
+	}
+	q := r.URL.Query()
+	targetID, _ := common.UserIDFromContext(ctx)
+	if role := common.RoleFromContext(ctx); role == common.RoleFaculty || role == common.RoleAdmin {
+		if override, err := strconv.Atoi(q.Get("user_id")); err == nil && override > 0 {
+			targetID = override
+		}
+	}
+	filter := bson.M{"user_id": targetID}
+	if cadence := strings.ToLower(strings.TrimSpace(q.Get("cadence"))); cadence != "" {
+		if !slices.Contains(common.Pools, cadence) {
+			common.WriteValidationError(w, common.ValidationErrors{{Field: "cadence", Rule: "oneof", Message: "must be one of: " + strings.Join(common.Pools, ", ")}})
+			return
+		}
+		filter["cadence"] = cadence
+	}
+	items, links, err := common.FindPage[models.QuestAssignment](ctx, common.QuestAssignmentsCol, filter, page)
+	if err != nil {
+		common.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to load assigned quests"})
+		return
+	}
+	common.WriteJSON(w, http.StatusOK, models.QuestAssignmentListResponse{Items: items, Next: links.Next, Prev: links.Prev})
+}
+
+// assignedQuests returns user's current set of pool quests, in the order drawn and marked
+// with their completions. Quests archived or hidden since the draw drop out of the set.
+func assignedQuests(ctx context.Context, user User, pool string, now time.Time) (models.AssignedQuests, error) {
+	a, err := common.EnsureAssignment(ctx, user, pool, now)
+	if err != nil {
+		return models.AssignedQuests{}, err
+	}
+	set := models.AssignedQuests{Cadence: pool, Period: a.Period, StartsAt: a.StartsAt, ExpiresAt: a.ExpiresAt, Items: []Quest{}}
+	if len(a.QuestIDs) == 0 {
+		return set, nil
+	}
+	filter := common.VisibleQuests(user, now)
+	filter["quest_id"] = bson.M{"$in": a.QuestIDs}
+	cursor, err := common.QuestsCol.Find(ctx, filter)
+	if err != nil {
+		return set, err
+	}
+	var quests []Quest
+	if err := cursor.All(ctx, &quests); err != nil {
+		return set, err
+	}
+	byID := make(map[int]Quest, len(quests))
+	for _, q := range quests {
+		byID[q.QuestID] = q
+	}
+	var done []struct {
+		QuestID int `bson:"quest_id"`
+	}
+	completions, err := common.UserQuestsCol.Find(ctx, bson.M{"user_id": user.UserID, "quest_id": bson.M{"$in": a.QuestIDs}, "completed": true})
+	if err != nil {
+		return set, err
+	}
+	if err := completions.All(ctx, &done); err != nil {
+		return set, err
+	}
+	completed := map[int]bool{}
+	for _, d := range done {
+		completed[d.QuestID] = true
+	}
+	for _, id := range a.QuestIDs {
+		q, ok := byID[id]
+		if !ok {
+			continue
+		}
+		q.Completed = completed[id]
+		q.Prompt, q.XP = answers.PromptFor(common.QuestCheck(q)), common.QuestXP(q)
+		set.Items = append(set.Items, q)
+	}
+	return set, nil
+}
+
+// POST /quests/{id}
+func UpdateQuest(w http.ResponseWriter, r *http.Request) {
+	var req models.UpdateQuestRequest
+	if !common.BindJSON(w, r, &req) {
+		return
+	}
+	before, ok := loadEditableQuest(w, r)
+	if !ok {
+		return
+	}
+	q := before
+	if req.Title != nil {
+		q.Title = strings.TrimSpace(*req.Title)
+	}
+	if req.Question != nil {
+		q.Question = strings.TrimSpace(*req.Question)
+	}
+	if req.Description != nil {
+		q.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.Icon != nil {
+		q.Icon = strings.TrimSpace(*req.Icon)
+	}
+	if req.Difficulty != nil {
+		q.Difficulty = questDifficulty(*req.Difficulty)
+	}
+	if req.Coins != nil {
+		q.Coins = *req.Coins
+	}
+	if req.XP != nil {
+		q.XP = *req.XP
+	}
+	if req.Status != nil {
+		q.Status = strings.ToLower(*req.Status)
+	}
+	if req.Pool != nil {
+		q.Pool = strings.ToLower(*req.Pool)
+	}
+	if req.Visibility != nil {
+		q.VisibleFrom, q.VisibleUntil = req.Visibility.From, req.Visibility.Until
+	}
+	if req.Roles != nil {
+		q.Roles = *req.Roles
+	}
+	if req.CourseIDs != nil {
+		q.CourseIDs = *req.CourseIDs
+	}
+	if req.Check != nil {
+		// The check supersedes a plain answer left from before checks existed.
+		q.Check, q.Answer = req.Check, ""
+		if req.Check.Kind == "" {
+			q.Check = nil
+		}
+	}
+	if req.MaxAttempts != nil {
+		q.MaxAttempts = *req.MaxAttempts
+	}
+	if req.Cooldown != nil {
+		q.Cooldown = *req.Cooldown
+	}
+	if errs := checkQuest(&q); len(errs) > 0 {
+		common.WriteValidationError(w, errs)
+		return
+	}
+	saveQuest(w, r, before, q)
+}
+
+// DELETE /quests/{id} archives a quest. Archived quests disappear for students but keep
+// their completions and coins; set the status back to active to restore one.
+func ArchiveQuest(w http.ResponseWriter, r *http.Request) {
+	before, ok := loadEditableQuest(w, r)
+	if !ok {
+		return
+	}
+	q := before
+	q.Status = common.QuestArchived
+	saveQuest(w, r, before, q)
+}
+
+// loadEditableQuest loads the quest named in the path if the caller may change it: admins
+// may change any quest, faculty only the ones they created.
+func loadEditableQuest(w http.ResponseWriter, r *http.Request) (Quest, bool) {
+	ctx := r.Context()
+	questID, _ := strconv.Atoi(mux.Vars(r)["id"])
+	audit.SetTarget(ctx, "quest", questID)
+	var q Quest
+	if err := common.QuestsCol.FindOne(ctx, bson.M{"quest_id": questID}).Decode(&q); errors.Is(err, mongo.ErrNoDocuments) {
+		common.WriteJSON(w, http.StatusNotFound, map[string]string{"error": "quest not found"})
+		return q, false
+	} else if err != nil {
+		common.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to load quest"})
+		return q, false
+	}
+	if !canEditQuest(ctx, q) {
+		common.WriteJSON(w, http.StatusForbidden, map[string]string{"error": "faculty can only change quests they created"})
+		return q, false
+	}
+	return q, true
+}
+
+// canEditQuest reports whether the caller may change q and see its answer check.
+func canEditQuest(ctx context.Context, q Quest) bool {
+	actorID, _ := common.UserIDFromContext(ctx)
+	return common.RoleFromContext(ctx) == common.RoleAdmin || q.CreatedBy == actorID
+}
+
+func saveQuest(w http.ResponseWriter, r *http.Request, before, q Quest) {
+	ctx := r.Context()
+	now := timeNow().UTC()
+	q.UpdatedAt = &now
+	res, err := common.QuestsCol.ReplaceOne(ctx, bson.M{"quest_id": q.QuestID}, q)
+	if err != nil {
+		common.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to save quest"})
+		return
+	}
+	if res.MatchedCount == 0 {
+		common.WriteJSON(w, http.StatusNotFound, map[string]string{"error": "quest not found"})
+		return
+	}
+	audit.SetChange(ctx, before, q)
+	common.Versions.Bump(ctx, httpcache.ScopeQuests)
+	common.WriteJSON(w, http.StatusOK, authoredQuest(q))
+}
+
+// checkQuest validates what the struct tags cannot: the audience and the visibility window.
+func checkQuest(q *Quest) common.ValidationErrors {
+	var errs common.ValidationErrors
+	for _, role := range q.Roles {
+		if !slices.Contains(questRoles, role) {
+			errs = append(errs, common.FieldError{Field: "roles", Rule: "oneof", Message: "must be one of: " + strings.Join(questRoles, ", ")})
+			break
+		}
+	}
+	for i, id := range q.CourseIDs {
+		if id <= 0 {
+			errs = append(errs, common.FieldError{Field: fmt.Sprintf("courseIds[%d]", i), Rule: "min", Message: "must be a course ID"})
+		}
+	}
+	if q.VisibleFrom != nil && q.VisibleUntil != nil && !q.VisibleUntil.After(*q.VisibleFrom) {
+		errs = append(errs, common.FieldError{Field: "visibility.until", Rule: "after", Message: "must be after from"})
+	}
+	if q.Check != nil {
+		if err := answers.Validate(*q.Check); err != nil {
+			errs = append(errs, answerError("check", err))
+		}
+	}
+	if len(q.Roles) == 0 {
+		q.Roles = nil
+	}
+	if len(q.CourseIDs) == 0 {
+		q.CourseIDs = nil
+	}
+	return errs
+}
+
+// gradeAnswer checks sub against the quest's answer check for userID. It returns the attempt
+// to store with the completion when the answer is right. Otherwise it stores the attempt,
+// writes the response and returns done: a wrong answer gets the author's feedback, never the
+// solution. Attempts past the quest's limit return 409, and attempts within the cooldown
+// after a wrong one return 429 with Retry-After; neither is stored or counted.
+func gradeAnswer(w http.ResponseWriter, r *http.Request, q Quest, check answers.Spec, userID int, sub *answers.Submission, now time.Time) (attempt *models.QuestAttempt, result *answers.Result, done bool) {
+	ctx := r.Context()
+	if sub == nil {
+		common.WriteValidationError(w, common.ValidationErrors{{Field: "answer", Rule: "required", Message: "is required"}})
+		return nil, nil, true
+	}
+	if n, err := common.UserQuestsCol.CountDocuments(ctx, bson.M{"user_id": userID, "quest_id": q.QuestID, "completed": true}); err != nil {
+		common.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to load completion"})
+		return nil, nil, true
+	} else if n > 0 {
+		common.WriteJSON(w, http.StatusConflict, map[string]string{"error": "quest already completed"})
+		return nil, nil, true
+	}
+	last, err := common.LastAttempt(ctx, userID, q.QuestID)
+	if err != nil {
+		common.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to load attempts"})
+		return nil, nil, true
+	}
+	number := 1
+	if last != nil {
+		number = last.Number + 1
+	}
+	if q.MaxAttempts > 0 && number > q.MaxAttempts {
+		common.WriteJSON(w, http.StatusConflict, map[string]string{"error": "no attempts left"})
+		return nil, nil, true
+	}
+	var retryAt *time.Time
+	if q.Cooldown > 0 {
+		if last != nil {
+			if next := last.At.Add(time.Duration(q.Cooldown) * time.Second); now.Before(next) {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(next.Sub(now).Seconds()))))
+				common.WriteJSON(w, http.StatusTooManyRequests, map[string]string{"error": "wait before answering again"})
+				return nil, nil, true
+			}
+		}
+		next := now.Add(time.Duration(q.Cooldown) * time.Second)
+		retryAt = &next
+	}
+	res, err := answers.Check(check, *sub)
+	if err != nil {
+		common.WriteValidationError(w, common.ValidationErrors{answerError("answer", err)})
+		return nil, nil, true
+	}
+	a := models.QuestAttempt{UserID: userID, QuestID: q.QuestID, Number: number, Answer: *sub, Correct: res.Correct, Parts: res.Parts, At: now}
+	if res.Correct {
+		return &a, &res, false
+	}
+	if err := common.RecordAttempt(ctx, a); errors.Is(err, common.ErrAttemptTaken) {
+		common.WriteJSON(w, http.StatusConflict, map[string]string{"error": "another attempt was submitted at the same time"})
+		return nil, nil, true
+	} else if err != nil {
+		common.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to save attempt"})
+		return nil, nil, true
+	}
+	resp := models.CompleteQuestResponse{Result: &res, Feedback: check.Feedback, RetryAt: retryAt}
+	if resp.Feedback == "" {
+		resp.Feedback = i18n.T(common.Locale(r), "That answer is not correct.")
+	}
+	if q.MaxAttempts > 0 {
+		left := q.MaxAttempts - number
+		resp.AttemptsLeft = &left
+		if left == 0 {
+			resp.RetryAt = nil
+		}
+	}
+	common.WriteJSON(w, http.StatusOK, resp)
+	return nil, nil, true
+}
+
+// answerError turns an error from the answers package into a field error under field.
+func answerError(field string, err error) common.FieldError {
+	var e *answers.Error
+	if errors.As(err, &e) && e.Field != "" {
+		return common.FieldError{Field: field + "." + e.Field, Rule: "answer", Message: e.Message}
+	}
+	if e != nil {
+		return common.FieldError{Field: field, Rule: "answer", Message: e.Message}
+	}
+	return common.FieldError{Field: field, Rule: "answer", Message: err.Error()}
+}
+
+// authoredQuest shows q to its authors, with the answer check and the XP it earns.
+func authoredQuest(q Quest) models.AuthoredQuest {
+	q.Prompt, q.XP = answers.PromptFor(common.QuestCheck(q)), common.QuestXP(q)
+	return models.AuthoredQuest{Quest: q, Check: common.QuestCheck(q)}
+}
+
+// questDifficulty stores difficulties capitalized, as the seeded quests have them.
+func questDifficulty(raw string) string {
+	d := strings.ToLower(strings.TrimSpace(raw))
+	if d == "" {
+		return d
+	}
+	return strings.ToUpper(d[:1]) + d[1:]
+}
+
+// questSummary is the line shown for a quest on the dashboard.
+func questSummary(q Quest) string {
+	if q.Description != "" {
+		return q.Description
+	}
+	return q.Question
+}
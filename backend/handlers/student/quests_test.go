@@ -0,0 +1,106 @@
+package student
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/answers"
+	"backend/handlers/common"
+	"backend/tenant"
+)
+
+// A missing answer is rejected before any attempt is loaded or stored.
+func TestGradeAnswerRequiresAnswer(t *testing.T) {
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/quests/1/complete", nil)
+	attempt, result, done := gradeAnswer(w, r, Quest{QuestID: 1}, answers.Spec{Kind: answers.KindText, Answers: []string{"a"}}, 1, nil, time.Now())
+	if !done || attempt != nil || result != nil {
+		t.Fatalf("gradeAnswer = %v, %v, %v; want done", attempt, result, done)
+	}
+	if w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("status = %d, want 422", w.Code)
+	}
+}
+
+// A completion lookup that fails must not be taken for "not completed yet".
+func TestGradeAnswerFailsWhenCompletionLookupFails(t *testing.T) {
+	// Connect is lazy and nothing listens on this port; without a tenant the count fails first.
+	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI("mongodb://127.0.0.1:1").SetServerSelectionTimeout(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Disconnect(context.Background())
+	saved := common.UserQuestsCol
+	common.UserQuestsCol = tenant.Wrap(client.Database("test").Collection("user_quests"))
+	defer func() { common.UserQuestsCol = saved }()
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/quests/1/complete", nil)
+	_, _, done := gradeAnswer(w, r, Quest{QuestID: 1}, answers.Spec{Kind: answers.KindText, Answers: []string{"a"}}, 1, &answers.Submission{Text: "a"}, time.Now())
+	if !done || w.Code != http.StatusInternalServerError || !strings.Contains(w.Body.String(), "failed to load completion") {
+		t.Errorf("done = %v, status = %d, body %s; want 500 from the completion lookup", done, w.Code, w.Body)
+	}
+}
+
+func TestAnswerError(t *testing.T) {
+	tests := []struct {
+		err  error
+		want common.FieldError
+	}{
+		{&answers.Error{Field: "parts[0].text", Message: "is required"}, common.FieldError{Field: "answer.parts[0].text", Rule: "answer", Message: "is required"}},
+		{&answers.Error{Message: "is required"}, common.FieldError{Field: "answer", Rule: "answer", Message: "is required"}},
+		{errors.New("boom"), common.FieldError{Field: "answer", Rule: "answer", Message: "boom"}},
+	}
+	for _, tt := range tests {
+		if got := answerError("answer", tt.err); got != tt.want {
+			t.Errorf("answerError(%v) = %+v, want %+v", tt.err, got, tt.want)
+		}
+	}
+}
+
+func TestCheckQuest(t *testing.T) {
+	from := time.Date(2026, time.October, 1, 0, 0, 0, 0, time.UTC)
+	until := from.Add(24 * time.Hour)
+	tests := []struct {
+		name   string
+		quest  Quest
+		fields []string
+	}{
+		{"open quest", Quest{}, nil},
+		{"audience", Quest{Roles: []string{common.RoleStudent, common.RoleFaculty}, CourseIDs: []int{101}}, nil},
+		{"unknown role", Quest{Roles: []string{common.RoleStudent, "dean", "guest"}}, []string{"roles"}},
+		{"bad course IDs", Quest{CourseIDs: []int{101, 0, -1}}, []string{"courseIds[1]", "courseIds[2]"}},
+		{"visibility window", Quest{VisibleFrom: &from, VisibleUntil: &until}, nil},
+		{"open-ended window", Quest{VisibleFrom: &until}, nil},
+		{"window ends before it starts", Quest{VisibleFrom: &until, VisibleUntil: &from}, []string{"visibility.until"}},
+		{"empty window", Quest{VisibleFrom: &from, VisibleUntil: &from}, []string{"visibility.until"}},
+		{"valid check", Quest{Check: &answers.Spec{Kind: answers.KindNumeric, Value: 42}}, nil},
+		{"invalid check", Quest{Check: &answers.Spec{Kind: "essay"}}, []string{"check.kind"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var fields []string
+			for _, e := range checkQuest(&tt.quest) {
+				fields = append(fields, e.Field)
+			}
+			if strings.Join(fields, ",") != strings.Join(tt.fields, ",") {
+				t.Errorf("errors on %v, want %v", fields, tt.fields)
+			}
+		})
+	}
+
+	// Empty audience lists are stored as absent, which VisibleQuests treats as open.
+	q := Quest{Roles: []string{}, CourseIDs: []int{}}
+	checkQuest(&q)
+	if q.Roles != nil || q.CourseIDs != nil {
+		t.Errorf("empty audience kept: %+v, %+v", q.Roles, q.CourseIDs)
+	}
+}
@@ -0,0 +1,87 @@
+package student
+
+import (
+	"errors"
+	"net/http"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"backend/audit"
+	"backend/handlers/common"
+	"backend/httpcache"
+	"backend/models"
+)
+
+var errNoFreezeBought = errors.New("streak freeze not bought")
+
+// GET /me/streak
+func GetStreak(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, _ := common.UserIDFromContext(ctx)
+	var user User
+	if err := common.UsersCol.FindOne(ctx, bson.M{"user_id": userID}).Decode(&user); err != nil {
+		common.WriteJSON(w, http.StatusNotFound, map[string]string{"error": "user not found"})
+		return
+	}
+	common.WriteJSON(w, http.StatusOK, streakResponse(r, user))
+}
+
+// POST /me/streak/freezes buys one streak freeze for the campus's price in coins. The
+// coins are entered in the ledger, so they also count against the buyer's windowed
+// leaderboards.
+func BuyStreakFreeze(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, _ := common.UserIDFromContext(ctx)
+	rules := common.TenantConfig(ctx).Gamification.Streaks
+	price, limit := rules.FreezePrice(), rules.FreezeLimit()
+	if limit == 0 {
+		common.WriteJSON(w, http.StatusNotFound, map[string]string{"error": "streak freezes are not available on this campus"})
+		return
+	}
+	audit.SetTarget(ctx, "user", userID)
+	now := timeNow().UTC()
+	err := common.WithTransaction(ctx, func(sc mongo.SessionContext) error {
+		res, err := common.UsersCol.UpdateOne(sc,
+			bson.M{"user_id": userID, "coins": bson.M{"$gte": price}, "streak_freezes": bson.M{"$not": bson.M{"$gte": limit}}},
+			bson.M{"$inc": bson.M{"coins": -price, "streak_freezes": 1}})
+		if err != nil {
+			return err
+		}
+		if res.MatchedCount == 0 {
+			return errNoFreezeBought
+		}
+		return common.RecordCoins(sc, userID, -price, common.LedgerStreakFreeze, 0, now)
+	})
+	if err != nil && !errors.Is(err, errNoFreezeBought) {
+		common.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": "failed to buy streak freeze"})
+		return
+	}
+	var user User
+	if err := common.UsersCol.FindOne(ctx, bson.M{"user_id": userID}).Decode(&user); err != nil {
+		common.WriteJSON(w, http.StatusNotFound, map[string]string{"error": "user not found"})
+		return
+	}
+	if errors.Is(err, errNoFreezeBought) {
+		if user.StreakFreezes >= limit {
+			common.WriteJSON(w, http.StatusConflict, map[string]string{"error": "you already hold the most streak freezes allowed"})
+		} else {
+			common.WriteJSON(w, http.StatusConflict, map[string]string{"error": "not enough coins"})
+		}
+		return
+	}
+	common.Versions.Bump(ctx, httpcache.ScopeUsers)
+	common.WriteJSON(w, http.StatusOK, streakResponse(r, user))
+}
+
+func streakResponse(r *http.Request, user User) models.StreakResponse {
+	g := common.TenantConfig(r.Context()).Gamification
+	loc := common.UserLocation(user, g)
+	now := timeNow()
+	resp := models.StreakResponse{Current: common.CurrentStreak(user, g, now), Longest: max(user.LongestStreak, user.Streak), LastActiveDay: user.StreakDay, Freezes: user.StreakFreezes, MaxFreezes: g.Streaks.FreezeLimit(), FreezeCost: g.Streaks.FreezePrice(), Coins: user.Coins, Timezone: loc.String(), Actions: g.Streaks.Actions}
+	resp.ActiveToday = user.StreakDay != "" && user.StreakDay == now.In(loc).Format("2006-01-02")
+	if len(resp.Actions) == 0 {
+		resp.Actions = common.Activities
+	}
+	return resp
+}
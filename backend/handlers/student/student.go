@@ -2,17 +2,25 @@ package student
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gorilla/mux"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 
+	"backend/answers"
+	"backend/audit"
+	"backend/events"
 	"backend/handlers/common"
+	"backend/httpcache"
+	"backend/i18n"
 	"backend/models"
 )
 
@@ -27,7 +35,7 @@ type (
 func GetUser(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id, _ := strconv.Atoi(vars["id"])
-	ctx := context.Background()
+	ctx := r.Context()
 	var user User
 	if err := common.UsersCol.FindOne(ctx, bson.M{"user_id": id}).Decode(&user); err != nil {
 		common.WriteJSON(w, http.StatusNotFound, map[string]string{"error":"user not found"}); return
@@ -38,6 +46,10 @@ func GetUser(w http.ResponseWriter, r *http.Request) {
 	common.WriteJSON(w, http.StatusOK, common.SanitizeUser(&user))
 }
 
+var questListSpec = common.ListSpec{Sorts: map[string]string{"id": "quest_id", "coins": "coins", "title": "title"}, DefaultSort: "id", TieBreaker: "quest_id", DefaultLimit: 50, MaxLimit: 100}
+
+// GetQuests lists the quests the target user can see and complete right now, apart from
+// pooled ones, which are handed out by rotation. Faculty and admins may pass status (draft, active, archived or all) to browse the catalog instead.
 func GetQuests(w http.ResponseWriter, r *http.Request) {
 	queryUserID, _ := strconv.Atoi(r.URL.Query().Get("user_id"))
 	actorID, actorPresent := common.UserIDFromContext(r.Context())
@@ -46,63 +58,222 @@ func GetQuests(w http.ResponseWriter, r *http.Request) {
 	if actorPresent {
 		if role == common.RoleStudent || targetID == 0 { targetID = actorID }
 	}
-	ctx := context.Background()
-	cursor, err := common.QuestsCol.Find(ctx, bson.M{})
+	page, err := common.ParsePage(r, questListSpec)
+	if err != nil { common.WriteValidationError(w, err); return }
+	ctx := r.Context()
+	var filter bson.M
+	if status := strings.TrimSpace(r.URL.Query().Get("status")); status != "" {
+		if role != common.RoleFaculty && role != common.RoleAdmin { common.WriteJSON(w, http.StatusForbidden, map[string]string{"error":"only faculty and admins can list quests by status"}); return }
+		switch {
+		case status == "all": filter = bson.M{}
+		case slices.Contains(common.QuestStatuses, status): filter = common.QuestsWithStatus(status)
+		default: common.WriteValidationError(w, common.ValidationErrors{{Field:"status", Rule:"oneof", Message:"must be one of: draft, active, archived, all"}}); return
+		}
+	} else {
+		var user User; if err := common.UsersCol.FindOne(ctx, bson.M{"user_id": targetID}).Decode(&user); err != nil { common.WriteJSON(w, http.StatusNotFound, map[string]string{"error":"user not found"}); return }
+		filter = common.VisibleQuests(user, timeNow().UTC())
+		// Pooled quests reach students through their daily and weekly sets; see GetTodayQuests.
+		filter["pool"] = bson.M{"$exists": false}
+	}
+	if difficulty := strings.TrimSpace(r.URL.Query().Get("difficulty")); difficulty != "" { filter["difficulty"] = common.CaseInsensitive(difficulty) }
+	quests, links, err := common.FindPage[Quest](ctx, common.QuestsCol, filter, page)
 	if err != nil { common.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error":"failed to fetch quests"}); return }
-	defer cursor.Close(ctx)
-	quests := []Quest{}
-	for cursor.Next(ctx) { var q Quest; if err := cursor.Decode(&q); err != nil { continue }; var userQuest struct{Completed bool `bson:"completed"`}; common.UserQuestsCol.FindOne(ctx, bson.M{"user_id":targetID,"quest_id":q.QuestID}).Decode(&userQuest); q.Completed = userQuest.Completed; quests = append(quests,q) }
-	common.WriteJSON(w, http.StatusOK, quests)
+	for i := range quests { var userQuest struct{Completed bool `bson:"completed"`}; common.UserQuestsCol.FindOne(ctx, bson.M{"user_id":targetID,"quest_id":quests[i].QuestID}).Decode(&userQuest); quests[i].Completed = userQuest.Completed; quests[i].Prompt = answers.PromptFor(common.QuestCheck(quests[i])); quests[i].XP = common.QuestXP(quests[i]) }
+	common.WriteJSON(w, http.StatusOK, models.QuestListResponse{Items: quests, Next: links.Next, Prev: links.Prev})
 }
 
+var errUserNotFound = errors.New("user not found")
+
+// CompleteQuest awards a quest's coins and XP exactly once per user. Completing it again,
+// even from a concurrent request, returns 409 and changes nothing. A quest with an answer
+// check needs a correct answer first; see gradeAnswer. A pooled quest must be in the user's
+// current daily or weekly set.
 func CompleteQuest(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r); questID,_ := strconv.Atoi(vars["id"])
-	var req struct{ UserID int `json:"user_id"` }
-	_ = common.DecodeJSON(r,&req)
+	var req models.CompleteQuestRequest
+	if !common.BindJSON(w, r, &req) { return }
 	actorID,_ := common.UserIDFromContext(r.Context())
 	role := common.RoleFromContext(r.Context())
 	targetUserID := req.UserID
 	if role == common.RoleStudent || targetUserID == 0 { targetUserID = actorID }
 	if role == common.RoleFaculty && targetUserID != actorID { common.WriteJSON(w, http.StatusForbidden, map[string]string{"error":"faculty cannot complete quests for students"}); return }
-	ctx := context.Background()
+	ctx := r.Context()
+	audit.SetSubject(ctx, targetUserID)
+	audit.SetTarget(ctx, "quest", questID)
+	var user User
+	if err := common.UsersCol.FindOne(ctx, bson.M{"user_id": targetUserID}).Decode(&user); err != nil { common.WriteJSON(w, http.StatusNotFound, map[string]string{"error":"user not found"}); return }
+	completedAt := timeNow().UTC()
+	// A quest the user cannot see, such as a draft or one aimed at another course, cannot be completed either.
 	var quest Quest
-	if err := common.QuestsCol.FindOne(ctx, bson.M{"quest_id": questID}).Decode(&quest); err != nil { common.WriteJSON(w, http.StatusNotFound, map[string]string{"error":"quest not found"}); return }
-	count, err := common.UserQuestsCol.CountDocuments(ctx, bson.M{"user_id": targetUserID, "quest_id": questID})
-	if err != nil { common.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error":"failed to update"}); return }
-	if count == 0 { _, _ = common.UserQuestsCol.InsertOne(ctx, bson.M{"user_id":targetUserID,"quest_id":questID,"completed":true,"completed_at": timeNow()}); _, _ = common.UsersCol.UpdateOne(ctx, bson.M{"user_id":targetUserID}, bson.M{"$inc": bson.M{"coins": quest.Coins}}) }
-	common.WriteJSON(w, http.StatusOK, map[string]interface{}{"success":true,"coins":quest.Coins})
+	filter := common.VisibleQuests(user, completedAt); filter["quest_id"] = questID
+	if err := common.QuestsCol.FindOne(ctx, filter).Decode(&quest); err != nil { common.WriteJSON(w, http.StatusNotFound, map[string]string{"error":"quest not found"}); return }
+	// Admins completing a quest for someone else award it by hand, without an answer or a rotation slot.
+	byHand := role == common.RoleAdmin && targetUserID != actorID
+	if quest.Pool != "" && !byHand {
+		assigned, err := common.IsAssigned(ctx, user, quest.Pool, questID, completedAt)
+		if err != nil { common.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error":"failed to load assigned quests"}); return }
+		if !assigned { common.WriteJSON(w, http.StatusConflict, map[string]string{"error":"quest is not currently assigned"}); return }
+	}
+	var attempt *models.QuestAttempt; var result *answers.Result
+	if check := common.QuestCheck(quest); check != nil && !byHand {
+		var done bool
+		if attempt, result, done = gradeAnswer(w, r, quest, *check, targetUserID, req.Answer, completedAt); done { return }
+	}
+	coins, xp := common.TenantConfig(ctx).Gamification.Coins(quest.Coins), common.QuestXP(quest)
+	var levelUp *models.LevelUp
+	// The completion, the coins, the XP, the ledger entries and the events commit together or not at all.
+	err := common.WithTransaction(ctx, func(sc mongo.SessionContext) error {
+		if err := common.EnsureXP(sc, targetUserID); err != nil { return err }
+		if err := common.CompleteQuestOnce(sc, targetUserID, questID, completedAt); err != nil { return err }
+		if attempt != nil { if err := common.RecordAttempt(sc, *attempt); err != nil { return err } }
+		res, err := common.UsersCol.UpdateOne(sc, bson.M{"user_id":targetUserID}, bson.M{"$inc": bson.M{"coins": coins}})
+		if err != nil { return err }
+		if res.MatchedCount == 0 { return errUserNotFound }
+		if err := common.RecordCoins(sc, targetUserID, coins, common.LedgerQuest, questID, completedAt); err != nil { return err }
+		if levelUp, err = common.AwardXP(sc, targetUserID, xp, common.XPQuest, questID, completedAt); err != nil { return err }
+		if err := common.RecordActivity(sc, targetUserID, common.ActivityQuest, completedAt); err != nil { return err }
+		return common.Outbox.Record(sc, events.QuestCompleted{UserID: targetUserID, QuestID: questID, Coins: coins, CompletedAt: completedAt})
+	})
+	switch {
+	case errors.Is(err, common.ErrQuestCompleted): common.WriteJSON(w, http.StatusConflict, map[string]string{"error":"quest already completed"}); return
+	case errors.Is(err, common.ErrAttemptTaken): common.WriteJSON(w, http.StatusConflict, map[string]string{"error":"another attempt was submitted at the same time"}); return
+	case errors.Is(err, errUserNotFound): common.WriteJSON(w, http.StatusNotFound, map[string]string{"error":"user not found"}); return
+	case err != nil: common.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error":"failed to update"}); return
+	}
+	common.Versions.Bump(ctx, httpcache.ScopeUsers, httpcache.ScopeQuests)
+	common.WriteJSON(w, http.StatusOK, models.CompleteQuestResponse{Success:true, Coins:coins, XP:xp, LevelUp:levelUp, Result:result})
 }
 
+var leaderboardListSpec = common.ListSpec{Sorts: map[string]string{"rank": "rank"}, DefaultSort: "rank", TieBreaker: "user_id", DefaultLimit: 20, MaxLimit: 100}
+
+// GetLeaderboard pages through one leaderboard: ?window= picks lifetime coins or those of
+// the current week, month or term, ?scope= the campus, a course or a cohort, and ?role= the
+// users ranked (students unless faculty, admin or all). The caller's own position is added
+// wherever it falls.
 func GetLeaderboard(w http.ResponseWriter, r *http.Request) {
-	ctx := context.Background(); limit,_ := strconv.Atoi(r.URL.Query().Get("limit")); findOpts := options.Find().SetSort(bson.M{"coins":-1}); if limit>0 { findOpts.SetLimit(int64(limit)) }
-	cursor, err := common.UsersCol.Find(ctx, bson.M{}, findOpts); if err != nil { common.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error":"failed to load leaderboard"}); return }; defer cursor.Close(ctx)
-	leaders := []LeaderboardEntry{}
-	for cursor.Next(ctx) { var user User; if err := cursor.Decode(&user); err != nil { continue }; count,_ := common.UserQuestsCol.CountDocuments(ctx, bson.M{"user_id": user.UserID, "completed": true}); leaders = append(leaders, LeaderboardEntry{UserID:user.UserID,Name:user.Name,CompletedQuests:int(count),Streak:user.Streak,Coins:user.Coins}) }
-	common.WriteJSON(w, http.StatusOK, leaders)
+	ctx := r.Context()
+	campus := common.TenantConfig(ctx)
+	if !campus.Gamification.LeaderboardEnabled { common.WriteJSON(w, http.StatusNotFound, map[string]string{"error":"leaderboard is disabled for this campus"}); return }
+	page, err := common.ParsePage(r, leaderboardListSpec)
+	if err != nil { common.WriteValidationError(w, err); return }
+	q := r.URL.Query()
+	scope, filter, ok := common.ParseScope(q.Get("scope"))
+	if !ok { common.WriteValidationError(w, common.ValidationErrors{{Field:"scope", Rule:"format", Message:"must be campus, course:{id} or cohort:{name}"}}); return }
+	window, ok := parseWindow(q.Get("window"))
+	if !ok { common.WriteValidationError(w, common.ValidationErrors{{Field:"window", Rule:"oneof", Message:"must be one of: " + strings.Join(common.Windows, ", ")}}); return }
+	role, ok := parseLeaderboardRole(q.Get("role"))
+	if !ok { common.WriteValidationError(w, common.ValidationErrors{{Field:"role", Rule:"oneof", Message:"must be one of: " + strings.Join(leaderboardRoles, ", ")}}); return }
+	if role != roleAll { filter["role"] = role }
+	period, err := common.CurrentPeriod(window, timeNow(), campus.Gamification)
+	if errors.Is(err, common.ErrNoTerm) { common.WriteJSON(w, http.StatusNotFound, map[string]string{"error":"no term is in progress"}); return }
+	board := common.Board{Filter: filter, Period: period}
+	leaders, links, err := board.Page(ctx, page)
+	if err != nil { common.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error":"failed to load leaderboard"}); return }
+	resp := models.LeaderboardResponse{Scope: scope, Period: period, Items: leaders, Next: links.Next, Prev: links.Prev}
+	if actorID, ok := common.UserIDFromContext(ctx); ok {
+		if resp.Me, resp.Around, err = board.Around(ctx, actorID); err != nil { common.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error":"failed to load leaderboard"}); return }
+	}
+	common.WriteJSON(w, http.StatusOK, resp)
+}
+
+var snapshotListSpec = common.ListSpec{Sorts: map[string]string{"start": "period.start"}, DefaultSort: "start", DefaultDesc: true, TieBreaker: "_id", DefaultLimit: 10, MaxLimit: 50}
+
+// GetLeaderboardHistory lists the final standings of closed weeks, months or terms for one
+// scope, newest first.
+func GetLeaderboardHistory(w http.ResponseWriter, r *http.Request) {
+	if !common.TenantConfig(r.Context()).Gamification.LeaderboardEnabled { common.WriteJSON(w, http.StatusNotFound, map[string]string{"error":"leaderboard is disabled for this campus"}); return }
+	page, err := common.ParsePage(r, snapshotListSpec)
+	if err != nil { common.WriteValidationError(w, err); return }
+	q := r.URL.Query()
+	scope, _, ok := common.ParseScope(q.Get("scope"))
+	if !ok { common.WriteValidationError(w, common.ValidationErrors{{Field:"scope", Rule:"format", Message:"must be campus, course:{id} or cohort:{name}"}}); return }
+	window := common.WindowWeek
+	if raw := strings.TrimSpace(q.Get("window")); raw != "" {
+		if window, ok = parseWindow(raw); !ok || window == common.WindowAll { common.WriteValidationError(w, common.ValidationErrors{{Field:"window", Rule:"oneof", Message:"must be one of: " + strings.Join(common.Windows[1:], ", ")}}); return }
+	}
+	snapshots, links, err := common.FindPage[models.LeaderboardSnapshot](r.Context(), common.SnapshotsCol, bson.M{"scope": scope, "period.window": window}, page)
+	if err != nil { common.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error":"failed to load leaderboard history"}); return }
+	common.WriteJSON(w, http.StatusOK, models.LeaderboardSnapshotListResponse{Items: snapshots, Next: links.Next, Prev: links.Prev})
 }
 
-func GetPolls(w http.ResponseWriter, r *http.Request) { ctx := context.Background(); cursor, err := common.PollsCol.Find(ctx, bson.M{}); if err != nil { common.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error":"failed to fetch polls"}); return }; defer cursor.Close(ctx); polls := []models.Poll{}; for cursor.Next(ctx) { var p models.Poll; if err := cursor.Decode(&p); err != nil { continue }; polls = append(polls,p) }; common.WriteJSON(w, http.StatusOK, polls) }
+const roleAll = "all"
+
+// leaderboardRoles are the ?role= values of GetLeaderboard; "all" ranks every user.
+var leaderboardRoles = []string{common.RoleStudent, common.RoleFaculty, common.RoleAdmin, roleAll}
+
+func parseLeaderboardRole(raw string) (string, bool) {
+	raw = strings.ToLower(strings.TrimSpace(raw))
+	if raw == "" { return common.RoleStudent, true }
+	for _, role := range leaderboardRoles { if raw == role { return role, true } }
+	return "", false
+}
+
+func parseWindow(raw string) (string, bool) {
+	raw = strings.ToLower(strings.TrimSpace(raw))
+	if raw == "" { return common.WindowAll, true }
+	for _, w := range common.Windows { if raw == w { return w, true } }
+	return "", false
+}
 
-func VoteOnPoll(w http.ResponseWriter, r *http.Request) { vars := mux.Vars(r); pollID,_ := strconv.Atoi(vars["id"]); var req struct{OptionIndex int `json:"option_index"`}; if err := common.DecodeJSON(r,&req); err != nil { common.WriteJSON(w, http.StatusBadRequest, map[string]string{"error":"invalid payload"}); return }; actorID, ok := common.UserIDFromContext(r.Context()); if !ok { common.WriteJSON(w,http.StatusUnauthorized,map[string]string{"error":"unauthorized"}); return }; ctx := context.Background(); count,_ := common.VotesCol.CountDocuments(ctx, bson.M{"user_id":actorID,"poll_id":pollID}); if count==0 { _,_ = common.VotesCol.InsertOne(ctx, bson.M{"user_id":actorID,"poll_id":pollID,"option_index":req.OptionIndex,"voted_at":timeNow()}); updateField := fmt.Sprintf("options.%d.votes", req.OptionIndex); _,_ = common.PollsCol.UpdateOne(ctx, bson.M{"poll_id":pollID}, bson.M{"$inc": bson.M{updateField:1}}) }; common.WriteJSON(w, http.StatusOK, map[string]bool{"success":true}) }
+var pollListSpec = common.ListSpec{Sorts: map[string]string{"id": "poll_id", "question": "question"}, DefaultSort: "id", TieBreaker: "poll_id", DefaultLimit: 20, MaxLimit: 100}
+
+func GetPolls(w http.ResponseWriter, r *http.Request) {
+	page, err := common.ParsePage(r, pollListSpec)
+	if err != nil { common.WriteValidationError(w, err); return }
+	filter := bson.M{}
+	if q := strings.TrimSpace(r.URL.Query().Get("q")); q != "" { filter["question"] = common.ContainsText(q) }
+	polls, links, err := common.FindPage[models.Poll](r.Context(), common.PollsCol, filter, page)
+	if err != nil { common.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error":"failed to fetch polls"}); return }
+	now := timeNow().UTC(); for i := range polls { polls[i].Closed = pollIsClosed(polls[i], now); polls[i].TimeLeft = pollTimeLeft(polls[i], now) }
+	common.WriteJSON(w, http.StatusOK, models.PollListResponse{Items: polls, Next: links.Next, Prev: links.Prev})
+}
+
+func VoteOnPoll(w http.ResponseWriter, r *http.Request) { vars := mux.Vars(r); pollID,_ := strconv.Atoi(vars["id"]); var req models.VotePollRequest; if !common.BindJSON(w, r, &req) { return }; actorID, ok := common.UserIDFromContext(r.Context()); if !ok { common.WriteJSON(w,http.StatusUnauthorized,map[string]string{"error":"unauthorized"}); return }; ctx := r.Context(); var poll models.Poll; if err := common.PollsCol.FindOne(ctx, bson.M{"poll_id":pollID}).Decode(&poll); err != nil { common.WriteJSON(w, http.StatusNotFound, map[string]string{"error":"poll not found"}); return }; if pollIsClosed(poll, timeNow().UTC()) { common.WriteJSON(w, http.StatusConflict, map[string]string{"error":"poll is closed"}); return }; if req.OptionIndex >= len(poll.Options) { common.WriteValidationError(w, common.ValidationErrors{{Field:"option_index", Rule:"max", Message:fmt.Sprintf("must be less than %d", len(poll.Options))}}); return }; count, err := common.VotesCol.CountDocuments(ctx, bson.M{"user_id":actorID,"poll_id":pollID}); if err != nil { common.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error":"failed to load votes"}); return }; if count > 0 { common.WriteJSON(w, http.StatusConflict, map[string]string{"error":"already voted on this poll"}); return }
+	switch err := castVote(ctx, actorID, pollID, req.OptionIndex); {
+	case errors.Is(err, common.ErrAlreadyVoted): common.WriteJSON(w, http.StatusConflict, map[string]string{"error":"already voted on this poll"}); return
+	case err != nil: common.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error":"failed to record vote"}); return
+	}
+	common.Versions.Bump(ctx, httpcache.ScopeUsers); common.WriteJSON(w, http.StatusOK, models.SuccessResponse{Success:true}) }
+
+// castVote stores the vote, bumps the option tally, counts the vote toward the voter's
+// streak and records VoteCast in one transaction. A second vote, even a concurrent one,
+// yields common.ErrAlreadyVoted and changes nothing.
+func castVote(ctx context.Context, userID, pollID, optionIndex int) error {
+	votedAt := timeNow().UTC()
+	return common.WithTransaction(ctx, func(sc mongo.SessionContext) error {
+		if err := common.InsertVote(sc, userID, pollID, optionIndex, votedAt); err != nil { return err }
+		updateField := fmt.Sprintf("options.%d.votes", optionIndex)
+		if _, err := common.PollsCol.UpdateOne(sc, bson.M{"poll_id":pollID}, bson.M{"$inc": bson.M{updateField:1}}); err != nil { return err }
+		if err := common.RecordActivity(sc, userID, common.ActivityVote, votedAt); err != nil { return err }
+		return common.Outbox.Record(sc, events.VoteCast{UserID: userID, PollID: pollID, OptionIndex: optionIndex, VotedAt: votedAt})
+	})
+}
 
 func GetStudentDashboard(w http.ResponseWriter, r *http.Request) {
 	actorID, ok := common.UserIDFromContext(r.Context()); if !ok { common.WriteJSON(w, http.StatusUnauthorized, map[string]string{"error":"unauthorized"}); return }
 	role := common.RoleFromContext(r.Context()); if role != common.RoleStudent && role != common.RoleAdmin { common.WriteJSON(w, http.StatusForbidden, map[string]string{"error":"student dashboard accessible only to students"}); return }
 	targetID := actorID; if role == common.RoleAdmin { if override, err := strconv.Atoi(r.URL.Query().Get("user_id")); err == nil && override>0 { targetID = override } }
-	ctx := context.Background(); var user User; if err := common.UsersCol.FindOne(ctx, bson.M{"user_id": targetID}).Decode(&user); err != nil { common.WriteJSON(w, http.StatusNotFound, map[string]string{"error":"student not found"}); return }
-	quests, err := collectQuestsForUser(ctx, targetID); if err != nil { common.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()}); return }
-	leaders, err := common.CollectLeaderboard(5); if err != nil { common.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()}); return }
-	daily := make([]DailyQuestItem,0,len(quests)); for _, q := range quests { daily = append(daily, DailyQuestItem{ID:q.QuestID, Title:q.Title, Description:q.Question, XP:q.Coins, Completed:q.Completed}) }
-	metrics := map[string]int{"courseProgress": user.CourseProgress, "academicStanding": user.AcademicStanding, "gamificationLevel": user.GamificationLevel, "currentStreak": user.Streak}
+	if etag, ok := common.ETag(r, targetID, httpcache.ScopeUsers, httpcache.ScopeQuests, httpcache.ScopeResearch); ok && httpcache.NotModified(w, r, etag) { return }
+	ctx := r.Context()
+	if err := common.LoadXP(ctx, targetID); err != nil { common.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error":"failed to load progress"}); return }
+	var user User; if err := common.UsersCol.FindOne(ctx, bson.M{"user_id": targetID}).Decode(&user); err != nil { common.WriteJSON(w, http.StatusNotFound, map[string]string{"error":"student not found"}); return }
+	now := timeNow().UTC()
+	g := common.TenantConfig(ctx).Gamification
+	daily, err := assignedQuests(ctx, user, common.PoolDaily, now); if err != nil { common.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()}); return }
+	weekly, err := assignedQuests(ctx, user, common.PoolWeekly, now); if err != nil { common.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()}); return }
+	leaders, err := common.CollectLeaderboard(r.Context(), 5); if err != nil { common.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()}); return }
+	// The level follows from XP and the current curve, so it is right even before Relevel catches up with a curve change.
+	progress := common.Progress(user.XP, g.Levels); user.GamificationLevel = progress.Level
+	metrics := map[string]int{"courseProgress": user.CourseProgress, "academicStanding": user.AcademicStanding, "gamificationLevel": progress.Level, "xp": progress.XP, "xpToNextLevel": progress.XPToNext, "levelProgress": progress.Percent, "currentStreak": common.CurrentStreak(user, g, now), "longestStreak": user.LongestStreak}
 	researchFeed, err := researchHandlersInternalFeed(ctx, targetID, 25); if err != nil { common.WriteJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()}); return }
-	resp := StudentDashboardResponse{User: common.SanitizeUser(&user), Metrics: metrics, DailyQuests: daily, Leaderboard: leaders, ActiveCourses: user.ActiveCourses, ResearchFeed: researchFeed}
+	resp := StudentDashboardResponse{User: common.SanitizeUser(&user), Metrics: metrics, DailyQuests: dashboardQuests(daily.Items), WeeklyQuests: dashboardQuests(weekly.Items), Leaderboard: leaders, ActiveCourses: user.ActiveCourses, ResearchFeed: researchFeed}
 	common.WriteJSON(w, http.StatusOK, resp)
 }
 
-func collectQuestsForUser(ctx context.Context, userID int) ([]Quest, error) {
-	cursor, err := common.QuestsCol.Find(ctx, bson.M{}); if err != nil { return nil, err }; defer cursor.Close(ctx)
-	quests := []Quest{}; for cursor.Next(ctx) { var quest Quest; if err := cursor.Decode(&quest); err != nil { continue }; var userQuest struct{Completed bool `bson:"completed"`}; common.UserQuestsCol.FindOne(ctx, bson.M{"user_id": userID, "quest_id": quest.QuestID}).Decode(&userQuest); quest.Completed = userQuest.Completed; quests = append(quests, quest) }
-	return quests, nil
+// dashboardQuests lists quests the way the dashboard shows them.
+func dashboardQuests(quests []Quest) []DailyQuestItem {
+	items := make([]DailyQuestItem, 0, len(quests)); for _, q := range quests { items = append(items, DailyQuestItem{ID:q.QuestID, Title:q.Title, Description:questSummary(q), XP:common.QuestXP(q), Completed:q.Completed}) }
+	return items
 }
 
 // student package now delegates research feed assembly to research package internals (unexported helper wrapper)
@@ -112,20 +283,19 @@ func researchHandlersInternalFeed(ctx context.Context, viewerID int, limit int64
 	cursor, err := common.ResearchPostsCol.Find(ctx, bson.M{}, findOpts); if err != nil { return nil, fmt.Errorf("failed to load research posts: %w", err) }
 	defer cursor.Close(ctx)
 	feed := []models.ResearchPostResponse{}
-	for cursor.Next(ctx) { var post models.ResearchPost; if err := cursor.Decode(&post); err != nil { continue }; feed = append(feed, researchHandlersInternalBuild(post, viewerID)) }
+	for cursor.Next(ctx) { var post models.ResearchPost; if err := cursor.Decode(&post); err != nil { continue }; feed = append(feed, researchHandlersInternalBuild(i18n.FromContext(ctx), post, viewerID)) }
 	return feed, nil
 }
 
-func researchHandlersInternalBuild(post models.ResearchPost, viewerID int) models.ResearchPostResponse {
+func researchHandlersInternalBuild(loc string, post models.ResearchPost, viewerID int) models.ResearchPostResponse {
 	// call research.buildResearchPostResponse indirectly by duplicating small piece to avoid import cycle
 	id := ""; if !post.ID.IsZero() { id = post.ID.Hex() } else { id = fmt.Sprintf("research-%d", post.CreatedAt.UnixNano()) }
 	title := strings.TrimSpace(post.Title); if title=="" { title = common.DeriveTitleFromBody(post.Body) }
-	summary := strings.TrimSpace(post.Summary); if summary=="" { summary = common.TruncateText(post.Body, 320) }; if summary=="" { summary = "An exciting research update from our community." }
-	timestamp := formatRelativeTimestamp(post.CreatedAt)
+	summary := strings.TrimSpace(post.Summary); if summary=="" { summary = common.TruncateText(post.Body, 320) }; if summary=="" { summary = i18n.T(loc, "An exciting research update from our community.") }
+	timestamp := i18n.RelativeTime(loc, post.CreatedAt)
 	createdAt := ""; if !post.CreatedAt.IsZero() { createdAt = post.CreatedAt.UTC().Format(time.RFC3339) }
-	return models.ResearchPostResponse{ ID:id, Title:title, Summary:summary, Category: post.Category, Tags: common.SanitizeTagList(post.Tags), Author: models.ResearchPostAuthor{Name: post.AuthorName, Role: post.AuthorRole}, Timestamp: timestamp, CreatedAt: createdAt, Image: strings.TrimSpace(post.ImageURL), Link: strings.TrimSpace(post.Link), Stats: models.ResearchPostStats{Likes: post.Likes, Comments: post.Comments, Collaborations: post.Collaborations}, IsCollaboration: post.IsCollaboration, IsMine: post.AuthorID==viewerID, Trending: isResearchPostTrending(post) }
+	return models.ResearchPostResponse{ ID:id, Title:title, Summary:summary, Category: post.Category, Tags: common.SanitizeTagList(post.Tags), Author: models.ResearchPostAuthor{Name: post.AuthorName, Role: i18n.Translate(loc, post.AuthorRole)}, Timestamp: timestamp, CreatedAt: createdAt, Image: strings.TrimSpace(post.ImageURL), Link: strings.TrimSpace(post.Link), Stats: models.ResearchPostStats{Likes: post.Likes, Comments: post.Comments, Collaborations: post.Collaborations}, IsCollaboration: post.IsCollaboration, IsMine: post.AuthorID==viewerID, Trending: isResearchPostTrending(post) }
 }
 
-func formatRelativeTimestamp(t time.Time) string { if t.IsZero() { return "Recently" }; diff := time.Since(t); if diff < time.Minute { return "Just now" }; if diff < time.Hour { m := int(diff/time.Minute); if m<=1 { return "1 minute ago" }; return fmt.Sprintf("%d minutes ago", m) }; if diff < 24*time.Hour { h := int(diff/time.Hour); if h<=1 { return "1 hour ago" }; return fmt.Sprintf("%d hours ago", h) }; if diff < 7*24*time.Hour { d := int(diff/(24*time.Hour)); if d<=1 { return "1 day ago" }; return fmt.Sprintf("%d days ago", d) }; if diff < 30*24*time.Hour { w := int(diff/(7*24*time.Hour)); if w<=1 { return "1 week ago" }; return fmt.Sprintf("%d weeks ago", w) }; return t.Format("Jan 2, 2006") }
 func isResearchPostTrending(post models.ResearchPost) bool { score := post.Likes + post.Comments + (post.Collaborations*3); if score >= 30 { return true }; if time.Since(post.CreatedAt) <= 72*time.Hour && score >= 12 { return true }; return false }
 func timeNow() time.Time { return time.Now() }
@@ -0,0 +1,23 @@
+package student
+
+import "testing"
+
+func TestParseLeaderboardRole(t *testing.T) {
+	tests := []struct {
+		raw, want string
+		ok        bool
+	}{
+		{"", "student", true},
+		{"faculty", "faculty", true},
+		{" Admin ", "admin", true},
+		{"all", "all", true},
+		{"superuser", "", false},
+		{"$ne", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := parseLeaderboardRole(tt.raw)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("parseLeaderboardRole(%q) = %q, %v; want %q, %v", tt.raw, got, ok, tt.want, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package httpcache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Cache-Control policies.
+const (
+	// NoStore keeps responses out of every cache; the default for authenticated routes.
+	NoStore = "no-store"
+	// Revalidate lets the browser keep a response but check its ETag before every reuse.
+	Revalidate = "private, no-cache"
+)
+
+// Public lets shared caches keep a response for maxAge.
+func Public(maxAge time.Duration) string {
+	return fmt.Sprintf("public, max-age=%d", int(maxAge/time.Second))
+}
+
+// Private lets only the browser keep a response, for maxAge without revalidating.
+func Private(maxAge time.Duration) string {
+	return fmt.Sprintf("private, max-age=%d", int(maxAge/time.Second))
+}
+
+// Default sets Cache-Control to policy on every response of the routes it wraps. Routes
+// wrapped in Policy override it.
+func Default(policy string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Cache-Control", policy)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Policy sets Cache-Control to policy before running h.
+func Policy(policy string, h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", policy)
+		h(w, r)
+	}
+}
+
+// ETag returns a strong entity tag over parts. Include everything the representation
+// depends on: data versions, the caller, query parameters and the response language.
+func ETag(parts ...interface{}) string {
+	h := sha256.New()
+	for _, p := range parts {
+		fmt.Fprintf(h, "%v\x00", p)
+	}
+	return `"` + hex.EncodeToString(h.Sum(nil)[:16]) + `"`
+}
+
+// NotModified sets the ETag header and reports whether the request's If-None-Match already
+// names etag. If so it has written 304 Not Modified and the handler should return.
+func NotModified(w http.ResponseWriter, r *http.Request, etag string) bool {
+	w.Header().Set("ETag", etag)
+	if !matches(r.Header.Get("If-None-Match"), etag) {
+		return false
+	}
+	w.WriteHeader(http.StatusNotModified)
+	return true
+}
+
+// matches applies the weak comparison RFC 9110 prescribes for If-None-Match.
+func matches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,90 @@
+// Package httpcache lets expensive GET endpoints answer conditional requests cheaply and
+// compresses large responses. Writers bump per-tenant version counters for the data they
+// change; a dashboard derives its ETag from the counters it depends on, so an unchanged
+// dashboard is answered with 304 Not Modified before any of it is rebuilt.
+package httpcache
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/tenant"
+)
+
+// Scopes name the data a version counter covers.
+const (
+	// ScopeUsers covers user documents: profiles, coins, streaks and enrollments. The
+	// leaderboard and the faculty course aggregates are built from them.
+	ScopeUsers = "users"
+	// ScopeQuests covers the quest catalog and quest completions.
+	ScopeQuests = "quests"
+	// ScopeResearch covers research posts.
+	ScopeResearch = "research"
+	// ScopeFaculty covers every faculty dashboard; bump FacultyScope for a single one.
+	ScopeFaculty = "faculty"
+)
+
+// FacultyScope covers the dashboard of one faculty member.
+func FacultyScope(facultyID int) string { return fmt.Sprintf("faculty:%d", facultyID) }
+
+// Versions stores one counter per tenant and scope in Mongo, so every instance sees a bump.
+type Versions struct {
+	Col *tenant.Collection
+}
+
+type versionDoc struct {
+	Scope   string `bson:"scope"`
+	Version int64  `bson:"version"`
+}
+
+// EnsureIndexes creates the unique (tenant, scope) index.
+func (v *Versions) EnsureIndexes(ctx context.Context) error {
+	_, err := v.Col.Raw().Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.D{{Key: tenant.Field, Value: 1}, {Key: "scope", Value: 1}}, Options: options.Index().SetUnique(true)})
+	return err
+}
+
+// Bump advances the counters of scopes for the tenant in ctx. Call it after the change has
+// committed; a reader in between only sees the new data under the old ETag once. Failures
+// are logged, as the change itself has already succeeded. A nil Versions does nothing.
+func (v *Versions) Bump(ctx context.Context, scopes ...string) {
+	if v == nil || v.Col == nil {
+		return
+	}
+	for _, scope := range scopes {
+		_, err := v.Col.UpdateOne(ctx, bson.M{"scope": scope}, bson.M{"$inc": bson.M{"version": 1}}, options.Update().SetUpsert(true))
+		if mongo.IsDuplicateKeyError(err) {
+			// Two first bumps raced on the upsert; the loser's retry is a plain update.
+			_, err = v.Col.UpdateOne(ctx, bson.M{"scope": scope}, bson.M{"$inc": bson.M{"version": 1}})
+		}
+		if err != nil {
+			log.Printf("httpcache: bump %s: %v", scope, err)
+		}
+	}
+}
+
+// Get returns the counters of scopes for the tenant in ctx, in order. A scope that was
+// never bumped is at version 0.
+func (v *Versions) Get(ctx context.Context, scopes ...string) ([]int64, error) {
+	cursor, err := v.Col.Find(ctx, bson.M{"scope": bson.M{"$in": scopes}})
+	if err != nil {
+		return nil, err
+	}
+	var docs []versionDoc
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, err
+	}
+	byScope := make(map[string]int64, len(docs))
+	for _, d := range docs {
+		byScope[d.Scope] = d.Version
+	}
+	out := make([]int64, len(scopes))
+	for i, scope := range scopes {
+		out[i] = byScope[scope]
+	}
+	return out, nil
+}
@@ -0,0 +1,236 @@
+package i18n
+
+// catalogs maps each non-English locale to its translations, keyed by the English source.
+// Keys with %d or %s also translate messages formatted from them; see Translate.
+var catalogs = map[string]map[string]string{
+	Hindi: {
+		// Relative timestamps
+		"Recently":       "हाल ही में",
+		"Just now":       "अभी-अभी",
+		"1 minute ago":   "1 मिनट पहले",
+		"%d minutes ago": "%d मिनट पहले",
+		"1 hour ago":     "1 घंटा पहले",
+		"%d hours ago":   "%d घंटे पहले",
+		"1 day ago":      "1 दिन पहले",
+		"%d days ago":    "%d दिन पहले",
+		"1 week ago":     "1 सप्ताह पहले",
+		"%d weeks ago":   "%d सप्ताह पहले",
+
+		// Course status labels
+		"Published":    "प्रकाशित",
+		"Draft":        "मसौदा",
+		"Archived":     "संग्रहीत",
+		"Meeting Soon": "जल्द बैठक",
+
+		// Research author roles and fallbacks
+		"Faculty Mentor":     "संकाय मार्गदर्शक",
+		"Administrator":      "प्रशासक",
+		"Student Researcher": "छात्र शोधकर्ता",
+		"Student · %s":       "छात्र · %s",
+		"An exciting research update from our community.": "हमारे समुदाय से एक रोचक शोध अपडेट।",
+
+		// Errors
+		"unauthorized":                                      "अनधिकृत",
+		"invalid credentials":                               "ईमेल या पासवर्ड गलत है",
+		"invalid payload":                                   "अनुरोध का प्रारूप अमान्य है",
+		"validation failed":                                 "सत्यापन विफल रहा",
+		"service unavailable":                               "सेवा उपलब्ध नहीं है",
+		"not found":                                         "नहीं मिला",
+		"user not found":                                    "उपयोगकर्ता नहीं मिला",
+		"student not found":                                 "छात्र नहीं मिला",
+		"quest not found":                                   "क्वेस्ट नहीं मिला",
+		"quest already completed":                           "क्वेस्ट पहले ही पूरा हो चुका है",
+		"poll not found":                                    "पोल नहीं मिला",
+		"poll is closed":                                    "पोल बंद हो चुका है",
+		"course not found":                                  "पाठ्यक्रम नहीं मिला",
+		"mentee not found":                                  "मेंटी नहीं मिला",
+		"suggestion not found":                              "सुझाव नहीं मिला",
+		"invalid course id":                                 "पाठ्यक्रम आईडी अमान्य है",
+		"invalid mentee id":                                 "मेंटी आईडी अमान्य है",
+		"invalid suggestion id":                             "सुझाव आईडी अमान्य है",
+		"password is incorrect":                             "पासवर्ड गलत है",
+		"admin access required":                             "प्रशासक पहुँच आवश्यक है",
+		"faculty access required":                           "संकाय पहुँच आवश्यक है",
+		"students may only view their own profile":          "छात्र केवल अपनी प्रोफ़ाइल देख सकते हैं",
+		"student dashboard accessible only to students":     "छात्र डैशबोर्ड केवल छात्रों के लिए है",
+		"faculty cannot complete quests for students":       "संकाय छात्रों के लिए क्वेस्ट पूरे नहीं कर सकता",
+		"leaderboard is disabled for this campus":           "इस कैंपस के लिए लीडरबोर्ड बंद है",
+		"no term is in progress":                            "अभी कोई सत्र नहीं चल रहा है",
+		"only faculty and admins can list quests by status": "केवल संकाय और व्यवस्थापक स्थिति के अनुसार क्वेस्ट देख सकते हैं",
+		"faculty can only change quests they created":       "संकाय केवल अपने बनाए क्वेस्ट बदल सकता है",
+		"failed to load quest":                              "क्वेस्ट लोड नहीं हो सका",
+		"failed to save quest":                              "क्वेस्ट सहेजा नहीं जा सका",
+		"no attempts left":                                  "कोई प्रयास शेष नहीं है",
+		"wait before answering again":                       "दोबारा उत्तर देने से पहले प्रतीक्षा करें",
+		"another attempt was submitted at the same time":    "उसी समय एक और प्रयास भेजा गया",
+		"failed to load attempts":                           "प्रयास लोड नहीं हो सके",
+		"failed to save attempt":                            "प्रयास सहेजा नहीं जा सका",
+		"That answer is not correct.":                       "यह उत्तर सही नहीं है।",
+		"quest is not currently assigned":                   "यह क्वेस्ट अभी आपको सौंपा नहीं गया है",
+		"failed to load assigned quests":                    "सौंपे गए क्वेस्ट लोड नहीं हो सके",
+		"streak freezes are not available on this campus":   "इस कैंपस पर स्ट्रीक फ़्रीज़ उपलब्ध नहीं हैं",
+		"you already hold the most streak freezes allowed":  "आपके पास पहले से अधिकतम अनुमत स्ट्रीक फ़्रीज़ हैं",
+		"not enough coins":                                  "पर्याप्त सिक्के नहीं हैं",
+		"failed to buy streak freeze":                       "स्ट्रीक फ़्रीज़ नहीं खरीदा जा सका",
+		"failed to load progress":                           "प्रगति लोड नहीं हो सकी",
+		"title is required":                                 "शीर्षक आवश्यक है",
+		"content is required":                               "सामग्री आवश्यक है",
+		"message is required":                               "संदेश आवश्यक है",
+		"failed to record vote":                             "वोट दर्ज नहीं हो सका",
+		"failed to save post":                               "पोस्ट सहेजी नहीं जा सकी",
+		"failed to generate token":                          "टोकन नहीं बन सका",
+
+		// Validation messages
+		"is required":                                              "आवश्यक है",
+		"must not be blank":                                        "खाली नहीं हो सकता",
+		"must be at least %d characters":                           "कम से कम %d अक्षर होने चाहिए",
+		"must be at most %d characters":                            "अधिकतम %d अक्षर हो सकते हैं",
+		"must contain at least %d items":                           "कम से कम %d आइटम होने चाहिए",
+		"must contain at most %d items":                            "अधिकतम %d आइटम हो सकते हैं",
+		"must be at least %s":                                      "कम से कम %s होना चाहिए",
+		"must be at most %s":                                       "अधिकतम %s हो सकता है",
+		"must be one of: %s":                                       "इनमें से एक होना चाहिए: %s",
+		"must be a valid http(s) URL":                              "मान्य http(s) URL होना चाहिए",
+		"must be a user ID":                                        "उपयोगकर्ता आईडी होनी चाहिए",
+		"must be an RFC 3339 timestamp":                            "RFC 3339 टाइमस्टैम्प होना चाहिए",
+		"is not a known campus":                                    "ज्ञात कैंपस नहीं है",
+		"is not a known campus for this host":                      "इस होस्ट के लिए ज्ञात कैंपस नहीं है",
+		"must be after start":                                      "प्रारंभ के बाद होना चाहिए",
+		"must be after from":                                       "from के बाद होना चाहिए",
+		"must be a course ID":                                      "पाठ्यक्रम आईडी होनी चाहिए",
+		"must contain 1 to %d answers":                             "1 से %d उत्तर होने चाहिए",
+		"must be 1 to %d characters":                               "1 से %d अक्षर होने चाहिए",
+		"must be a valid regular expression":                       "मान्य रेगुलर एक्सप्रेशन होना चाहिए",
+		"must be an IANA timezone such as Asia/Kolkata":            "Asia/Kolkata जैसा IANA समय क्षेत्र होना चाहिए",
+		"must be more than 0 and more than the previous threshold": "0 से और पिछली सीमा से अधिक होना चाहिए",
+		"must be a finite number":                                  "सीमित संख्या होनी चाहिए",
+		"must be a finite number of at least 0":                    "0 या उससे अधिक की सीमित संख्या होनी चाहिए",
+		"must be a number":                                         "संख्या होनी चाहिए",
+		"must contain 2 to %d options":                             "2 से %d विकल्प होने चाहिए",
+		"must name at least one option":                            "कम से कम एक विकल्प बताना चाहिए",
+		"must be less than %d":                                     "%d से कम होना चाहिए",
+		"must not repeat an option":                                "कोई विकल्प दोहराया नहीं जा सकता",
+		"must contain 1 to %d parts":                               "1 से %d भाग होने चाहिए",
+		"must not be multipart":                                    "बहु-भागीय नहीं हो सकता",
+		"must contain %d answers":                                  "%d उत्तर होने चाहिए",
+		"must be campus, course:{id} or cohort:{name}":             "campus, course:{id} या cohort:{name} होना चाहिए",
+	},
+	Tamil: {
+		// Relative timestamps
+		"Recently":       "சமீபத்தில்",
+		"Just now":       "இப்போதுதான்",
+		"1 minute ago":   "1 நிமிடத்திற்கு முன்பு",
+		"%d minutes ago": "%d நிமிடங்களுக்கு முன்பு",
+		"1 hour ago":     "1 மணிநேரத்திற்கு முன்பு",
+		"%d hours ago":   "%d மணிநேரங்களுக்கு முன்பு",
+		"1 day ago":      "1 நாளுக்கு முன்பு",
+		"%d days ago":    "%d நாட்களுக்கு முன்பு",
+		"1 week ago":     "1 வாரத்திற்கு முன்பு",
+		"%d weeks ago":   "%d வாரங்களுக்கு முன்பு",
+
+		// Course status labels
+		"Published":    "வெளியிடப்பட்டது",
+		"Draft":        "வரைவு",
+		"Archived":     "காப்பகப்படுத்தப்பட்டது",
+		"Meeting Soon": "விரைவில் சந்திப்பு",
+
+		// Research author roles and fallbacks
+		"Faculty Mentor":     "ஆசிரிய வழிகாட்டி",
+		"Administrator":      "நிர்வாகி",
+		"Student Researcher": "மாணவ ஆய்வாளர்",
+		"Student · %s":       "மாணவர் · %s",
+		"An exciting research update from our community.": "எங்கள் சமூகத்திலிருந்து ஒரு சுவாரஸ்யமான ஆய்வுச் செய்தி.",
+
+		// Errors
+		"unauthorized":                                      "அங்கீகரிக்கப்படவில்லை",
+		"invalid credentials":                               "மின்னஞ்சல் அல்லது கடவுச்சொல் தவறு",
+		"invalid payload":                                   "கோரிக்கையின் வடிவம் தவறானது",
+		"validation failed":                                 "சரிபார்ப்பு தோல்வியடைந்தது",
+		"service unavailable":                               "சேவை கிடைக்கவில்லை",
+		"not found":                                         "கிடைக்கவில்லை",
+		"user not found":                                    "பயனர் கிடைக்கவில்லை",
+		"student not found":                                 "மாணவர் கிடைக்கவில்லை",
+		"quest not found":                                   "குவெஸ்ட் கிடைக்கவில்லை",
+		"quest already completed":                           "குவெஸ்ட் ஏற்கனவே முடிக்கப்பட்டது",
+		"poll not found":                                    "வாக்கெடுப்பு கிடைக்கவில்லை",
+		"poll is closed":                                    "வாக்கெடுப்பு முடிந்துவிட்டது",
+		"course not found":                                  "பாடநெறி கிடைக்கவில்லை",
+		"mentee not found":                                  "வழிகாட்டப்படுபவர் கிடைக்கவில்லை",
+		"suggestion not found":                              "பரிந்துரை கிடைக்கவில்லை",
+		"invalid course id":                                 "பாடநெறி ஐடி தவறானது",
+		"invalid mentee id":                                 "வழிகாட்டப்படுபவர் ஐடி தவறானது",
+		"invalid suggestion id":                             "பரிந்துரை ஐடி தவறானது",
+		"password is incorrect":                             "கடவுச்சொல் தவறு",
+		"admin access required":                             "நிர்வாகி அனுமதி தேவை",
+		"faculty access required":                           "ஆசிரியர் அனுமதி தேவை",
+		"students may only view their own profile":          "மாணவர்கள் தங்கள் சுயவிவரத்தை மட்டுமே பார்க்கலாம்",
+		"student dashboard accessible only to students":     "மாணவர் டாஷ்போர்டு மாணவர்களுக்கு மட்டுமே",
+		"faculty cannot complete quests for students":       "ஆசிரியர்கள் மாணவர்களுக்காக குவெஸ்ட்களை முடிக்க முடியாது",
+		"leaderboard is disabled for this campus":           "இந்த வளாகத்தில் தரவரிசை முடக்கப்பட்டுள்ளது",
+		"no term is in progress":                            "தற்போது எந்தப் பருவமும் நடைபெறவில்லை",
+		"only faculty and admins can list quests by status": "ஆசிரியர்களும் நிர்வாகிகளும் மட்டுமே நிலையின்படி குவெஸ்ட்களைப் பட்டியலிட முடியும்",
+		"faculty can only change quests they created":       "ஆசிரியர்கள் தாங்கள் உருவாக்கிய குவெஸ்ட்களை மட்டுமே மாற்ற முடியும்",
+		"failed to load quest":                              "குவெஸ்டை ஏற்ற முடியவில்லை",
+		"failed to save quest":                              "குவெஸ்டைச் சேமிக்க முடியவில்லை",
+		"no attempts left":                                  "முயற்சிகள் எதுவும் மீதமில்லை",
+		"wait before answering again":                       "மீண்டும் பதிலளிக்கும் முன் காத்திருக்கவும்",
+		"another attempt was submitted at the same time":    "அதே நேரத்தில் மற்றொரு முயற்சி சமர்ப்பிக்கப்பட்டது",
+		"failed to load attempts":                           "முயற்சிகளை ஏற்ற முடியவில்லை",
+		"failed to save attempt":                            "முயற்சியைச் சேமிக்க முடியவில்லை",
+		"That answer is not correct.":                       "அந்த பதில் சரியல்ல.",
+		"quest is not currently assigned":                   "இந்த குவெஸ்ட் தற்போது உங்களுக்கு ஒதுக்கப்படவில்லை",
+		"failed to load assigned quests":                    "ஒதுக்கப்பட்ட குவெஸ்ட்களை ஏற்ற முடியவில்லை",
+		"streak freezes are not available on this campus":   "இந்த வளாகத்தில் ஸ்ட்ரீக் ஃப்ரீஸ்கள் கிடைக்காது",
+		"you already hold the most streak freezes allowed":  "அனுமதிக்கப்பட்ட அதிகபட்ச ஸ்ட்ரீக் ஃப்ரீஸ்கள் ஏற்கனவே உங்களிடம் உள்ளன",
+		"not enough coins":                                  "போதுமான நாணயங்கள் இல்லை",
+		"failed to buy streak freeze":                       "ஸ்ட்ரீக் ஃப்ரீஸை வாங்க முடியவில்லை",
+		"failed to load progress":                           "முன்னேற்றத்தை ஏற்ற முடியவில்லை",
+		"title is required":                                 "தலைப்பு தேவை",
+		"content is required":                               "உள்ளடக்கம் தேவை",
+		"message is required":                               "செய்தி தேவை",
+		"failed to record vote":                             "வாக்கைப் பதிவு செய்ய முடியவில்லை",
+		"failed to save post":                               "பதிவைச் சேமிக்க முடியவில்லை",
+		"failed to generate token":                          "டோக்கனை உருவாக்க முடியவில்லை",
+
+		// Validation messages
+		"is required":                                              "தேவை",
+		"must not be blank":                                        "காலியாக இருக்கக்கூடாது",
+		"must be at least %d characters":                           "குறைந்தது %d எழுத்துகள் இருக்க வேண்டும்",
+		"must be at most %d characters":                            "அதிகபட்சம் %d எழுத்துகள் இருக்கலாம்",
+		"must contain at least %d items":                           "குறைந்தது %d உருப்படிகள் இருக்க வேண்டும்",
+		"must contain at most %d items":                            "அதிகபட்சம் %d உருப்படிகள் இருக்கலாம்",
+		"must be at least %s":                                      "குறைந்தது %s ஆக இருக்க வேண்டும்",
+		"must be at most %s":                                       "அதிகபட்சம் %s ஆக இருக்கலாம்",
+		"must be one of: %s":                                       "இவற்றில் ஒன்றாக இருக்க வேண்டும்: %s",
+		"must be a valid http(s) URL":                              "சரியான http(s) URL ஆக இருக்க வேண்டும்",
+		"must be a user ID":                                        "பயனர் ஐடியாக இருக்க வேண்டும்",
+		"must be an RFC 3339 timestamp":                            "RFC 3339 நேரமுத்திரையாக இருக்க வேண்டும்",
+		"is not a known campus":                                    "அறியப்பட்ட வளாகம் அல்ல",
+		"is not a known campus for this host":                      "இந்த ஹோஸ்டுக்கான அறியப்பட்ட வளாகம் அல்ல",
+		"must be after start":                                      "தொடக்கத்திற்குப் பிறகு இருக்க வேண்டும்",
+		"must be after from":                                       "from-க்குப் பிறகு இருக்க வேண்டும்",
+		"must be a course ID":                                      "பாடநெறி ஐடியாக இருக்க வேண்டும்",
+		"must contain 1 to %d answers":                             "1 முதல் %d பதில்கள் இருக்க வேண்டும்",
+		"must be 1 to %d characters":                               "1 முதல் %d எழுத்துகள் இருக்க வேண்டும்",
+		"must be a valid regular expression":                       "சரியான ரெகுலர் எக்ஸ்பிரஷனாக இருக்க வேண்டும்",
+		"must be an IANA timezone such as Asia/Kolkata":            "Asia/Kolkata போன்ற IANA நேர மண்டலமாக இருக்க வேண்டும்",
+		"must be more than 0 and more than the previous threshold": "0 ஐயும் முந்தைய வரம்பையும் விட அதிகமாக இருக்க வேண்டும்",
+		"must be a finite number":                                  "வரம்புக்குட்பட்ட எண்ணாக இருக்க வேண்டும்",
+		"must be a finite number of at least 0":                    "0 அல்லது அதற்கு மேற்பட்ட வரம்புக்குட்பட்ட எண்ணாக இருக்க வேண்டும்",
+		"must be a number":                                         "எண்ணாக இருக்க வேண்டும்",
+		"must contain 2 to %d options":                             "2 முதல் %d விருப்பங்கள் இருக்க வேண்டும்",
+		"must name at least one option":                            "குறைந்தது ஒரு விருப்பத்தைக் குறிப்பிட வேண்டும்",
+		"must be less than %d":                                     "%d-ஐ விடக் குறைவாக இருக்க வேண்டும்",
+		"must not repeat an option":                                "ஒரு விருப்பத்தை மீண்டும் தேர்ந்தெடுக்கக் கூடாது",
+		"must contain 1 to %d parts":                               "1 முதல் %d பகுதிகள் இருக்க வேண்டும்",
+		"must not be multipart":                                    "பல பகுதிகளாக இருக்கக் கூடாது",
+		"must contain %d answers":                                  "%d பதில்கள் இருக்க வேண்டும்",
+		"must be campus, course:{id} or cohort:{name}":             "campus, course:{id} அல்லது cohort:{name} ஆக இருக்க வேண்டும்",
+	},
+}
+
+// monthNames are the month names Date uses per locale; English keeps Go's layout.
+var monthNames = map[string][]string{
+	Hindi: {"जनवरी", "फ़रवरी", "मार्च", "अप्रैल", "मई", "जून", "जुलाई", "अगस्त", "सितंबर", "अक्तूबर", "नवंबर", "दिसंबर"},
+	Tamil: {"ஜனவரி", "பிப்ரவரி", "மார்ச்", "ஏப்ரல்", "மே", "ஜூன்", "ஜூலை", "ஆகஸ்ட்", "செப்டம்பர்", "அக்டோபர்", "நவம்பர்", "டிசம்பர்"},
+}
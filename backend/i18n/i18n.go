@@ -0,0 +1,248 @@
+// Package i18n localizes the text the server writes itself: relative timestamps, status and
+// role labels, and error messages. Catalogs are keyed by the English source string, so code
+// keeps writing English and a missing translation falls back to it. The response locale is
+// negotiated from the caller's saved preference and the Accept-Language header.
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	English = "en"
+	Hindi   = "hi"
+	Tamil   = "ta"
+
+	// Default is used when nothing the client accepts is supported.
+	Default = English
+)
+
+// Supported lists the locales with a catalog, in the order clients should offer them.
+var Supported = []string{English, Hindi, Tamil}
+
+// IsSupported reports whether loc has a catalog.
+func IsSupported(loc string) bool {
+	return loc == English || catalogs[loc] != nil
+}
+
+// Negotiate picks the response locale: a supported preference wins, then the best supported
+// language in acceptLanguage by q-value ("ta-IN" matches "ta"), then Default.
+func Negotiate(preference, acceptLanguage string) string {
+	if pref := strings.ToLower(strings.TrimSpace(preference)); IsSupported(pref) {
+		return pref
+	}
+	type candidate struct {
+		tag string
+		q   float64
+	}
+	var candidates []candidate
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if tag = strings.ToLower(strings.TrimSpace(tag)); tag != "" && q > 0 {
+			candidates = append(candidates, candidate{tag, q})
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].q > candidates[j].q })
+	for _, c := range candidates {
+		if c.tag == "*" {
+			return Default
+		}
+		base, _, _ := strings.Cut(c.tag, "-")
+		if IsSupported(base) {
+			return base
+		}
+	}
+	return Default
+}
+
+type ctxKey struct{}
+
+// WithLocale returns ctx carrying loc.
+func WithLocale(ctx context.Context, loc string) context.Context {
+	return context.WithValue(ctx, ctxKey{}, loc)
+}
+
+// FromContext returns the locale negotiated for the request, or Default.
+func FromContext(ctx context.Context) string {
+	if ctx != nil {
+		if loc, ok := ctx.Value(ctxKey{}).(string); ok && loc != "" {
+			return loc
+		}
+	}
+	return Default
+}
+
+// FromHeader returns the locale a response is being written in, read back from the
+// Content-Language header Middleware sets. It serves writers that have no request at hand.
+func FromHeader(h http.Header) string {
+	if loc := h.Get("Content-Language"); IsSupported(loc) {
+		return loc
+	}
+	return Default
+}
+
+// Middleware negotiates the locale, stores it in the request context and announces it in
+// Content-Language. preference, when not nil, returns the caller's saved locale; mount a
+// second instance after authentication so the saved preference can override the header.
+func Middleware(preference func(context.Context) string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			pref := ""
+			if preference != nil {
+				pref = preference(r.Context())
+			}
+			loc := Negotiate(pref, r.Header.Get("Accept-Language"))
+			h := w.Header()
+			h.Set("Content-Language", loc)
+			if !hasToken(h.Values("Vary"), "Accept-Language") {
+				h.Add("Vary", "Accept-Language")
+			}
+			next.ServeHTTP(w, r.WithContext(WithLocale(r.Context(), loc)))
+		})
+	}
+}
+
+func hasToken(values []string, token string) bool {
+	for _, v := range values {
+		for _, part := range strings.Split(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// T formats the English format string format in loc, like fmt.Sprintf.
+func T(loc, format string, args ...interface{}) string {
+	if tr, ok := catalogs[loc][format]; ok {
+		format = tr
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// Translate localizes an already formatted English message, such as an error or a stored
+// label. Messages built from a catalog format ("must be at most %d characters") are matched
+// against it and rebuilt with the same arguments; anything unknown is returned unchanged.
+func Translate(loc, msg string) string {
+	cat := catalogs[loc]
+	if cat == nil {
+		return msg
+	}
+	if tr, ok := cat[msg]; ok {
+		return tr
+	}
+	for _, p := range patterns[loc] {
+		if m := p.re.FindStringSubmatch(msg); m != nil {
+			args := make([]interface{}, 0, len(m)-1)
+			for _, arg := range m[1:] {
+				args = append(args, arg)
+			}
+			return fmt.Sprintf(p.format, args...)
+		}
+	}
+	return msg
+}
+
+// RelativeTime describes t relative to now, e.g. "2 hours ago", and falls back to a date
+// after four weeks. A zero t reads "Recently".
+func RelativeTime(loc string, t time.Time) string {
+	if t.IsZero() {
+		return T(loc, "Recently")
+	}
+	diff := time.Since(t)
+	switch {
+	case diff < time.Minute:
+		return T(loc, "Just now")
+	case diff < time.Hour:
+		return plural(loc, int(diff/time.Minute), "1 minute ago", "%d minutes ago")
+	case diff < 24*time.Hour:
+		return plural(loc, int(diff/time.Hour), "1 hour ago", "%d hours ago")
+	case diff < 7*24*time.Hour:
+		return plural(loc, int(diff/(24*time.Hour)), "1 day ago", "%d days ago")
+	case diff < 30*24*time.Hour:
+		return plural(loc, int(diff/(7*24*time.Hour)), "1 week ago", "%d weeks ago")
+	}
+	return Date(loc, t)
+}
+
+func plural(loc string, n int, one, other string) string {
+	if n <= 1 {
+		return T(loc, one)
+	}
+	return T(loc, other, n)
+}
+
+// Date formats t as a day, month name and year, e.g. "Jan 2, 2006" or "2 जनवरी 2006".
+func Date(loc string, t time.Time) string {
+	months := monthNames[loc]
+	if months == nil {
+		return t.Format("Jan 2, 2006")
+	}
+	return fmt.Sprintf("%d %s %d", t.Day(), months[t.Month()-1], t.Year())
+}
+
+type pattern struct {
+	re     *regexp.Regexp
+	format string
+}
+
+// patterns holds, per locale, a matcher for every catalog key with a %d or %s verb.
+var patterns = map[string][]pattern{}
+
+var verb = regexp.MustCompile(`%[ds]`)
+
+func init() {
+	for loc, cat := range catalogs {
+		keys := make([]string, 0, len(cat))
+		for key := range cat {
+			if verb.MatchString(key) {
+				keys = append(keys, key)
+			}
+		}
+		// Longer keys first, so "must be at least %d characters" wins over "must be at least %s".
+		sort.Slice(keys, func(i, j int) bool {
+			if len(keys[i]) != len(keys[j]) {
+				return len(keys[i]) > len(keys[j])
+			}
+			return keys[i] < keys[j]
+		})
+		for _, key := range keys {
+			parts := verb.Split(key, -1)
+			verbs := verb.FindAllString(key, -1)
+			var expr strings.Builder
+			expr.WriteString("^")
+			for i, part := range parts {
+				expr.WriteString(regexp.QuoteMeta(part))
+				if i < len(verbs) {
+					if verbs[i] == "%d" {
+						expr.WriteString(`(-?\d+)`)
+					} else {
+						expr.WriteString(`(.+)`)
+					}
+				}
+			}
+			expr.WriteString("$")
+			// Arguments come back as captured text, so every verb is rendered with %s.
+			patterns[loc] = append(patterns[loc], pattern{re: regexp.MustCompile(expr.String()), format: verb.ReplaceAllString(cat[key], "%s")})
+		}
+	}
+}
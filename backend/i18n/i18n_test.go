@@ -0,0 +1,156 @@
+package i18n
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		name           string
+		preference     string
+		acceptLanguage string
+		want           string
+	}{
+		{"nothing", "", "", English},
+		{"exact tag", "", "hi", Hindi},
+		{"region falls back to base", "", "ta-IN", Tamil},
+		{"tag case", "", "TA-in", Tamil},
+		{"first supported in order", "", "fr, ta, hi", Tamil},
+		{"highest q wins", "", "hi;q=0.5, ta;q=0.9, en;q=0.1", Tamil},
+		{"equal q keeps order", "", "hi;q=0.8, ta;q=0.8", Hindi},
+		{"implicit q of 1", "", "ta;q=0.9, hi", Hindi},
+		{"unsupported only", "", "fr-FR, de", English},
+		{"q of 0 excludes", "", "hi;q=0, ta;q=0.1", Tamil},
+		{"malformed q skipped", "", "hi;q=high, ta", Tamil},
+		{"wildcard", "", "fr, *;q=0.5, hi;q=0.1", English},
+		{"whitespace", "", "  ,  ta-LK ;q=0.7 ,", Tamil},
+		{"preference wins", "hi", "ta", Hindi},
+		{"preference case", " TA ", "hi", Tamil},
+		{"unsupported preference ignored", "fr", "hi", Hindi},
+		{"region preference ignored", "hi-IN", "ta", Tamil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Negotiate(tt.preference, tt.acceptLanguage); got != tt.want {
+				t.Errorf("Negotiate(%q, %q) = %q, want %q", tt.preference, tt.acceptLanguage, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	var got string
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { got = FromContext(r.Context()) })
+	saved := func(context.Context) string { return Hindi }
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Accept-Language", "ta-IN,en;q=0.5")
+	w := httptest.NewRecorder()
+	Middleware(nil)(next).ServeHTTP(w, r)
+	if got != Tamil || w.Header().Get("Content-Language") != Tamil || FromHeader(w.Header()) != Tamil {
+		t.Errorf("header only: context %q, Content-Language %q", got, w.Header().Get("Content-Language"))
+	}
+
+	// The second instance, after authentication, lets the saved preference override the
+	// header without repeating Vary.
+	w = httptest.NewRecorder()
+	Middleware(nil)(Middleware(saved)(next)).ServeHTTP(w, r)
+	if got != Hindi || w.Header().Get("Content-Language") != Hindi {
+		t.Errorf("with preference: context %q, Content-Language %q", got, w.Header().Get("Content-Language"))
+	}
+	if vary := w.Header().Values("Vary"); !slices.Equal(vary, []string{"Accept-Language"}) {
+		t.Errorf("Vary = %q", vary)
+	}
+}
+
+func TestLocaleFallbacks(t *testing.T) {
+	if loc := FromContext(context.Background()); loc != Default {
+		t.Errorf("FromContext without a locale = %q", loc)
+	}
+	if loc := FromContext(WithLocale(context.Background(), "")); loc != Default {
+		t.Errorf("FromContext with an empty locale = %q", loc)
+	}
+	if loc := FromHeader(http.Header{"Content-Language": {"fr"}}); loc != Default {
+		t.Errorf("FromHeader with an unsupported locale = %q", loc)
+	}
+	for _, loc := range Supported {
+		if !IsSupported(loc) {
+			t.Errorf("IsSupported(%q) = false", loc)
+		}
+	}
+	if IsSupported("fr") || IsSupported("") {
+		t.Error("IsSupported accepts a locale without a catalog")
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	tests := []struct {
+		loc, msg, want string
+	}{
+		{Hindi, "quest not found", "क्वेस्ट नहीं मिला"},
+		{Tamil, "must be at most 120 characters", "அதிகபட்சம் 120 எழுத்துகள் இருக்கலாம்"},
+		{Hindi, "must be at most 1.5", "अधिकतम 1.5 हो सकता है"},
+		{Hindi, "Student · Physics", "छात्र · Physics"},
+		{Hindi, "something new", "something new"},
+		{English, "quest not found", "quest not found"},
+		{"fr", "quest not found", "quest not found"},
+	}
+	for _, tt := range tests {
+		if got := Translate(tt.loc, tt.msg); got != tt.want {
+			t.Errorf("Translate(%q, %q) = %q, want %q", tt.loc, tt.msg, got, tt.want)
+		}
+	}
+	if got := T(Tamil, "%d minutes ago", 5); got != "5 நிமிடங்களுக்கு முன்பு" {
+		t.Errorf("T = %q", got)
+	}
+	if got := T("fr", "%d minutes ago", 5); got != "5 minutes ago" {
+		t.Errorf("T without a catalog = %q", got)
+	}
+}
+
+// Every translation must keep the verbs of its key, in order, or Translate and T would
+// format it with the wrong arguments.
+func TestCatalogVerbs(t *testing.T) {
+	for loc, cat := range catalogs {
+		for key, tr := range cat {
+			if !slices.Equal(verb.FindAllString(key, -1), verb.FindAllString(tr, -1)) {
+				t.Errorf("%s: %q translates to %q with different verbs", loc, key, tr)
+			}
+		}
+		if len(monthNames[loc]) != 12 {
+			t.Errorf("%s has %d month names", loc, len(monthNames[loc]))
+		}
+	}
+}
+
+func TestRelativeTime(t *testing.T) {
+	now := time.Now()
+	old := time.Date(2026, time.January, 2, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		at   time.Time
+		loc  string
+		want string
+	}{
+		{time.Time{}, English, "Recently"},
+		{now.Add(-10 * time.Second), English, "Just now"},
+		{now.Add(-90 * time.Second), English, "1 minute ago"},
+		{now.Add(-5 * time.Minute), English, "5 minutes ago"},
+		{now.Add(-3 * time.Hour), English, "3 hours ago"},
+		{now.Add(-25 * time.Hour), English, "1 day ago"},
+		{now.Add(-15 * 24 * time.Hour), English, "2 weeks ago"},
+		{now.Add(-5 * time.Minute), Hindi, "5 मिनट पहले"},
+		{old, English, "Jan 2, 2026"},
+		{old, Hindi, "2 जनवरी 2026"},
+		{old, Tamil, "2 ஜனவரி 2026"},
+	}
+	for _, tt := range tests {
+		if got := RelativeTime(tt.loc, tt.at); got != tt.want {
+			t.Errorf("RelativeTime(%s, %v) = %q, want %q", tt.loc, tt.at, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"backend/handlers/common"
+	"backend/middleware"
+)
+
+func TestIdempotencyStoreIgnoresExpiredKeys(t *testing.T) {
+	newTestServer(t)
+	ctx := context.Background()
+	db := common.Client.Database(fmt.Sprintf("test_idempotency_%d", time.Now().UnixNano()))
+	t.Cleanup(func() { db.Drop(context.Background()) })
+	store := common.MongoIdempotencyStore{Col: db.Collection("idempotency_keys")}
+
+	if err := store.Reserve(ctx, "key", "a", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Complete(ctx, "key", middleware.IdempotencyRecord{Fingerprint: "a", Completed: true, Status: 201}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Reserve(ctx, "key", "a", time.Hour); !errors.Is(err, middleware.ErrIdempotencyKeyExists) {
+		t.Fatalf("reserve a live key: err = %v, want ErrIdempotencyKeyExists", err)
+	}
+
+	// Expire the record without waiting for the TTL monitor.
+	if _, err := store.Col.UpdateOne(ctx, bson.M{"_id": "key"}, bson.M{"$set": bson.M{"expires_at": time.Now().Add(-time.Second)}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Load(ctx, "key"); !errors.Is(err, mongo.ErrNoDocuments) {
+		t.Errorf("load an expired key: err = %v, want ErrNoDocuments", err)
+	}
+	if err := store.Reserve(ctx, "key", "b", time.Hour); err != nil {
+		t.Fatalf("reserve an expired key: %v", err)
+	}
+	rec, err := store.Load(ctx, "key")
+	if err != nil || rec.Fingerprint != "b" || rec.Completed {
+		t.Errorf("record = %+v, %v; want a fresh reservation", rec, err)
+	}
+}
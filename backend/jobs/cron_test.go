@@ -0,0 +1,123 @@
+package jobs
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseField(t *testing.T) {
+	bits := func(vs ...int) uint64 {
+		var b uint64
+		for _, v := range vs {
+			b |= 1 << uint(v)
+		}
+		return b
+	}
+	tests := []struct {
+		field    string
+		min, max int
+		want     uint64
+	}{
+		{"*", 0, 6, bits(0, 1, 2, 3, 4, 5, 6)},
+		{"5", 0, 59, bits(5)},
+		{"1,3-5", 0, 59, bits(1, 3, 4, 5)},
+		{"*/20", 0, 59, bits(0, 20, 40)},
+		{"5/20", 0, 59, bits(5, 25, 45)},
+		{"10-20/5", 0, 59, bits(10, 15, 20)},
+		{"*/5", 1, 12, bits(1, 6, 11)},
+		{"7", 0, 6, bits(0)},
+		{"5-7", 0, 6, bits(0, 5, 6)},
+	}
+	for _, tt := range tests {
+		got, err := parseField(tt.field, tt.min, tt.max)
+		if err != nil || got != tt.want {
+			t.Errorf("parseField(%q, %d, %d) = %b, %v; want %b", tt.field, tt.min, tt.max, got, err, tt.want)
+		}
+	}
+}
+
+func TestParseScheduleRejects(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"*/x * * * *",
+		"a * * * *",
+		"1-x * * * *",
+		"@yearly",
+		"@every soon",
+		"@every 500ms",
+	} {
+		if _, err := ParseSchedule(spec); err == nil {
+			t.Errorf("ParseSchedule(%q) succeeded, want an error", spec)
+		}
+	}
+}
+
+func TestScheduleNext(t *testing.T) {
+	// Sunday 18 October 2026, 10:30 UTC.
+	after := time.Date(2026, time.October, 18, 10, 30, 0, 0, time.UTC)
+	at := func(month time.Month, day, hour, min int) time.Time {
+		return time.Date(2026, month, day, hour, min, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		name string
+		spec string
+		want time.Time
+	}{
+		{"every minute", "* * * * *", at(time.October, 18, 10, 31)},
+		{"strictly after", "30 10 * * *", at(time.October, 19, 10, 30)},
+		{"minute step", "*/15 * * * *", at(time.October, 18, 10, 45)},
+		{"minute list", "5,40 * * * *", at(time.October, 18, 10, 40)},
+		{"hour range with step", "0 9-17/4 * * *", at(time.October, 18, 13, 0)},
+		{"hour range wraps to next day", "0 6-9 * * *", at(time.October, 19, 6, 0)},
+		{"day of month", "0 0 13 * *", at(time.November, 13, 0, 0)},
+		{"day of week", "0 0 * * 5", at(time.October, 23, 0, 0)},
+		{"sunday as 7", "0 0 * * 7", at(time.October, 25, 0, 0)},
+		{"day of month or day of week, weekday first", "0 0 13 * 5", at(time.October, 23, 0, 0)},
+		{"day of month or day of week, date first", "0 0 20 * 5", at(time.October, 20, 0, 0)},
+		{"day of month and starred day of week", "0 0 20 * *", at(time.October, 20, 0, 0)},
+		{"restricted months", "0 0 1 1,7 *", time.Date(2027, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		{"leap day", "0 0 29 2 *", time.Date(2028, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{"hourly", "@hourly", at(time.October, 18, 11, 0)},
+		{"daily", "@daily", at(time.October, 19, 0, 0)},
+		{"weekly", "@weekly", at(time.October, 25, 0, 0)},
+		{"monthly", "@monthly", at(time.November, 1, 0, 0)},
+		{"every", "@every 1h", at(time.October, 18, 11, 0)},
+		{"every with seconds", "@every 45s", time.Date(2026, time.October, 18, 10, 30, 45, 0, time.UTC)},
+		{"impossible date stops at the five-year bound", "0 0 30 2 *", time.Time{}},
+		{"impossible date in a 30-day month", "0 0 31 4 *", time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := ParseSchedule(tt.spec)
+			if err != nil {
+				t.Fatalf("ParseSchedule(%q): %v", tt.spec, err)
+			}
+			if got := s.Next(after); !got.Equal(tt.want) {
+				t.Errorf("Next(%q) = %v, want %v", tt.spec, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScheduleNextUsesUTC(t *testing.T) {
+	s, err := ParseSchedule("0 9 * * *")
+	if err != nil {
+		t.Fatal(err)
+	}
+	kolkata := time.FixedZone("IST", 5*3600+1800)
+	// 14:00 in Kolkata is 08:30 UTC, so 09:00 UTC is still ahead today.
+	after := time.Date(2026, time.October, 18, 14, 0, 0, 0, kolkata)
+	want := time.Date(2026, time.October, 18, 9, 0, 0, 0, time.UTC)
+	if got := s.Next(after); !got.Equal(want) || got.Location() != time.UTC {
+		t.Errorf("Next = %v, want %v", got, want)
+	}
+}
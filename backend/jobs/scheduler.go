@@ -0,0 +1,330 @@
+// Package jobs runs recurring platform work on cron-style schedules. Every instance runs
+// a Scheduler, but a lease in the jobs collection ensures each run happens on exactly one
+// of them. Each run is recorded in job_runs with its duration and error.
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const (
+	DefaultTimeout   = 10 * time.Minute
+	tickInterval     = 30 * time.Second
+	runRetention     = 30 * 24 * time.Hour
+	TriggerSchedule  = "schedule"
+	TriggerManual    = "manual"
+	RunStatusRunning = "running"
+	RunStatusOK      = "ok"
+	RunStatusFailed  = "failed"
+)
+
+var (
+	ErrUnknownJob = errors.New("unknown job")
+	ErrJobRunning = errors.New("job is already running")
+)
+
+// Job is one unit of recurring work.
+type Job struct {
+	Name        string
+	Description string
+	Schedule    string
+	Timeout     time.Duration
+	Run         func(ctx context.Context) error
+
+	schedule Schedule
+}
+
+// State is a job's shared scheduling state, stored in the jobs collection.
+type State struct {
+	Name        string     `bson:"_id" json:"name"`
+	Description string     `bson:"description" json:"description"`
+	Schedule    string     `bson:"schedule" json:"schedule"`
+	Paused      bool       `bson:"paused" json:"paused"`
+	NextRunAt   time.Time  `bson:"next_run_at" json:"nextRunAt"`
+	LockedBy    string     `bson:"locked_by,omitempty" json:"lockedBy,omitempty"`
+	LockedUntil *time.Time `bson:"locked_until,omitempty" json:"-"`
+	LastRun     *Run       `bson:"last_run,omitempty" json:"lastRun,omitempty"`
+	Running     bool       `bson:"-" json:"running"`
+}
+
+// Run is one execution of a job.
+type Run struct {
+	ID         primitive.ObjectID `bson:"_id" json:"id"`
+	Job        string             `bson:"job" json:"job"`
+	Trigger    string             `bson:"trigger" json:"trigger"`
+	Instance   string             `bson:"instance" json:"instance"`
+	Status     string             `bson:"status" json:"status"`
+	Error      string             `bson:"error,omitempty" json:"error,omitempty"`
+	StartedAt  time.Time          `bson:"started_at" json:"startedAt"`
+	FinishedAt *time.Time         `bson:"finished_at,omitempty" json:"finishedAt,omitempty"`
+	DurationMS int64              `bson:"duration_ms" json:"durationMs"`
+}
+
+// JobList is the admin list response.
+type JobList struct {
+	Items []State `json:"items"`
+}
+
+// RunList is a page of run history.
+type RunList struct {
+	Items []Run  `json:"items"`
+	Next  string `json:"next,omitempty"`
+	Prev  string `json:"prev,omitempty"`
+}
+
+// Scheduler owns the registered jobs for this instance.
+type Scheduler struct {
+	Jobs     *mongo.Collection
+	Runs     *mongo.Collection
+	Instance string
+
+	mu   sync.RWMutex
+	jobs map[string]*Job
+	wg   sync.WaitGroup
+}
+
+// NewScheduler returns a scheduler storing state in jobsCol and history in runsCol.
+func NewScheduler(jobsCol, runsCol *mongo.Collection) *Scheduler {
+	host, _ := os.Hostname()
+	return &Scheduler{Jobs: jobsCol, Runs: runsCol, Instance: fmt.Sprintf("%s-%d", host, os.Getpid()), jobs: map[string]*Job{}}
+}
+
+// Register adds a job; it panics on a bad schedule or duplicate name since both are
+// programming errors caught at startup.
+func (s *Scheduler) Register(job Job) {
+	sched, err := ParseSchedule(job.Schedule)
+	if err != nil {
+		panic(fmt.Sprintf("jobs: %s: %v", job.Name, err))
+	}
+	if sched.Next(time.Now()).IsZero() {
+		panic(fmt.Sprintf("jobs: %s: schedule %q never fires", job.Name, job.Schedule))
+	}
+	job.schedule = sched
+	if job.Timeout <= 0 {
+		job.Timeout = DefaultTimeout
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, dup := s.jobs[job.Name]; dup {
+		panic("jobs: duplicate job " + job.Name)
+	}
+	s.jobs[job.Name] = &job
+}
+
+func (s *Scheduler) job(name string) (*Job, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	j, ok := s.jobs[name]
+	return j, ok
+}
+
+// Setup creates indexes and upserts a state document per job, keeping an existing
+// next_run_at and paused flag. A changed schedule takes effect after the next run.
+func (s *Scheduler) Setup(ctx context.Context) error {
+	if _, err := s.Runs.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "job", Value: 1}, {Key: "started_at", Value: -1}}},
+		{Keys: bson.M{"started_at": 1}, Options: options.Index().SetExpireAfterSeconds(int32(runRetention / time.Second))},
+	}); err != nil {
+		return err
+	}
+	now := time.Now().UTC()
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, j := range s.jobs {
+		_, err := s.Jobs.UpdateOne(ctx, bson.M{"_id": j.Name}, bson.M{
+			"$set":         bson.M{"description": j.Description, "schedule": j.Schedule},
+			"$setOnInsert": bson.M{"paused": false, "next_run_at": j.schedule.Next(now)},
+		}, options.Update().SetUpsert(true))
+		if err != nil {
+			return fmt.Errorf("register job %s: %w", j.Name, err)
+		}
+	}
+	return nil
+}
+
+// Start runs due jobs until ctx is cancelled, then waits for in-flight runs to finish.
+func (s *Scheduler) Start(ctx context.Context) {
+	ticker := time.NewTicker(tickInterval)
+	defer ticker.Stop()
+	for {
+		s.runDue(ctx)
+		select {
+		case <-ctx.Done():
+			s.wg.Wait()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scheduler) runDue(ctx context.Context) {
+	s.mu.RLock()
+	names := make([]string, 0, len(s.jobs))
+	for name := range s.jobs {
+		names = append(names, name)
+	}
+	s.mu.RUnlock()
+	for _, name := range names {
+		j, _ := s.job(name)
+		now := time.Now().UTC()
+		filter := bson.M{"_id": name, "paused": false, "next_run_at": bson.M{"$lte": now}}
+		run, err := s.acquire(ctx, j, filter, TriggerSchedule, j.schedule.Next(now))
+		if err != nil {
+			if !errors.Is(err, mongo.ErrNoDocuments) {
+				log.Printf("jobs: %s: %v", name, err)
+			}
+			continue
+		}
+		s.wg.Add(1)
+		go s.execute(ctx, j, run)
+	}
+}
+
+// Trigger starts name now, outside its schedule. Paused jobs can still be triggered.
+func (s *Scheduler) Trigger(ctx context.Context, name string) (Run, error) {
+	j, ok := s.job(name)
+	if !ok {
+		return Run{}, ErrUnknownJob
+	}
+	run, err := s.acquire(ctx, j, bson.M{"_id": name}, TriggerManual, time.Time{})
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return Run{}, ErrJobRunning
+	}
+	if err != nil {
+		return Run{}, err
+	}
+	s.wg.Add(1)
+	// Manual runs must outlive the admin request that started them.
+	go s.execute(context.Background(), j, run)
+	return run, nil
+}
+
+// acquire takes the job lease if no other instance holds it and records a running Run.
+// A zero next leaves next_run_at unchanged.
+func (s *Scheduler) acquire(ctx context.Context, j *Job, filter bson.M, trigger string, next time.Time) (Run, error) {
+	now := time.Now().UTC()
+	filter["$or"] = bson.A{bson.M{"locked_until": bson.M{"$exists": false}}, bson.M{"locked_until": nil}, bson.M{"locked_until": bson.M{"$lte": now}}}
+	set := bson.M{"locked_by": s.Instance, "locked_until": now.Add(j.Timeout)}
+	if !next.IsZero() {
+		set["next_run_at"] = next
+	}
+	if err := s.Jobs.FindOneAndUpdate(ctx, filter, bson.M{"$set": set}).Err(); err != nil {
+		return Run{}, err
+	}
+	run := Run{ID: primitive.NewObjectID(), Job: j.Name, Trigger: trigger, Instance: s.Instance, Status: RunStatusRunning, StartedAt: now}
+	if _, err := s.Runs.InsertOne(ctx, run); err != nil {
+		s.release(ctx, j.Name, nil)
+		return Run{}, err
+	}
+	return run, nil
+}
+
+func (s *Scheduler) execute(ctx context.Context, j *Job, run Run) {
+	defer s.wg.Done()
+	runCtx, cancel := context.WithTimeout(ctx, j.Timeout)
+	err := safeRun(runCtx, j.Run)
+	cancel()
+	finished := time.Now().UTC()
+	run.FinishedAt = &finished
+	run.DurationMS = finished.Sub(run.StartedAt).Milliseconds()
+	run.Status = RunStatusOK
+	if err != nil {
+		run.Status = RunStatusFailed
+		run.Error = err.Error()
+		log.Printf("jobs: %s failed after %dms: %v", j.Name, run.DurationMS, err)
+	}
+	// Record the outcome even if the scheduler is shutting down.
+	storeCtx, cancelStore := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancelStore()
+	if _, err := s.Runs.ReplaceOne(storeCtx, bson.M{"_id": run.ID}, run); err != nil {
+		log.Printf("jobs: %s: failed to record run: %v", j.Name, err)
+	}
+	s.release(storeCtx, j.Name, &run)
+}
+
+func (s *Scheduler) release(ctx context.Context, name string, last *Run) {
+	update := bson.M{"$unset": bson.M{"locked_by": "", "locked_until": ""}}
+	if last != nil {
+		update["$set"] = bson.M{"last_run": last}
+	}
+	if _, err := s.Jobs.UpdateOne(ctx, bson.M{"_id": name, "locked_by": s.Instance}, update); err != nil {
+		log.Printf("jobs: %s: failed to release lock: %v", name, err)
+	}
+}
+
+func safeRun(ctx context.Context, fn func(context.Context) error) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+	return fn(ctx)
+}
+
+// SetPaused pauses or resumes scheduled runs of name and returns its state.
+func (s *Scheduler) SetPaused(ctx context.Context, name string, paused bool) (State, error) {
+	j, ok := s.job(name)
+	if !ok {
+		return State{}, ErrUnknownJob
+	}
+	set := bson.M{"paused": paused}
+	if !paused {
+		// Resuming does not replay the runs missed while paused.
+		set["next_run_at"] = j.schedule.Next(time.Now().UTC())
+	}
+	var st State
+	err := s.Jobs.FindOneAndUpdate(ctx, bson.M{"_id": name}, bson.M{"$set": set}, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&st)
+	st.Running = isLocked(st)
+	return st, err
+}
+
+// List returns the state of every registered job, sorted by name.
+func (s *Scheduler) List(ctx context.Context) ([]State, error) {
+	cursor, err := s.Jobs.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, err
+	}
+	var stored []State
+	if err := cursor.All(ctx, &stored); err != nil {
+		return nil, err
+	}
+	byName := map[string]State{}
+	for _, st := range stored {
+		byName[st.Name] = st
+	}
+	s.mu.RLock()
+	states := make([]State, 0, len(s.jobs))
+	for name, j := range s.jobs {
+		st, ok := byName[name]
+		if !ok {
+			st = State{Name: name, Description: j.Description, Schedule: j.Schedule}
+		}
+		st.Running = isLocked(st)
+		states = append(states, st)
+	}
+	s.mu.RUnlock()
+	sort.Slice(states, func(i, k int) bool { return states[i].Name < states[k].Name })
+	return states, nil
+}
+
+// Has reports whether name is a registered job.
+func (s *Scheduler) Has(name string) bool {
+	_, ok := s.job(name)
+	return ok
+}
+
+func isLocked(st State) bool {
+	return st.LockedUntil != nil && st.LockedUntil.After(time.Now())
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"backend/handlers/common"
+	"backend/models"
+	"backend/tenant"
+)
+
+// seedLeaderboard stores students with tied coin totals, a faculty member with the most
+// coins, and a student of another campus, plus quest completions and ledger entries.
+func seedLeaderboard(t *testing.T) (ctx context.Context, week *models.LeaderboardPeriod) {
+	t.Helper()
+	newTestServer(t)
+	ctx = tenant.WithID(context.Background(), testTenantA)
+	other := tenant.WithID(context.Background(), testTenantB)
+	week, _ = common.CurrentPeriod(common.WindowWeek, time.Now(), tenant.Gamification{})
+	inWeek, beforeWeek := week.Start.Add(time.Hour), week.Start.Add(-time.Hour)
+	users := []models.User{
+		{UserID: 1, Name: "Ada", Role: common.RoleStudent, Coins: 50, Streak: 3},
+		{UserID: 2, Name: "Grace", Role: common.RoleStudent, Coins: 80},
+		{UserID: 3, Name: "Alan", Role: common.RoleStudent, Coins: 50},
+		{UserID: 4, Name: "Edsger", Role: common.RoleStudent, Coins: 30},
+		{UserID: 5, Name: "Barbara", Role: common.RoleStudent},
+		{UserID: 6, Name: "Prof", Role: common.RoleFaculty, Coins: 100},
+	}
+	for _, u := range users {
+		if _, err := common.UsersCol.InsertOne(ctx, u); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := common.UsersCol.InsertOne(other, models.User{UserID: 7, Name: "Elsewhere", Role: common.RoleStudent, Coins: 90}); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		ctx   context.Context
+		user  int
+		quest int
+		done  bool
+	}{{ctx, 1, 1, true}, {ctx, 1, 2, true}, {ctx, 1, 3, false}, {other, 1, 1, true}} {
+		if _, err := common.UserQuestsCol.InsertOne(c.ctx, bson.M{"user_id": c.user, "quest_id": c.quest, "completed": c.done}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, e := range []struct {
+		ctx    context.Context
+		user   int
+		coins  int
+		reason string
+		at     time.Time
+	}{
+		{ctx, 4, 20, common.LedgerQuest, inWeek},
+		{ctx, 4, 15, "level", inWeek},
+		{ctx, 1, 10, common.LedgerQuest, inWeek},
+		{ctx, 3, 40, common.LedgerQuest, beforeWeek},
+		{ctx, 6, 50, common.LedgerQuest, inWeek},
+		{other, 7, 60, common.LedgerQuest, inWeek},
+	} {
+		if err := common.RecordCoins(e.ctx, e.user, e.coins, e.reason, 1, e.at); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return ctx, week
+}
+
+func TestLeaderboardDenseRank(t *testing.T) {
+	ctx, week := seedLeaderboard(t)
+
+	got, err := common.Board{Filter: common.StudentsOnly()}.Top(ctx, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []models.LeaderboardEntry{
+		{Rank: 1, UserID: 2, Name: "Grace", Coins: 80},
+		{Rank: 2, UserID: 1, Name: "Ada", Coins: 50, Streak: 3, CompletedQuests: 2},
+		{Rank: 2, UserID: 3, Name: "Alan", Coins: 50},
+		{Rank: 3, UserID: 4, Name: "Edsger", Coins: 30},
+		{Rank: 4, UserID: 5, Name: "Barbara"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("lifetime board = %+v, want %+v", got, want)
+	}
+	if top, _ := (common.Board{Filter: common.StudentsOnly()}).Top(ctx, 2); len(top) != 2 || top[1].UserID != 1 {
+		t.Errorf("top 2 = %+v", top)
+	}
+
+	got, err = common.Board{Filter: common.StudentsOnly(), Period: week}.Top(ctx, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = []models.LeaderboardEntry{
+		{Rank: 1, UserID: 4, Name: "Edsger", Coins: 35, CompletedQuests: 1},
+		{Rank: 2, UserID: 1, Name: "Ada", Coins: 10, Streak: 3, CompletedQuests: 1},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("weekly board = %+v, want %+v", got, want)
+	}
+}
+
+func TestLeaderboardAround(t *testing.T) {
+	ctx, week := seedLeaderboard(t)
+	board := common.Board{Filter: common.StudentsOnly()}
+	ids := func(entries []models.LeaderboardEntry) []int {
+		out := []int{}
+		for _, e := range entries {
+			out = append(out, e.UserID)
+		}
+		return out
+	}
+	tests := []struct {
+		name   string
+		board  common.Board
+		userID int
+		around []int
+	}{
+		{"between ties", board, 1, []int{2, 1, 3}},
+		{"top of the board", board, 2, []int{2, 1}},
+		{"bottom of the board", board, 5, []int{4, 5}},
+		{"filtered out", board, 6, nil},
+		{"other campus", board, 7, nil},
+		{"windowed", common.Board{Filter: common.StudentsOnly(), Period: week}, 1, []int{4, 1}},
+		{"no coins in the window", common.Board{Filter: common.StudentsOnly(), Period: week}, 3, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			me, around, err := tt.board.Around(ctx, tt.userID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.around == nil {
+				if me != nil || around != nil {
+					t.Errorf("Around = %+v, %+v; want the user off the board", me, around)
+				}
+				return
+			}
+			if me == nil || me.UserID != tt.userID || !reflect.DeepEqual(ids(around), tt.around) {
+				t.Errorf("Around = %+v, %v; want %v", me, ids(around), tt.around)
+			}
+		})
+	}
+	me, _, _ := board.Around(ctx, 1)
+	if me.Rank != 2 || me.CompletedQuests != 2 || me.Streak != 3 {
+		t.Errorf("entry = %+v, want rank 2 with 2 completed quests", me)
+	}
+}
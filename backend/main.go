@@ -7,6 +7,8 @@ import (
 	"os"
 	"strings"
 	"time"
+	// Timezones are embedded so quest days follow campus and student zones on hosts without a zoneinfo database.
+	_ "time/tzdata"
 
 	gorillahandlers "github.com/gorilla/handlers"
 	"github.com/gorilla/mux"
@@ -16,12 +18,14 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 
-	adminHandlers "backend/handlers/admin"
+	"backend/cache"
 	"backend/handlers/common"
-	facultyHandlers "backend/handlers/faculty"
-	researchHandlers "backend/handlers/research"
-	studentHandlers "backend/handlers/student"
+	"backend/mailer"
 	"backend/middleware"
+	"backend/realtime"
+	"backend/routes"
+	"backend/tenant"
+	"backend/webhooks"
 )
 
 var (
@@ -34,6 +38,25 @@ var (
 	userQuestsCol        *mongo.Collection
 	researchPostsCol     *mongo.Collection
 	facultyDashboardsCol *mongo.Collection
+	idempotencyKeysCol   *mongo.Collection
+	webhookSubsCol       *mongo.Collection
+	webhookDeliveriesCol *mongo.Collection
+	eventOutboxCol       *mongo.Collection
+	liveEventsCol        *mongo.Collection
+	jobsCol              *mongo.Collection
+	jobRunsCol           *mongo.Collection
+	tenantsCol           *mongo.Collection
+	countersCol          *mongo.Collection
+	invitationsCol       *mongo.Collection
+	auditLogCol          *mongo.Collection
+	flagsCol             *mongo.Collection
+	cacheVersionsCol     *mongo.Collection
+	cacheEntriesCol      *mongo.Collection
+	coinLedgerCol        *mongo.Collection
+	snapshotsCol         *mongo.Collection
+	questAttemptsCol     *mongo.Collection
+	questAssignmentsCol  *mongo.Collection
+	xpLedgerCol          *mongo.Collection
 	geminiAPIKey         string
 	geminiModel          string
 	jwtSecret            string
@@ -91,8 +114,52 @@ func main() {
 	userQuestsCol = database.Collection("user_quests")
 	researchPostsCol = database.Collection("research_posts")
 	facultyDashboardsCol = database.Collection("faculty_dashboards")
+	idempotencyKeysCol = database.Collection("idempotency_keys")
+	webhookSubsCol = database.Collection("webhook_subscriptions")
+	webhookDeliveriesCol = database.Collection("webhook_deliveries")
+	eventOutboxCol = database.Collection("event_outbox")
+	liveEventsCol = database.Collection("live_events")
+	jobsCol = database.Collection("jobs")
+	jobRunsCol = database.Collection("job_runs")
+	tenantsCol = database.Collection("tenants")
+	countersCol = database.Collection("counters")
+	invitationsCol = database.Collection("invitations")
+	auditLogCol = database.Collection("audit_log")
+	flagsCol = database.Collection("feature_flags")
+	cacheVersionsCol = database.Collection("cache_versions")
+	cacheEntriesCol = database.Collection("cache_entries")
+	coinLedgerCol = database.Collection("coin_ledger")
+	snapshotsCol = database.Collection("leaderboard_snapshots")
+	questAttemptsCol = database.Collection("quest_attempts")
+	questAssignmentsCol = database.Collection("quest_assignments")
+	xpLedgerCol = database.Collection("xp_ledger")
+
+	defaultTenant := strings.TrimSpace(os.Getenv("DEFAULT_TENANT"))
+	if defaultTenant == "" {
+		defaultTenant = "srm"
+	}
+
+	inviteURL := strings.TrimSpace(os.Getenv("INVITE_URL"))
+	if inviteURL == "" {
+		inviteURL = "http://localhost:5173/accept-invite"
+	}
+
+	idempotencyTTL, _ := time.ParseDuration(strings.TrimSpace(os.Getenv("IDEMPOTENCY_TTL")))
+	deletionGrace, _ := time.ParseDuration(strings.TrimSpace(os.Getenv("ACCOUNT_DELETION_GRACE")))
+	cacheTTL, _ := time.ParseDuration(strings.TrimSpace(os.Getenv("CACHE_TTL")))
+
+	// Aggregate cache: per instance by default, or shared through Mongo with CACHE_STORE=mongo
+	var cacheStore cache.Store = cache.NewMemory(0)
+	if strings.EqualFold(strings.TrimSpace(os.Getenv("CACHE_STORE")), "mongo") {
+		shared := cache.Mongo{Col: cacheEntriesCol}
+		if err := shared.EnsureIndexes(ctx); err != nil {
+			log.Printf("failed to ensure cache entry indexes: %v", err)
+		}
+		cacheStore = shared
+	}
 
     common.Configure(common.Dependencies{
+        Client:               client,
         UsersCol:             usersCol,
         QuestsCol:            questsCol,
         PollsCol:             pollsCol,
@@ -100,52 +167,107 @@ func main() {
         UserQuestsCol:        userQuestsCol,
         ResearchPostsCol:     researchPostsCol,
         FacultyDashboardsCol: facultyDashboardsCol,
+        IdempotencyKeysCol:   idempotencyKeysCol,
+        WebhookSubsCol:       webhookSubsCol,
+        WebhookDeliveriesCol: webhookDeliveriesCol,
+        EventOutboxCol:       eventOutboxCol,
+        LiveEventsCol:        liveEventsCol,
+        JobsCol:              jobsCol,
+        JobRunsCol:           jobRunsCol,
+        TenantsCol:           tenantsCol,
+        CountersCol:          countersCol,
+        InvitationsCol:       invitationsCol,
+        AuditLogCol:          auditLogCol,
+        FlagsCol:             flagsCol,
+        CacheVersionsCol:     cacheVersionsCol,
+        CoinLedgerCol:        coinLedgerCol,
+        SnapshotsCol:         snapshotsCol,
+        QuestAttemptsCol:     questAttemptsCol,
+        QuestAssignmentsCol:  questAssignmentsCol,
+        XPLedgerCol:          xpLedgerCol,
+        CacheStore:           cacheStore,
+        CacheTTL:             cacheTTL,
+        DefaultTenant:        defaultTenant,
+        InviteURL:            inviteURL,
         GeminiAPIKey:         geminiAPIKey,
         GeminiModel:          geminiModel,
         JWTSecret:            jwtSecret,
+        IdempotencyTTL:       idempotencyTTL,
+        DeletionGrace:        deletionGrace,
+        Mailer:               mailer.FromEnv(),
     })
 
+	// Tenants: make sure the default campus exists and owns any pre-tenancy data
+	if err := common.Tenants.Setup(ctx); err != nil {
+		log.Fatalf("failed to set up default tenant: %v", err)
+	}
+	if err := common.Tenants.Backfill(ctx, usersCol, questsCol, pollsCol, votesCol, userQuestsCol, researchPostsCol, facultyDashboardsCol, webhookSubsCol, webhookDeliveriesCol, eventOutboxCol); err != nil {
+		log.Printf("failed to backfill tenant ids: %v", err)
+	}
+
+	if err := (common.MongoIdempotencyStore{Col: idempotencyKeysCol}).EnsureIndexes(ctx); err != nil {
+		log.Printf("failed to ensure idempotency key indexes: %v", err)
+	}
+	if err := common.Webhooks.EnsureIndexes(ctx); err != nil {
+		log.Printf("failed to ensure webhook delivery indexes: %v", err)
+	}
+
+	if err := common.Outbox.EnsureIndexes(ctx); err != nil {
+		log.Printf("failed to ensure event outbox indexes: %v", err)
+	}
+	if err := common.Live.EnsureIndexes(ctx); err != nil {
+		log.Printf("failed to ensure live event indexes: %v", err)
+	}
+	if err := common.Invitations.EnsureIndexes(ctx); err != nil {
+		log.Printf("failed to ensure invitation indexes: %v", err)
+	}
+	if err := common.Audit.EnsureIndexes(ctx); err != nil {
+		log.Printf("failed to ensure audit log indexes: %v", err)
+	}
+	if err := common.Versions.EnsureIndexes(ctx); err != nil {
+		log.Printf("failed to ensure cache version indexes: %v", err)
+	}
+	if err := common.EnsureQuestIndexes(ctx); err != nil {
+		log.Printf("failed to ensure quest completion indexes: %v", err)
+	}
+	if err := common.EnsureVoteIndexes(ctx); err != nil {
+		log.Printf("failed to ensure vote indexes: %v", err)
+	}
+	if err := common.EnsureLeaderboardIndexes(ctx); err != nil {
+		log.Printf("failed to ensure leaderboard indexes: %v", err)
+	}
+	if err := common.EnsureXPIndexes(ctx); err != nil {
+		log.Printf("failed to ensure xp ledger indexes: %v", err)
+	}
+
+	// Domain event subscribers, then the outbox dispatcher and webhook delivery worker
+	webhooks.Subscribe(common.Events, common.Webhooks)
+	realtime.Subscribe(common.Events, common.Live)
+	go common.Events.Run(context.Background())
+	go common.Live.Run(context.Background())
+
+	// Recurring jobs
+	registerJobs(common.Jobs)
+	if err := common.Jobs.Setup(ctx); err != nil {
+		log.Printf("failed to set up job scheduler: %v", err)
+	}
+	go common.Jobs.Start(context.Background())
+	go (&webhooks.Worker{Store: common.Webhooks}).Run(context.Background())
+
 	// Insert sample data
 	go insertSampleData()
 
 	// Setup routes
 	r := mux.NewRouter()
-	api := r.PathPrefix("/api").Subrouter()
-
-	// Public routes
-	api.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		w.Write([]byte(`{"status":"ok"}`))
-	}).Methods("GET")
-	api.HandleFunc("/auth/login", common.LoginHandler).Methods("POST")
-	// Protected routes
-	protected := api.PathPrefix("").Subrouter()
-	protected.Use(middleware.NewAuthMiddleware(jwtSecret))
-	protected.HandleFunc("/me", common.GetMeHandler).Methods("GET")
-	protected.HandleFunc("/user/{id}", studentHandlers.GetUser).Methods("GET")
-	protected.HandleFunc("/quests", studentHandlers.GetQuests).Methods("GET")
-	protected.HandleFunc("/quests/{id}/complete", studentHandlers.CompleteQuest).Methods("POST")
-	protected.HandleFunc("/leaderboard", studentHandlers.GetLeaderboard).Methods("GET")
-	protected.HandleFunc("/polls", studentHandlers.GetPolls).Methods("GET")
-	protected.HandleFunc("/polls/{id}/vote", studentHandlers.VoteOnPoll).Methods("POST")
-	// Research & AI endpoints (AI not yet reimplemented after refactor; research restored)
-	protected.HandleFunc("/research/posts", researchHandlers.GetPosts).Methods("GET")
-	protected.HandleFunc("/research/posts", researchHandlers.CreatePost).Methods("POST")
-	protected.HandleFunc("/student/dashboard", common.WithRoles(studentHandlers.GetStudentDashboard, common.RoleStudent, common.RoleAdmin)).Methods("GET")
-	protected.HandleFunc("/admin/overview", common.WithRoles(adminHandlers.GetOverview, common.RoleAdmin)).Methods("GET")
-	protected.HandleFunc("/faculty/overview", common.WithRoles(facultyHandlers.GetOverview, common.RoleFaculty, common.RoleAdmin)).Methods("GET")
-	protected.HandleFunc("/faculty/dashboard", common.WithRoles(facultyHandlers.GetOverview, common.RoleFaculty, common.RoleAdmin)).Methods("GET")
-	protected.HandleFunc("/faculty/dashboard/ai/{id}/review", common.WithRoles(facultyHandlers.ReviewAISuggestion, common.RoleFaculty, common.RoleAdmin)).Methods("POST")
-	protected.HandleFunc("/faculty/dashboard/mentorship", common.WithRoles(facultyHandlers.AddMentee, common.RoleFaculty, common.RoleAdmin)).Methods("POST")
-	protected.HandleFunc("/faculty/dashboard/mentorship/{id}/status", common.WithRoles(facultyHandlers.UpdateMenteeStatus, common.RoleFaculty, common.RoleAdmin)).Methods("POST")
-	protected.HandleFunc("/faculty/dashboard/courses", common.WithRoles(facultyHandlers.AddCourse, common.RoleFaculty, common.RoleAdmin)).Methods("POST")
-	protected.HandleFunc("/faculty/dashboard/courses/{id}/status", common.WithRoles(facultyHandlers.UpdateCourseStatus, common.RoleFaculty, common.RoleAdmin)).Methods("POST")
+	r.Use(common.Tenants.Middleware)
+	routes.Register(r, jwtSecret)
+
 	// CORS
 	corsHandler := gorillahandlers.CORS(
 		gorillahandlers.AllowedOrigins([]string{"*"}),
 		gorillahandlers.AllowedMethods([]string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}),
-		gorillahandlers.AllowedHeaders([]string{"Content-Type", "Authorization"}),
+		gorillahandlers.AllowedHeaders([]string{"Content-Type", "Authorization", "Idempotency-Key", "Last-Event-ID"}),
+		gorillahandlers.ExposedHeaders([]string{"API-Version", "Deprecation", "Sunset", "Link", "X-Next-Cursor", "X-Prev-Cursor", "Idempotent-Replayed"}),
 	)
 
 	handler := corsHandler(r)
@@ -163,32 +285,29 @@ func main() {
 	}
 }
 
+// insertSampleData seeds the default tenant through the scoped collections.
 func insertSampleData() {
-	ctx := context.Background()
+	ctx := tenant.WithID(context.Background(), common.Tenants.Default)
 
 	seedUsers := []struct {
-		userID            int
-		name              string
-		email             string
-		password          string
-		role              string
-		coins             int
-		streak            int
-		academicStanding  int
-		gamificationLevel int
-		courseProgress    int
-		courses           []bson.M
+		userID           int
+		name             string
+		email            string
+		password         string
+		role             string
+		coins            int
+		academicStanding int
+		courseProgress   int
+		courses          []bson.M
 	}{
 		{
-			userID:            1,
-			name:              "Alex Sharma",
-			email:             "alex@learnonline.edu",
-			password:          "student123",
-			role:              common.RoleStudent,
-			streak:            14,
-			academicStanding:  90,
-			gamificationLevel: 60,
-			courseProgress:    75,
+			userID:           1,
+			name:             "Alex Sharma",
+			email:            "alex@learnonline.edu",
+			password:         "student123",
+			role:             common.RoleStudent,
+			academicStanding: 90,
+			courseProgress:   75,
 			courses: []bson.M{
 				{"course_id": 101, "title": "Introduction to AI", "progress": 75, "instructor": "Dr. Sen", "due_next": "Module 3 Quiz"},
 				{"course_id": 102, "title": "Machine Learning Basics", "progress": 40, "instructor": "Prof. Singh", "due_next": "Peer Review"},
@@ -196,85 +315,73 @@ func insertSampleData() {
 			},
 		},
 		{
-			userID:            2,
-			name:              "Jordan Lee",
-			email:             "jordan@learnonline.edu",
-			password:          "student123",
-			role:              common.RoleStudent,
-			coins:             12500,
-			streak:            18,
-			academicStanding:  92,
-			gamificationLevel: 72,
-			courseProgress:    82,
+			userID:           2,
+			name:             "Jordan Lee",
+			email:            "jordan@learnonline.edu",
+			password:         "student123",
+			role:             common.RoleStudent,
+			coins:            12500,
+			academicStanding: 92,
+			courseProgress:   82,
 			courses: []bson.M{
 				{"course_id": 104, "title": "Advanced Robotics", "progress": 68, "instructor": "Dr. Tan", "due_next": "Lab Report"},
 			},
 		},
 		{
-			userID:            3,
-			name:              "Casey Wong",
-			email:             "casey@learnonline.edu",
-			password:          "student123",
-			role:              common.RoleStudent,
-			coins:             11800,
-			streak:            12,
-			academicStanding:  88,
-			gamificationLevel: 65,
-			courseProgress:    70,
-			courses:           []bson.M{},
+			userID:           3,
+			name:             "Casey Wong",
+			email:            "casey@learnonline.edu",
+			password:         "student123",
+			role:             common.RoleStudent,
+			coins:            11800,
+			academicStanding: 88,
+			courseProgress:   70,
+			courses:          []bson.M{},
 		},
 		{
-			userID:            4,
-			name:              "Taylor Green",
-			email:             "taylor@learnonline.edu",
-			password:          "student123",
-			role:              common.RoleStudent,
-			coins:             10900,
-			streak:            10,
-			academicStanding:  86,
-			gamificationLevel: 59,
-			courseProgress:    66,
-			courses:           []bson.M{},
+			userID:           4,
+			name:             "Taylor Green",
+			email:            "taylor@learnonline.edu",
+			password:         "student123",
+			role:             common.RoleStudent,
+			coins:            10900,
+			academicStanding: 86,
+			courseProgress:   66,
+			courses:          []bson.M{},
 		},
 		{
-			userID:            5,
-			name:              "Samira Khan",
-			email:             "samira@learnonline.edu",
-			password:          "student123",
-			role:              common.RoleStudent,
-			coins:             10100,
-			streak:            8,
-			academicStanding:  84,
-			gamificationLevel: 55,
-			courseProgress:    60,
-			courses:           []bson.M{},
+			userID:           5,
+			name:             "Samira Khan",
+			email:            "samira@learnonline.edu",
+			password:         "student123",
+			role:             common.RoleStudent,
+			coins:            10100,
+			academicStanding: 84,
+			courseProgress:   60,
+			courses:          []bson.M{},
 		},
 		{
-			userID:            6,
-			name:              "Dr. Meera Iyer",
-			email:             "meera@learnonline.edu",
-			password:          "faculty123",
-			role:              common.RoleFaculty,
-			streak:            6,
-			academicStanding:  0,
-			gamificationLevel: 0,
-			courseProgress:    0,
+			userID:           6,
+			name:             "Dr. Meera Iyer",
+			email:            "meera@learnonline.edu",
+			password:         "faculty123",
+			role:             common.RoleFaculty,
+			academicStanding: 0,
+			courseProgress:   0,
 			courses: []bson.M{
 				{"course_id": 101, "title": "Introduction to AI", "progress": 0},
 				{"course_id": 104, "title": "Advanced Robotics", "progress": 0},
 			},
 		},
 		{
-			userID:            7,
-			name:              "Admin User",
-			email:             "admin@learnonline.edu",
-			password:          "admin123",
-			role:              common.RoleAdmin,
-			streak:            4,
-			academicStanding:  0,
-			gamificationLevel: 0,
-			courseProgress:    0,
-			courses:           []bson.M{},
+			userID:           7,
+			name:             "Admin User",
+			email:            "admin@learnonline.edu",
+			password:         "admin123",
+			role:             common.RoleAdmin,
+			academicStanding: 0,
+			courseProgress:   0,
+			courses:          []bson.M{},
 		},
 	}
 
@@ -286,35 +393,34 @@ func insertSampleData() {
 		}
 
 		update := bson.M{
-			"user_id":            seed.userID,
-			"name":               seed.name,
-			"email":              strings.ToLower(seed.email),
-			"role":               seed.role,
-			"coins":              seed.coins,
-			"streak":             seed.streak,
-			"password_hash":      hash,
-			"academic_standing":  seed.academicStanding,
-			"gamification_level": seed.gamificationLevel,
-			"course_progress":    seed.courseProgress,
-			"active_courses":     seed.courses,
+			"user_id":           seed.userID,
+			"name":              seed.name,
+			"email":             strings.ToLower(seed.email),
+			"role":              seed.role,
+			"coins":             seed.coins,
+			"password_hash":     hash,
+			"academic_standing": seed.academicStanding,
+			"course_progress":   seed.courseProgress,
+			"active_courses":    seed.courses,
 		}
 
 		opts := options.Update().SetUpsert(true)
-		if _, err := usersCol.UpdateOne(ctx, bson.M{"user_id": seed.userID}, bson.M{"$set": update}, opts); err != nil {
+		if _, err := common.UsersCol.UpdateOne(ctx, bson.M{"user_id": seed.userID}, bson.M{"$set": update}, opts); err != nil {
 			log.Printf("failed to upsert user %s: %v", seed.email, err)
 		}
 	}
 
 	quests := []bson.M{
-		{"quest_id": 1, "title": "Complete Module 3 Quiz", "question": "Finish the quiz for \"Introduction to AI\"", "answer": "", "icon": "✅", "difficulty": "Easy", "coins": 50},
-		{"quest_id": 2, "title": "Review 3 Peer Submissions", "question": "Provide feedback on research projects", "answer": "", "icon": "📝", "difficulty": "Medium", "coins": 75},
-		{"quest_id": 3, "title": "Participate in Forum Discussion", "question": "Post a question or answer in \"Machine Learning Basics\"", "answer": "", "icon": "�", "difficulty": "Easy", "coins": 25},
-		{"quest_id": 4, "title": "Lab Prep", "question": "Read the robotics lab brief before tomorrow", "answer": "", "icon": "🤖", "difficulty": "Medium", "coins": 40},
+		{"quest_id": 1, "title": "Complete Module 3 Quiz", "question": "Finish the quiz for \"Introduction to AI\"", "answer": "", "icon": "✅", "difficulty": "Easy", "coins": 50, "status": "active", "pool": "daily"},
+		{"quest_id": 2, "title": "Review 3 Peer Submissions", "question": "Provide feedback on research projects", "answer": "", "icon": "📝", "difficulty": "Medium", "coins": 75, "status": "active", "pool": "daily"},
+		{"quest_id": 3, "title": "Participate in Forum Discussion", "question": "Post a question or answer in \"Machine Learning Basics\"", "answer": "", "icon": "�", "difficulty": "Easy", "coins": 25, "status": "active", "pool": "daily"},
+		{"quest_id": 4, "title": "Lab Prep", "question": "Read the robotics lab brief before tomorrow", "answer": "", "icon": "🤖", "difficulty": "Medium", "coins": 40, "status": "active", "pool": "weekly"},
 	}
 
+	// Seeded quests are only inserted, so edits made through the quest API survive restarts.
 	questOpts := options.Update().SetUpsert(true)
 	for _, quest := range quests {
-		_, err := questsCol.UpdateOne(ctx, bson.M{"quest_id": quest["quest_id"]}, bson.M{"$set": quest}, questOpts)
+		_, err := common.QuestsCol.UpdateOne(ctx, bson.M{"quest_id": quest["quest_id"]}, bson.M{"$setOnInsert": quest}, questOpts)
 		if err != nil {
 			log.Printf("failed to upsert quest %v: %v", quest["quest_id"], err)
 		}
@@ -346,7 +452,7 @@ func insertSampleData() {
 
 	pollOpts := options.Update().SetUpsert(true)
 	for _, poll := range polls {
-		_, err := pollsCol.UpdateOne(ctx, bson.M{"poll_id": poll["poll_id"]}, bson.M{"$set": poll}, pollOpts)
+		_, err := common.PollsCol.UpdateOne(ctx, bson.M{"poll_id": poll["poll_id"]}, bson.M{"$set": poll}, pollOpts)
 		if err != nil {
 			log.Printf("failed to upsert poll %v: %v", poll["poll_id"], err)
 		}
@@ -360,7 +466,7 @@ func insertSampleData() {
 	completionOpts := options.Update().SetUpsert(true)
 	for _, record := range completionSamples {
 		filter := bson.M{"user_id": record["user_id"], "quest_id": record["quest_id"]}
-		_, err := userQuestsCol.UpdateOne(ctx, filter, bson.M{"$set": record}, completionOpts)
+		_, err := common.UserQuestsCol.UpdateOne(ctx, filter, bson.M{"$set": record}, completionOpts)
 		if err != nil {
 			log.Printf("failed to upsert user quest %v/%v: %v", record["user_id"], record["quest_id"], err)
 		}
@@ -423,12 +529,12 @@ func insertSampleData() {
 	for _, post := range researchPosts {
 		filter := bson.M{"title": post["title"]}
 		update := bson.M{"$setOnInsert": post}
-		if _, err := researchPostsCol.UpdateOne(ctx, filter, update, postOpts); err != nil {
+		if _, err := common.ResearchPostsCol.UpdateOne(ctx, filter, update, postOpts); err != nil {
 			log.Printf("failed to ensure research post %s: %v", post["title"], err)
 		}
 	}
 
-	facultyDashboardExists, err := facultyDashboardsCol.CountDocuments(ctx, bson.M{"faculty_id": 6})
+	facultyDashboardExists, err := common.FacultyDashboardsCol.CountDocuments(ctx, bson.M{"faculty_id": 6})
 	if err != nil {
 		log.Printf("failed to check faculty dashboard seed: %v", err)
 	} else if facultyDashboardExists == 0 {
@@ -569,7 +675,7 @@ func insertSampleData() {
 			"updated_at": now,
 		}
 
-		if _, err := facultyDashboardsCol.InsertOne(ctx, dashboardDoc); err != nil {
+		if _, err := common.FacultyDashboardsCol.InsertOne(ctx, dashboardDoc); err != nil {
 			log.Printf("failed to insert faculty dashboard seed: %v", err)
 		}
 	}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/handlers/common"
+	"backend/models"
+	"backend/routes"
+	"backend/tenant"
+)
+
+// Tests that go through handlers need a MongoDB replica set (handlers use transactions).
+// Point MONGODB_TEST_URI at one to run them, e.g.
+//
+//	MONGODB_TEST_URI="mongodb://localhost:27017/?replicaSet=rs0&directConnection=true" go test ./...
+//
+// Each test gets its own database, dropped when it ends.
+
+const (
+	testTenantA = "default"
+	testTenantB = "other"
+	testHostB   = "other.example.edu"
+)
+
+// newTestServer configures the handlers against a fresh database with two campuses: the
+// default one, served on any host, and testTenantB, pinned to testHostB.
+func newTestServer(t *testing.T) http.Handler {
+	t.Helper()
+	uri := os.Getenv("MONGODB_TEST_URI")
+	if uri == "" {
+		t.Skip("MONGODB_TEST_URI not set")
+	}
+	ctx := context.Background()
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := client.Database(fmt.Sprintf("test_%s_%d", t.Name(), time.Now().UnixNano()))
+	t.Cleanup(func() {
+		db.Drop(context.Background())
+		client.Disconnect(context.Background())
+	})
+
+	deps := common.Dependencies{Client: client, DefaultTenant: testTenantA, JWTSecret: "test-secret"}
+	v := reflect.ValueOf(&deps).Elem()
+	for i := 0; i < v.NumField(); i++ {
+		if f := v.Type().Field(i); f.Type == reflect.TypeOf((*mongo.Collection)(nil)) {
+			v.Field(i).Set(reflect.ValueOf(db.Collection(f.Name)))
+		}
+	}
+	common.Configure(deps)
+	if err := common.Tenants.Setup(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := deps.TenantsCol.InsertOne(ctx, tenant.Tenant{ID: testTenantB, Name: "Other", Hosts: []string{testHostB}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := common.EnsureQuestIndexes(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := common.EnsureVoteIndexes(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	r := mux.NewRouter()
+	r.Use(common.Tenants.Middleware)
+	routes.Register(r, deps.JWTSecret)
+	return r
+}
+
+// testToken returns a bearer token for a user of tenantID.
+func testToken(t *testing.T, tenantID string, userID int, role string) string {
+	t.Helper()
+	token, _, err := common.GenerateToken(&models.User{UserID: userID, Role: role, TenantID: tenantID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return "Bearer " + token
+}
+
+// serve sends a request to h as token, on host when it is not empty.
+func serve(h http.Handler, method, path, host, token string) *httptest.ResponseRecorder {
+	return serveJSON(h, method, path, host, token, "")
+}
+
+// serveJSON is serve with a JSON body.
+func serveJSON(h http.Handler, method, path, host, token, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if host != "" {
+		req.Host = host
+	}
+	if token != "" {
+		req.Header.Set("Authorization", token)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
@@ -14,8 +14,10 @@ import (
 const DefaultTokenTTL = 24 * time.Hour
 
 type Claims struct {
-	UserID int    `json:"userId"`
-	Role   string `json:"role"`
+	UserID   int    `json:"userId"`
+	Role     string `json:"role"`
+	TenantID string `json:"tenant,omitempty"`
+	Locale   string `json:"locale,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -40,8 +42,10 @@ func GenerateToken(user *models.User, secret string, ttl time.Duration) (string,
 	}
 	expiresAt := time.Now().Add(ttl)
 	claims := Claims{
-		UserID: user.UserID,
-		Role:   user.Role,
+		UserID:   user.UserID,
+		Role:     user.Role,
+		TenantID: user.TenantID,
+		Locale:   user.Locale,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(expiresAt),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
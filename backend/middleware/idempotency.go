@@ -0,0 +1,165 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"backend/tenant"
+)
+
+const (
+	IdempotencyHeader       = "Idempotency-Key"
+	IdempotentReplayHeader  = "Idempotent-Replayed"
+	DefaultIdempotencyTTL   = 24 * time.Hour
+	maxIdempotencyKeyLength = 255
+)
+
+// ErrIdempotencyKeyExists is returned by IdempotencyStore.Reserve when the key is already held.
+var ErrIdempotencyKeyExists = errors.New("idempotency key already reserved")
+
+// IdempotencyRecord is the stored outcome of the first request made with a key.
+type IdempotencyRecord struct {
+	Fingerprint string
+	Completed   bool
+	Status      int
+	Header      http.Header
+	Body        []byte
+}
+
+// IdempotencyStore persists idempotency keys. Reserve must be atomic: exactly one caller
+// may reserve a key, every other caller gets ErrIdempotencyKeyExists.
+type IdempotencyStore interface {
+	Reserve(ctx context.Context, key, fingerprint string, ttl time.Duration) error
+	Load(ctx context.Context, key string) (*IdempotencyRecord, error)
+	Complete(ctx context.Context, key string, record IdempotencyRecord) error
+	Release(ctx context.Context, key string) error
+}
+
+// NewIdempotencyMiddleware replays the stored response for POST requests that repeat an
+// Idempotency-Key. Keys are scoped to the tenant, caller and route, a key reused with a
+// different body is rejected with 422, and a retry while the first request is still running gets 409.
+// Server errors release the key so the client can retry for real.
+func NewIdempotencyMiddleware(store IdempotencyStore, ttl time.Duration) func(http.Handler) http.Handler {
+	if ttl <= 0 {
+		ttl = DefaultIdempotencyTTL
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key := strings.TrimSpace(r.Header.Get(IdempotencyHeader))
+			if r.Method != http.MethodPost || key == "" || store == nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(key) > maxIdempotencyKeyLength {
+				writeIdempotencyError(w, http.StatusBadRequest, "Idempotency-Key must be at most 255 characters")
+				return
+			}
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				writeIdempotencyError(w, http.StatusBadRequest, "invalid payload")
+				return
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			userID, _ := UserIDFromContext(r.Context())
+			tenantID, _ := tenant.ID(r.Context())
+			scoped := fmt.Sprintf("%s:%d:%s:%s:%s", tenantID, userID, r.Method, r.URL.Path, key)
+			sum := sha256.Sum256(append([]byte(r.URL.RawQuery+"\n"), body...))
+			fingerprint := hex.EncodeToString(sum[:])
+			ctx := r.Context()
+
+			if err := store.Reserve(ctx, scoped, fingerprint, ttl); err != nil {
+				if !errors.Is(err, ErrIdempotencyKeyExists) {
+					writeIdempotencyError(w, http.StatusInternalServerError, "failed to check idempotency key")
+					return
+				}
+				existing, loadErr := store.Load(ctx, scoped)
+				switch {
+				case loadErr != nil || existing == nil:
+					writeIdempotencyError(w, http.StatusConflict, "a request with this Idempotency-Key is still in progress")
+				case existing.Fingerprint != fingerprint:
+					writeIdempotencyError(w, http.StatusUnprocessableEntity, "Idempotency-Key was already used with a different payload")
+				case !existing.Completed:
+					writeIdempotencyError(w, http.StatusConflict, "a request with this Idempotency-Key is still in progress")
+				default:
+					for k, vals := range existing.Header {
+						w.Header()[k] = vals
+					}
+					w.Header().Set(IdempotentReplayHeader, "true")
+					w.WriteHeader(existing.Status)
+					_, _ = w.Write(existing.Body)
+				}
+				return
+			}
+
+			// The client may have gone away; persist the outcome regardless.
+			storeCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+			defer cancel()
+			rec := &recordingWriter{ResponseWriter: w, status: http.StatusOK}
+			func() {
+				// A panicking handler must not hold the key for the whole replay window.
+				defer func() {
+					if p := recover(); p != nil {
+						_ = store.Release(storeCtx, scoped)
+						panic(p)
+					}
+				}()
+				next.ServeHTTP(rec, r)
+			}()
+			if rec.status >= 500 {
+				_ = store.Release(storeCtx, scoped)
+				return
+			}
+			_ = store.Complete(storeCtx, scoped, IdempotencyRecord{Fingerprint: fingerprint, Completed: true, Status: rec.status, Header: replayHeader(rec.Header()), Body: rec.body.Bytes()})
+		})
+	}
+}
+
+// encodingHeaders describe how the response was sent rather than what it is. The recorder sits
+// inside the compression middleware, so it sees the body before gzip but the header map after;
+// a replay is compressed (or not) afresh for the retrying client.
+var encodingHeaders = []string{"Content-Encoding", "Content-Length", "Vary"}
+
+func replayHeader(h http.Header) http.Header {
+	h = h.Clone()
+	for _, k := range encodingHeaders {
+		h.Del(k)
+	}
+	return h
+}
+
+type recordingWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (rw *recordingWriter) WriteHeader(status int) {
+	if !rw.wroteHeader {
+		rw.status = status
+		rw.wroteHeader = true
+	}
+	rw.ResponseWriter.WriteHeader(status)
+}
+
+func (rw *recordingWriter) Write(p []byte) (int, error) {
+	rw.wroteHeader = true
+	rw.body.Write(p)
+	return rw.ResponseWriter.Write(p)
+}
+
+func writeIdempotencyError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
@@ -0,0 +1,176 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"backend/httpcache"
+)
+
+// memoryIdempotencyStore keeps records in a map, for tests.
+type memoryIdempotencyStore struct {
+	mu      sync.Mutex
+	records map[string]*IdempotencyRecord
+}
+
+func (s *memoryIdempotencyStore) Reserve(_ context.Context, key, fingerprint string, _ time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.records[key]; ok {
+		return ErrIdempotencyKeyExists
+	}
+	s.records[key] = &IdempotencyRecord{Fingerprint: fingerprint}
+	return nil
+}
+
+func (s *memoryIdempotencyStore) Load(_ context.Context, key string) (*IdempotencyRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.records[key], nil
+}
+
+func (s *memoryIdempotencyStore) Complete(_ context.Context, key string, record IdempotencyRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.records[key] = &record
+	return nil
+}
+
+func (s *memoryIdempotencyStore) Release(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.records, key)
+	return nil
+}
+
+func TestIdempotencyReplay(t *testing.T) {
+	payload := `{"items":"` + strings.Repeat("x", 2048) + `"}`
+	calls := 0
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, payload)
+	})
+	store := &memoryIdempotencyStore{records: map[string]*IdempotencyRecord{}}
+	// Compression wraps idempotency, as in the router.
+	h := httpcache.Compress(httpcache.DefaultMinCompressSize)(NewIdempotencyMiddleware(store, time.Hour)(handler))
+	send := func(acceptEncoding, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/quests", strings.NewReader(body))
+		req.Header.Set(IdempotencyHeader, "key-1")
+		if acceptEncoding != "" {
+			req.Header.Set("Accept-Encoding", acceptEncoding)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+	readBody := func(t *testing.T, w *httptest.ResponseRecorder) string {
+		t.Helper()
+		var r io.Reader = w.Body
+		if w.Header().Get("Content-Encoding") == "gzip" {
+			gz, err := gzip.NewReader(w.Body)
+			if err != nil {
+				t.Fatalf("body labelled gzip is not gzip: %v", err)
+			}
+			r = gz
+		}
+		b, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+
+	first := send("gzip", "{}")
+	if first.Code != http.StatusCreated || first.Header().Get("Content-Encoding") != "gzip" {
+		t.Fatalf("first: status %d, encoding %q", first.Code, first.Header().Get("Content-Encoding"))
+	}
+	if got := readBody(t, first); got != payload {
+		t.Fatalf("first body = %.40q", got)
+	}
+
+	tests := []struct {
+		name, acceptEncoding, wantEncoding string
+	}{
+		{"replay without gzip", "", ""},
+		{"replay with gzip", "gzip", "gzip"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := send(tt.acceptEncoding, "{}")
+			if w.Code != http.StatusCreated || w.Header().Get(IdempotentReplayHeader) != "true" {
+				t.Fatalf("status %d, replayed %q", w.Code, w.Header().Get(IdempotentReplayHeader))
+			}
+			if got := w.Header().Get("Content-Encoding"); got != tt.wantEncoding {
+				t.Errorf("Content-Encoding = %q, want %q", got, tt.wantEncoding)
+			}
+			if got := w.Header().Values("Vary"); len(got) != 1 {
+				t.Errorf("Vary = %q, want it once", got)
+			}
+			if got := readBody(t, w); got != payload {
+				t.Errorf("body = %.40q", got)
+			}
+		})
+	}
+	if calls != 1 {
+		t.Errorf("handler ran %d times, want 1", calls)
+	}
+
+	if w := send("", `{"other":true}`); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("different payload: status %d, want 422", w.Code)
+	}
+}
+
+func TestIdempotencyReleasesServerErrors(t *testing.T) {
+	calls := 0
+	h := NewIdempotencyMiddleware(&memoryIdempotencyStore{records: map[string]*IdempotencyRecord{}}, time.Hour)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest(http.MethodPost, "/quests", strings.NewReader("{}"))
+		req.Header.Set(IdempotencyHeader, "key-1")
+		h.ServeHTTP(httptest.NewRecorder(), req)
+	}
+	if calls != 2 {
+		t.Errorf("handler ran %d times, want 2", calls)
+	}
+}
+
+func TestIdempotencyReleasesPanics(t *testing.T) {
+	store := &memoryIdempotencyStore{records: map[string]*IdempotencyRecord{}}
+	calls := 0
+	h := NewIdempotencyMiddleware(store, time.Hour)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls == 1 {
+			panic("boom")
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+	send := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/quests", strings.NewReader("{}"))
+		req.Header.Set(IdempotencyHeader, "key-1")
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		return w
+	}
+	func() {
+		defer func() {
+			if p := recover(); p != "boom" {
+				t.Errorf("recovered %v, want the handler's panic", p)
+			}
+		}()
+		send()
+	}()
+	if w := send(); w.Code != http.StatusCreated || calls != 2 {
+		t.Errorf("retry after a panic: status %d after %d calls, want the handler to run again", w.Code, calls)
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"strings"
 
 	"github.com/golang-jwt/jwt/v5"
+
+	"backend/tenant"
 )
 
 type contextKey string
@@ -13,6 +15,7 @@ type contextKey string
 const (
 	contextKeyUserID   contextKey = "userID"
 	contextKeyUserRole contextKey = "userRole"
+	contextKeyLocale   contextKey = "locale"
 )
 
 func NewAuthMiddleware(jwtSecret string) func(http.Handler) http.Handler {
@@ -24,12 +27,18 @@ func NewAuthMiddleware(jwtSecret string) func(http.Handler) http.Handler {
 			}
 
 			header := r.Header.Get("Authorization")
-			if header == "" || !strings.HasPrefix(header, "Bearer ") {
+			tokenString := strings.TrimPrefix(header, "Bearer ")
+			if header == "" && isEventStream(r) {
+				// EventSource cannot set headers, so streams may pass the token in the query.
+				tokenString = r.URL.Query().Get("access_token")
+			} else if !strings.HasPrefix(header, "Bearer ") {
+				tokenString = ""
+			}
+			if tokenString == "" {
 				http.Error(w, "missing bearer token", http.StatusUnauthorized)
 				return
 			}
 
-			tokenString := strings.TrimPrefix(header, "Bearer ")
 			claims := &Claims{}
 
 			token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
@@ -40,13 +49,28 @@ func NewAuthMiddleware(jwtSecret string) func(http.Handler) http.Handler {
 				return
 			}
 
-			ctx := context.WithValue(r.Context(), contextKeyUserID, claims.UserID)
+			ctx := r.Context()
+			if claims.TenantID != "" {
+				// A campus host only accepts its own tokens; on a shared host the token picks the campus.
+				if current, _ := tenant.ID(ctx); tenant.FromHost(ctx) && current != claims.TenantID {
+					http.Error(w, "token not valid for this campus", http.StatusUnauthorized)
+					return
+				}
+				ctx = tenant.WithID(ctx, claims.TenantID)
+			}
+
+			ctx = context.WithValue(ctx, contextKeyUserID, claims.UserID)
 			ctx = context.WithValue(ctx, contextKeyUserRole, claims.Role)
+			ctx = context.WithValue(ctx, contextKeyLocale, claims.Locale)
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
 
+func isEventStream(r *http.Request) bool {
+	return r.Method == http.MethodGet && strings.Contains(r.Header.Get("Accept"), "text/event-stream")
+}
+
 func UserIDFromContext(ctx context.Context) (int, bool) {
 	if ctx == nil {
 		return 0, false
@@ -65,6 +89,15 @@ func RoleFromContext(ctx context.Context) string {
 	return ""
 }
 
+// LocaleFromContext returns the caller's saved language preference, if their token has one.
+func LocaleFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	locale, _ := ctx.Value(contextKeyLocale).(string)
+	return locale
+}
+
 func HasRole(ctx context.Context, allowed ...string) bool {
 	role := RoleFromContext(ctx)
 	if role == "" {
@@ -4,6 +4,8 @@ import (
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"backend/answers"
 )
 
 type CourseProgress struct {
@@ -16,16 +18,35 @@ type CourseProgress struct {
 
 type User struct {
 	UserID            int              `json:"id" bson:"user_id"`
+	TenantID          string           `json:"-" bson:"tenant_id,omitempty"`
 	Name              string           `json:"name" bson:"name"`
 	Email             string           `json:"email" bson:"email"`
 	Coins             int              `json:"coins" bson:"coins"`
 	Streak            int              `json:"streak" bson:"streak"`
+	LongestStreak     int              `json:"longestStreak" bson:"longest_streak,omitempty"`
 	Role              string           `json:"role" bson:"role"`
 	PasswordHash      string           `json:"-" bson:"password_hash"`
 	AcademicStanding  int              `json:"academicStanding" bson:"academic_standing"`
 	GamificationLevel int              `json:"gamificationLevel" bson:"gamification_level"`
 	CourseProgress    int              `json:"courseProgress" bson:"course_progress"`
 	ActiveCourses     []CourseProgress `json:"activeCourses" bson:"active_courses"`
+	// Cohort groups students for cohort leaderboards, e.g. "2027" or "CSE-A".
+	Cohort string `json:"cohort,omitempty" bson:"cohort,omitempty"`
+	// Locale is the preferred language for server-generated text; empty follows Accept-Language.
+	Locale string `json:"locale,omitempty" bson:"locale,omitempty"`
+	// Timezone is the IANA zone whose midnight ends the user's quest day; empty uses the campus's.
+	Timezone string `json:"timezone,omitempty" bson:"timezone,omitempty"`
+	// StreakDay is the last local day ("2006-01-02") that counted toward Streak. It is missing
+	// until the streak has been computed from the user's activity.
+	StreakDay string `json:"-" bson:"streak_day,omitempty"`
+	// StreakFreezes are bought with coins and each cover one missed day.
+	StreakFreezes int `json:"streakFreezes" bson:"streak_freezes,omitempty"`
+	// XP is the lifetime total of the user's XP ledger; GamificationLevel follows from it and
+	// the campus level curve. XP is missing until computed from the user's completed quests.
+	XP int `json:"xp" bson:"xp,omitempty"`
+	// Set while a requested account deletion is in its grace period.
+	DeletionRequestedAt  *time.Time `json:"-" bson:"deletion_requested_at,omitempty"`
+	DeletionScheduledFor *time.Time `json:"-" bson:"deletion_scheduled_for,omitempty"`
 }
 
 type PublicUser struct {
@@ -34,22 +55,114 @@ type PublicUser struct {
 	Email             string           `json:"email"`
 	Coins             int              `json:"coins"`
 	Streak            int              `json:"streak"`
+	LongestStreak     int              `json:"longestStreak"`
 	Role              string           `json:"role"`
 	AcademicStanding  int              `json:"academicStanding"`
 	GamificationLevel int              `json:"gamificationLevel"`
 	CourseProgress    int              `json:"courseProgress"`
 	ActiveCourses     []CourseProgress `json:"activeCourses"`
+	Cohort            string           `json:"cohort,omitempty"`
+	Locale            string           `json:"locale,omitempty"`
+	Timezone          string           `json:"timezone,omitempty"`
 }
 
+// Quest is a task students complete for coins. Students only see active quests inside
+// their visibility window whose audience includes them: empty Roles or CourseIDs mean
+// everyone. Quests stored before statuses existed have no status and count as active.
+// Check holds the solution and is never sent to students; Prompt is the part they see.
+// A quest in a Pool is not listed on its own: it reaches students through their daily or
+// weekly rotation. Completing a quest earns its XP as well as its coins; quests without XP
+// earn their difficulty's default.
 type Quest struct {
-	QuestID    int    `json:"id" bson:"quest_id"`
-	Title      string `json:"title" bson:"title"`
-	Question   string `json:"question" bson:"question"`
-	Answer     string `json:"-" bson:"answer"`
-	Icon       string `json:"icon" bson:"icon"`
-	Difficulty string `json:"difficulty" bson:"difficulty"`
-	Coins      int    `json:"coins" bson:"coins"`
-	Completed  bool   `json:"completed" bson:"completed,omitempty"`
+	QuestID      int             `json:"id" bson:"quest_id"`
+	Title        string          `json:"title" bson:"title"`
+	Question     string          `json:"question" bson:"question"`
+	Description  string          `json:"description,omitempty" bson:"description,omitempty"`
+	Answer       string          `json:"-" bson:"answer"`
+	Check        *answers.Spec   `json:"-" bson:"check,omitempty"`
+	Prompt       *answers.Prompt `json:"prompt,omitempty" bson:"-"`
+	MaxAttempts  int             `json:"maxAttempts,omitempty" bson:"max_attempts,omitempty"`
+	Cooldown     int             `json:"cooldownSeconds,omitempty" bson:"cooldown_seconds,omitempty"`
+	Icon         string          `json:"icon" bson:"icon"`
+	Difficulty   string          `json:"difficulty" bson:"difficulty"`
+	Coins        int             `json:"coins" bson:"coins"`
+	XP           int             `json:"xp" bson:"xp,omitempty"`
+	Status       string          `json:"status,omitempty" bson:"status,omitempty"`
+	Pool         string          `json:"pool,omitempty" bson:"pool,omitempty"`
+	VisibleFrom  *time.Time      `json:"visibleFrom,omitempty" bson:"visible_from,omitempty"`
+	VisibleUntil *time.Time      `json:"visibleUntil,omitempty" bson:"visible_until,omitempty"`
+	Roles        []string        `json:"roles,omitempty" bson:"roles,omitempty"`
+	CourseIDs    []int           `json:"courseIds,omitempty" bson:"course_ids,omitempty"`
+	CreatedBy    int             `json:"createdBy,omitempty" bson:"created_by,omitempty"`
+	CreatedAt    *time.Time      `json:"createdAt,omitempty" bson:"created_at,omitempty"`
+	UpdatedAt    *time.Time      `json:"updatedAt,omitempty" bson:"updated_at,omitempty"`
+	Completed    bool            `json:"completed" bson:"completed,omitempty"`
+}
+
+// AuthoredQuest is a quest as its authors see it, with the answer check.
+type AuthoredQuest struct {
+	Quest
+	Check *answers.Spec `json:"check,omitempty"`
+}
+
+// QuestAttempt is one answer a user submitted for a quest. Attempts are numbered from 1 per
+// user and quest.
+type QuestAttempt struct {
+	ID      primitive.ObjectID `json:"-" bson:"_id,omitempty"`
+	UserID  int                `json:"userId" bson:"user_id"`
+	QuestID int                `json:"questId" bson:"quest_id"`
+	Number  int                `json:"number" bson:"number"`
+	Answer  answers.Submission `json:"answer" bson:"answer"`
+	Correct bool               `json:"correct" bson:"correct"`
+	Parts   []bool             `json:"parts,omitempty" bson:"parts,omitempty"`
+	At      time.Time          `json:"at" bson:"at"`
+}
+
+// QuestAssignment is the set of quests one student was handed for one day or week. Period
+// is the local day ("2026-10-18") or ISO week ("2026-W42") in Timezone, and the set expires
+// at the local midnight that ends it.
+type QuestAssignment struct {
+	ID         primitive.ObjectID `json:"-" bson:"_id,omitempty"`
+	UserID     int                `json:"userId" bson:"user_id"`
+	Cadence    string             `json:"cadence" bson:"cadence"`
+	Period     string             `json:"period" bson:"period"`
+	Timezone   string             `json:"timezone" bson:"timezone"`
+	QuestIDs   []int              `json:"questIds" bson:"quest_ids"`
+	StartsAt   time.Time          `json:"startsAt" bson:"starts_at"`
+	ExpiresAt  time.Time          `json:"expiresAt" bson:"expires_at"`
+	AssignedAt time.Time          `json:"assignedAt" bson:"assigned_at"`
+}
+
+// AssignedQuests is a student's current daily or weekly set, with the quests in full.
+type AssignedQuests struct {
+	Cadence   string    `json:"cadence"`
+	Period    string    `json:"period"`
+	StartsAt  time.Time `json:"startsAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+	Items     []Quest   `json:"items"`
+}
+
+// TodayQuestsResponse holds the caller's current daily and weekly quests.
+type TodayQuestsResponse struct {
+	Daily  AssignedQuests `json:"daily"`
+	Weekly AssignedQuests `json:"weekly"`
+}
+
+// StreakResponse describes the caller's streak. Days are local to Timezone: LastActiveDay is
+// the last day that counted, active or covered by a freeze, and ActiveToday says whether
+// today already counts. Freezes cover missed days automatically, and up to MaxFreezes can
+// be bought for FreezeCost coins each.
+type StreakResponse struct {
+	Current       int      `json:"current"`
+	Longest       int      `json:"longest"`
+	LastActiveDay string   `json:"lastActiveDay,omitempty"`
+	ActiveToday   bool     `json:"activeToday"`
+	Freezes       int      `json:"freezes"`
+	MaxFreezes    int      `json:"maxFreezes"`
+	FreezeCost    int      `json:"freezeCost"`
+	Coins         int      `json:"coins"`
+	Timezone      string   `json:"timezone"`
+	Actions       []string `json:"actions"`
 }
 
 type DailyQuestItem struct {
@@ -61,6 +174,7 @@ type DailyQuestItem struct {
 }
 
 type LeaderboardEntry struct {
+	Rank            int    `json:"rank" bson:"rank"`
 	UserID          int    `json:"id" bson:"user_id"`
 	Name            string `json:"name" bson:"name"`
 	CompletedQuests int    `json:"completedQuests" bson:"completed_quests"`
@@ -69,10 +183,12 @@ type LeaderboardEntry struct {
 }
 
 type Poll struct {
-	PollID   int          `json:"id" bson:"poll_id"`
-	Question string       `json:"question" bson:"question"`
-	TimeLeft string       `json:"timeLeft" bson:"time_left"`
-	Options  []PollOption `json:"options" bson:"options"`
+	PollID    int          `json:"id" bson:"poll_id"`
+	Question  string       `json:"question" bson:"question"`
+	TimeLeft  string       `json:"timeLeft" bson:"time_left"`
+	ExpiresAt *time.Time   `json:"expiresAt,omitempty" bson:"expires_at,omitempty"`
+	Closed    bool         `json:"closed" bson:"closed"`
+	Options   []PollOption `json:"options" bson:"options"`
 }
 
 type PollOption struct {
@@ -132,6 +248,7 @@ type StudentDashboardResponse struct {
 	User          PublicUser            `json:"user"`
 	Metrics       map[string]int        `json:"metrics"`
 	DailyQuests   []DailyQuestItem      `json:"dailyQuests"`
+	WeeklyQuests  []DailyQuestItem      `json:"weeklyQuests"`
 	Leaderboard   []LeaderboardEntry    `json:"leaderboard"`
 	ActiveCourses []CourseProgress      `json:"activeCourses"`
 	ResearchFeed  []ResearchPostResponse `json:"researchFeed"`
@@ -186,10 +303,13 @@ type FacultyAIGrading struct {
 	Suggestions  []FacultyAISuggestion `json:"suggestions"`
 	PendingCount int                   `json:"pendingCount"`
 	LastUpdated  string                `json:"lastUpdated"`
+	// LastUpdatedAt is the RFC 3339 time behind the localized LastUpdated label.
+	LastUpdatedAt string `json:"lastUpdatedAt,omitempty"`
 }
 
 type FacultyMentee struct {
 	ID          string `json:"id"`
+	StudentID   int    `json:"studentId,omitempty"`
 	Name        string `json:"name"`
 	Status      string `json:"status"`
 	NextSession string `json:"nextSession,omitempty"`
@@ -201,6 +321,8 @@ type FacultyMentorship struct {
 	Mentees     []FacultyMentee `json:"mentees"`
 	ActiveCount int             `json:"activeCount"`
 	LastUpdated string          `json:"lastUpdated"`
+	// LastUpdatedAt is the RFC 3339 time behind the localized LastUpdated label.
+	LastUpdatedAt string `json:"lastUpdatedAt,omitempty"`
 }
 
 type FacultyCourseCard struct {
@@ -228,3 +350,148 @@ type FacultyOverviewResponse struct {
 	CourseProgress []FacultyCourse       `json:"courseProgress,omitempty"`
 	TopPerformers  []LeaderboardEntry    `json:"topPerformers,omitempty"`
 }
+
+type ErrorResponse struct {
+	Error string `json:"error"`
+}
+
+type SuccessResponse struct {
+	Success bool `json:"success"`
+}
+
+type HealthResponse struct {
+	Status string `json:"status"`
+}
+
+type LoginResponse struct {
+	Token     string     `json:"token"`
+	ExpiresAt time.Time  `json:"expiresAt"`
+	User      PublicUser `json:"user"`
+}
+
+// AccountDeletionResponse reports whether the account is scheduled for deletion. Status
+// is "scheduled" during the grace period and "none" otherwise.
+type AccountDeletionResponse struct {
+	Status       string     `json:"status"`
+	RequestedAt  *time.Time `json:"requestedAt,omitempty"`
+	ScheduledFor *time.Time `json:"scheduledFor,omitempty"`
+}
+
+// LevelUp reports the levels a user reached with one XP award and the coins paid for them.
+type LevelUp struct {
+	From  int `json:"from"`
+	To    int `json:"to"`
+	Coins int `json:"coins"`
+}
+
+// XPEntry is one dated XP award in a user's XP ledger.
+type XPEntry struct {
+	ID      primitive.ObjectID `json:"-" bson:"_id,omitempty"`
+	UserID  int                `json:"userId" bson:"user_id"`
+	XP      int                `json:"xp" bson:"xp"`
+	Reason  string             `json:"reason" bson:"reason"`
+	QuestID int                `json:"questId,omitempty" bson:"quest_id,omitempty"`
+	At      time.Time          `json:"at" bson:"at"`
+}
+
+type XPEntryListResponse struct {
+	Items []XPEntry `json:"items"`
+	Next  string    `json:"next,omitempty"`
+	Prev  string    `json:"prev,omitempty"`
+}
+
+// ProgressResponse places a user's lifetime XP on the campus level curve. LevelXP and
+// NextLevelXP are the lifetime XP at which the current and next level start; at the top
+// level NextLevelXP and XPToNext are 0 and MaxLevel is set.
+type ProgressResponse struct {
+	XP          int  `json:"xp"`
+	Level       int  `json:"level"`
+	LevelXP     int  `json:"levelXp"`
+	NextLevelXP int  `json:"nextLevelXp"`
+	XPIntoLevel int  `json:"xpIntoLevel"`
+	XPToNext    int  `json:"xpToNext"`
+	Percent     int  `json:"percent"`
+	MaxLevel    bool `json:"maxLevel"`
+	LevelReward int  `json:"levelReward"`
+}
+
+// CompleteQuestResponse reports a completion. For a quest with an answer check, Result says
+// whether the answer was right; a wrong answer comes with Success false, the author's
+// feedback, the attempts left when they are limited and when the next attempt is allowed.
+type CompleteQuestResponse struct {
+	Success      bool            `json:"success"`
+	Coins        int             `json:"coins"`
+	XP           int             `json:"xp,omitempty"`
+	LevelUp      *LevelUp        `json:"levelUp,omitempty"`
+	Result       *answers.Result `json:"result,omitempty"`
+	Feedback     string          `json:"feedback,omitempty"`
+	AttemptsLeft *int            `json:"attemptsLeft,omitempty"`
+	RetryAt      *time.Time      `json:"retryAt,omitempty"`
+}
+
+type ResearchFeedResponse struct {
+	Items []ResearchPostResponse `json:"items"`
+	Next  string                 `json:"next,omitempty"`
+	Prev  string                 `json:"prev,omitempty"`
+}
+
+type QuestListResponse struct {
+	Items []Quest `json:"items"`
+	Next  string  `json:"next,omitempty"`
+	Prev  string  `json:"prev,omitempty"`
+}
+
+// LeaderboardResponse is one page of the leaderboard. Me and Around describe the caller
+// wherever they rank: Around holds the entry just above them, their own and the one just
+// below. Both are omitted when the caller is not on this leaderboard.
+type LeaderboardResponse struct {
+	Scope  string             `json:"scope"`
+	Period *LeaderboardPeriod `json:"period,omitempty"`
+	Items  []LeaderboardEntry `json:"items"`
+	Me     *LeaderboardEntry  `json:"me,omitempty"`
+	Around []LeaderboardEntry `json:"around,omitempty"`
+	Next   string             `json:"next,omitempty"`
+	Prev   string             `json:"prev,omitempty"`
+}
+
+// LeaderboardPeriod is the span a windowed leaderboard counts coins over, from Start up to
+// but excluding End. Label names it: "2026-W42", "2026-10" or the term's name.
+type LeaderboardPeriod struct {
+	Window string    `json:"window" bson:"window"`
+	Label  string    `json:"label" bson:"label"`
+	Start  time.Time `json:"start" bson:"start"`
+	End    time.Time `json:"end" bson:"end"`
+}
+
+// LeaderboardSnapshot is the final top of a closed period, kept for history.
+type LeaderboardSnapshot struct {
+	ID      primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	Scope   string             `json:"scope" bson:"scope"`
+	Period  LeaderboardPeriod  `json:"period" bson:"period"`
+	Entries []LeaderboardEntry `json:"entries" bson:"entries"`
+	TakenAt time.Time          `json:"takenAt" bson:"taken_at"`
+}
+
+type LeaderboardSnapshotListResponse struct {
+	Items []LeaderboardSnapshot `json:"items"`
+	Next  string                `json:"next,omitempty"`
+	Prev  string                `json:"prev,omitempty"`
+}
+
+type QuestAssignmentListResponse struct {
+	Items []QuestAssignment `json:"items"`
+	Next  string            `json:"next,omitempty"`
+	Prev  string            `json:"prev,omitempty"`
+}
+
+type PollListResponse struct {
+	Items []Poll `json:"items"`
+	Next  string `json:"next,omitempty"`
+	Prev  string `json:"prev,omitempty"`
+}
+
+type AdminActivityListResponse struct {
+	Items []AdminActivity `json:"items"`
+	Next  string          `json:"next,omitempty"`
+	Prev  string          `json:"prev,omitempty"`
+}
@@ -0,0 +1,228 @@
+package models
+
+import (
+	"time"
+
+	"backend/answers"
+)
+
+type LoginRequest struct {
+	Email    string `json:"email" validate:"required,max=254"`
+	Password string `json:"password" validate:"required,max=128"`
+	Tenant   string `json:"tenant,omitempty" validate:"max=64"`
+}
+
+// CompleteQuestRequest completes a quest. Answer is required for quests with an answer check.
+type CompleteQuestRequest struct {
+	UserID int                 `json:"user_id" validate:"min=0"`
+	Answer *answers.Submission `json:"answer"`
+}
+
+// QuestWindowRequest bounds when a quest is visible. A missing bound leaves that side open.
+type QuestWindowRequest struct {
+	From  *time.Time `json:"from"`
+	Until *time.Time `json:"until"`
+}
+
+type CreateQuestRequest struct {
+	Title       string              `json:"title" validate:"required,max=160"`
+	Question    string              `json:"question" validate:"max=500"`
+	Description string              `json:"description" validate:"max=2000"`
+	Icon        string              `json:"icon" validate:"max=16"`
+	Difficulty  string              `json:"difficulty" validate:"required,oneof=easy medium hard"`
+	Coins       int                 `json:"coins" validate:"min=0,max=10000"`
+	XP          int                 `json:"xp" validate:"min=0,max=10000"`
+	Status      string              `json:"status" validate:"oneof=draft active archived"`
+	Pool        string              `json:"pool" validate:"oneof=daily weekly"`
+	Visibility  *QuestWindowRequest `json:"visibility"`
+	Roles       []string            `json:"roles" validate:"max=3"`
+	CourseIDs   []int               `json:"courseIds" validate:"max=50"`
+	Check       *answers.Spec       `json:"check"`
+	MaxAttempts int                 `json:"maxAttempts" validate:"min=0,max=100"`
+	Cooldown    int                 `json:"cooldownSeconds" validate:"min=0,max=604800"`
+}
+
+// UpdateQuestRequest replaces the fields it sets; omitted fields keep their value. Visibility
+// replaces both bounds, so {} makes the quest visible at any time, and an empty roles or
+// courseIds list opens the quest to everyone again. Check replaces the answer check; a check
+// without a kind removes it. Zero maxAttempts or cooldownSeconds lift the limit, and an
+// empty pool takes the quest out of rotation. Zero xp uses the difficulty's default.
+type UpdateQuestRequest struct {
+	Title       *string             `json:"title" validate:"notblank,max=160"`
+	Question    *string             `json:"question" validate:"max=500"`
+	Description *string             `json:"description" validate:"max=2000"`
+	Icon        *string             `json:"icon" validate:"max=16"`
+	Difficulty  *string             `json:"difficulty" validate:"notblank,oneof=easy medium hard"`
+	Coins       *int                `json:"coins" validate:"min=0,max=10000"`
+	XP          *int                `json:"xp" validate:"min=0,max=10000"`
+	Status      *string             `json:"status" validate:"notblank,oneof=draft active archived"`
+	Pool        *string             `json:"pool" validate:"oneof=daily weekly"`
+	Visibility  *QuestWindowRequest `json:"visibility"`
+	Roles       *[]string           `json:"roles" validate:"max=3"`
+	CourseIDs   *[]int              `json:"courseIds" validate:"max=50"`
+	Check       *answers.Spec       `json:"check"`
+	MaxAttempts *int                `json:"maxAttempts" validate:"min=0,max=100"`
+	Cooldown    *int                `json:"cooldownSeconds" validate:"min=0,max=604800"`
+}
+
+type VotePollRequest struct {
+	OptionIndex int `json:"option_index" validate:"min=0"`
+}
+
+type CreateResearchPostRequest struct {
+	Title           string   `json:"title" validate:"max=200"`
+	Summary         string   `json:"summary" validate:"max=2000"`
+	Body            string   `json:"body" validate:"max=20000"`
+	Category        string   `json:"category" validate:"max=60"`
+	Link            string   `json:"link" validate:"max=2048,url"`
+	Image           string   `json:"image" validate:"max=2048,url"`
+	AuthorRole      string   `json:"authorRole" validate:"max=120"`
+	Tags            []string `json:"tags" validate:"max=20"`
+	IsCollaboration *bool    `json:"isCollaboration"`
+}
+
+type ReviewAISuggestionRequest struct {
+	Status          string `json:"status" validate:"oneof=pending approved needs_follow_up reviewed escalated dismissed"`
+	Recommendation  string `json:"recommendation" validate:"max=2000"`
+	GradeSuggestion string `json:"gradeSuggestion" validate:"max=8"`
+}
+
+type AddMenteeRequest struct {
+	StudentID   int    `json:"studentId" validate:"min=0"`
+	Name        string `json:"name" validate:"required,max=120"`
+	Status      string `json:"status" validate:"oneof=active meeting_soon archived"`
+	NextSession string `json:"nextSession" validate:"max=120"`
+	Note        string `json:"note" validate:"max=500"`
+}
+
+type UpdateMenteeRequest struct {
+	Status      *string `json:"status" validate:"notblank,oneof=active meeting_soon archived"`
+	NextSession *string `json:"nextSession" validate:"max=120"`
+	Note        *string `json:"note" validate:"max=500"`
+}
+
+type AddCourseRequest struct {
+	Title  string `json:"title" validate:"required,max=160"`
+	Status string `json:"status" validate:"oneof=published draft archived"`
+	Code   string `json:"code" validate:"max=20"`
+}
+
+type UpdateCourseRequest struct {
+	Status *string `json:"status" validate:"notblank,oneof=published draft archived"`
+	Title  *string `json:"title" validate:"notblank,max=160"`
+	Code   *string `json:"code" validate:"max=20"`
+}
+
+type CreateWebhookRequest struct {
+	URL    string   `json:"url" validate:"required,url,max=2048"`
+	Events []string `json:"events" validate:"required,max=10"`
+	Secret string   `json:"secret" validate:"max=256"`
+	Active *bool    `json:"active"`
+}
+
+type UpdateWebhookRequest struct {
+	URL    *string  `json:"url" validate:"notblank,url,max=2048"`
+	Events []string `json:"events" validate:"max=10"`
+	Active *bool    `json:"active"`
+}
+
+type UpdateTenantRequest struct {
+	Branding     *TenantBrandingRequest     `json:"branding"`
+	AI           *TenantAIRequest           `json:"ai"`
+	Gamification *TenantGamificationRequest `json:"gamification"`
+}
+
+type TenantBrandingRequest struct {
+	DisplayName  *string `json:"displayName" validate:"notblank,max=120"`
+	LogoURL      *string `json:"logoUrl" validate:"url,max=2048"`
+	PrimaryColor *string `json:"primaryColor" validate:"max=20"`
+	AccentColor  *string `json:"accentColor" validate:"max=20"`
+}
+
+type TenantAIRequest struct {
+	Enabled *bool   `json:"enabled"`
+	Model   *string `json:"model" validate:"max=100"`
+}
+
+// TenantGamificationRequest updates the reward rules. Terms replaces the whole term list
+// when present; send [] to remove every term.
+type TenantGamificationRequest struct {
+	CoinMultiplier     *float64               `json:"coinMultiplier" validate:"min=0.1,max=10"`
+	LeaderboardEnabled *bool                  `json:"leaderboardEnabled"`
+	Terms              []TenantTermRequest    `json:"terms" validate:"max=20"`
+	Timezone           *string                `json:"timezone" validate:"max=64"`
+	Rotation           *TenantRotationRequest `json:"rotation"`
+	Streaks            *TenantStreaksRequest  `json:"streaks"`
+	Levels             *TenantLevelsRequest   `json:"levels"`
+}
+
+// TenantLevelsRequest sets the level curve. Thresholds, when non-empty, lists the lifetime
+// XP each level from 2 starts at and overrides base and exponent; send [] to go back to the
+// polynomial curve. Reward is the coins paid per level reached.
+type TenantLevelsRequest struct {
+	Base       *int     `json:"base" validate:"min=1,max=100000"`
+	Exponent   *float64 `json:"exponent" validate:"min=1,max=3"`
+	Thresholds *[]int   `json:"thresholds" validate:"max=200"`
+	Reward     *int     `json:"reward" validate:"min=0,max=10000"`
+}
+
+// TenantStreaksRequest sets what keeps a streak going and what streak freezes cost. An
+// empty actions list counts every kind of activity; maxFreezes 0 disables freezes.
+type TenantStreaksRequest struct {
+	Actions    *[]string `json:"actions" validate:"max=3"`
+	FreezeCost *int      `json:"freezeCost" validate:"min=1,max=10000"`
+	MaxFreezes *int      `json:"maxFreezes" validate:"min=0,max=10"`
+}
+
+// TenantRotationRequest sets how many quests each student is handed per day and per week.
+type TenantRotationRequest struct {
+	Daily  *int `json:"daily" validate:"min=1,max=10"`
+	Weekly *int `json:"weekly" validate:"min=1,max=10"`
+}
+
+type TenantTermRequest struct {
+	Name  string    `json:"name"`
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+type AcceptInvitationRequest struct {
+	Token    string `json:"token" validate:"required,max=200"`
+	Password string `json:"password" validate:"required,min=8,max=128"`
+}
+
+// UpdatePreferencesRequest sets the caller's language for server-generated text and the
+// timezone their quest days follow. Omitted fields keep their value; an empty locale clears
+// it so Accept-Language decides again, and an empty timezone follows the campus's.
+type UpdatePreferencesRequest struct {
+	Locale   *string `json:"locale" validate:"oneof=en hi ta"`
+	Timezone *string `json:"timezone" validate:"max=64"`
+}
+
+// RequestAccountDeletionRequest confirms a deletion request with the account password.
+type RequestAccountDeletionRequest struct {
+	Password string `json:"password" validate:"required,max=128"`
+}
+
+type FlagRuleRequest struct {
+	Roles      []string `json:"roles"`
+	Percentage int      `json:"percentage" validate:"min=0,max=100"`
+}
+
+type CreateFlagRequest struct {
+	Key         string            `json:"key" validate:"required,max=64"`
+	Description string            `json:"description" validate:"max=300"`
+	Enabled     *bool             `json:"enabled"`
+	Tenants     []string          `json:"tenants" validate:"max=50"`
+	Users       []int             `json:"users" validate:"max=1000"`
+	Rules       []FlagRuleRequest `json:"rules" validate:"max=10"`
+}
+
+// UpdateFlagRequest replaces the fields it sets; omitted fields keep their value.
+type UpdateFlagRequest struct {
+	Description *string            `json:"description" validate:"max=300"`
+	Enabled     *bool              `json:"enabled"`
+	Tenants     *[]string          `json:"tenants" validate:"max=50"`
+	Users       *[]int             `json:"users" validate:"max=1000"`
+	Rules       *[]FlagRuleRequest `json:"rules" validate:"max=10"`
+}
@@ -0,0 +1,77 @@
+package openapi
+
+import (
+	_ "embed"
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gorilla/mux"
+)
+
+//go:embed docs.html
+var docsPage []byte
+
+var (
+	specOnce sync.Once
+	specJSON []byte
+)
+
+// GET /openapi.json
+func SpecHandler(w http.ResponseWriter, r *http.Request) {
+	specOnce.Do(func() {
+		specJSON, _ = json.MarshalIndent(Build(Routes), "", "  ")
+	})
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(specJSON)
+}
+
+// GET /docs. The page loads Swagger UI from unpkg.com rather than bundling it, so viewing
+// the docs needs internet access; the spec it renders comes from this server.
+func DocsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(docsPage)
+}
+
+// Undocumented walks router and returns "METHOD /path" for every route under /api (or a
+// versioned /api/vN prefix) that has no entry in Routes.
+func Undocumented(router *mux.Router) []string {
+	documented := map[string]bool{}
+	for _, rt := range Routes {
+		documented[routeKey(rt.Method, rt.Path)] = true
+	}
+	missing := []string{}
+	_ = router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		tmpl, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+		methods, err := route.GetMethods()
+		if err != nil {
+			return nil
+		}
+		if !strings.HasPrefix(tmpl, "/api") {
+			return nil
+		}
+		path := versionPrefix.ReplaceAllString(tmpl, "")
+		for _, m := range methods {
+			if !documented[routeKey(m, path)] {
+				missing = append(missing, m+" "+tmpl)
+			}
+		}
+		return nil
+	})
+	sort.Strings(missing)
+	return missing
+}
+
+var versionPrefix = regexp.MustCompile(`^/api(/v[0-9]+)?`)
+
+func routeKey(method, path string) string {
+	return strings.ToUpper(method) + " " + path
+}
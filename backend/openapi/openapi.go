@@ -0,0 +1,374 @@
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"backend/handlers/common"
+	"backend/models"
+)
+
+// Route documents one registered API route. Path is relative to the /api prefix and uses
+// the same {param} templates as the mux router so the two can be compared directly.
+type Route struct {
+	Method   string
+	Path     string
+	Summary  string
+	Tag      string
+	Public   bool
+	Roles    []string
+	Query    []Param
+	Request  interface{}
+	Response interface{}
+	Status   int
+}
+
+// Param is a documented query string parameter.
+type Param struct {
+	Name        string
+	Type        string
+	Description string
+}
+
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Servers    []Server            `json:"servers"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+type Server struct {
+	URL         string `json:"url"`
+	Description string `json:"description,omitempty"`
+}
+
+type PathItem map[string]*Operation
+
+type Operation struct {
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	OperationID string                `json:"operationId"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]Response   `json:"responses"`
+	Security    []map[string][]string `json:"security"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Required    bool    `json:"required"`
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes"`
+}
+
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+}
+
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+}
+
+const Version = "1.0.0"
+
+// Build assembles the OpenAPI document for routes. Named types from the models package are
+// emitted once under components/schemas and referenced from operations.
+func Build(routes []Route) Document {
+	gen := &schemaGen{schemas: map[string]*Schema{}}
+	doc := Document{
+		OpenAPI: "3.0.3",
+		Info:    Info{Title: "Learnify API", Version: Version, Description: "REST API for the SRM campus portal. Meta routes (health, openapi.json, docs) are served unversioned under /api. Unversioned business routes under /api behave like v1 and are deprecated."},
+		Servers: []Server{
+			{URL: "/api/v2", Description: "Current version"},
+			{URL: "/api/v1", Description: "Deprecated: list endpoints return bare arrays instead of {items}"},
+		},
+		Paths: map[string]PathItem{},
+		Components: Components{
+			Schemas:         gen.schemas,
+			SecuritySchemes: map[string]SecurityScheme{"bearerAuth": {Type: "http", Scheme: "bearer", BearerFormat: "JWT"}},
+		},
+	}
+	errorSchema := gen.schemaFor(reflect.TypeOf(models.ErrorResponse{}))
+	validationSchema := gen.schemaFor(reflect.TypeOf(ValidationErrorResponse{}))
+	for _, rt := range routes {
+		item, ok := doc.Paths[rt.Path]
+		if !ok {
+			item = PathItem{}
+			doc.Paths[rt.Path] = item
+		}
+		op := &Operation{Summary: rt.Summary, OperationID: operationID(rt), Responses: map[string]Response{}, Security: []map[string][]string{}}
+		if rt.Tag != "" {
+			op.Tags = []string{rt.Tag}
+		}
+		if len(rt.Roles) > 0 {
+			op.Description = "Requires role: " + strings.Join(rt.Roles, ", ") + "."
+		}
+		if !rt.Public {
+			op.Security = []map[string][]string{{"bearerAuth": {}}}
+			op.Responses["401"] = Response{Description: "Missing or invalid bearer token"}
+			if len(rt.Roles) > 0 {
+				op.Responses["403"] = jsonResponse("Caller lacks the required role", errorSchema)
+			}
+		}
+		for _, name := range pathParams(rt.Path) {
+			op.Parameters = append(op.Parameters, Parameter{Name: name, In: "path", Required: true, Schema: &Schema{Type: "string"}})
+		}
+		for _, q := range rt.Query {
+			typ := q.Type
+			if typ == "" {
+				typ = "string"
+			}
+			op.Parameters = append(op.Parameters, Parameter{Name: q.Name, In: "query", Description: q.Description, Schema: &Schema{Type: typ}})
+		}
+		if rt.Method == http.MethodPost && !rt.Public {
+			op.Parameters = append(op.Parameters, Parameter{Name: "Idempotency-Key", In: "header", Description: "Retries with the same key replay the first response", Schema: &Schema{Type: "string"}})
+			op.Responses["409"] = jsonResponse("A request with this Idempotency-Key is still in progress", errorSchema)
+		}
+		if rt.Request != nil {
+			op.RequestBody = &RequestBody{Required: true, Content: map[string]MediaType{"application/json": {Schema: gen.schemaFor(reflect.TypeOf(rt.Request))}}}
+			op.Responses["400"] = jsonResponse("Malformed JSON payload", errorSchema)
+			op.Responses["422"] = jsonResponse("Payload failed validation", validationSchema)
+		}
+		status := rt.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		success := Response{Description: http.StatusText(status)}
+		if rt.Response != nil {
+			success = jsonResponse(http.StatusText(status), gen.schemaFor(reflect.TypeOf(rt.Response)))
+		}
+		op.Responses[strconv.Itoa(status)] = success
+		item[strings.ToLower(rt.Method)] = op
+	}
+	return doc
+}
+
+// ValidationErrorResponse documents the 422 body written by common.WriteValidationError.
+type ValidationErrorResponse struct {
+	Error  string                  `json:"error"`
+	Fields common.ValidationErrors `json:"fields"`
+}
+
+func jsonResponse(description string, schema *Schema) Response {
+	return Response{Description: description, Content: map[string]MediaType{"application/json": {Schema: schema}}}
+}
+
+func operationID(rt Route) string {
+	parts := []string{strings.ToLower(rt.Method)}
+	for _, seg := range strings.Split(strings.Trim(rt.Path, "/"), "/") {
+		seg = strings.Trim(seg, "{}")
+		seg = strings.NewReplacer(".", "", "-", "").Replace(seg)
+		if seg == "" {
+			continue
+		}
+		parts = append(parts, strings.ToUpper(seg[:1])+seg[1:])
+	}
+	return strings.Join(parts, "")
+}
+
+func pathParams(path string) []string {
+	var names []string
+	for _, seg := range strings.Split(path, "/") {
+		if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") {
+			name, _, _ := strings.Cut(strings.Trim(seg, "{}"), ":")
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// --- schema generation ---
+
+type schemaGen struct {
+	schemas map[string]*Schema
+}
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	objectIDType = reflect.TypeOf(primitive.ObjectID{})
+)
+
+func (g *schemaGen) schemaFor(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch t {
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case objectIDType:
+		return &Schema{Type: "string"}
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: g.schemaFor(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schemaFor(t.Elem())}
+	case reflect.Interface:
+		return &Schema{}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		name := t.Name()
+		if _, ok := g.schemas[name]; !ok {
+			g.schemas[name] = &Schema{Type: "object"}
+			g.schemas[name] = g.structSchema(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + name}
+	}
+	return &Schema{}
+}
+
+func (g *schemaGen) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		tag := sf.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		if name == "" && sf.Anonymous && sf.Type.Kind() == reflect.Struct {
+			// Embedded structs are flattened like encoding/json does; the outer fields win.
+			embedded := g.structSchema(sf.Type)
+			for k, v := range embedded.Properties {
+				if _, ok := s.Properties[k]; !ok {
+					s.Properties[k] = v
+				}
+			}
+			s.Required = append(s.Required, embedded.Required...)
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		prop := g.schemaFor(sf.Type)
+		if rules := sf.Tag.Get("validate"); rules != "" {
+			prop = applyRules(prop, rules)
+			if hasRule(rules, "required") {
+				s.Required = append(s.Required, name)
+			}
+		}
+		if sf.Type.Kind() == reflect.Ptr && prop.Ref == "" {
+			prop.Nullable = true
+		}
+		s.Properties[name] = prop
+	}
+	sort.Strings(s.Required)
+	return s
+}
+
+// applyRules mirrors common.Validate tags onto the schema so clients see the same limits.
+func applyRules(s *Schema, rules string) *Schema {
+	if s.Ref != "" {
+		return s
+	}
+	for _, part := range strings.Split(rules, ",") {
+		key, arg, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "oneof":
+			s.Enum = strings.Fields(arg)
+		case "url":
+			s.Format = "uri"
+		case "notblank":
+			one := 1
+			s.MinLength = &one
+		case "min", "max":
+			n, err := strconv.ParseFloat(arg, 64)
+			if err != nil {
+				continue
+			}
+			switch s.Type {
+			case "string":
+				v := int(n)
+				if key == "min" {
+					s.MinLength = &v
+				} else {
+					s.MaxLength = &v
+				}
+			case "array":
+				if key == "max" {
+					v := int(n)
+					s.MaxItems = &v
+				}
+			case "integer", "number":
+				if key == "min" {
+					s.Minimum = &n
+				} else {
+					s.Maximum = &n
+				}
+			}
+		}
+	}
+	return s
+}
+
+func hasRule(rules, name string) bool {
+	for _, part := range strings.Split(rules, ",") {
+		key, _, _ := strings.Cut(strings.TrimSpace(part), "=")
+		if key == name {
+			return true
+		}
+	}
+	return false
+}
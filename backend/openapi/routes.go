@@ -0,0 +1,125 @@
+package openapi
+
+import (
+	"net/http"
+
+	"backend/apiversion"
+	"backend/audit"
+	"backend/cache"
+	"backend/flags"
+	"backend/handlers/common"
+	"backend/jobs"
+	"backend/models"
+	"backend/roster"
+	"backend/tenant"
+	"backend/webhooks"
+)
+
+// pageParams documents the shared cursor pagination parameters (see common.ParsePage).
+var (
+	leaderboardWindow = Param{Name: "window", Description: "all (default), week, month or term"}
+	leaderboardScope  = Param{Name: "scope", Description: "campus (default), course:{id} or cohort:{name}"}
+)
+
+func pageParams(sorts string, filters ...Param) []Param {
+	params := []Param{
+		{Name: "limit", Type: "integer", Description: "Page size; bare-array (v1) lists return everything when it is left out"},
+		{Name: "cursor", Description: "Opaque cursor from a previous response's next/prev; an edited cursor returns 400"},
+		{Name: "sort", Description: "One of: " + sorts},
+		{Name: "order", Description: "asc or desc"},
+	}
+	return append(params, filters...)
+}
+
+// deliveryParams documents the webhook delivery list filters.
+func deliveryParams(extra ...Param) []Param {
+	return pageParams("created_at, next_attempt_at", append(extra, Param{Name: "event"}, Param{Name: "subscription_id"})...)
+}
+
+var (
+	adminRoles   = []string{common.RoleAdmin}
+	facultyRoles = []string{common.RoleFaculty, common.RoleAdmin}
+	auditParams  = []Param{{Name: "actor_id", Type: "integer"}, {Name: "subject_id", Type: "integer", Description: "User the request acted for"}, {Name: "action", Description: "Action contains, e.g. courses"}, {Name: "target"}, {Name: "from", Description: "RFC 3339 timestamp, inclusive"}, {Name: "to", Description: "RFC 3339 timestamp, exclusive"}}
+	facultyQuery = []Param{{Name: "faculty_id", Type: "integer", Description: "Admin only: act on another faculty member's dashboard"}}
+)
+
+// Routes is the documented API surface. Keep it in sync with mountVersion in routes.go;
+// startup fails when a registered route has no entry here.
+var Routes = []Route{
+	{Method: "GET", Path: "/health", Summary: "Service health check", Tag: "system", Public: true, Response: models.HealthResponse{}},
+	{Method: "GET", Path: "/openapi.json", Summary: "This OpenAPI document", Tag: "system", Public: true},
+	{Method: "GET", Path: "/docs", Summary: "Interactive API documentation", Tag: "system", Public: true},
+	{Method: "POST", Path: "/auth/login", Summary: "Exchange credentials for a JWT", Tag: "auth", Public: true, Request: models.LoginRequest{}, Response: models.LoginResponse{}},
+	{Method: "POST", Path: "/auth/invitations/accept", Summary: "Set a password from an invitation link and sign in", Tag: "auth", Public: true, Request: models.AcceptInvitationRequest{}, Response: models.LoginResponse{}},
+	{Method: "GET", Path: "/tenant", Summary: "Branding for the campus serving this host", Tag: "tenants", Public: true, Response: tenant.Public{}},
+
+	{Method: "GET", Path: "/me", Summary: "Current user profile", Tag: "users", Response: models.PublicUser{}},
+	{Method: "GET", Path: "/me/flags", Summary: "Feature flags evaluated for the current user", Tag: "flags", Response: flags.Evaluation{}},
+	{Method: "POST", Path: "/me/preferences", Summary: "Set the language for server-generated text (en, hi, ta; empty follows Accept-Language) and the timezone quest days follow, and get a fresh token", Tag: "users", Request: models.UpdatePreferencesRequest{}, Response: models.LoginResponse{}},
+	{Method: "GET", Path: "/me/streak", Summary: "The caller's current and longest streak, in their timezone, and their streak freezes", Tag: "users", Response: models.StreakResponse{}},
+	{Method: "POST", Path: "/me/streak/freezes", Summary: "Buy a streak freeze with coins; 409 when coins run short or the most freezes allowed are held", Tag: "users", Response: models.StreakResponse{}},
+	{Method: "GET", Path: "/me/progress", Summary: "The caller's XP and level on the campus level curve, with the XP into the level and to the next", Tag: "users", Response: models.ProgressResponse{}},
+	{Method: "GET", Path: "/me/xp", Summary: "The caller's XP ledger, newest first", Tag: "users", Query: pageParams("at"), Response: models.XPEntryListResponse{}},
+	{Method: "GET", Path: "/me/export", Summary: "ZIP of JSON files with all data tied to the current user (application/zip)", Tag: "users"},
+	{Method: "GET", Path: "/me/deletion", Summary: "Whether the current account is scheduled for deletion", Tag: "users", Response: models.AccountDeletionResponse{}},
+	{Method: "POST", Path: "/me/deletion", Summary: "Schedule the current account for deletion after the grace period", Tag: "users", Request: models.RequestAccountDeletionRequest{}, Response: models.AccountDeletionResponse{}, Status: http.StatusAccepted},
+	{Method: "DELETE", Path: "/me/deletion", Summary: "Cancel a pending account deletion", Tag: "users", Response: models.AccountDeletionResponse{}},
+	{Method: "GET", Path: "/user/{id}", Summary: "User profile by ID (students may only read their own)", Tag: "users", Response: models.PublicUser{}},
+
+	{Method: "GET", Path: "/quests", Summary: "Quests the user can see right now, with completion state; pooled quests are handed out by /quests/today instead", Tag: "quests", Query: pageParams("id, coins, title", Param{Name: "user_id", Type: "integer", Description: "Faculty/admin only: quests and completion state for another user"}, Param{Name: "difficulty"}, Param{Name: "status", Description: "Faculty/admin only: draft, active, archived or all, listing the catalog regardless of audience"}), Response: models.QuestListResponse{}},
+	{Method: "POST", Path: "/quests", Summary: "Create a quest (a draft unless status is set)", Tag: "quests", Roles: facultyRoles, Request: models.CreateQuestRequest{}, Response: models.AuthoredQuest{}, Status: http.StatusCreated},
+	{Method: "GET", Path: "/quests/today", Summary: "The caller's current daily and weekly quests, expiring at local midnight", Tag: "quests", Query: []Param{{Name: "user_id", Type: "integer", Description: "Admin only: another student's quests"}}, Response: models.TodayQuestsResponse{}},
+	{Method: "GET", Path: "/quests/assignments", Summary: "Daily and weekly quest sets handed to a student, newest first", Tag: "quests", Query: pageParams("start", Param{Name: "cadence", Description: "daily or weekly"}, Param{Name: "user_id", Type: "integer", Description: "Faculty/admin only: another student's history"}), Response: models.QuestAssignmentListResponse{}},
+	{Method: "GET", Path: "/quests/{id}", Summary: "One quest; students only see quests visible to them, its author and admins also get the answer check", Tag: "quests", Response: models.AuthoredQuest{}},
+	{Method: "POST", Path: "/quests/{id}", Summary: "Update a quest (faculty only their own)", Tag: "quests", Roles: facultyRoles, Request: models.UpdateQuestRequest{}, Response: models.AuthoredQuest{}},
+	{Method: "DELETE", Path: "/quests/{id}", Summary: "Archive a quest (faculty only their own)", Tag: "quests", Roles: facultyRoles, Response: models.AuthoredQuest{}},
+	{Method: "POST", Path: "/quests/{id}/complete", Summary: "Answer and complete a quest, awarding coins once; 409 if already completed or out of attempts, 429 during the cooldown", Tag: "quests", Request: models.CompleteQuestRequest{}, Response: models.CompleteQuestResponse{}},
+	{Method: "GET", Path: "/leaderboard", Summary: "Coin leaderboard with dense ranks and the caller's own position", Tag: "quests", Query: pageParams("rank", leaderboardWindow, leaderboardScope, Param{Name: "role", Description: "student (default), faculty, admin or all"}), Response: models.LeaderboardResponse{}},
+	{Method: "GET", Path: "/leaderboard/history", Summary: "Snapshots of closed leaderboard periods, newest first", Tag: "quests", Query: pageParams("start", Param{Name: "window", Description: "week (default), month or term"}, leaderboardScope), Response: models.LeaderboardSnapshotListResponse{}},
+
+	{Method: "GET", Path: "/polls", Summary: "List campus polls", Tag: "polls", Query: pageParams("id, question", Param{Name: "q", Description: "Question text contains"}), Response: models.PollListResponse{}},
+	{Method: "POST", Path: "/polls/{id}/vote", Summary: "Vote on a poll option; 409 if the poll is closed or the user already voted", Tag: "polls", Request: models.VotePollRequest{}, Response: models.SuccessResponse{}},
+
+	{Method: "GET", Path: "/research/posts", Summary: "Research feed for the viewer", Tag: "research", Query: pageParams("created_at, likes, comments", Param{Name: "tag"}, Param{Name: "category"}, Param{Name: "author", Description: "Author user ID or name fragment"}), Response: models.ResearchFeedResponse{}},
+	{Method: "POST", Path: "/research/posts", Summary: "Publish a research update", Tag: "research", Request: models.CreateResearchPostRequest{}, Response: models.ResearchPostResponse{}, Status: http.StatusCreated},
+	{Method: "GET", Path: "/stream", Summary: "Server-Sent Events stream of live updates (text/event-stream)", Tag: "live", Query: []Param{{Name: "topics", Description: "Comma-separated: leaderboard, research, poll:{id}, faculty:{id}"}, {Name: "access_token", Description: "JWT for EventSource clients that cannot send Authorization"}, {Name: "last_event_id", Description: "Resume after this event ID (the Last-Event-ID header also works)"}}},
+
+	{Method: "GET", Path: "/student/dashboard", Summary: "Student dashboard (ETag; 304 for a matching If-None-Match)", Tag: "student", Roles: []string{common.RoleStudent, common.RoleAdmin}, Query: []Param{{Name: "user_id", Type: "integer", Description: "Admin only: view another student's dashboard"}}, Response: models.StudentDashboardResponse{}},
+	{Method: "GET", Path: "/admin/overview", Summary: "Platform totals and recent activity", Tag: "admin", Roles: []string{common.RoleAdmin}, Response: models.AdminOverviewResponse{}},
+	{Method: "GET", Path: "/admin/activity", Summary: "Quest completion activity", Tag: "admin", Roles: []string{common.RoleAdmin}, Query: pageParams("completed_at", Param{Name: "user_id", Type: "integer"}, Param{Name: "quest_id", Type: "integer"}), Response: models.AdminActivityListResponse{}},
+	{Method: "GET", Path: "/admin/api-usage", Summary: "Request counts per API version", Tag: "admin", Roles: []string{common.RoleAdmin}, Response: apiversion.UsageReport{}},
+	{Method: "GET", Path: "/admin/cache", Summary: "Hit rates of the leaderboard and faculty aggregate caches", Tag: "admin", Roles: []string{common.RoleAdmin}, Response: cache.StatsReport{}},
+	{Method: "GET", Path: "/admin/webhooks", Summary: "List webhook subscriptions", Tag: "webhooks", Roles: adminRoles, Response: webhooks.SubscriptionList{}},
+	{Method: "POST", Path: "/admin/webhooks", Summary: "Create a webhook subscription (response includes the signing secret)", Tag: "webhooks", Roles: adminRoles, Request: models.CreateWebhookRequest{}, Response: webhooks.CreatedSubscription{}, Status: http.StatusCreated},
+	{Method: "POST", Path: "/admin/webhooks/{id}", Summary: "Update a webhook subscription", Tag: "webhooks", Roles: adminRoles, Request: models.UpdateWebhookRequest{}, Response: webhooks.Subscription{}},
+	{Method: "DELETE", Path: "/admin/webhooks/{id}", Summary: "Delete a webhook subscription", Tag: "webhooks", Roles: adminRoles, Response: models.SuccessResponse{}},
+	{Method: "GET", Path: "/admin/webhooks/deliveries", Summary: "Webhook delivery log", Tag: "webhooks", Roles: adminRoles, Query: deliveryParams(Param{Name: "status", Description: "pending, sending, delivered or dead"}), Response: webhooks.DeliveryList{}},
+	{Method: "GET", Path: "/admin/webhooks/dead-letters", Summary: "Deliveries that exhausted their retries", Tag: "webhooks", Roles: adminRoles, Query: deliveryParams(), Response: webhooks.DeliveryList{}},
+	{Method: "POST", Path: "/admin/webhooks/deliveries/{id}/replay", Summary: "Requeue a delivery with a fresh retry budget", Tag: "webhooks", Roles: adminRoles, Response: webhooks.Delivery{}, Status: http.StatusAccepted},
+	{Method: "POST", Path: "/admin/users/import", Summary: "Import users from a CSV roster sent as text/csv or a multipart file field", Tag: "users", Roles: adminRoles, Query: []Param{{Name: "dry_run", Type: "boolean", Description: "Validate and report without writing"}, {Name: "invite", Type: "boolean", Description: "Email new users an invitation link (default true)"}}, Response: roster.Report{}},
+	{Method: "GET", Path: "/admin/tenant", Summary: "Configuration of the admin's campus", Tag: "tenants", Roles: adminRoles, Response: tenant.Tenant{}},
+	{Method: "POST", Path: "/admin/tenant", Summary: "Update campus branding, AI settings and gamification rules", Tag: "tenants", Roles: adminRoles, Request: models.UpdateTenantRequest{}, Response: tenant.Tenant{}},
+	{Method: "GET", Path: "/admin/audit", Summary: "Audit trail of mutating requests", Tag: "audit", Roles: adminRoles, Query: pageParams("seq, at", auditParams...), Response: audit.EntryList{}},
+	{Method: "GET", Path: "/admin/audit/export", Summary: "Audit entries matching the filters as CSV (text/csv), oldest first", Tag: "audit", Roles: adminRoles, Query: auditParams},
+	{Method: "GET", Path: "/admin/audit/verify", Summary: "Check the campus's audit hash chain", Tag: "audit", Roles: adminRoles, Response: audit.Verification{}},
+
+	{Method: "GET", Path: "/admin/flags", Summary: "All feature flags (default campus admins only)", Tag: "flags", Roles: adminRoles, Response: flags.FlagList{}},
+	{Method: "POST", Path: "/admin/flags", Summary: "Create a feature flag", Tag: "flags", Roles: adminRoles, Request: models.CreateFlagRequest{}, Response: flags.Flag{}, Status: http.StatusCreated},
+	{Method: "GET", Path: "/admin/flags/{key}", Summary: "One feature flag", Tag: "flags", Roles: adminRoles, Response: flags.Flag{}},
+	{Method: "POST", Path: "/admin/flags/{key}", Summary: "Update a feature flag's targeting", Tag: "flags", Roles: adminRoles, Request: models.UpdateFlagRequest{}, Response: flags.Flag{}},
+	{Method: "DELETE", Path: "/admin/flags/{key}", Summary: "Delete a feature flag", Tag: "flags", Roles: adminRoles, Response: models.SuccessResponse{}},
+
+	{Method: "GET", Path: "/admin/jobs", Summary: "Scheduled jobs with their state and last run (default campus admins only)", Tag: "jobs", Roles: adminRoles, Response: jobs.JobList{}},
+	{Method: "GET", Path: "/admin/jobs/{name}/runs", Summary: "Run history for a job", Tag: "jobs", Roles: adminRoles, Query: pageParams("started_at"), Response: jobs.RunList{}},
+	{Method: "POST", Path: "/admin/jobs/{name}/run", Summary: "Run a job now", Tag: "jobs", Roles: adminRoles, Response: jobs.Run{}, Status: http.StatusAccepted},
+	{Method: "POST", Path: "/admin/jobs/{name}/pause", Summary: "Pause a job's schedule", Tag: "jobs", Roles: adminRoles, Response: jobs.State{}},
+	{Method: "POST", Path: "/admin/jobs/{name}/resume", Summary: "Resume a paused job", Tag: "jobs", Roles: adminRoles, Response: jobs.State{}},
+
+	{Method: "GET", Path: "/faculty/overview", Summary: "Faculty dashboard overview (ETag; 304 for a matching If-None-Match)", Tag: "faculty", Roles: facultyRoles, Query: facultyQuery, Response: models.FacultyOverviewResponse{}},
+	{Method: "GET", Path: "/faculty/dashboard", Summary: "Faculty dashboard overview (alias)", Tag: "faculty", Roles: facultyRoles, Query: facultyQuery, Response: models.FacultyOverviewResponse{}},
+	{Method: "POST", Path: "/faculty/dashboard/ai/{id}/review", Summary: "Review an AI grading suggestion", Tag: "faculty", Roles: facultyRoles, Query: facultyQuery, Request: models.ReviewAISuggestionRequest{}, Response: models.FacultyOverviewResponse{}},
+	{Method: "POST", Path: "/faculty/dashboard/mentorship", Summary: "Add a mentee", Tag: "faculty", Roles: facultyRoles, Query: facultyQuery, Request: models.AddMenteeRequest{}, Response: models.FacultyOverviewResponse{}},
+	{Method: "POST", Path: "/faculty/dashboard/mentorship/{id}/status", Summary: "Update a mentee", Tag: "faculty", Roles: facultyRoles, Query: facultyQuery, Request: models.UpdateMenteeRequest{}, Response: models.FacultyOverviewResponse{}},
+	{Method: "POST", Path: "/faculty/dashboard/courses", Summary: "Add a course card", Tag: "faculty", Roles: facultyRoles, Query: facultyQuery, Request: models.AddCourseRequest{}, Response: models.FacultyOverviewResponse{}},
+	{Method: "POST", Path: "/faculty/dashboard/courses/{id}/status", Summary: "Update a course card", Tag: "faculty", Roles: facultyRoles, Query: facultyQuery, Request: models.UpdateCourseRequest{}, Response: models.FacultyOverviewResponse{}},
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"backend/events"
+	"backend/handlers/common"
+	"backend/models"
+	"backend/tenant"
+)
+
+func TestVoteConcurrently(t *testing.T) {
+	h := newTestServer(t)
+	ctx := tenant.WithID(context.Background(), testTenantA)
+	if _, err := common.UsersCol.InsertOne(ctx, models.User{UserID: 1, Name: "Student", Role: common.RoleStudent}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := common.PollsCol.InsertOne(ctx, models.Poll{PollID: 1, Question: "Tabs or spaces?", Options: []models.PollOption{{Text: "Tabs"}, {Text: "Spaces"}}}); err != nil {
+		t.Fatal(err)
+	}
+	token := testToken(t, testTenantA, 1, common.RoleStudent)
+
+	const requests = 8
+	codes := make(chan int, requests)
+	var wg sync.WaitGroup
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			codes <- serveJSON(h, http.MethodPost, "/api/v2/polls/1/vote", "", token, `{"option_index":1}`).Code
+		}()
+	}
+	wg.Wait()
+	close(codes)
+	counts := map[int]int{}
+	for code := range codes {
+		counts[code]++
+	}
+	if counts[http.StatusOK] != 1 || counts[http.StatusConflict] != requests-1 {
+		t.Fatalf("status counts = %v, want one 200 and %d 409s", counts, requests-1)
+	}
+
+	var poll models.Poll
+	if err := common.PollsCol.FindOne(ctx, bson.M{"poll_id": 1}).Decode(&poll); err != nil {
+		t.Fatal(err)
+	}
+	if poll.Options[1].Votes != 1 {
+		t.Errorf("tally = %d, want 1", poll.Options[1].Votes)
+	}
+	if n, _ := common.VotesCol.CountDocuments(ctx, bson.M{"user_id": 1}); n != 1 {
+		t.Errorf("votes stored = %d, want 1", n)
+	}
+	if n, _ := common.EventOutboxCol.CountDocuments(ctx, bson.M{"type": events.TypeVoteCast}); n != 1 {
+		t.Errorf("VoteCast events = %d, want 1", n)
+	}
+}
+
+func TestEnsureVoteIndexesRemovesDuplicates(t *testing.T) {
+	newTestServer(t)
+	ctx := context.Background()
+	raw := common.VotesCol.Raw()
+	if _, err := raw.Indexes().DropAll(ctx); err != nil {
+		t.Fatal(err)
+	}
+	// Every vote below was counted, but user 1 keeps only their earliest.
+	if _, err := common.PollsCol.Raw().InsertOne(ctx, bson.M{tenant.Field: testTenantA, "poll_id": 1, "options": bson.A{bson.M{"text": "a", "votes": 3}, bson.M{"text": "b", "votes": 1}}}); err != nil {
+		t.Fatal(err)
+	}
+	at := time.Date(2026, time.March, 2, 9, 0, 0, 0, time.UTC)
+	for _, doc := range []bson.M{
+		{tenant.Field: testTenantA, "user_id": 1, "poll_id": 1, "option_index": 1, "voted_at": at.Add(time.Hour)},
+		{tenant.Field: testTenantA, "user_id": 1, "poll_id": 1, "option_index": 0, "voted_at": at},
+		{tenant.Field: testTenantA, "user_id": 1, "poll_id": 1, "option_index": 0, "voted_at": at.Add(2 * time.Hour)},
+		{tenant.Field: testTenantA, "user_id": 2, "poll_id": 1, "option_index": 0, "voted_at": at},
+	} {
+		if _, err := raw.InsertOne(ctx, doc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := common.EnsureVoteIndexes(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if n, _ := raw.CountDocuments(ctx, bson.M{"user_id": 1}); n != 1 {
+		t.Errorf("user 1 has %d votes, want 1", n)
+	}
+	var poll models.Poll
+	if err := common.PollsCol.Raw().FindOne(ctx, bson.M{"poll_id": 1}).Decode(&poll); err != nil {
+		t.Fatal(err)
+	}
+	if poll.Options[0].Votes != 2 || poll.Options[1].Votes != 0 {
+		t.Errorf("tallies = %+v, want [2 0]", poll.Options)
+	}
+	if _, err := raw.InsertOne(ctx, bson.M{tenant.Field: testTenantA, "user_id": 2, "poll_id": 1, "option_index": 1}); err == nil {
+		t.Error("a second vote was accepted after the index was created")
+	}
+}
@@ -0,0 +1,350 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"backend/answers"
+	"backend/handlers/common"
+	"backend/models"
+	"backend/tenant"
+)
+
+func TestCompleteQuestConcurrently(t *testing.T) {
+	h := newTestServer(t)
+	ctx := tenant.WithID(context.Background(), testTenantA)
+	if _, err := common.UsersCol.InsertOne(ctx, models.User{UserID: 1, Name: "Student", Role: common.RoleStudent}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := common.QuestsCol.InsertOne(ctx, models.Quest{QuestID: 1, Title: "Quest", Coins: 10}); err != nil {
+		t.Fatal(err)
+	}
+	token := testToken(t, testTenantA, 1, common.RoleStudent)
+
+	const requests = 8
+	codes := make(chan int, requests)
+	var wg sync.WaitGroup
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			codes <- serve(h, http.MethodPost, "/api/v2/quests/1/complete", "", token).Code
+		}()
+	}
+	wg.Wait()
+	close(codes)
+	counts := map[int]int{}
+	for code := range codes {
+		counts[code]++
+	}
+	if counts[http.StatusOK] != 1 || counts[http.StatusConflict] != requests-1 {
+		t.Fatalf("status counts = %v, want one 200 and %d 409s", counts, requests-1)
+	}
+
+	var user models.User
+	if err := common.UsersCol.FindOne(ctx, bson.M{"user_id": 1}).Decode(&user); err != nil {
+		t.Fatal(err)
+	}
+	if user.Coins != 10 {
+		t.Errorf("coins = %d, want 10", user.Coins)
+	}
+	if n, _ := common.CoinLedgerCol.CountDocuments(ctx, bson.M{"user_id": 1}); n != 1 {
+		t.Errorf("coin ledger entries = %d, want 1", n)
+	}
+}
+
+func TestCompleteQuestOnce(t *testing.T) {
+	newTestServer(t)
+	ctx := tenant.WithID(context.Background(), testTenantA)
+	at := time.Date(2026, time.March, 2, 9, 0, 0, 0, time.UTC)
+	complete := func(userID int) error {
+		return common.WithTransaction(ctx, func(sc mongo.SessionContext) error {
+			return common.CompleteQuestOnce(sc, userID, 1, at)
+		})
+	}
+
+	if err := complete(1); err != nil {
+		t.Fatalf("first completion: %v", err)
+	}
+	// The second insert hits the unique index; that must read as "already completed".
+	if err := complete(1); !errors.Is(err, common.ErrQuestCompleted) {
+		t.Errorf("second completion: err = %v, want ErrQuestCompleted", err)
+	}
+
+	// A record that was only started is completed in place.
+	if _, err := common.UserQuestsCol.InsertOne(ctx, bson.M{"user_id": 2, "quest_id": 1, "completed": false}); err != nil {
+		t.Fatal(err)
+	}
+	if err := complete(2); err != nil {
+		t.Fatalf("completing a started quest: %v", err)
+	}
+	if n, _ := common.UserQuestsCol.CountDocuments(ctx, bson.M{"user_id": 2, "quest_id": 1, "completed": true}); n != 1 {
+		t.Errorf("user 2 has %d completed records, want 1", n)
+	}
+	if n, _ := common.UserQuestsCol.CountDocuments(ctx, bson.M{"user_id": 2}); n != 1 {
+		t.Errorf("user 2 has %d records, want the started one completed in place", n)
+	}
+}
+
+func TestEnsureQuestIndexesKeepsCompletion(t *testing.T) {
+	newTestServer(t)
+	ctx := context.Background()
+	raw := common.UserQuestsCol.Raw()
+	if _, err := raw.Indexes().DropAll(ctx); err != nil {
+		t.Fatal(err)
+	}
+	at := time.Date(2026, time.March, 2, 9, 0, 0, 0, time.UTC)
+	// The record that was only started sorts first by time and by _id; the completion must win.
+	for _, doc := range []bson.M{
+		{tenant.Field: testTenantA, "user_id": 1, "quest_id": 1, "completed": false},
+		{tenant.Field: testTenantA, "user_id": 1, "quest_id": 1, "completed": true, "completed_at": at},
+		{tenant.Field: testTenantA, "user_id": 1, "quest_id": 1, "completed": true, "completed_at": at.Add(time.Hour)},
+		{tenant.Field: testTenantB, "user_id": 1, "quest_id": 1, "completed": false},
+	} {
+		if _, err := raw.InsertOne(ctx, doc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := common.EnsureQuestIndexes(ctx); err != nil {
+		t.Fatal(err)
+	}
+	var kept []struct {
+		Tenant      string    `bson:"tenant_id"`
+		Completed   bool      `bson:"completed"`
+		CompletedAt time.Time `bson:"completed_at"`
+	}
+	cursor, err := raw.Find(ctx, bson.M{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cursor.All(ctx, &kept); err != nil {
+		t.Fatal(err)
+	}
+	if len(kept) != 2 {
+		t.Fatalf("kept %d records, want one per tenant", len(kept))
+	}
+	for _, k := range kept {
+		if k.Tenant == testTenantA && (!k.Completed || !k.CompletedAt.Equal(at)) {
+			t.Errorf("kept %+v, want the earliest completion", k)
+		}
+	}
+}
+
+func TestQuestAnswers(t *testing.T) {
+	h := newTestServer(t)
+	ctx := tenant.WithID(context.Background(), testTenantA)
+	if _, err := common.UsersCol.InsertOne(ctx, models.User{UserID: 1, Name: "Student", Role: common.RoleStudent}); err != nil {
+		t.Fatal(err)
+	}
+	check := &answers.Spec{Kind: answers.KindNumeric, Value: 42, Feedback: "Think of the ultimate question."}
+	for _, q := range []models.Quest{
+		{QuestID: 1, Title: "Limited", Coins: 10, Check: check, MaxAttempts: 2},
+		{QuestID: 2, Title: "Cooldown", Coins: 10, Check: check, Cooldown: 3600},
+		{QuestID: 3, Title: "Open", Coins: 10, Check: check},
+	} {
+		if _, err := common.QuestsCol.InsertOne(ctx, q); err != nil {
+			t.Fatal(err)
+		}
+	}
+	token := testToken(t, testTenantA, 1, common.RoleStudent)
+	answer := func(questID int, text string) (*httptest.ResponseRecorder, models.CompleteQuestResponse) {
+		w := serveJSON(h, http.MethodPost, fmt.Sprintf("/api/v2/quests/%d/complete", questID), "", token, fmt.Sprintf(`{"answer":{"text":%q}}`, text))
+		var resp models.CompleteQuestResponse
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return w, resp
+	}
+
+	t.Run("wrong answers use up attempts", func(t *testing.T) {
+		for left := 1; left >= 0; left-- {
+			w, resp := answer(1, "41")
+			if w.Code != http.StatusOK || resp.Success || resp.Result == nil || resp.Result.Correct {
+				t.Fatalf("status %d, %+v; want a wrong answer", w.Code, resp)
+			}
+			if resp.Feedback != check.Feedback || resp.AttemptsLeft == nil || *resp.AttemptsLeft != left {
+				t.Errorf("feedback %q, attempts left %v; want %d", resp.Feedback, resp.AttemptsLeft, left)
+			}
+			if strings.Contains(w.Body.String(), "42") {
+				t.Errorf("response leaks the solution: %s", w.Body)
+			}
+		}
+		if w, _ := answer(1, "42"); w.Code != http.StatusConflict {
+			t.Errorf("answer past the limit: status %d, want 409", w.Code)
+		}
+	})
+
+	t.Run("cooldown after a wrong answer", func(t *testing.T) {
+		w, resp := answer(2, "41")
+		if w.Code != http.StatusOK || resp.RetryAt == nil {
+			t.Fatalf("status %d, %+v; want a wrong answer with retryAt", w.Code, resp)
+		}
+		w, _ = answer(2, "42")
+		if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") == "" {
+			t.Errorf("answer within the cooldown: status %d, Retry-After %q; want 429 with Retry-After", w.Code, w.Header().Get("Retry-After"))
+		}
+	})
+
+	t.Run("right answer completes", func(t *testing.T) {
+		if w, resp := answer(3, "4x"); w.Code != http.StatusUnprocessableEntity {
+			t.Errorf("malformed answer: status %d, %+v; want 422", w.Code, resp)
+		}
+		w, resp := answer(3, "42.0")
+		if w.Code != http.StatusOK || !resp.Success || resp.Coins != 10 {
+			t.Fatalf("status %d, %+v; want the quest completed", w.Code, resp)
+		}
+		if n, _ := common.QuestAttemptsCol.CountDocuments(ctx, bson.M{"user_id": 1, "quest_id": 3, "correct": true}); n != 1 {
+			t.Errorf("stored %d correct attempts, want 1", n)
+		}
+		if w, _ := answer(3, "42"); w.Code != http.StatusConflict {
+			t.Errorf("answer after completion: status %d, want 409", w.Code)
+		}
+	})
+}
+
+func TestGetQuestShowsCheckToItsAuthor(t *testing.T) {
+	h := newTestServer(t)
+	ctx := tenant.WithID(context.Background(), testTenantA)
+	q := models.Quest{QuestID: 1, Title: "Quest", CreatedBy: 2, Check: &answers.Spec{Kind: answers.KindNumeric, Value: 42}}
+	if _, err := common.QuestsCol.InsertOne(ctx, q); err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range []struct {
+		name   string
+		userID int
+		role   string
+		check  bool
+	}{
+		{"author", 2, common.RoleFaculty, true},
+		{"other faculty", 3, common.RoleFaculty, false},
+		{"admin", 4, common.RoleAdmin, true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(h, http.MethodGet, "/api/v2/quests/1", "", testToken(t, testTenantA, tt.userID, tt.role))
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d: %s", w.Code, w.Body)
+			}
+			var got models.AuthoredQuest
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			if (got.Check != nil) != tt.check {
+				t.Errorf("check = %+v, want shown: %v", got.Check, tt.check)
+			}
+		})
+	}
+}
+
+func TestQuestAuthoringLifecycle(t *testing.T) {
+	h := newTestServer(t)
+	ctx := tenant.WithID(context.Background(), testTenantA)
+	if _, err := common.UsersCol.InsertOne(ctx, models.User{UserID: 1, Name: "Student", Role: common.RoleStudent}); err != nil {
+		t.Fatal(err)
+	}
+	author := testToken(t, testTenantA, 10, common.RoleFaculty)
+	otherFaculty := testToken(t, testTenantA, 11, common.RoleFaculty)
+	admin := testToken(t, testTenantA, 12, common.RoleAdmin)
+	student := testToken(t, testTenantA, 1, common.RoleStudent)
+
+	status := func(w *httptest.ResponseRecorder) string {
+		t.Helper()
+		var q models.AuthoredQuest
+		if err := json.Unmarshal(w.Body.Bytes(), &q); err != nil {
+			t.Fatalf("%s: %v", w.Body, err)
+		}
+		return q.Status
+	}
+	studentSees := func(id int) bool {
+		t.Helper()
+		w := serve(h, http.MethodGet, fmt.Sprintf("/api/v2/quests/%d", id), "", student)
+		var list models.QuestListResponse
+		if err := json.Unmarshal(serve(h, http.MethodGet, "/api/v2/quests", "", student).Body.Bytes(), &list); err != nil {
+			t.Fatal(err)
+		}
+		listed := false
+		for _, q := range list.Items {
+			listed = listed || q.QuestID == id
+		}
+		if (w.Code == http.StatusOK) != listed {
+			t.Errorf("GET quest %d = %d but listed = %v", id, w.Code, listed)
+		}
+		return listed
+	}
+
+	w := serveJSON(h, http.MethodPost, "/api/v2/quests", "", author, `{"title":"Read a paper","difficulty":"easy","coins":20}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: status = %d: %s", w.Code, w.Body)
+	}
+	var created models.AuthoredQuest
+	if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil {
+		t.Fatal(err)
+	}
+	if created.Status != common.QuestDraft || created.CreatedBy != 10 {
+		t.Fatalf("created quest = %+v, want a draft by its author", created)
+	}
+	path := fmt.Sprintf("/api/v2/quests/%d", created.QuestID)
+	if studentSees(created.QuestID) {
+		t.Error("students see a draft")
+	}
+	if w := serve(h, http.MethodGet, "/api/v2/quests?status=draft", "", author); !strings.Contains(w.Body.String(), `"Read a paper"`) {
+		t.Errorf("draft missing from the faculty catalog: %s", w.Body)
+	}
+
+	// Only the author and admins may change it.
+	if w := serveJSON(h, http.MethodPost, path, "", otherFaculty, `{"status":"active"}`); w.Code != http.StatusForbidden {
+		t.Errorf("other faculty update: status = %d", w.Code)
+	}
+	if w := serve(h, http.MethodDelete, path, "", otherFaculty); w.Code != http.StatusForbidden {
+		t.Errorf("other faculty archive: status = %d", w.Code)
+	}
+	if w := serveJSON(h, http.MethodPost, path, "", student, `{"status":"active"}`); w.Code != http.StatusForbidden {
+		t.Errorf("student update: status = %d", w.Code)
+	}
+	if w := serveJSON(h, http.MethodPost, path, "", author, `{"status":"published"}`); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("unknown status: status = %d", w.Code)
+	}
+
+	w = serveJSON(h, http.MethodPost, path, "", author, `{"status":"active","title":"Read two papers"}`)
+	if w.Code != http.StatusOK || status(w) != common.QuestActive {
+		t.Fatalf("publish: status = %d: %s", w.Code, w.Body)
+	}
+	if !studentSees(created.QuestID) {
+		t.Error("students do not see a published quest")
+	}
+
+	w = serve(h, http.MethodDelete, path, "", author)
+	if w.Code != http.StatusOK || status(w) != common.QuestArchived {
+		t.Fatalf("archive: status = %d: %s", w.Code, w.Body)
+	}
+	if studentSees(created.QuestID) {
+		t.Error("students see an archived quest")
+	}
+	if w := serve(h, http.MethodGet, path, "", otherFaculty); w.Code != http.StatusOK {
+		t.Errorf("faculty cannot read an archived quest: status = %d", w.Code)
+	}
+
+	// Admins may restore any quest.
+	w = serveJSON(h, http.MethodPost, path, "", admin, `{"status":"active"}`)
+	if w.Code != http.StatusOK || status(w) != common.QuestActive {
+		t.Fatalf("restore: status = %d: %s", w.Code, w.Body)
+	}
+	var stored models.Quest
+	if err := common.QuestsCol.FindOne(ctx, bson.M{"quest_id": created.QuestID}).Decode(&stored); err != nil {
+		t.Fatal(err)
+	}
+	if stored.Title != "Read two papers" || stored.CreatedBy != 10 || !studentSees(created.QuestID) {
+		t.Errorf("restored quest = %+v", stored)
+	}
+	if w := serve(h, http.MethodDelete, "/api/v2/quests/999", "", admin); w.Code != http.StatusNotFound {
+		t.Errorf("archive a missing quest: status = %d", w.Code)
+	}
+}
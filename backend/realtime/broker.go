@@ -0,0 +1,194 @@
+// Package realtime fans live updates out to Server-Sent Events clients. Messages are
+// written to the live_events collection and every backend instance tails it with a change
+// stream, so a client connected to any instance sees updates published by all of them.
+// Messages are retained for a short window so reconnecting clients can resume from their
+// Last-Event-ID.
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/tenant"
+)
+
+const (
+	DefaultRetention = time.Hour
+	subscriberBuffer = 64
+	maxReplay        = 500
+)
+
+// Message is one event on a topic. Data is the JSON sent as the SSE data field.
+type Message struct {
+	ID        primitive.ObjectID `bson:"_id"`
+	Tenant    string             `bson:"tenant_id,omitempty"`
+	Topic     string             `bson:"topic"`
+	Event     string             `bson:"event"`
+	Data      string             `bson:"data"`
+	CreatedAt time.Time          `bson:"created_at"`
+}
+
+// Broker publishes messages and delivers them to local subscribers.
+type Broker struct {
+	Col       *mongo.Collection
+	Retention time.Duration
+
+	mu   sync.RWMutex
+	subs map[*Subscription]struct{}
+
+	// since is Since, replaceable in tests that run without MongoDB.
+	since func(ctx context.Context, tenantID string, after primitive.ObjectID, topics []string) ([]Message, error)
+}
+
+// Subscription receives messages for its topics on C. C is closed when the subscriber
+// falls too far behind or is unsubscribed; clients then reconnect with Last-Event-ID.
+type Subscription struct {
+	C       chan Message
+	tenant  string
+	topics  map[string]bool
+	once    sync.Once
+	dropped atomic.Bool
+}
+
+func (s *Subscription) close() {
+	s.once.Do(func() {
+		s.dropped.Store(true)
+		close(s.C)
+	})
+}
+
+// NewBroker returns a broker backed by col.
+func NewBroker(col *mongo.Collection) *Broker {
+	b := &Broker{Col: col, Retention: DefaultRetention, subs: map[*Subscription]struct{}{}}
+	b.since = b.Since
+	return b
+}
+
+// EnsureIndexes creates the retention TTL index and the replay index.
+func (b *Broker) EnsureIndexes(ctx context.Context) error {
+	retention := b.Retention
+	if retention <= 0 {
+		retention = DefaultRetention
+	}
+	_, err := b.Col.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.M{"created_at": 1}, Options: options.Index().SetExpireAfterSeconds(int32(retention / time.Second))},
+		{Keys: bson.D{{Key: "tenant_id", Value: 1}, {Key: "topic", Value: 1}, {Key: "_id", Value: 1}}},
+	})
+	return err
+}
+
+// Publish stores a message for the tenant in ctx; every instance's Run loop delivers it to
+// that tenant's matching subscribers.
+func (b *Broker) Publish(ctx context.Context, topic, event string, data interface{}) error {
+	tenantID, ok := tenant.ID(ctx)
+	if !ok {
+		return tenant.ErrNoTenant
+	}
+	body, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	_, err = b.Col.InsertOne(ctx, Message{ID: primitive.NewObjectID(), Tenant: tenantID, Topic: topic, Event: event, Data: string(body), CreatedAt: time.Now().UTC()})
+	return err
+}
+
+// Subscribe registers a local subscriber for topics within tenantID.
+func (b *Broker) Subscribe(tenantID string, topics []string) *Subscription {
+	s := &Subscription{C: make(chan Message, subscriberBuffer), tenant: tenantID, topics: map[string]bool{}}
+	for _, t := range topics {
+		s.topics[t] = true
+	}
+	b.mu.Lock()
+	b.subs[s] = struct{}{}
+	b.mu.Unlock()
+	return s
+}
+
+// Unsubscribe removes s and closes its channel.
+func (b *Broker) Unsubscribe(s *Subscription) {
+	b.mu.Lock()
+	delete(b.subs, s)
+	b.mu.Unlock()
+	s.close()
+}
+
+// Since returns tenantID's retained messages on topics published after the message with ID
+// after, oldest first.
+func (b *Broker) Since(ctx context.Context, tenantID string, after primitive.ObjectID, topics []string) ([]Message, error) {
+	filter := bson.M{"_id": bson.M{"$gt": after}, "tenant_id": tenantID, "topic": bson.M{"$in": topics}}
+	cursor, err := b.Col.Find(ctx, filter, options.Find().SetSort(bson.M{"_id": 1}).SetLimit(maxReplay))
+	if err != nil {
+		return nil, err
+	}
+	msgs := []Message{}
+	err = cursor.All(ctx, &msgs)
+	return msgs, err
+}
+
+// Run tails live_events until ctx is cancelled, reconnecting from the last resume token
+// after errors.
+func (b *Broker) Run(ctx context.Context) {
+	var resume bson.Raw
+	pipeline := mongo.Pipeline{{{Key: "$match", Value: bson.M{"operationType": "insert"}}}}
+	for ctx.Err() == nil {
+		opts := options.ChangeStream()
+		if resume != nil {
+			opts.SetStartAfter(resume)
+		}
+		stream, err := b.Col.Watch(ctx, pipeline, opts)
+		if err != nil {
+			log.Printf("realtime: watch failed: %v", err)
+			sleep(ctx, 2*time.Second)
+			continue
+		}
+		for stream.Next(ctx) {
+			var change struct {
+				Doc Message `bson:"fullDocument"`
+			}
+			if err := stream.Decode(&change); err == nil {
+				b.fanout(change.Doc)
+			}
+			resume = stream.ResumeToken()
+		}
+		if err := stream.Err(); err != nil && !errors.Is(err, context.Canceled) {
+			log.Printf("realtime: change stream ended: %v", err)
+		}
+		_ = stream.Close(context.Background())
+		sleep(ctx, time.Second)
+	}
+}
+
+func (b *Broker) fanout(msg Message) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for s := range b.subs {
+		if msg.Tenant != s.tenant || !s.topics[msg.Topic] || s.dropped.Load() {
+			continue
+		}
+		select {
+		case s.C <- msg:
+		default:
+			// Slow consumer: drop it rather than block everyone else.
+			s.close()
+		}
+	}
+}
+
+func sleep(ctx context.Context, d time.Duration) {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+	case <-t.C:
+	}
+}
@@ -0,0 +1,70 @@
+package realtime
+
+import (
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func message(tenantID, topic string) Message {
+	return Message{ID: primitive.NewObjectID(), Tenant: tenantID, Topic: topic, Event: "test", Data: "{}"}
+}
+
+func TestFanoutFiltersByTenantAndTopic(t *testing.T) {
+	b := NewBroker(nil)
+	match := b.Subscribe("a", []string{"poll:1", TopicLeaderboard})
+	otherTenant := b.Subscribe("b", []string{"poll:1"})
+	otherTopic := b.Subscribe("a", []string{"poll:2"})
+	gone := b.Subscribe("a", []string{"poll:1"})
+	b.Unsubscribe(gone)
+
+	msgs := []Message{message("a", "poll:1"), message("b", TopicLeaderboard), message("a", TopicLeaderboard), message("", "poll:1")}
+	for _, m := range msgs {
+		b.fanout(m)
+	}
+
+	for _, want := range []Message{msgs[0], msgs[2]} {
+		if got := <-match.C; got.ID != want.ID {
+			t.Errorf("matching subscriber got %v, want %v", got, want)
+		}
+	}
+	if n := len(match.C); n != 0 {
+		t.Errorf("matching subscriber has %d extra messages", n)
+	}
+	if n := len(otherTenant.C); n != 0 {
+		t.Errorf("subscriber in another tenant got %d messages", n)
+	}
+	if n := len(otherTopic.C); n != 0 {
+		t.Errorf("subscriber to another topic got %d messages", n)
+	}
+	if _, ok := <-gone.C; ok {
+		t.Error("unsubscribed channel is still open")
+	}
+}
+
+func TestFanoutDropsSlowConsumers(t *testing.T) {
+	b := NewBroker(nil)
+	slow := b.Subscribe("a", []string{"poll:1"})
+	fast := b.Subscribe("a", []string{"poll:1"})
+	for i := 0; i < subscriberBuffer+1; i++ {
+		b.fanout(message("a", "poll:1"))
+		<-fast.C
+	}
+	// Once dropped, the slow subscriber is skipped instead of sent to after close.
+	b.fanout(message("a", "poll:1"))
+	<-fast.C
+
+	if !slow.dropped.Load() {
+		t.Fatal("slow subscriber was not dropped")
+	}
+	n := 0
+	for range slow.C {
+		n++
+	}
+	if n != subscriberBuffer {
+		t.Errorf("slow subscriber drained %d buffered messages, want %d", n, subscriberBuffer)
+	}
+	if fast.dropped.Load() {
+		t.Error("fast subscriber was dropped")
+	}
+}
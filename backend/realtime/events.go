@@ -0,0 +1,60 @@
+package realtime
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"backend/events"
+)
+
+// Topics clients can subscribe to.
+const (
+	TopicLeaderboard = "leaderboard"
+	TopicResearch    = "research"
+)
+
+func PollTopic(pollID int) string       { return "poll:" + strconv.Itoa(pollID) }
+func FacultyTopic(facultyID int) string { return "faculty:" + strconv.Itoa(facultyID) }
+
+// ParseTopic splits a topic into its kind and numeric ID (0 for leaderboard/research).
+func ParseTopic(topic string) (kind string, id int, err error) {
+	switch topic {
+	case TopicLeaderboard, TopicResearch:
+		return topic, 0, nil
+	}
+	kind, raw, found := strings.Cut(topic, ":")
+	if found && (kind == "poll" || kind == "faculty") {
+		if id, err := strconv.Atoi(raw); err == nil && id > 0 {
+			return kind, id, nil
+		}
+	}
+	return "", 0, fmt.Errorf("unknown topic %q", topic)
+}
+
+// Subscribe publishes a live message for each domain event clients can watch.
+func Subscribe(bus *events.Bus, broker *Broker) {
+	bus.Subscribe("realtime", func(ctx context.Context, env events.Envelope) error {
+		switch e := env.Event.(type) {
+		case events.VoteCast:
+			return broker.Publish(ctx, PollTopic(e.PollID), "poll.vote", map[string]interface{}{"pollId": e.PollID, "optionIndex": e.OptionIndex, "delta": 1})
+		case events.QuestCompleted:
+			return broker.Publish(ctx, TopicLeaderboard, "leaderboard.coins", map[string]interface{}{"userId": e.UserID, "questId": e.QuestID, "coinsDelta": e.Coins})
+		case events.LevelUp:
+			if e.Coins == 0 {
+				return nil
+			}
+			return broker.Publish(ctx, TopicLeaderboard, "leaderboard.coins", map[string]interface{}{"userId": e.UserID, "coinsDelta": e.Coins})
+		case events.ResearchPostCreated:
+			return broker.Publish(ctx, TopicResearch, "research.post_created", map[string]interface{}{"id": e.PostID.Hex(), "authorId": e.AuthorID, "title": e.Title, "category": e.Category, "tags": e.Tags, "createdAt": e.CreatedAt})
+		case events.MenteeAdded:
+			return broker.Publish(ctx, FacultyTopic(e.FacultyID), "faculty.mentee_added", map[string]interface{}{"menteeId": e.MenteeID.Hex(), "name": e.Name, "status": e.Status})
+		case events.AISuggestionReviewed:
+			return broker.Publish(ctx, FacultyTopic(e.FacultyID), "faculty.ai_reviewed", map[string]interface{}{"suggestionId": e.SuggestionID.Hex(), "status": e.Status})
+		case events.FacultyDashboardEdited:
+			return broker.Publish(ctx, FacultyTopic(e.FacultyID), "faculty.edited", map[string]interface{}{"section": e.Section, "itemId": e.ItemID.Hex()})
+		}
+		return nil
+	})
+}
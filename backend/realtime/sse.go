@@ -0,0 +1,91 @@
+package realtime
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"backend/tenant"
+)
+
+const (
+	heartbeatInterval = 25 * time.Second
+	clientRetry       = 3 * time.Second
+)
+
+// Serve streams the request tenant's messages on topics to w as Server-Sent Events until
+// the client goes away. A Last-Event-ID header (or last_event_id query parameter, for clients that
+// reconnect by hand) first replays retained messages published after that ID.
+func (b *Broker) Serve(w http.ResponseWriter, r *http.Request, topics []string) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	tenantID, ok := tenant.ID(r.Context())
+	if !ok {
+		http.Error(w, "no tenant", http.StatusBadRequest)
+		return
+	}
+	// Streams outlive the server's WriteTimeout.
+	_ = http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+	h := w.Header()
+	h.Set("Content-Type", "text/event-stream")
+	h.Set("Cache-Control", "no-cache")
+	h.Set("Connection", "keep-alive")
+	h.Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, "retry: %d\n\n", clientRetry.Milliseconds())
+
+	// Subscribe before replaying so nothing published in between is lost; anything seen
+	// in both is skipped.
+	sub := b.Subscribe(tenantID, topics)
+	defer b.Unsubscribe(sub)
+
+	sent := map[primitive.ObjectID]bool{}
+	lastID := strings.TrimSpace(r.Header.Get("Last-Event-ID"))
+	if lastID == "" {
+		lastID = strings.TrimSpace(r.URL.Query().Get("last_event_id"))
+	}
+	if after, err := primitive.ObjectIDFromHex(lastID); err == nil {
+		missed, err := b.since(r.Context(), tenantID, after, topics)
+		if err != nil {
+			return
+		}
+		for _, msg := range missed {
+			writeMessage(w, msg)
+			sent[msg.ID] = true
+		}
+	}
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": ping\n\n")
+			flusher.Flush()
+		case msg, ok := <-sub.C:
+			if !ok {
+				return
+			}
+			if sent[msg.ID] {
+				delete(sent, msg.ID)
+				continue
+			}
+			writeMessage(w, msg)
+			flusher.Flush()
+		}
+	}
+}
+
+func writeMessage(w http.ResponseWriter, msg Message) {
+	fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", msg.ID.Hex(), msg.Event, msg.Data)
+}
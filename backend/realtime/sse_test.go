@@ -0,0 +1,154 @@
+package realtime
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"backend/tenant"
+)
+
+// stream serves b for tenant "a" on topic poll:1 and opens a client connection to it.
+func stream(t *testing.T, b *Broker, path, lastEventID string) *bufio.Reader {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b.Serve(w, r.WithContext(tenant.WithID(r.Context(), "a")), []string{"poll:1"})
+	}))
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(func() {
+		cancel()
+		srv.Close()
+	})
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, srv.URL+path, nil)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q", ct)
+	}
+	body := bufio.NewReader(resp.Body)
+	if retry := readEvent(t, body); retry["retry"] != "3000" {
+		t.Fatalf("first block = %v, want the retry hint", retry)
+	}
+	return body
+}
+
+// readEvent reads one SSE block into its fields, or returns nil at the end of the stream.
+func readEvent(t *testing.T, r *bufio.Reader) map[string]string {
+	t.Helper()
+	fields := map[string]string{}
+	for {
+		line, err := r.ReadString('\n')
+		if errors.Is(err, io.EOF) && len(fields) == 0 {
+			return nil
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			return fields
+		}
+		name, value, _ := strings.Cut(line, ": ")
+		fields[name] = value
+	}
+}
+
+func TestServeReplaysThenStreamsWithoutDuplicates(t *testing.T) {
+	b := NewBroker(nil)
+	last := primitive.NewObjectID()
+	replayed := []Message{message("a", "poll:1"), message("a", "poll:1")}
+	live := message("a", "poll:1")
+	b.since = func(_ context.Context, tenantID string, after primitive.ObjectID, topics []string) ([]Message, error) {
+		if tenantID != "a" || after != last || len(topics) != 1 || topics[0] != "poll:1" {
+			t.Errorf("Since(%q, %v, %v)", tenantID, after, topics)
+		}
+		// The second replayed message also reached the live subscription while replaying.
+		b.fanout(replayed[1])
+		b.fanout(message("b", "poll:1"))
+		b.fanout(live)
+		return replayed, nil
+	}
+
+	body := stream(t, b, "/", last.Hex())
+	for _, want := range []Message{replayed[0], replayed[1], live} {
+		got := readEvent(t, body)
+		if got["id"] != want.ID.Hex() || got["event"] != "test" || got["data"] != "{}" {
+			t.Fatalf("got event %v, want %s", got, want.ID.Hex())
+		}
+	}
+}
+
+func TestServeLastEventIDQueryParameter(t *testing.T) {
+	b := NewBroker(nil)
+	last := primitive.NewObjectID()
+	replayed := message("a", "poll:1")
+	b.since = func(_ context.Context, _ string, after primitive.ObjectID, _ []string) ([]Message, error) {
+		if after != last {
+			t.Errorf("Since after %v, want %v", after, last)
+		}
+		return []Message{replayed}, nil
+	}
+	body := stream(t, b, "/?last_event_id="+last.Hex(), "")
+	if got := readEvent(t, body); got["id"] != replayed.ID.Hex() {
+		t.Errorf("got event %v, want %s", got, replayed.ID.Hex())
+	}
+}
+
+func TestServeWithoutLastEventIDSkipsReplay(t *testing.T) {
+	b := NewBroker(nil)
+	b.since = func(context.Context, string, primitive.ObjectID, []string) ([]Message, error) {
+		t.Error("Since called without a Last-Event-ID")
+		return nil, nil
+	}
+	body := stream(t, b, "/", "not-an-id")
+	live := message("a", "poll:1")
+	b.fanout(live)
+	if got := readEvent(t, body); got["id"] != live.ID.Hex() {
+		t.Errorf("got event %v, want %s", got, live.ID.Hex())
+	}
+}
+
+func TestServeEndsWhenReplayFails(t *testing.T) {
+	b := NewBroker(nil)
+	b.since = func(context.Context, string, primitive.ObjectID, []string) ([]Message, error) {
+		return nil, errors.New("database down")
+	}
+	body := stream(t, b, "/", primitive.NewObjectID().Hex())
+	if got := readEvent(t, body); got != nil {
+		t.Errorf("got event %v, want the stream to end", got)
+	}
+}
+
+func TestServeEndsWhenDropped(t *testing.T) {
+	b := NewBroker(nil)
+	body := stream(t, b, "/", "")
+	b.mu.RLock()
+	for s := range b.subs {
+		s.close()
+	}
+	b.mu.RUnlock()
+	if got := readEvent(t, body); got != nil {
+		t.Errorf("got event %v, want the stream to end", got)
+	}
+}
+
+func TestServeRequiresTenant(t *testing.T) {
+	w := httptest.NewRecorder()
+	NewBroker(nil).Serve(w, httptest.NewRequest(http.MethodGet, "/", nil), []string{"poll:1"})
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
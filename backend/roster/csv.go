@@ -0,0 +1,182 @@
+// Package roster imports students and faculty in bulk from a CSV roster. Import validates
+// every row first, and a dry run stops there and reports what would change. Otherwise it
+// creates or updates users in the current tenant and emails new users an invitation link
+// to set their password.
+package roster
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/mail"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"backend/models"
+)
+
+const (
+	MaxRows    = 5000
+	MaxBytes   = 5 << 20
+	maxName    = 120
+	maxEmail   = 254
+	maxCourses = 20
+	maxCohort  = 60
+)
+
+// Roles a roster row may assign.
+var Roles = []string{"student", "faculty", "admin"}
+
+// Entry is one parsed roster row. Errors lists everything wrong with it; rows with errors
+// are never written.
+type Entry struct {
+	Line    int
+	Name    string
+	Email   string
+	Role    string
+	Courses []models.CourseProgress
+	Cohort  string
+	Errors  []RowError
+}
+
+// RowError is a problem with one field of a row.
+type RowError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Parse reads a roster with a header row naming at least the name, email and role columns
+// (courses and cohort are optional; other columns are ignored). Courses are separated by semicolons,
+// each either a course ID or "ID:Title". Row-level problems are recorded on the entry, so
+// the returned error is only for unreadable input.
+func Parse(r io.Reader) ([]Entry, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("roster is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read header: %w", err)
+	}
+	cols := map[string]int{}
+	for i, h := range header {
+		cols[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(h, "\ufeff")))] = i
+	}
+	for _, required := range []string{"name", "email", "role"} {
+		if _, ok := cols[required]; !ok {
+			return nil, fmt.Errorf("header is missing the %q column", required)
+		}
+	}
+	cell := func(record []string, name string) string {
+		if i, ok := cols[name]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	entries := []Entry{}
+	firstLine := map[string]int{}
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read roster: %w", err)
+		}
+		line, _ := reader.FieldPos(0)
+		if isBlank(record) {
+			continue
+		}
+		if len(entries) == MaxRows {
+			return nil, fmt.Errorf("roster has more than %d rows", MaxRows)
+		}
+		e := Entry{Line: line, Name: cell(record, "name"), Email: strings.ToLower(cell(record, "email")), Role: strings.ToLower(cell(record, "role")), Cohort: cell(record, "cohort")}
+		e.check()
+		e.Courses = e.parseCourses(cell(record, "courses"))
+		if e.Email != "" {
+			if first, dup := firstLine[e.Email]; dup {
+				e.Errors = append(e.Errors, RowError{Field: "email", Message: fmt.Sprintf("duplicate of line %d", first)})
+			} else {
+				firstLine[e.Email] = line
+			}
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+func (e *Entry) check() {
+	switch n := utf8.RuneCountInString(e.Name); {
+	case n == 0:
+		e.Errors = append(e.Errors, RowError{Field: "name", Message: "is required"})
+	case n > maxName:
+		e.Errors = append(e.Errors, RowError{Field: "name", Message: fmt.Sprintf("must be at most %d characters", maxName)})
+	}
+	switch {
+	case e.Email == "":
+		e.Errors = append(e.Errors, RowError{Field: "email", Message: "is required"})
+	case len(e.Email) > maxEmail || !validEmail(e.Email):
+		e.Errors = append(e.Errors, RowError{Field: "email", Message: "must be a valid email address"})
+	}
+	if utf8.RuneCountInString(e.Cohort) > maxCohort {
+		e.Errors = append(e.Errors, RowError{Field: "cohort", Message: fmt.Sprintf("must be at most %d characters", maxCohort)})
+	}
+	if e.Role == "" {
+		e.Errors = append(e.Errors, RowError{Field: "role", Message: "is required"})
+	} else if !validRole(e.Role) {
+		e.Errors = append(e.Errors, RowError{Field: "role", Message: "must be one of: " + strings.Join(Roles, ", ")})
+	}
+}
+
+func (e *Entry) parseCourses(raw string) []models.CourseProgress {
+	courses := []models.CourseProgress{}
+	seen := map[int]bool{}
+	for _, part := range strings.Split(raw, ";") {
+		if part = strings.TrimSpace(part); part == "" {
+			continue
+		}
+		rawID, title, _ := strings.Cut(part, ":")
+		id, err := strconv.Atoi(strings.TrimSpace(rawID))
+		if err != nil || id <= 0 {
+			e.Errors = append(e.Errors, RowError{Field: "courses", Message: fmt.Sprintf("%q is not a course ID", rawID)})
+			continue
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		courses = append(courses, models.CourseProgress{CourseID: id, Title: strings.TrimSpace(title)})
+	}
+	if len(courses) > maxCourses {
+		e.Errors = append(e.Errors, RowError{Field: "courses", Message: fmt.Sprintf("must list at most %d courses", maxCourses)})
+	}
+	return courses
+}
+
+func validEmail(email string) bool {
+	addr, err := mail.ParseAddress(email)
+	return err == nil && addr.Address == email && strings.Contains(email[strings.LastIndex(email, "@"):], ".")
+}
+
+func validRole(role string) bool {
+	for _, r := range Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+func isBlank(record []string) bool {
+	for _, v := range record {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,153 @@
+package roster
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"backend/models"
+)
+
+func TestParseHeader(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		err   string
+	}{
+		{"empty", "", "roster is empty"},
+		{"missing role", "name,email\nAda,ada@example.edu\n", `missing the "role" column`},
+		{"missing email", "name,role\n", `missing the "email" column`},
+		{"unterminated quote", "name,email,\"role\n", "read header"},
+		{"bare quote in row", "name,email,role\nA\"da,ada@example.edu,student\n", "read roster"},
+		{"unterminated quote in row", "name,email,role\n\"Ada,ada@example.edu,student\n", "read roster"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tt.input))
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("err = %v, want it to mention %q", err, tt.err)
+			}
+		})
+	}
+}
+
+func TestParseColumns(t *testing.T) {
+	input := "\ufeff Role ,NOTES,Email, name ,Courses,cohort\n" +
+		"Student,ignored,ADA@Example.edu,  Ada Lovelace ,101:Intro;102,2026\n"
+	entries, err := Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Entry{{
+		Line:    2,
+		Name:    "Ada Lovelace",
+		Email:   "ada@example.edu",
+		Role:    "student",
+		Courses: []models.CourseProgress{{CourseID: 101, Title: "Intro"}, {CourseID: 102}},
+		Cohort:  "2026",
+	}}
+	if !reflect.DeepEqual(entries, want) {
+		t.Errorf("entries = %+v, want %+v", entries, want)
+	}
+}
+
+func TestParseQuotingAndLines(t *testing.T) {
+	input := "name,email,role\r\n" +
+		"\"Lovelace, Ada\",ada@example.edu,student\r\n" +
+		"\r\n" +
+		" , , \r\n" +
+		"\"Grace \"\"Amazing\"\" Hopper\",grace@example.edu,faculty\r\n" +
+		"\"Alan\nTuring\",alan@example.edu,admin\r\n" +
+		"Edsger,edsger@example.edu\r\n"
+	entries, err := Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		line   int
+		name   string
+		errors []RowError
+	}{
+		{2, "Lovelace, Ada", nil},
+		{5, `Grace "Amazing" Hopper`, nil},
+		{6, "Alan\nTuring", nil},
+		{8, "Edsger", []RowError{{Field: "role", Message: "is required"}}},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("got %d entries, want %d: %+v", len(entries), len(want), entries)
+	}
+	for i, w := range want {
+		e := entries[i]
+		if e.Line != w.line || e.Name != w.name || !reflect.DeepEqual(e.Errors, w.errors) {
+			t.Errorf("entry %d = line %d, name %q, errors %v; want %d, %q, %v", i, e.Line, e.Name, e.Errors, w.line, w.name, w.errors)
+		}
+	}
+}
+
+func TestParseRowErrors(t *testing.T) {
+	long := strings.Repeat("a", maxName+1)
+	var manyCourses []string
+	for i := 1; i <= maxCourses+1; i++ {
+		manyCourses = append(manyCourses, strconv.Itoa(100+i))
+	}
+	tests := []struct {
+		name string
+		row  string
+		want []RowError
+	}{
+		{"valid", "Ada,ada@example.edu,student,,", nil},
+		{"name required", ",ada@example.edu,student,,", []RowError{{"name", "is required"}}},
+		{"name too long", long + ",ada@example.edu,student,,", []RowError{{"name", "must be at most 120 characters"}}},
+		{"email required", "Ada,,student,,", []RowError{{"email", "is required"}}},
+		{"email with display name", "Ada,Ada <ada@example.edu>,student,,", []RowError{{"email", "must be a valid email address"}}},
+		{"email without a dot in the domain", "Ada,ada@localhost,student,,", []RowError{{"email", "must be a valid email address"}}},
+		{"unknown role", "Ada,ada@example.edu,dean,,", []RowError{{"role", "must be one of: student, faculty, admin"}}},
+		{"cohort too long", "Ada,ada@example.edu,student,," + strings.Repeat("c", maxCohort+1), []RowError{{"cohort", "must be at most 60 characters"}}},
+		{"bad course ID", "Ada,ada@example.edu,student,101;x:Art;-2,", []RowError{{"courses", `"x" is not a course ID`}, {"courses", `"-2" is not a course ID`}}},
+		{"too many courses", "Ada,ada@example.edu,student," + strings.Join(manyCourses, ";") + ",", []RowError{{"courses", "must list at most 20 courses"}}},
+		{"every problem reported", ",,,,2026", []RowError{{"name", "is required"}, {"email", "is required"}, {"role", "is required"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := Parse(strings.NewReader("name,email,role,courses,cohort\n" + tt.row + "\n"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(entries) != 1 || !reflect.DeepEqual(entries[0].Errors, tt.want) {
+				t.Errorf("errors = %+v, want %+v", entries, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseDuplicatesAndCourses(t *testing.T) {
+	input := "name,email,role,courses\n" +
+		"Ada,ada@example.edu,student,101; 101:Again ;102:Data\n" +
+		"Ada again,ADA@example.edu,student,\n"
+	entries, err := Parse(strings.NewReader(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []models.CourseProgress{{CourseID: 101}, {CourseID: 102, Title: "Data"}}; !reflect.DeepEqual(entries[0].Courses, want) {
+		t.Errorf("courses = %+v, want %+v", entries[0].Courses, want)
+	}
+	if want := []RowError{{"email", "duplicate of line 2"}}; !reflect.DeepEqual(entries[1].Errors, want) {
+		t.Errorf("duplicate errors = %+v, want %+v", entries[1].Errors, want)
+	}
+}
+
+func TestParseMaxRows(t *testing.T) {
+	var b strings.Builder
+	b.WriteString("name,email,role\n")
+	for i := 0; i < MaxRows; i++ {
+		b.WriteString("A,a@example.edu,student\n")
+	}
+	if _, err := Parse(strings.NewReader(b.String())); err != nil {
+		t.Fatalf("%d rows: %v", MaxRows, err)
+	}
+	b.WriteString("A,a@example.edu,student\n")
+	if _, err := Parse(strings.NewReader(b.String())); err == nil {
+		t.Errorf("%d rows were accepted", MaxRows+1)
+	}
+}
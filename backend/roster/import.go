@@ -0,0 +1,208 @@
+package roster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/httpcache"
+	"backend/mailer"
+	"backend/models"
+	"backend/tenant"
+)
+
+// Row actions in an import report.
+const (
+	ActionCreate = "create"
+	ActionUpdate = "update"
+	ActionError  = "error"
+)
+
+// ErrInvalidRows is returned by Import when a non-dry-run roster has rows with errors.
+// Nothing is written in that case.
+var ErrInvalidRows = errors.New("roster has invalid rows")
+
+// Importer writes roster entries to the users collection.
+type Importer struct {
+	Users    *tenant.Collection
+	Counters *mongo.Collection
+	Invites  *Invitations
+	Mailer   mailer.Mailer
+	// Versions, when set, is bumped after users are written so cached dashboards refresh.
+	Versions *httpcache.Versions
+}
+
+// Options control one import.
+type Options struct {
+	DryRun bool
+	// Invite emails newly created users a link to set their password.
+	Invite bool
+	// Campus names the campus in invitation emails.
+	Campus string
+}
+
+// Report describes what an import did, or would do on a dry run, row by row.
+type Report struct {
+	DryRun       bool        `json:"dryRun"`
+	Total        int         `json:"total"`
+	Created      int         `json:"created"`
+	Updated      int         `json:"updated"`
+	Invalid      int         `json:"invalid"`
+	Invited      int         `json:"invited"`
+	InviteFailed int         `json:"inviteFailed"`
+	Rows         []RowResult `json:"rows"`
+}
+
+// RowResult is the outcome for one roster row.
+type RowResult struct {
+	Line   int        `json:"line"`
+	Email  string     `json:"email"`
+	Action string     `json:"action"`
+	UserID int        `json:"userId,omitempty"`
+	Errors []RowError `json:"errors,omitempty"`
+}
+
+// Import plans entries against the users of the tenant in ctx and, unless opts.DryRun,
+// applies the plan. A roster with any invalid row is rejected as a whole with
+// ErrInvalidRows so a fixed file can simply be uploaded again.
+func (im *Importer) Import(ctx context.Context, entries []Entry, opts Options) (Report, error) {
+	report := Report{DryRun: opts.DryRun, Total: len(entries), Rows: make([]RowResult, len(entries))}
+	existing, err := im.existingUsers(ctx, entries)
+	if err != nil {
+		return report, err
+	}
+	for i, e := range entries {
+		row := RowResult{Line: e.Line, Email: e.Email, Errors: e.Errors}
+		switch user, found := existing[e.Email]; {
+		case len(e.Errors) > 0:
+			row.Action = ActionError
+			report.Invalid++
+		case found:
+			row.Action, row.UserID = ActionUpdate, user.UserID
+			report.Updated++
+		default:
+			row.Action = ActionCreate
+			report.Created++
+		}
+		report.Rows[i] = row
+	}
+	if opts.DryRun {
+		return report, nil
+	}
+	if report.Invalid > 0 {
+		return report, ErrInvalidRows
+	}
+
+	ids, err := im.allocateUserIDs(ctx, report.Created)
+	if err != nil {
+		return report, fmt.Errorf("allocate user ids: %w", err)
+	}
+	// Bump even after a partial failure: some rows may already be written.
+	defer im.Versions.Bump(ctx, httpcache.ScopeUsers)
+	now := time.Now().UTC()
+	for i, e := range entries {
+		row := &report.Rows[i]
+		set := bson.M{"name": e.Name, "role": e.Role, "updated_at": now}
+		if len(e.Courses) > 0 {
+			set["active_courses"] = e.Courses
+		}
+		if e.Cohort != "" {
+			set["cohort"] = e.Cohort
+		}
+		if row.Action == ActionUpdate {
+			if _, err := im.Users.UpdateOne(ctx, bson.M{"user_id": row.UserID}, bson.M{"$set": set}); err != nil {
+				return report, fmt.Errorf("line %d: %w", e.Line, err)
+			}
+			continue
+		}
+		row.UserID, ids = ids[0], ids[1:]
+		courses := e.Courses
+		if courses == nil {
+			courses = []models.CourseProgress{}
+		}
+		user := models.User{UserID: row.UserID, Name: e.Name, Email: e.Email, Role: e.Role, ActiveCourses: courses, Cohort: e.Cohort}
+		if _, err := im.Users.InsertOne(ctx, user); err != nil {
+			return report, fmt.Errorf("line %d: %w", e.Line, err)
+		}
+		if opts.Invite {
+			if err := im.invite(ctx, user, opts.Campus); err != nil {
+				log.Printf("roster: invite %s: %v", user.Email, err)
+				report.InviteFailed++
+			} else {
+				report.Invited++
+			}
+		}
+	}
+	return report, nil
+}
+
+func (im *Importer) existingUsers(ctx context.Context, entries []Entry) (map[string]models.User, error) {
+	emails := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if e.Email != "" {
+			emails = append(emails, e.Email)
+		}
+	}
+	found := map[string]models.User{}
+	if len(emails) == 0 {
+		return found, nil
+	}
+	cursor, err := im.Users.Find(ctx, bson.M{"email": bson.M{"$in": emails}}, options.Find().SetProjection(bson.M{"user_id": 1, "email": 1}))
+	if err != nil {
+		return nil, err
+	}
+	var users []models.User
+	if err := cursor.All(ctx, &users); err != nil {
+		return nil, err
+	}
+	for _, u := range users {
+		found[u.Email] = u
+	}
+	return found, nil
+}
+
+// allocateUserIDs reserves n consecutive user IDs. IDs are unique across tenants so tokens,
+// events and webhooks never need a tenant to disambiguate a user. The counter starts above
+// the highest user_id already stored, which covers seeded and hand-created users.
+func (im *Importer) allocateUserIDs(ctx context.Context, n int) ([]int, error) {
+	if n == 0 {
+		return nil, nil
+	}
+	var highest struct {
+		UserID int `bson:"user_id"`
+	}
+	err := im.Users.Raw().FindOne(ctx, bson.M{}, options.FindOne().SetSort(bson.M{"user_id": -1}).SetProjection(bson.M{"user_id": 1})).Decode(&highest)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, err
+	}
+	if _, err := im.Counters.UpdateOne(ctx, bson.M{"_id": "user_id"}, bson.M{"$max": bson.M{"seq": highest.UserID}}, options.Update().SetUpsert(true)); err != nil {
+		return nil, err
+	}
+	var counter struct {
+		Seq int `bson:"seq"`
+	}
+	err = im.Counters.FindOneAndUpdate(ctx, bson.M{"_id": "user_id"}, bson.M{"$inc": bson.M{"seq": n}}, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&counter)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]int, n)
+	for i := range ids {
+		ids[i] = counter.Seq - n + 1 + i
+	}
+	return ids, nil
+}
+
+func (im *Importer) invite(ctx context.Context, user models.User, campus string) error {
+	link, err := im.Invites.Create(ctx, user)
+	if err != nil {
+		return err
+	}
+	body := fmt.Sprintf("Hi %s,\n\nYou have been added to %s as a %s. Set your password to sign in:\n\n%s\n\nThis link expires in %d days.\n", user.Name, campus, user.Role, link, int(InviteTTL/(24*time.Hour)))
+	return im.Mailer.Send(ctx, mailer.Message{To: user.Email, Subject: "You're invited to " + campus, Body: body})
+}
@@ -0,0 +1,93 @@
+package roster
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/models"
+	"backend/tenant"
+)
+
+// InviteTTL is how long an invitation link stays valid.
+const InviteTTL = 7 * 24 * time.Hour
+
+// ErrInvalidInvite covers unknown, expired and already used invitation tokens alike.
+var ErrInvalidInvite = errors.New("invitation is invalid or has expired")
+
+// Invitation is a one-time password-setup link. Only the SHA-256 of the token is stored.
+type Invitation struct {
+	TokenHash  string     `bson:"_id"`
+	TenantID   string     `bson:"tenant_id"`
+	UserID     int        `bson:"user_id"`
+	Email      string     `bson:"email"`
+	CreatedAt  time.Time  `bson:"created_at"`
+	ExpiresAt  time.Time  `bson:"expires_at"`
+	AcceptedAt *time.Time `bson:"accepted_at,omitempty"`
+}
+
+// Invitations stores invitation tokens; BaseURL is the client page that accepts them.
+type Invitations struct {
+	Col     *tenant.Collection
+	BaseURL string
+}
+
+// EnsureIndexes removes invitations a day after they expire.
+func (s *Invitations) EnsureIndexes(ctx context.Context) error {
+	_, err := s.Col.Raw().Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.M{"expires_at": 1}, Options: options.Index().SetExpireAfterSeconds(int32((24 * time.Hour) / time.Second))})
+	return err
+}
+
+// Create stores a fresh invitation for user in the tenant in ctx and returns its link.
+// Earlier unused invitations for the user stop working.
+func (s *Invitations) Create(ctx context.Context, user models.User) (string, error) {
+	tenantID, ok := tenant.ID(ctx)
+	if !ok {
+		return "", tenant.ErrNoTenant
+	}
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(buf)
+	now := time.Now().UTC()
+	if _, err := s.Col.DeleteMany(ctx, bson.M{"user_id": user.UserID, "accepted_at": nil}); err != nil {
+		return "", err
+	}
+	inv := Invitation{TokenHash: hashToken(token), TenantID: tenantID, UserID: user.UserID, Email: user.Email, CreatedAt: now, ExpiresAt: now.Add(InviteTTL)}
+	if _, err := s.Col.InsertOne(ctx, inv); err != nil {
+		return "", err
+	}
+	return s.BaseURL + "?token=" + url.QueryEscape(token), nil
+}
+
+// Accept marks token used and returns its invitation. The caller sets the password in
+// the invitation's tenant.
+func (s *Invitations) Accept(ctx context.Context, token string) (Invitation, error) {
+	now := time.Now().UTC()
+	var inv Invitation
+	// Tokens are unguessable and globally unique, so the lookup spans tenants; the link
+	// works from any host.
+	err := s.Col.FindOneAndUpdate(tenant.Unscoped(ctx),
+		bson.M{"_id": hashToken(token), "accepted_at": nil, "expires_at": bson.M{"$gt": now}},
+		bson.M{"$set": bson.M{"accepted_at": now}},
+	).Decode(&inv)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return inv, ErrInvalidInvite
+	}
+	return inv, err
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,158 @@
+// Package routes mounts the HTTP API on a router.
+package routes
+
+import (
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	"backend/apiversion"
+	"backend/audit"
+	accountHandlers "backend/handlers/account"
+	adminHandlers "backend/handlers/admin"
+	"backend/handlers/common"
+	facultyHandlers "backend/handlers/faculty"
+	liveHandlers "backend/handlers/live"
+	researchHandlers "backend/handlers/research"
+	studentHandlers "backend/handlers/student"
+	"backend/httpcache"
+	"backend/i18n"
+	"backend/middleware"
+	"backend/models"
+	"backend/openapi"
+)
+
+// apiVersions lists the mounted API generations, current first. The unversioned /api routes
+// are the pre-versioning surface and behave like v1.
+func apiVersions() []apiversion.Version {
+	v1Deprecated := envDate("API_V1_DEPRECATED", time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC))
+	v1Sunset := envDate("API_V1_SUNSET", time.Date(2027, time.June, 30, 0, 0, 0, 0, time.UTC))
+	return []apiversion.Version{
+		{Name: "v2", Prefix: "/v2"},
+		{Name: "v1", Prefix: "/v1", Deprecated: v1Deprecated, Sunset: v1Sunset, Successor: "/api/v2", UnwrapLists: true},
+		{Name: "legacy", Prefix: "", Deprecated: v1Deprecated, Sunset: v1Sunset, Successor: "/api/v2", UnwrapLists: true},
+	}
+}
+
+// Register mounts every API route on r, with bearer tokens signed by jwtSecret. Each route
+// must have a matching entry in openapi.Routes; the openapi tests fail otherwise.
+func Register(r *mux.Router, jwtSecret string) {
+	api := r.PathPrefix("/api").Subrouter()
+	api.Use(httpcache.Compress(httpcache.DefaultMinCompressSize))
+
+	// Unversioned meta routes
+	api.HandleFunc("/health", httpcache.Policy(httpcache.NoStore, func(w http.ResponseWriter, r *http.Request) {
+		common.WriteJSON(w, http.StatusOK, models.HealthResponse{Status: "ok"})
+	})).Methods("GET")
+	api.HandleFunc("/openapi.json", httpcache.Policy(httpcache.Public(time.Hour), openapi.SpecHandler)).Methods("GET")
+	api.HandleFunc("/docs", httpcache.Policy(httpcache.Public(time.Hour), openapi.DocsHandler)).Methods("GET")
+
+	for _, v := range apiVersions() {
+		apiversion.Register(v)
+		mountVersion(api, v, jwtSecret)
+	}
+}
+
+// mountVersion registers the shared handlers under v's prefix. Handlers always produce the
+// current (v2) shape; older versions get response adapters layered on top.
+func mountVersion(api *mux.Router, v apiversion.Version, jwtSecret string) {
+	base := api.PathPrefix(v.Prefix).Subrouter()
+	base.Use(v.Middleware())
+	base.Use(i18n.Middleware(nil))
+	list := func(h http.HandlerFunc) http.HandlerFunc {
+		if v.UnwrapLists {
+			return apiversion.UnwrapList(h)
+		}
+		return h
+	}
+
+	// Public routes
+	base.HandleFunc("/auth/login", common.LoginHandler).Methods("POST")
+	base.HandleFunc("/auth/invitations/accept", common.AcceptInvitationHandler).Methods("POST")
+	base.HandleFunc("/tenant", httpcache.Policy(httpcache.Public(5*time.Minute), common.GetTenantBranding)).Methods("GET")
+	// Protected routes
+	protected := base.PathPrefix("").Subrouter()
+	protected.Use(middleware.NewAuthMiddleware(jwtSecret))
+	protected.Use(i18n.Middleware(middleware.LocaleFromContext))
+	protected.Use(httpcache.Default(httpcache.NoStore))
+	protected.Use(middleware.NewIdempotencyMiddleware(common.MongoIdempotencyStore{Col: common.IdempotencyKeysCol}, common.IdempotencyTTL))
+	protected.Use(audit.Middleware(common.Audit))
+	protected.HandleFunc("/me", common.GetMeHandler).Methods("GET")
+	protected.HandleFunc("/me/flags", httpcache.Policy(httpcache.Private(30*time.Second), common.GetMyFlagsHandler)).Methods("GET")
+	protected.HandleFunc("/me/preferences", common.UpdatePreferencesHandler).Methods("POST")
+	protected.HandleFunc("/me/streak", studentHandlers.GetStreak).Methods("GET")
+	protected.HandleFunc("/me/streak/freezes", studentHandlers.BuyStreakFreeze).Methods("POST")
+	protected.HandleFunc("/me/progress", studentHandlers.GetProgress).Methods("GET")
+	protected.HandleFunc("/me/xp", list(studentHandlers.GetXPLedger)).Methods("GET")
+	protected.HandleFunc("/me/export", accountHandlers.ExportData).Methods("GET")
+	protected.HandleFunc("/me/deletion", accountHandlers.GetDeletion).Methods("GET")
+	protected.HandleFunc("/me/deletion", accountHandlers.RequestDeletion).Methods("POST")
+	protected.HandleFunc("/me/deletion", accountHandlers.CancelDeletion).Methods("DELETE")
+	protected.HandleFunc("/user/{id}", studentHandlers.GetUser).Methods("GET")
+	protected.HandleFunc("/quests", list(studentHandlers.GetQuests)).Methods("GET")
+	protected.HandleFunc("/quests", common.WithRoles(studentHandlers.CreateQuest, common.RoleFaculty, common.RoleAdmin)).Methods("POST")
+	protected.HandleFunc("/quests/today", studentHandlers.GetTodayQuests).Methods("GET")
+	protected.HandleFunc("/quests/assignments", list(studentHandlers.GetQuestAssignments)).Methods("GET")
+	protected.HandleFunc("/quests/{id}", studentHandlers.GetQuest).Methods("GET")
+	protected.HandleFunc("/quests/{id}", common.WithRoles(studentHandlers.UpdateQuest, common.RoleFaculty, common.RoleAdmin)).Methods("POST")
+	protected.HandleFunc("/quests/{id}", common.WithRoles(studentHandlers.ArchiveQuest, common.RoleFaculty, common.RoleAdmin)).Methods("DELETE")
+	protected.HandleFunc("/quests/{id}/complete", studentHandlers.CompleteQuest).Methods("POST")
+	protected.HandleFunc("/leaderboard", list(studentHandlers.GetLeaderboard)).Methods("GET")
+	protected.HandleFunc("/leaderboard/history", list(studentHandlers.GetLeaderboardHistory)).Methods("GET")
+	protected.HandleFunc("/polls", list(studentHandlers.GetPolls)).Methods("GET")
+	protected.HandleFunc("/polls/{id}/vote", studentHandlers.VoteOnPoll).Methods("POST")
+	// Research & AI endpoints (AI not yet reimplemented after refactor; research restored)
+	protected.HandleFunc("/research/posts", researchHandlers.GetPosts).Methods("GET")
+	protected.HandleFunc("/research/posts", researchHandlers.CreatePost).Methods("POST")
+	protected.HandleFunc("/stream", liveHandlers.Stream).Methods("GET")
+	protected.HandleFunc("/student/dashboard", httpcache.Policy(httpcache.Revalidate, common.WithRoles(studentHandlers.GetStudentDashboard, common.RoleStudent, common.RoleAdmin))).Methods("GET")
+	protected.HandleFunc("/admin/overview", common.WithRoles(adminHandlers.GetOverview, common.RoleAdmin)).Methods("GET")
+	protected.HandleFunc("/admin/activity", common.WithRoles(adminHandlers.GetActivity, common.RoleAdmin)).Methods("GET")
+	protected.HandleFunc("/admin/api-usage", common.WithRoles(adminHandlers.GetAPIUsage, common.RoleAdmin)).Methods("GET")
+	protected.HandleFunc("/admin/cache", common.WithRoles(adminHandlers.GetCacheStats, common.RoleAdmin)).Methods("GET")
+	protected.HandleFunc("/admin/webhooks", common.WithRoles(adminHandlers.ListWebhooks, common.RoleAdmin)).Methods("GET")
+	protected.HandleFunc("/admin/webhooks", common.WithRoles(adminHandlers.CreateWebhook, common.RoleAdmin)).Methods("POST")
+	protected.HandleFunc("/admin/webhooks/deliveries", common.WithRoles(adminHandlers.ListWebhookDeliveries, common.RoleAdmin)).Methods("GET")
+	protected.HandleFunc("/admin/webhooks/dead-letters", common.WithRoles(adminHandlers.ListDeadLetters, common.RoleAdmin)).Methods("GET")
+	protected.HandleFunc("/admin/webhooks/deliveries/{id}/replay", common.WithRoles(adminHandlers.ReplayWebhookDelivery, common.RoleAdmin)).Methods("POST")
+	protected.HandleFunc("/admin/webhooks/{id}", common.WithRoles(adminHandlers.UpdateWebhook, common.RoleAdmin)).Methods("POST")
+	protected.HandleFunc("/admin/webhooks/{id}", common.WithRoles(adminHandlers.DeleteWebhook, common.RoleAdmin)).Methods("DELETE")
+	protected.HandleFunc("/admin/users/import", common.WithRoles(adminHandlers.ImportRoster, common.RoleAdmin)).Methods("POST")
+	protected.HandleFunc("/admin/tenant", common.WithRoles(adminHandlers.GetTenant, common.RoleAdmin)).Methods("GET")
+	protected.HandleFunc("/admin/tenant", common.WithRoles(adminHandlers.UpdateTenant, common.RoleAdmin)).Methods("POST")
+	protected.HandleFunc("/admin/audit", common.WithRoles(adminHandlers.ListAuditLog, common.RoleAdmin)).Methods("GET")
+	protected.HandleFunc("/admin/audit/export", common.WithRoles(adminHandlers.ExportAuditLog, common.RoleAdmin)).Methods("GET")
+	protected.HandleFunc("/admin/audit/verify", common.WithRoles(adminHandlers.VerifyAuditLog, common.RoleAdmin)).Methods("GET")
+	protected.HandleFunc("/admin/flags", common.WithRoles(adminHandlers.ListFlags, common.RoleAdmin)).Methods("GET")
+	protected.HandleFunc("/admin/flags", common.WithRoles(adminHandlers.CreateFlag, common.RoleAdmin)).Methods("POST")
+	protected.HandleFunc("/admin/flags/{key}", common.WithRoles(adminHandlers.GetFlag, common.RoleAdmin)).Methods("GET")
+	protected.HandleFunc("/admin/flags/{key}", common.WithRoles(adminHandlers.UpdateFlag, common.RoleAdmin)).Methods("POST")
+	protected.HandleFunc("/admin/flags/{key}", common.WithRoles(adminHandlers.DeleteFlag, common.RoleAdmin)).Methods("DELETE")
+	protected.HandleFunc("/admin/jobs", common.WithRoles(adminHandlers.ListJobs, common.RoleAdmin)).Methods("GET")
+	protected.HandleFunc("/admin/jobs/{name}/runs", common.WithRoles(adminHandlers.ListJobRuns, common.RoleAdmin)).Methods("GET")
+	protected.HandleFunc("/admin/jobs/{name}/run", common.WithRoles(adminHandlers.TriggerJob, common.RoleAdmin)).Methods("POST")
+	protected.HandleFunc("/admin/jobs/{name}/pause", common.WithRoles(adminHandlers.PauseJob, common.RoleAdmin)).Methods("POST")
+	protected.HandleFunc("/admin/jobs/{name}/resume", common.WithRoles(adminHandlers.ResumeJob, common.RoleAdmin)).Methods("POST")
+	protected.HandleFunc("/faculty/overview", httpcache.Policy(httpcache.Revalidate, common.WithRoles(facultyHandlers.GetOverview, common.RoleFaculty, common.RoleAdmin))).Methods("GET")
+	protected.HandleFunc("/faculty/dashboard", httpcache.Policy(httpcache.Revalidate, common.WithRoles(facultyHandlers.GetOverview, common.RoleFaculty, common.RoleAdmin))).Methods("GET")
+	protected.HandleFunc("/faculty/dashboard/ai/{id}/review", common.WithRoles(facultyHandlers.ReviewAISuggestion, common.RoleFaculty, common.RoleAdmin)).Methods("POST")
+	protected.HandleFunc("/faculty/dashboard/mentorship", common.WithRoles(facultyHandlers.AddMentee, common.RoleFaculty, common.RoleAdmin)).Methods("POST")
+	protected.HandleFunc("/faculty/dashboard/mentorship/{id}/status", common.WithRoles(facultyHandlers.UpdateMenteeStatus, common.RoleFaculty, common.RoleAdmin)).Methods("POST")
+	protected.HandleFunc("/faculty/dashboard/courses", common.WithRoles(facultyHandlers.AddCourse, common.RoleFaculty, common.RoleAdmin)).Methods("POST")
+	protected.HandleFunc("/faculty/dashboard/courses/{id}/status", common.WithRoles(facultyHandlers.UpdateCourseStatus, common.RoleFaculty, common.RoleAdmin)).Methods("POST")
+}
+
+func envDate(key string, fallback time.Time) time.Time {
+	raw := strings.TrimSpace(os.Getenv(key))
+	if raw == "" {
+		return fallback
+	}
+	parsed, err := time.Parse("2006-01-02", raw)
+	if err != nil {
+		return fallback
+	}
+	return parsed
+}
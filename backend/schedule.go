@@ -0,0 +1,30 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"backend/handlers/account"
+	"backend/handlers/common"
+	"backend/handlers/faculty"
+	"backend/handlers/student"
+	"backend/jobs"
+)
+
+// registerJobs declares the recurring platform jobs. Schedules are UTC cron expressions;
+// admins can pause, resume or trigger them under /api/v2/admin/jobs. Each run covers every
+// tenant in turn.
+func registerJobs(s *jobs.Scheduler) {
+	s.Register(jobs.Job{Name: "polls.expire", Description: "Close expired polls and refresh their time-left labels", Schedule: "*/5 * * * *", Run: perTenant(student.ExpirePolls)})
+	s.Register(jobs.Job{Name: "quests.rotate", Description: "Draw each student's daily and weekly quests once their local day or week begins", Schedule: "*/30 * * * *", Timeout: 15 * time.Minute, Run: perTenant(student.RotateQuests)})
+	s.Register(jobs.Job{Name: "streaks.break", Description: "Spend streak freezes on missed days or break the streaks they do not cover", Schedule: "10 0 * * *", Run: perTenant(student.BreakStreaks)})
+	s.Register(jobs.Job{Name: "leaderboard.snapshot", Description: "Snapshot the leaderboards of closed weeks, months and terms", Schedule: "5 0 * * *", Timeout: 15 * time.Minute, Run: perTenant(student.SnapshotLeaderboards)})
+	s.Register(jobs.Job{Name: "digest.weekly", Description: "Email students their weekly activity digest", Schedule: "0 8 * * 1", Timeout: 30 * time.Minute, Run: perTenant(student.SendWeeklyDigests)})
+	s.Register(jobs.Job{Name: "faculty.analytics", Description: "Recompute faculty dashboard analytics and pending reviews", Schedule: "30 2 * * *", Run: perTenant(faculty.RecomputeAnalytics)})
+	s.Register(jobs.Job{Name: "accounts.purge", Description: "Delete accounts whose deletion grace period has ended", Schedule: "0 3 * * *", Run: perTenant(account.PurgeDeletedAccounts)})
+}
+
+// perTenant runs a tenant-scoped job once for each tenant.
+func perTenant(run func(ctx context.Context) error) func(ctx context.Context) error {
+	return func(ctx context.Context) error { return common.Tenants.ForEach(ctx, run) }
+}
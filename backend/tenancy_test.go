@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"backend/handlers/common"
+	"backend/models"
+	"backend/tenant"
+)
+
+func TestTenantIsolation(t *testing.T) {
+	h := newTestServer(t)
+	ctx := context.Background()
+	a, b := tenant.WithID(ctx, testTenantA), tenant.WithID(ctx, testTenantB)
+	for _, seed := range []struct {
+		ctx  context.Context
+		poll models.Poll
+	}{
+		{a, models.Poll{PollID: 1, Question: "A's poll"}},
+		{b, models.Poll{PollID: 2, Question: "B's poll"}},
+	} {
+		if _, err := common.PollsCol.InsertOne(seed.ctx, seed.poll); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := common.QuestsCol.InsertOne(b, models.Quest{QuestID: 7, Title: "B's quest"}); err != nil {
+		t.Fatal(err)
+	}
+	studentA := testToken(t, testTenantA, 1, common.RoleStudent)
+	facultyA := testToken(t, testTenantA, 2, common.RoleFaculty)
+	studentB := testToken(t, testTenantB, 1, common.RoleStudent)
+
+	t.Run("lists only the caller's campus", func(t *testing.T) {
+		w := serve(h, http.MethodGet, "/api/v2/polls", "", studentA)
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d: %s", w.Code, w.Body)
+		}
+		var got models.PollListResponse
+		if err := json.NewDecoder(w.Body).Decode(&got); err != nil {
+			t.Fatal(err)
+		}
+		if len(got.Items) != 1 || got.Items[0].PollID != 1 {
+			t.Errorf("polls = %+v, want only poll 1", got.Items)
+		}
+	})
+
+	t.Run("cannot read another campus's record by id", func(t *testing.T) {
+		if w := serve(h, http.MethodGet, "/api/v2/quests/7", "", facultyA); w.Code != http.StatusNotFound {
+			t.Errorf("status = %d, want 404: %s", w.Code, w.Body)
+		}
+		if w := serve(h, http.MethodGet, "/api/v2/quests/7", testHostB, testToken(t, testTenantB, 2, common.RoleFaculty)); w.Code != http.StatusOK {
+			t.Errorf("own campus: status = %d, want 200: %s", w.Code, w.Body)
+		}
+	})
+
+	t.Run("campus host rejects other campuses' tokens", func(t *testing.T) {
+		if w := serve(h, http.MethodGet, "/api/v2/polls", testHostB, studentA); w.Code != http.StatusUnauthorized {
+			t.Errorf("status = %d, want 401", w.Code)
+		}
+		if w := serve(h, http.MethodGet, "/api/v2/polls", testHostB, studentB); w.Code != http.StatusOK {
+			t.Errorf("own campus: status = %d, want 200: %s", w.Code, w.Body)
+		}
+	})
+
+	t.Run("other campuses' admins cannot manage jobs", func(t *testing.T) {
+		adminB := testToken(t, testTenantB, 3, common.RoleAdmin)
+		for _, req := range []struct{ method, path string }{
+			{http.MethodGet, "/api/v2/admin/jobs"},
+			{http.MethodPost, "/api/v2/admin/jobs/polls.expire/run"},
+		} {
+			if w := serve(h, req.method, req.path, testHostB, adminB); w.Code != http.StatusForbidden {
+				t.Errorf("%s %s: status = %d, want 403", req.method, req.path, w.Code)
+			}
+		}
+		if w := serve(h, http.MethodGet, "/api/v2/admin/jobs", "", testToken(t, testTenantA, 3, common.RoleAdmin)); w.Code != http.StatusOK {
+			t.Errorf("default campus admin: status = %d, want 200: %s", w.Code, w.Body)
+		}
+	})
+}
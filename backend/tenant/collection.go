@@ -0,0 +1,235 @@
+package tenant
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Collection is a tenant-scoped view of a Mongo collection. Its methods mirror
+// *mongo.Collection. A scoped context gets tenant_id added to filters, inserted documents
+// and aggregation pipelines. An Unscoped context passes through untouched. Any other
+// context fails with ErrNoTenant. Only the scoped methods are exposed; anything else goes
+// through Raw.
+//
+// $lookup stages inside pipelines are not rewritten; join only on collections whose
+// documents were already filtered to the tenant, or add the tenant condition yourself.
+type Collection struct {
+	col *mongo.Collection
+}
+
+// Wrap returns a tenant-scoped view of col; a nil col stays nil.
+func Wrap(col *mongo.Collection) *Collection {
+	if col == nil {
+		return nil
+	}
+	return &Collection{col: col}
+}
+
+// Raw returns the underlying collection for index management and migrations.
+func (c *Collection) Raw() *mongo.Collection { return c.col }
+
+// Filter returns filter restricted to the tenant in ctx.
+func Filter(ctx context.Context, filter interface{}) (interface{}, error) {
+	if isUnscoped(ctx) {
+		if filter == nil {
+			return bson.M{}, nil
+		}
+		return filter, nil
+	}
+	id, ok := ID(ctx)
+	if !ok {
+		return nil, ErrNoTenant
+	}
+	switch f := filter.(type) {
+	case nil:
+		return bson.M{Field: id}, nil
+	case bson.M:
+		if _, set := f[Field]; !set {
+			scoped := make(bson.M, len(f)+1)
+			for k, v := range f {
+				scoped[k] = v
+			}
+			scoped[Field] = id
+			return scoped, nil
+		}
+	case bson.D:
+		if len(f) == 0 {
+			return bson.M{Field: id}, nil
+		}
+	}
+	return bson.M{"$and": bson.A{bson.M{Field: id}, filter}}, nil
+}
+
+// stamp returns doc as a bson.D carrying the tenant from ctx; a document that names a
+// different tenant is rejected.
+func stamp(ctx context.Context, doc interface{}) (interface{}, error) {
+	if isUnscoped(ctx) {
+		return doc, nil
+	}
+	id, ok := ID(ctx)
+	if !ok {
+		return nil, ErrNoTenant
+	}
+	raw, err := bson.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	var d bson.D
+	if err := bson.Unmarshal(raw, &d); err != nil {
+		return nil, err
+	}
+	for _, e := range d {
+		if e.Key == Field {
+			if e.Value != id {
+				return nil, fmt.Errorf("tenant: document belongs to tenant %v, context is %s", e.Value, id)
+			}
+			return d, nil
+		}
+	}
+	return append(d, bson.E{Key: Field, Value: id}), nil
+}
+
+// pipeline prepends a tenant $match to an aggregation pipeline.
+func pipeline(ctx context.Context, p interface{}) (interface{}, error) {
+	match, err := Filter(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	if isUnscoped(ctx) {
+		return p, nil
+	}
+	stages := bson.A{bson.M{"$match": match}}
+	v := reflect.ValueOf(p)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, fmt.Errorf("tenant: unsupported pipeline type %T", p)
+	}
+	for i := 0; i < v.Len(); i++ {
+		stages = append(stages, v.Index(i).Interface())
+	}
+	return stages, nil
+}
+
+func (c *Collection) Find(ctx context.Context, filter interface{}, opts ...*options.FindOptions) (*mongo.Cursor, error) {
+	f, err := Filter(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return c.col.Find(ctx, f, opts...)
+}
+
+func (c *Collection) FindOne(ctx context.Context, filter interface{}, opts ...*options.FindOneOptions) *mongo.SingleResult {
+	f, err := Filter(ctx, filter)
+	if err != nil {
+		return mongo.NewSingleResultFromDocument(bson.D{}, err, nil)
+	}
+	return c.col.FindOne(ctx, f, opts...)
+}
+
+func (c *Collection) FindOneAndUpdate(ctx context.Context, filter, update interface{}, opts ...*options.FindOneAndUpdateOptions) *mongo.SingleResult {
+	f, err := Filter(ctx, filter)
+	if err != nil {
+		return mongo.NewSingleResultFromDocument(bson.D{}, err, nil)
+	}
+	return c.col.FindOneAndUpdate(ctx, f, update, opts...)
+}
+
+func (c *Collection) FindOneAndDelete(ctx context.Context, filter interface{}, opts ...*options.FindOneAndDeleteOptions) *mongo.SingleResult {
+	f, err := Filter(ctx, filter)
+	if err != nil {
+		return mongo.NewSingleResultFromDocument(bson.D{}, err, nil)
+	}
+	return c.col.FindOneAndDelete(ctx, f, opts...)
+}
+
+func (c *Collection) CountDocuments(ctx context.Context, filter interface{}, opts ...*options.CountOptions) (int64, error) {
+	f, err := Filter(ctx, filter)
+	if err != nil {
+		return 0, err
+	}
+	return c.col.CountDocuments(ctx, f, opts...)
+}
+
+func (c *Collection) Distinct(ctx context.Context, field string, filter interface{}, opts ...*options.DistinctOptions) ([]interface{}, error) {
+	f, err := Filter(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return c.col.Distinct(ctx, field, f, opts...)
+}
+
+func (c *Collection) Aggregate(ctx context.Context, p interface{}, opts ...*options.AggregateOptions) (*mongo.Cursor, error) {
+	scoped, err := pipeline(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	return c.col.Aggregate(ctx, scoped, opts...)
+}
+
+func (c *Collection) InsertOne(ctx context.Context, doc interface{}, opts ...*options.InsertOneOptions) (*mongo.InsertOneResult, error) {
+	d, err := stamp(ctx, doc)
+	if err != nil {
+		return nil, err
+	}
+	return c.col.InsertOne(ctx, d, opts...)
+}
+
+func (c *Collection) InsertMany(ctx context.Context, docs []interface{}, opts ...*options.InsertManyOptions) (*mongo.InsertManyResult, error) {
+	stamped := make([]interface{}, 0, len(docs))
+	for _, doc := range docs {
+		d, err := stamp(ctx, doc)
+		if err != nil {
+			return nil, err
+		}
+		stamped = append(stamped, d)
+	}
+	return c.col.InsertMany(ctx, stamped, opts...)
+}
+
+func (c *Collection) UpdateOne(ctx context.Context, filter, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	f, err := Filter(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return c.col.UpdateOne(ctx, f, update, opts...)
+}
+
+func (c *Collection) UpdateMany(ctx context.Context, filter, update interface{}, opts ...*options.UpdateOptions) (*mongo.UpdateResult, error) {
+	f, err := Filter(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return c.col.UpdateMany(ctx, f, update, opts...)
+}
+
+func (c *Collection) ReplaceOne(ctx context.Context, filter, replacement interface{}, opts ...*options.ReplaceOptions) (*mongo.UpdateResult, error) {
+	f, err := Filter(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	r, err := stamp(ctx, replacement)
+	if err != nil {
+		return nil, err
+	}
+	return c.col.ReplaceOne(ctx, f, r, opts...)
+}
+
+func (c *Collection) DeleteOne(ctx context.Context, filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
+	f, err := Filter(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return c.col.DeleteOne(ctx, f, opts...)
+}
+
+func (c *Collection) DeleteMany(ctx context.Context, filter interface{}, opts ...*options.DeleteOptions) (*mongo.DeleteResult, error) {
+	f, err := Filter(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return c.col.DeleteMany(ctx, f, opts...)
+}
@@ -0,0 +1,167 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func TestFilter(t *testing.T) {
+	a := WithID(context.Background(), "a")
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		filter interface{}
+		want   interface{}
+		err    error
+	}{
+		{"nil filter", a, nil, bson.M{Field: "a"}, nil},
+		{"map filter", a, bson.M{"user_id": 1}, bson.M{"user_id": 1, Field: "a"}, nil},
+		{"map naming another tenant", a, bson.M{Field: "b"}, bson.M{"$and": bson.A{bson.M{Field: "a"}, bson.M{Field: "b"}}}, nil},
+		{"empty document", a, bson.D{}, bson.M{Field: "a"}, nil},
+		{"document", a, bson.D{{Key: "user_id", Value: 1}}, bson.M{"$and": bson.A{bson.M{Field: "a"}, bson.D{{Key: "user_id", Value: 1}}}}, nil},
+		{"no tenant", context.Background(), bson.M{"user_id": 1}, nil, ErrNoTenant},
+		{"unscoped", Unscoped(context.Background()), bson.M{"user_id": 1}, bson.M{"user_id": 1}, nil},
+		{"unscoped nil filter", Unscoped(context.Background()), nil, bson.M{}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Filter(tt.ctx, tt.filter)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("err = %v, want %v", err, tt.err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Filter = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterLeavesCallerMapAlone(t *testing.T) {
+	filter := bson.M{"user_id": 1}
+	if _, err := Filter(WithID(context.Background(), "a"), filter); err != nil {
+		t.Fatal(err)
+	}
+	if _, set := filter[Field]; set {
+		t.Errorf("Filter added %s to the caller's map", Field)
+	}
+}
+
+func TestStamp(t *testing.T) {
+	type doc struct {
+		UserID int    `bson:"user_id"`
+		Tenant string `bson:"tenant_id,omitempty"`
+	}
+	a := WithID(context.Background(), "a")
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		doc     interface{}
+		want    interface{}
+		wantErr bool
+	}{
+		{"struct", a, doc{UserID: 1}, bson.D{{Key: "user_id", Value: int32(1)}, {Key: Field, Value: "a"}}, false},
+		{"map", a, bson.M{"user_id": 1}, bson.D{{Key: "user_id", Value: int32(1)}, {Key: Field, Value: "a"}}, false},
+		{"same tenant", a, doc{UserID: 1, Tenant: "a"}, bson.D{{Key: "user_id", Value: int32(1)}, {Key: Field, Value: "a"}}, false},
+		{"other tenant", a, doc{UserID: 1, Tenant: "b"}, nil, true},
+		{"no tenant", context.Background(), doc{UserID: 1}, nil, true},
+		{"unscoped", Unscoped(context.Background()), doc{UserID: 1}, doc{UserID: 1}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := stamp(tt.ctx, tt.doc)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("stamp = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPipeline(t *testing.T) {
+	a := WithID(context.Background(), "a")
+	group := bson.M{"$group": bson.M{"_id": "$user_id"}}
+	match := bson.M{"$match": bson.M{Field: "a"}}
+	tests := []struct {
+		name     string
+		ctx      context.Context
+		pipeline interface{}
+		want     interface{}
+		err      bool
+	}{
+		{"bson.A", a, bson.A{group}, bson.A{match, group}, false},
+		{"slice of maps", a, []bson.M{group}, bson.A{match, group}, false},
+		{"mongo.Pipeline", a, mongo.Pipeline{{{Key: "$limit", Value: 1}}}, bson.A{match, bson.D{{Key: "$limit", Value: 1}}}, false},
+		{"empty", a, bson.A{}, bson.A{match}, false},
+		{"not a pipeline", a, group, nil, true},
+		{"no tenant", context.Background(), bson.A{group}, nil, true},
+		{"unscoped", Unscoped(context.Background()), bson.A{group}, bson.A{group}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := pipeline(tt.ctx, tt.pipeline)
+			if (err != nil) != tt.err {
+				t.Fatalf("err = %v, want error %v", err, tt.err)
+			}
+			if !tt.err && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("pipeline = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+// Every method must refuse a context without a tenant before it reaches the server, so a
+// handler that forgot to scope its context reads nothing instead of every campus's data.
+func TestCollectionRequiresTenant(t *testing.T) {
+	// Connect is lazy and nothing listens on this port; the calls below must fail first.
+	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI("mongodb://127.0.0.1:1").SetServerSelectionTimeout(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Disconnect(context.Background())
+	col := Wrap(client.Database("test").Collection("users"))
+	ctx := context.Background()
+	calls := map[string]func() error{
+		"Find":    func() error { _, err := col.Find(ctx, bson.M{}); return err },
+		"FindOne": func() error { return col.FindOne(ctx, bson.M{}).Err() },
+		"FindOneAndUpdate": func() error {
+			return col.FindOneAndUpdate(ctx, bson.M{}, bson.M{"$set": bson.M{"x": 1}}).Err()
+		},
+		"FindOneAndDelete": func() error { return col.FindOneAndDelete(ctx, bson.M{}).Err() },
+		"CountDocuments":   func() error { _, err := col.CountDocuments(ctx, bson.M{}); return err },
+		"Distinct":         func() error { _, err := col.Distinct(ctx, "user_id", bson.M{}); return err },
+		"Aggregate":        func() error { _, err := col.Aggregate(ctx, bson.A{}); return err },
+		"InsertOne":        func() error { _, err := col.InsertOne(ctx, bson.M{"x": 1}); return err },
+		"InsertMany":       func() error { _, err := col.InsertMany(ctx, []interface{}{bson.M{"x": 1}}); return err },
+		"UpdateOne": func() error {
+			_, err := col.UpdateOne(ctx, bson.M{}, bson.M{"$set": bson.M{"x": 1}})
+			return err
+		},
+		"UpdateMany": func() error {
+			_, err := col.UpdateMany(ctx, bson.M{}, bson.M{"$set": bson.M{"x": 1}})
+			return err
+		},
+		"ReplaceOne": func() error { _, err := col.ReplaceOne(ctx, bson.M{}, bson.M{"x": 1}); return err },
+		"DeleteOne":  func() error { _, err := col.DeleteOne(ctx, bson.M{}); return err },
+		"DeleteMany": func() error { _, err := col.DeleteMany(ctx, bson.M{}); return err },
+	}
+	for name, call := range calls {
+		if err := call(); !errors.Is(err, ErrNoTenant) {
+			t.Errorf("%s without a tenant: err = %v, want ErrNoTenant", name, err)
+		}
+	}
+	// Every method but Raw must be scoped, and checked here.
+	typ := reflect.TypeOf(col)
+	for i := 0; i < typ.NumMethod(); i++ {
+		if name := typ.Method(i).Name; name != "Raw" && calls[name] == nil {
+			t.Errorf("%s is not checked for tenant scoping", name)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+// Package tenant isolates campuses that share one deployment. Every tenant-owned document
+// carries a tenant_id; Collection wraps a Mongo collection and adds the current tenant to
+// every filter, insert and pipeline, so handlers cannot read or write another campus's
+// data. The tenant travels in the request context, resolved from the host name or the
+// caller's token.
+package tenant
+
+import (
+	"context"
+	"errors"
+)
+
+// Field is the document field holding the owning tenant's ID.
+const Field = "tenant_id"
+
+// ErrNoTenant is returned by scoped collections when the context carries no tenant.
+var ErrNoTenant = errors.New("tenant: no tenant in context")
+
+type ctxKey int
+
+const (
+	keyID ctxKey = iota
+	keyFromHost
+	keyUnscoped
+)
+
+// WithID returns ctx scoped to tenant id.
+func WithID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, keyID, id)
+}
+
+// ID returns the tenant ctx is scoped to.
+func ID(ctx context.Context) (string, bool) {
+	if ctx == nil {
+		return "", false
+	}
+	id, ok := ctx.Value(keyID).(string)
+	return id, ok && id != ""
+}
+
+// withHost marks the tenant in ctx as pinned by the request's host name.
+func withHost(ctx context.Context, id string) context.Context {
+	return context.WithValue(WithID(ctx, id), keyFromHost, true)
+}
+
+// FromHost reports whether the tenant in ctx was pinned by the host name, in which case
+// a token issued for another tenant must be rejected.
+func FromHost(ctx context.Context) bool {
+	pinned, _ := ctx.Value(keyFromHost).(bool)
+	return pinned
+}
+
+// Unscoped returns ctx allowed to query across all tenants. Only background workers that
+// process every tenant's queue (outbox, webhook deliveries) should use it.
+func Unscoped(ctx context.Context) context.Context {
+	return context.WithValue(ctx, keyUnscoped, true)
+}
+
+func isUnscoped(ctx context.Context) bool {
+	unscoped, _ := ctx.Value(keyUnscoped).(bool)
+	return unscoped
+}
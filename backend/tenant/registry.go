@@ -0,0 +1,460 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math"
+	"net"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const cacheTTL = time.Minute
+
+var ErrUnknownTenant = errors.New("unknown tenant")
+
+// Tenant is one campus and its configuration, stored in the tenants collection.
+type Tenant struct {
+	ID           string       `bson:"_id" json:"id"`
+	Name         string       `bson:"name" json:"name"`
+	Hosts        []string     `bson:"hosts" json:"hosts"`
+	Branding     Branding     `bson:"branding" json:"branding"`
+	AI           AISettings   `bson:"ai" json:"ai"`
+	Gamification Gamification `bson:"gamification" json:"gamification"`
+	CreatedAt    time.Time    `bson:"created_at" json:"createdAt"`
+	UpdatedAt    time.Time    `bson:"updated_at" json:"updatedAt"`
+}
+
+// Branding is what clients need to theme the app for a campus.
+type Branding struct {
+	DisplayName  string `bson:"display_name" json:"displayName"`
+	LogoURL      string `bson:"logo_url,omitempty" json:"logoUrl,omitempty"`
+	PrimaryColor string `bson:"primary_color,omitempty" json:"primaryColor,omitempty"`
+	AccentColor  string `bson:"accent_color,omitempty" json:"accentColor,omitempty"`
+}
+
+// AISettings controls the AI Buddy for a campus. An empty Model uses the deployment default.
+type AISettings struct {
+	Enabled bool   `bson:"enabled" json:"enabled"`
+	Model   string `bson:"model,omitempty" json:"model,omitempty"`
+}
+
+// Gamification holds the campus's reward rules.
+type Gamification struct {
+	CoinMultiplier     float64 `bson:"coin_multiplier" json:"coinMultiplier"`
+	LeaderboardEnabled bool    `bson:"leaderboard_enabled" json:"leaderboardEnabled"`
+	// Terms are the academic terms the term leaderboard is computed over.
+	Terms []Term `bson:"terms,omitempty" json:"terms,omitempty"`
+	// Timezone is the IANA zone whose midnight ends a student's quest day unless they chose
+	// their own; empty means UTC.
+	Timezone string      `bson:"timezone,omitempty" json:"timezone,omitempty"`
+	Rotation Rotation    `bson:"rotation" json:"rotation"`
+	Streaks  StreakRules `bson:"streaks" json:"streaks"`
+	Levels   LevelCurve  `bson:"levels" json:"levels"`
+}
+
+// StreakRules decide what keeps a streak going and what streak freezes cost. Empty Actions
+// count every kind of activity; a nil MaxFreezes uses the default and zero disables freezes.
+type StreakRules struct {
+	Actions    []string `bson:"actions,omitempty" json:"actions,omitempty"`
+	FreezeCost int      `bson:"freeze_cost,omitempty" json:"freezeCost,omitempty"`
+	MaxFreezes *int     `bson:"max_freezes,omitempty" json:"maxFreezes,omitempty"`
+}
+
+// Default streak freeze rules.
+const (
+	DefaultFreezeCost = 50
+	DefaultMaxFreezes = 2
+)
+
+// Qualifies reports whether activity of kind action keeps a streak going.
+func (s StreakRules) Qualifies(action string) bool {
+	return len(s.Actions) == 0 || slices.Contains(s.Actions, action)
+}
+
+// FreezePrice returns the coins one streak freeze costs.
+func (s StreakRules) FreezePrice() int {
+	if s.FreezeCost > 0 {
+		return s.FreezeCost
+	}
+	return DefaultFreezeCost
+}
+
+// FreezeLimit returns how many streak freezes a user may hold at once.
+func (s StreakRules) FreezeLimit() int {
+	if s.MaxFreezes != nil {
+		return *s.MaxFreezes
+	}
+	return DefaultMaxFreezes
+}
+
+// LevelCurve decides how much XP each level takes and what reaching one pays. Level L
+// starts at Base*(L-1)^Exponent lifetime XP, or at Thresholds[L-2] when Thresholds is set,
+// in which case the last threshold is the top level. Zero Base or Exponent use the
+// defaults; a nil Reward uses the default and zero pays nothing.
+type LevelCurve struct {
+	Base       int     `bson:"base,omitempty" json:"base,omitempty"`
+	Exponent   float64 `bson:"exponent,omitempty" json:"exponent,omitempty"`
+	Thresholds []int   `bson:"thresholds,omitempty" json:"thresholds,omitempty"`
+	Reward     *int    `bson:"reward,omitempty" json:"reward,omitempty"`
+}
+
+// Default level curve. MaxLevel caps the polynomial curve.
+const (
+	DefaultLevelBase     = 100
+	DefaultLevelExponent = 1.5
+	DefaultLevelReward   = 25
+	MaxLevel             = 1000
+)
+
+// Threshold returns the lifetime XP at which level starts, and false past the top level.
+func (c LevelCurve) Threshold(level int) (int, bool) {
+	switch {
+	case level <= 1:
+		return 0, true
+	case len(c.Thresholds) > 0:
+		if level-2 >= len(c.Thresholds) {
+			return 0, false
+		}
+		return c.Thresholds[level-2], true
+	case level > MaxLevel:
+		return 0, false
+	}
+	base, exp := c.Base, c.Exponent
+	if base <= 0 {
+		base = DefaultLevelBase
+	}
+	if exp <= 0 {
+		exp = DefaultLevelExponent
+	}
+	return int(math.Round(float64(base) * math.Pow(float64(level-1), exp))), true
+}
+
+// Level returns the level reached with xp lifetime XP. Levels start at 1.
+func (c LevelCurve) Level(xp int) int {
+	level := 1
+	for {
+		next, ok := c.Threshold(level + 1)
+		if !ok || xp < next {
+			return level
+		}
+		level++
+	}
+}
+
+// LevelReward returns the coins paid for reaching each level.
+func (c LevelCurve) LevelReward() int {
+	if c.Reward != nil {
+		return *c.Reward
+	}
+	return DefaultLevelReward
+}
+
+// Rotation sizes the sets of daily and weekly quests handed to each student. Zero uses
+// the default.
+type Rotation struct {
+	Daily  int `bson:"daily,omitempty" json:"daily,omitempty"`
+	Weekly int `bson:"weekly,omitempty" json:"weekly,omitempty"`
+}
+
+// Default rotation sizes.
+const (
+	DefaultDailyQuests  = 3
+	DefaultWeeklyQuests = 2
+)
+
+// DailyQuests returns how many daily quests each student gets.
+func (r Rotation) DailyQuests() int {
+	if r.Daily > 0 {
+		return r.Daily
+	}
+	return DefaultDailyQuests
+}
+
+// WeeklyQuests returns how many weekly quests each student gets.
+func (r Rotation) WeeklyQuests() int {
+	if r.Weekly > 0 {
+		return r.Weekly
+	}
+	return DefaultWeeklyQuests
+}
+
+// Location returns the campus timezone, or UTC when it is unset or unknown.
+func (g Gamification) Location() *time.Location {
+	if g.Timezone != "" {
+		if loc, err := time.LoadLocation(g.Timezone); err == nil {
+			return loc
+		}
+	}
+	return time.UTC
+}
+
+// Term is an academic term, from Start up to but excluding End.
+type Term struct {
+	Name  string    `bson:"name" json:"name"`
+	Start time.Time `bson:"start" json:"start"`
+	End   time.Time `bson:"end" json:"end"`
+}
+
+// TermAt returns the term in progress at t.
+func (g Gamification) TermAt(t time.Time) (Term, bool) {
+	for _, term := range g.Terms {
+		if !t.Before(term.Start) && t.Before(term.End) {
+			return term, true
+		}
+	}
+	return Term{}, false
+}
+
+// LastTermBefore returns the latest term that ended at or before t.
+func (g Gamification) LastTermBefore(t time.Time) (Term, bool) {
+	var last Term
+	found := false
+	for _, term := range g.Terms {
+		if !term.End.After(t) && (!found || term.End.After(last.End)) {
+			last, found = term, true
+		}
+	}
+	return last, found
+}
+
+// Coins applies the campus multiplier to a base reward.
+func (g Gamification) Coins(base int) int {
+	if g.CoinMultiplier <= 0 {
+		return base
+	}
+	return int(float64(base)*g.CoinMultiplier + 0.5)
+}
+
+// Public is the branding subset served to unauthenticated clients.
+type Public struct {
+	ID       string   `json:"id"`
+	Name     string   `json:"name"`
+	Branding Branding `json:"branding"`
+}
+
+func (t Tenant) Public() Public { return Public{ID: t.ID, Name: t.Name, Branding: t.Branding} }
+
+// defaults returns the configuration a new tenant starts with.
+func defaults(id string) Tenant {
+	now := time.Now().UTC()
+	return Tenant{
+		ID:           id,
+		Name:         id,
+		Hosts:        []string{},
+		Branding:     Branding{DisplayName: id},
+		AI:           AISettings{Enabled: true},
+		Gamification: Gamification{CoinMultiplier: 1, LeaderboardEnabled: true},
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+}
+
+// Registry loads tenants and resolves requests to them. Lookups are served from a cache
+// refreshed at most once a minute.
+type Registry struct {
+	Col     *mongo.Collection
+	Default string
+
+	mu       sync.RWMutex
+	byID     map[string]Tenant
+	byHost   map[string]string
+	loadedAt time.Time
+}
+
+// NewRegistry returns a registry over col; requests from unmapped hosts use defaultID.
+func NewRegistry(col *mongo.Collection, defaultID string) *Registry {
+	return &Registry{Col: col, Default: defaultID}
+}
+
+// Setup creates the default tenant if it does not exist yet.
+func (r *Registry) Setup(ctx context.Context) error {
+	_, err := r.Col.UpdateOne(ctx, bson.M{"_id": r.Default}, bson.M{"$setOnInsert": defaults(r.Default)}, options.Update().SetUpsert(true))
+	r.invalidate()
+	return err
+}
+
+// Backfill assigns documents created before multi-tenancy to the default tenant and
+// indexes tenant_id on each collection.
+func (r *Registry) Backfill(ctx context.Context, cols ...*mongo.Collection) error {
+	for _, col := range cols {
+		if _, err := col.Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.M{Field: 1}}); err != nil {
+			return err
+		}
+		res, err := col.UpdateMany(ctx, bson.M{Field: bson.M{"$exists": false}}, bson.M{"$set": bson.M{Field: r.Default}})
+		if err != nil {
+			return err
+		}
+		if res.ModifiedCount > 0 {
+			log.Printf("tenant: assigned %d %s documents to %s", res.ModifiedCount, col.Name(), r.Default)
+		}
+	}
+	return nil
+}
+
+func (r *Registry) invalidate() {
+	r.mu.Lock()
+	r.loadedAt = time.Time{}
+	r.mu.Unlock()
+}
+
+func (r *Registry) load(ctx context.Context) error {
+	r.mu.RLock()
+	fresh := time.Since(r.loadedAt) < cacheTTL
+	r.mu.RUnlock()
+	if fresh {
+		return nil
+	}
+	cursor, err := r.Col.Find(ctx, bson.M{})
+	if err != nil {
+		return err
+	}
+	var all []Tenant
+	if err := cursor.All(ctx, &all); err != nil {
+		return err
+	}
+	byID := make(map[string]Tenant, len(all))
+	byHost := map[string]string{}
+	for _, t := range all {
+		byID[t.ID] = t
+		for _, h := range t.Hosts {
+			byHost[normalizeHost(h)] = t.ID
+		}
+	}
+	r.mu.Lock()
+	r.byID, r.byHost, r.loadedAt = byID, byHost, time.Now()
+	r.mu.Unlock()
+	return nil
+}
+
+// Get returns the tenant with id.
+func (r *Registry) Get(ctx context.Context, id string) (Tenant, error) {
+	if err := r.load(ctx); err != nil {
+		return Tenant{}, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	t, ok := r.byID[id]
+	if !ok {
+		return Tenant{}, ErrUnknownTenant
+	}
+	return t, nil
+}
+
+// Current returns the configuration of the tenant in ctx, or the defaults when it cannot
+// be loaded, so a config outage degrades rules rather than failing requests.
+func (r *Registry) Current(ctx context.Context) Tenant {
+	id, ok := ID(ctx)
+	if !ok {
+		id = r.Default
+	}
+	t, err := r.Get(ctx, id)
+	if err != nil {
+		return defaults(id)
+	}
+	return t
+}
+
+// Resolve maps a request host to a tenant; pinned is false when the host is not mapped
+// and the default tenant was used.
+func (r *Registry) Resolve(ctx context.Context, host string) (id string, pinned bool, err error) {
+	if err := r.load(ctx); err != nil {
+		return "", false, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if id, ok := r.byHost[normalizeHost(host)]; ok {
+		return id, true, nil
+	}
+	return r.Default, false, nil
+}
+
+// IDs lists every tenant.
+func (r *Registry) IDs(ctx context.Context) ([]string, error) {
+	if err := r.load(ctx); err != nil {
+		return nil, err
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	ids := make([]string, 0, len(r.byID))
+	for id := range r.byID {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// ForEach calls fn once per tenant with ctx scoped to it, continuing past failures and
+// returning the first error.
+func (r *Registry) ForEach(ctx context.Context, fn func(ctx context.Context) error) error {
+	ids, err := r.IDs(ctx)
+	if err != nil {
+		return err
+	}
+	var first error
+	for _, id := range ids {
+		if err := fn(WithID(ctx, id)); err != nil {
+			log.Printf("tenant %s: %v", id, err)
+			if first == nil {
+				first = err
+			}
+		}
+	}
+	return first
+}
+
+// Update replaces the editable configuration of the tenant in ctx.
+func (r *Registry) Update(ctx context.Context, branding Branding, ai AISettings, gamification Gamification) (Tenant, error) {
+	id, ok := ID(ctx)
+	if !ok {
+		return Tenant{}, ErrNoTenant
+	}
+	var t Tenant
+	err := r.Col.FindOneAndUpdate(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{
+		"branding":     branding,
+		"ai":           ai,
+		"gamification": gamification,
+		"updated_at":   time.Now().UTC(),
+	}}, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&t)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return Tenant{}, ErrUnknownTenant
+	}
+	r.invalidate()
+	return t, err
+}
+
+// Middleware scopes each request to the tenant mapped to its host, falling back to the
+// default tenant. The auth middleware may then narrow an unpinned request to the tenant
+// named in the caller's token.
+func (r *Registry) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		id, pinned, err := r.Resolve(req.Context(), req.Host)
+		if err != nil {
+			log.Printf("tenant: resolve %s: %v", req.Host, err)
+			http.Error(w, "tenant lookup failed", http.StatusServiceUnavailable)
+			return
+		}
+		ctx := WithID(req.Context(), id)
+		if pinned {
+			ctx = withHost(req.Context(), id)
+		}
+		next.ServeHTTP(w, req.WithContext(ctx))
+	})
+}
+
+func normalizeHost(host string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.TrimSuffix(host, ".")
+}
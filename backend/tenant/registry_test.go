@@ -0,0 +1,66 @@
+package tenant
+
+import "testing"
+
+func TestLevelCurveThreshold(t *testing.T) {
+	custom := LevelCurve{Thresholds: []int{50, 150, 400}}
+	tests := []struct {
+		name   string
+		curve  LevelCurve
+		level  int
+		want   int
+		exists bool
+	}{
+		{"level 1 starts at zero", LevelCurve{}, 1, 0, true},
+		{"default level 2", LevelCurve{}, 2, 100, true},
+		{"default level 3 rounds", LevelCurve{}, 3, 283, true},
+		{"default top level", LevelCurve{}, MaxLevel, 3157535, true},
+		{"default past the top", LevelCurve{}, MaxLevel + 1, 0, false},
+		{"own base and exponent", LevelCurve{Base: 50, Exponent: 2}, 4, 450, true},
+		{"thresholds", custom, 3, 150, true},
+		{"last threshold", custom, 4, 400, true},
+		{"past the thresholds", custom, 5, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := tt.curve.Threshold(tt.level)
+			if got != tt.want || ok != tt.exists {
+				t.Errorf("Threshold(%d) = %d, %v; want %d, %v", tt.level, got, ok, tt.want, tt.exists)
+			}
+		})
+	}
+}
+
+func TestLevelCurveLevel(t *testing.T) {
+	custom := LevelCurve{Thresholds: []int{50, 150}}
+	tests := []struct {
+		curve LevelCurve
+		xp    int
+		want  int
+	}{
+		{LevelCurve{}, 0, 1},
+		{LevelCurve{}, 99, 1},
+		{LevelCurve{}, 100, 2},
+		{LevelCurve{}, 282, 2},
+		{LevelCurve{}, 283, 3},
+		{LevelCurve{}, 1 << 40, MaxLevel},
+		{custom, 49, 1},
+		{custom, 150, 3},
+		{custom, 1 << 40, 3},
+	}
+	for _, tt := range tests {
+		if got := tt.curve.Level(tt.xp); got != tt.want {
+			t.Errorf("%+v.Level(%d) = %d, want %d", tt.curve, tt.xp, got, tt.want)
+		}
+	}
+}
+
+func TestLevelReward(t *testing.T) {
+	zero := 0
+	if got := (LevelCurve{}).LevelReward(); got != DefaultLevelReward {
+		t.Errorf("default LevelReward = %d, want %d", got, DefaultLevelReward)
+	}
+	if got := (LevelCurve{Reward: &zero}).LevelReward(); got != 0 {
+		t.Errorf("LevelReward with rewards turned off = %d, want 0", got)
+	}
+}
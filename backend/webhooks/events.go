@@ -0,0 +1,24 @@
+package webhooks
+
+import (
+	"context"
+
+	"backend/events"
+)
+
+// Subscribe forwards the domain events that have a public webhook type to store.
+func Subscribe(bus *events.Bus, store *Store) {
+	bus.Subscribe("webhooks", func(ctx context.Context, env events.Envelope) error {
+		switch e := env.Event.(type) {
+		case events.QuestCompleted:
+			return store.Enqueue(ctx, EventQuestCompleted, QuestCompleted{UserID: e.UserID, QuestID: e.QuestID, Coins: e.Coins, CompletedAt: e.CompletedAt})
+		case events.VoteCast:
+			return store.Enqueue(ctx, EventPollVoted, PollVoted{UserID: e.UserID, PollID: e.PollID, OptionIndex: e.OptionIndex, VotedAt: e.VotedAt})
+		case events.ResearchPostCreated:
+			return store.Enqueue(ctx, EventResearchPostCreated, ResearchPostCreated{ID: e.PostID.Hex(), AuthorID: e.AuthorID, Title: e.Title, Category: e.Category, Tags: e.Tags, CreatedAt: e.CreatedAt})
+		case events.LevelUp:
+			return store.Enqueue(ctx, EventUserLeveledUp, UserLeveledUp{UserID: e.UserID, From: e.From, To: e.To, XP: e.XP, Coins: e.Coins, At: e.At})
+		}
+		return nil
+	}, events.TypeQuestCompleted, events.TypeVoteCast, events.TypeResearchPostCreated, events.TypeLevelUp)
+}
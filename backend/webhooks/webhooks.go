@@ -0,0 +1,307 @@
+// Package webhooks delivers platform events to admin-registered HTTP endpoints. Events are
+// queued as one delivery per matching subscription in the webhook_deliveries collection and
+// sent by a background Worker with HMAC-SHA256 signatures and exponential-backoff retries.
+// Deliveries that exhaust their attempts are kept with status "dead" until replayed.
+package webhooks
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/tenant"
+)
+
+// Event types a subscription can listen to. "*" subscribes to all of them.
+const (
+	EventQuestCompleted      = "quest.completed"
+	EventPollVoted           = "poll.voted"
+	EventResearchPostCreated = "research_post.created"
+	EventUserLeveledUp       = "user.leveled_up"
+	EventAll                 = "*"
+)
+
+// EventTypes lists every event that can be delivered.
+var EventTypes = []string{EventQuestCompleted, EventPollVoted, EventResearchPostCreated, EventUserLeveledUp}
+
+// Delivery statuses.
+const (
+	StatusPending   = "pending"
+	StatusSending   = "sending"
+	StatusDelivered = "delivered"
+	StatusDead      = "dead"
+)
+
+// Headers sent with every delivery.
+const (
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+var ErrNotFound = errors.New("webhook not found")
+
+// Subscription is an admin-managed receiver. Secret signs payloads and is only returned
+// to the admin when the subscription is created.
+type Subscription struct {
+	ID        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	URL       string             `bson:"url" json:"url"`
+	Events    []string           `bson:"events" json:"events"`
+	Secret    string             `bson:"secret" json:"-"`
+	Active    bool               `bson:"active" json:"active"`
+	CreatedBy int                `bson:"created_by" json:"createdBy"`
+	CreatedAt time.Time          `bson:"created_at" json:"createdAt"`
+}
+
+// Delivery is one queued POST of an event to a subscription.
+type Delivery struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	SubscriptionID primitive.ObjectID `bson:"subscription_id" json:"subscriptionId"`
+	URL            string             `bson:"url" json:"url"`
+	Event          string             `bson:"event" json:"event"`
+	Payload        string             `bson:"payload" json:"payload"`
+	Status         string             `bson:"status" json:"status"`
+	Attempts       int                `bson:"attempts" json:"attempts"`
+	NextAttemptAt  time.Time          `bson:"next_attempt_at" json:"nextAttemptAt"`
+	LastStatusCode int                `bson:"last_status_code,omitempty" json:"lastStatusCode,omitempty"`
+	LastError      string             `bson:"last_error,omitempty" json:"lastError,omitempty"`
+	CreatedAt      time.Time          `bson:"created_at" json:"createdAt"`
+	DeliveredAt    *time.Time         `bson:"delivered_at,omitempty" json:"deliveredAt,omitempty"`
+}
+
+// Envelope is the JSON body POSTed to receivers.
+type Envelope struct {
+	ID        string      `json:"id"`
+	Type      string      `json:"type"`
+	CreatedAt time.Time   `json:"createdAt"`
+	Data      interface{} `json:"data"`
+}
+
+// Payloads for each event type.
+type QuestCompleted struct {
+	UserID      int       `json:"userId"`
+	QuestID     int       `json:"questId"`
+	Coins       int       `json:"coins"`
+	CompletedAt time.Time `json:"completedAt"`
+}
+
+type PollVoted struct {
+	UserID      int       `json:"userId"`
+	PollID      int       `json:"pollId"`
+	OptionIndex int       `json:"optionIndex"`
+	VotedAt     time.Time `json:"votedAt"`
+}
+
+type ResearchPostCreated struct {
+	ID        string    `json:"id"`
+	AuthorID  int       `json:"authorId"`
+	Title     string    `json:"title"`
+	Category  string    `json:"category"`
+	Tags      []string  `json:"tags"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type UserLeveledUp struct {
+	UserID int       `json:"userId"`
+	From   int       `json:"from"`
+	To     int       `json:"to"`
+	XP     int       `json:"xp"`
+	Coins  int       `json:"coins"`
+	At     time.Time `json:"at"`
+}
+
+// Sign returns the X-Webhook-Signature value for body sent at timestamp:
+// "t=<unix seconds>,v1=<hex HMAC-SHA256 of "<t>.<body>">".
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	ts := strconv.FormatInt(timestamp.Unix(), 10)
+	return "t=" + ts + ",v1=" + computeMAC(secret, ts, body)
+}
+
+// Verify checks a signature header produced by Sign and rejects timestamps older than
+// tolerance (zero disables the age check). Receivers can use it as-is.
+func Verify(secret, header string, body []byte, tolerance time.Duration) error {
+	var ts, mac string
+	for _, part := range strings.Split(header, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		switch key {
+		case "t":
+			ts = value
+		case "v1":
+			mac = value
+		}
+	}
+	if ts == "" || mac == "" {
+		return errors.New("malformed signature header")
+	}
+	if !hmac.Equal([]byte(mac), []byte(computeMAC(secret, ts, body))) {
+		return errors.New("signature mismatch")
+	}
+	if tolerance > 0 {
+		unix, err := strconv.ParseInt(ts, 10, 64)
+		if err != nil {
+			return errors.New("malformed signature timestamp")
+		}
+		if age := time.Since(time.Unix(unix, 0)); age > tolerance || age < -tolerance {
+			return errors.New("signature timestamp outside tolerance")
+		}
+	}
+	return nil
+}
+
+func computeMAC(secret, ts string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// NewSecret returns a random signing secret.
+func NewSecret() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return "whsec_" + hex.EncodeToString(buf), nil
+}
+
+// Store reads and writes subscriptions and deliveries. Both collections are tenant-scoped:
+// a campus only sees its own subscriptions and only its events reach them.
+type Store struct {
+	Subscriptions *tenant.Collection
+	Deliveries    *tenant.Collection
+}
+
+// EnsureIndexes creates the index the worker polls on.
+func (s *Store) EnsureIndexes(ctx context.Context) error {
+	_, err := s.Deliveries.Raw().Indexes().CreateOne(ctx, mongo.IndexModel{Keys: bson.D{{Key: "status", Value: 1}, {Key: "next_attempt_at", Value: 1}}})
+	return err
+}
+
+// Enqueue queues event for every active subscription that wants it. A nil store or one
+// without collections is a no-op so handlers can always call it.
+func (s *Store) Enqueue(ctx context.Context, event string, data interface{}) error {
+	if s == nil || s.Subscriptions == nil || s.Deliveries == nil {
+		return nil
+	}
+	cursor, err := s.Subscriptions.Find(ctx, bson.M{"active": true, "events": bson.M{"$in": bson.A{event, EventAll}}})
+	if err != nil {
+		return err
+	}
+	var subs []Subscription
+	if err := cursor.All(ctx, &subs); err != nil {
+		return err
+	}
+	if len(subs) == 0 {
+		return nil
+	}
+	now := time.Now().UTC()
+	docs := make([]interface{}, 0, len(subs))
+	for _, sub := range subs {
+		id := primitive.NewObjectID()
+		body, err := json.Marshal(Envelope{ID: id.Hex(), Type: event, CreatedAt: now, Data: data})
+		if err != nil {
+			return fmt.Errorf("encode %s payload: %w", event, err)
+		}
+		docs = append(docs, Delivery{ID: id, SubscriptionID: sub.ID, URL: sub.URL, Event: event, Payload: string(body), Status: StatusPending, NextAttemptAt: now, CreatedAt: now})
+	}
+	_, err = s.Deliveries.InsertMany(ctx, docs)
+	return err
+}
+
+// Subscription loads one subscription by ID.
+func (s *Store) Subscription(ctx context.Context, id primitive.ObjectID) (Subscription, error) {
+	var sub Subscription
+	err := s.Subscriptions.FindOne(ctx, bson.M{"_id": id}).Decode(&sub)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return sub, ErrNotFound
+	}
+	return sub, err
+}
+
+// claimDue atomically moves the oldest due pending delivery to "sending" and returns it.
+// The lease lets a crashed worker's delivery be picked up again after lease has passed.
+func (s *Store) claimDue(ctx context.Context, lease time.Duration) (*Delivery, error) {
+	now := time.Now().UTC()
+	filter := bson.M{"$or": bson.A{
+		bson.M{"status": StatusPending, "next_attempt_at": bson.M{"$lte": now}},
+		bson.M{"status": StatusSending, "next_attempt_at": bson.M{"$lte": now}},
+	}}
+	update := bson.M{"$set": bson.M{"status": StatusSending, "next_attempt_at": now.Add(lease)}}
+	opts := options.FindOneAndUpdate().SetSort(bson.M{"next_attempt_at": 1}).SetReturnDocument(options.After)
+	var d Delivery
+	err := s.Deliveries.FindOneAndUpdate(ctx, filter, update, opts).Decode(&d)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+func (s *Store) markDelivered(ctx context.Context, d *Delivery, status int) error {
+	now := time.Now().UTC()
+	_, err := s.Deliveries.UpdateOne(ctx, bson.M{"_id": d.ID}, bson.M{
+		"$set":   bson.M{"status": StatusDelivered, "last_status_code": status, "delivered_at": now},
+		"$unset": bson.M{"last_error": ""},
+		"$inc":   bson.M{"attempts": 1},
+	})
+	return err
+}
+
+func (s *Store) markFailed(ctx context.Context, d *Delivery, status int, cause error, next time.Time, dead bool) error {
+	set := bson.M{"status": StatusPending, "last_status_code": status, "last_error": cause.Error(), "next_attempt_at": next}
+	if dead {
+		set["status"] = StatusDead
+	}
+	_, err := s.Deliveries.UpdateOne(ctx, bson.M{"_id": d.ID}, bson.M{"$set": set, "$inc": bson.M{"attempts": 1}})
+	return err
+}
+
+// Replay requeues a delivery (typically a dead one) for immediate sending with a fresh
+// attempt budget. The original payload and delivery ID are kept so receivers can dedupe.
+func (s *Store) Replay(ctx context.Context, id primitive.ObjectID) (Delivery, error) {
+	var d Delivery
+	update := bson.M{"$set": bson.M{"status": StatusPending, "attempts": 0, "next_attempt_at": time.Now().UTC()}, "$unset": bson.M{"delivered_at": ""}}
+	err := s.Deliveries.FindOneAndUpdate(ctx, bson.M{"_id": id, "status": bson.M{"$ne": StatusSending}}, update, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&d)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return d, ErrNotFound
+	}
+	return d, err
+}
+
+// SubscriptionList is the admin list response for subscriptions.
+type SubscriptionList struct {
+	Items []Subscription `json:"items"`
+}
+
+// CreatedSubscription is returned once when a subscription is created; it is the only
+// response that includes the signing secret.
+type CreatedSubscription struct {
+	ID        primitive.ObjectID `json:"id"`
+	URL       string             `json:"url"`
+	Events    []string           `json:"events"`
+	Active    bool               `json:"active"`
+	Secret    string             `json:"secret"`
+	CreatedAt time.Time          `json:"createdAt"`
+}
+
+// DeliveryList is a page of deliveries.
+type DeliveryList struct {
+	Items []Delivery `json:"items"`
+	Next  string     `json:"next,omitempty"`
+	Prev  string     `json:"prev,omitempty"`
+}
@@ -0,0 +1,99 @@
+package webhooks
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestSignVerify(t *testing.T) {
+	body := []byte(`{"type":"quest.completed"}`)
+	now := time.Now()
+	header := Sign("whsec_test", now, body)
+	if err := Verify("whsec_test", header, body, 5*time.Minute); err != nil {
+		t.Fatalf("Verify(Sign(...)) = %v", err)
+	}
+	tests := []struct {
+		name, secret, header string
+		body                 []byte
+		tolerance            time.Duration
+	}{
+		{"wrong secret", "whsec_other", header, body, 0},
+		{"edited body", "whsec_test", header, []byte(`{"type":"poll.voted"}`), 0},
+		{"malformed header", "whsec_test", "v1=abc", body, 0},
+		{"too old", "whsec_test", Sign("whsec_test", now.Add(-10*time.Minute), body), body, 5 * time.Minute},
+		{"from the future", "whsec_test", Sign("whsec_test", now.Add(10*time.Minute), body), body, 5 * time.Minute},
+	}
+	for _, tt := range tests {
+		if err := Verify(tt.secret, tt.header, tt.body, tt.tolerance); err == nil {
+			t.Errorf("%s: Verify accepted the signature", tt.name)
+		}
+	}
+	if err := Verify("whsec_test", Sign("whsec_test", now.Add(-time.Hour), body), body, 0); err != nil {
+		t.Errorf("zero tolerance: Verify = %v, want the age unchecked", err)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	base, max := 30*time.Second, 10*time.Minute
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{0, 30 * time.Second},
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{5, 8 * time.Minute},
+		{6, 10 * time.Minute},
+		{60, 10 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got := Backoff(tt.attempt, base, max); got != tt.want {
+			t.Errorf("Backoff(%d) = %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+}
+
+func TestSenderSignsDeliveries(t *testing.T) {
+	var got *http.Request
+	var gotBody []byte
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		gotBody, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	d := Delivery{ID: primitive.NewObjectID(), Event: EventQuestCompleted, Payload: `{"type":"quest.completed"}`}
+	status, err := Sender{}.Send(context.Background(), Subscription{URL: receiver.URL, Secret: "whsec_test"}, d)
+	if err != nil || status != http.StatusNoContent {
+		t.Fatalf("Send = %d, %v", status, err)
+	}
+	if string(gotBody) != d.Payload {
+		t.Errorf("body = %s, want %s", gotBody, d.Payload)
+	}
+	if got.Header.Get(HeaderEvent) != d.Event || got.Header.Get(HeaderDelivery) != d.ID.Hex() {
+		t.Errorf("headers = %v", got.Header)
+	}
+	if err := Verify("whsec_test", got.Header.Get(HeaderSignature), gotBody, time.Minute); err != nil {
+		t.Errorf("receiver could not verify the signature: %v", err)
+	}
+}
+
+func TestSenderReportsReceiverErrors(t *testing.T) {
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer receiver.Close()
+	status, err := Sender{}.Send(context.Background(), Subscription{URL: receiver.URL}, Delivery{})
+	if err == nil || status != http.StatusBadGateway || !strings.Contains(err.Error(), "502") {
+		t.Errorf("Send = %d, %v; want 502 and an error", status, err)
+	}
+}
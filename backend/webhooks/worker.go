@@ -0,0 +1,161 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"backend/tenant"
+)
+
+// Worker defaults; zero-valued Worker fields fall back to these.
+const (
+	DefaultMaxAttempts  = 8
+	DefaultBaseBackoff  = 30 * time.Second
+	DefaultMaxBackoff   = 6 * time.Hour
+	DefaultPollInterval = 5 * time.Second
+	DefaultTimeout      = 10 * time.Second
+)
+
+// Sender POSTs signed payloads. It has no storage dependency, so it can be exercised
+// directly against an httptest receiver.
+type Sender struct {
+	Client *http.Client
+	Now    func() time.Time
+}
+
+// Send delivers d to sub and returns the receiver's status code. Any non-2xx status is
+// reported as an error.
+func (s Sender) Send(ctx context.Context, sub Subscription, d Delivery) (int, error) {
+	client := s.Client
+	if client == nil {
+		client = &http.Client{Timeout: DefaultTimeout}
+	}
+	now := time.Now
+	if s.Now != nil {
+		now = s.Now
+	}
+	body := []byte(d.Payload)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "srm-campus-webhooks/1")
+	req.Header.Set(HeaderEvent, d.Event)
+	req.Header.Set(HeaderDelivery, d.ID.Hex())
+	req.Header.Set(HeaderSignature, Sign(sub.Secret, now(), body))
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("receiver responded %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// Backoff is the delay before retry number attempt (1-based): base doubled per attempt,
+// capped at max.
+func Backoff(attempt int, base, max time.Duration) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	delay := base
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= max {
+			return max
+		}
+	}
+	if delay > max {
+		return max
+	}
+	return delay
+}
+
+// Worker drains the delivery queue.
+type Worker struct {
+	Store        *Store
+	Sender       Sender
+	MaxAttempts  int
+	BaseBackoff  time.Duration
+	MaxBackoff   time.Duration
+	PollInterval time.Duration
+}
+
+// Run polls for due deliveries until ctx is cancelled.
+func (w *Worker) Run(ctx context.Context) {
+	interval := w.PollInterval
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := w.Drain(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("webhooks: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Drain sends every delivery that is currently due, across all tenants.
+func (w *Worker) Drain(ctx context.Context) error {
+	ctx = tenant.Unscoped(ctx)
+	for ctx.Err() == nil {
+		d, err := w.Store.claimDue(ctx, 2*DefaultTimeout)
+		if err != nil {
+			return fmt.Errorf("claim delivery: %w", err)
+		}
+		if d == nil {
+			return nil
+		}
+		if err := w.attempt(ctx, d); err != nil {
+			return fmt.Errorf("record delivery %s: %w", d.ID.Hex(), err)
+		}
+	}
+	return ctx.Err()
+}
+
+func (w *Worker) attempt(ctx context.Context, d *Delivery) error {
+	maxAttempts := w.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = DefaultMaxAttempts
+	}
+	base, max := w.BaseBackoff, w.MaxBackoff
+	if base <= 0 {
+		base = DefaultBaseBackoff
+	}
+	if max <= 0 {
+		max = DefaultMaxBackoff
+	}
+	sub, err := w.Store.Subscription(ctx, d.SubscriptionID)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return w.Store.markFailed(ctx, d, 0, fmt.Errorf("subscription deleted"), time.Now().UTC(), true)
+		}
+		return err
+	}
+	if !sub.Active {
+		return w.Store.markFailed(ctx, d, 0, fmt.Errorf("subscription disabled"), time.Now().UTC(), true)
+	}
+	status, sendErr := w.Sender.Send(ctx, sub, *d)
+	if sendErr == nil {
+		return w.Store.markDelivered(ctx, d, status)
+	}
+	attempts := d.Attempts + 1
+	next := time.Now().UTC().Add(Backoff(attempts, base, max))
+	return w.Store.markFailed(ctx, d, status, sendErr, next, attempts >= maxAttempts)
+}
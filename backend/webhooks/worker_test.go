@@ -0,0 +1,178 @@
+package webhooks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"backend/tenant"
+)
+
+// newTestStore returns a Store on a fresh database, skipping the test when MONGODB_TEST_URI
+// is not set.
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+	uri := os.Getenv("MONGODB_TEST_URI")
+	if uri == "" {
+		t.Skip("MONGODB_TEST_URI not set")
+	}
+	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI(uri))
+	if err != nil {
+		t.Fatal(err)
+	}
+	db := client.Database(fmt.Sprintf("test_webhooks_%d", time.Now().UnixNano()))
+	t.Cleanup(func() {
+		db.Drop(context.Background())
+		client.Disconnect(context.Background())
+	})
+	return &Store{Subscriptions: tenant.Wrap(db.Collection("webhook_subscriptions")), Deliveries: tenant.Wrap(db.Collection("webhook_deliveries"))}
+}
+
+// receiver answers with the status in fail while it is non-zero, 204 after, and counts hits.
+type receiver struct {
+	fail atomic.Int32
+	hits atomic.Int32
+}
+
+func (rc *receiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	rc.hits.Add(1)
+	if status := rc.fail.Load(); status != 0 {
+		w.WriteHeader(int(status))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// enqueue subscribes url to every event and queues one delivery for it.
+func enqueue(t *testing.T, store *Store, url string) Delivery {
+	t.Helper()
+	ctx := tenant.WithID(context.Background(), "campus")
+	if _, err := store.Subscriptions.InsertOne(ctx, Subscription{URL: url, Events: []string{EventAll}, Secret: "whsec_test", Active: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Enqueue(ctx, EventQuestCompleted, QuestCompleted{UserID: 1, QuestID: 2}); err != nil {
+		t.Fatal(err)
+	}
+	var d Delivery
+	if err := store.Deliveries.FindOne(ctx, bson.M{}).Decode(&d); err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func load(t *testing.T, store *Store, d Delivery) Delivery {
+	t.Helper()
+	var got Delivery
+	if err := store.Deliveries.FindOne(tenant.Unscoped(context.Background()), bson.M{"_id": d.ID}).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	return got
+}
+
+func TestWorkerDelivers(t *testing.T) {
+	store := newTestStore(t)
+	rc := &receiver{}
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+	d := enqueue(t, store, srv.URL)
+
+	if err := (&Worker{Store: store}).Drain(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	got := load(t, store, d)
+	if got.Status != StatusDelivered || got.Attempts != 1 || got.LastStatusCode != http.StatusNoContent || got.DeliveredAt == nil {
+		t.Errorf("delivery = %+v, want delivered on the first attempt", got)
+	}
+}
+
+func TestWorkerBacksOff(t *testing.T) {
+	store := newTestStore(t)
+	rc := &receiver{}
+	rc.fail.Store(http.StatusServiceUnavailable)
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+	d := enqueue(t, store, srv.URL)
+
+	before := time.Now()
+	if err := (&Worker{Store: store, BaseBackoff: time.Hour, MaxBackoff: 4 * time.Hour}).Drain(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	got := load(t, store, d)
+	if got.Status != StatusPending || got.Attempts != 1 || got.LastStatusCode != http.StatusServiceUnavailable || got.LastError == "" {
+		t.Errorf("delivery = %+v, want pending after one failed attempt", got)
+	}
+	if wait := got.NextAttemptAt.Sub(before); wait < time.Hour || wait > time.Hour+time.Minute {
+		t.Errorf("next attempt in %v, want one base backoff", wait)
+	}
+	if rc.hits.Load() != 1 {
+		t.Errorf("receiver hit %d times, want 1 before the backoff passes", rc.hits.Load())
+	}
+}
+
+func TestWorkerDeadLettersAndReplays(t *testing.T) {
+	store := newTestStore(t)
+	rc := &receiver{}
+	rc.fail.Store(http.StatusInternalServerError)
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+	d := enqueue(t, store, srv.URL)
+
+	// A nanosecond backoff makes every retry due at once, so one Drain runs them all.
+	w := &Worker{Store: store, MaxAttempts: 3, BaseBackoff: time.Nanosecond, MaxBackoff: time.Nanosecond}
+	if err := w.Drain(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	got := load(t, store, d)
+	if got.Status != StatusDead || got.Attempts != 3 || rc.hits.Load() != 3 {
+		t.Fatalf("delivery = %+v after %d hits, want dead after 3 attempts", got, rc.hits.Load())
+	}
+	if err := w.Drain(context.Background()); err != nil || rc.hits.Load() != 3 {
+		t.Fatalf("dead delivery was retried (%d hits, err %v)", rc.hits.Load(), err)
+	}
+
+	rc.fail.Store(0)
+	replayed, err := store.Replay(tenant.WithID(context.Background(), "campus"), d.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if replayed.Status != StatusPending || replayed.Attempts != 0 {
+		t.Errorf("replayed = %+v, want pending with a fresh attempt budget", replayed)
+	}
+	if err := w.Drain(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	got = load(t, store, d)
+	if got.Status != StatusDelivered || got.Payload != d.Payload {
+		t.Errorf("delivery = %+v, want the original payload delivered", got)
+	}
+	if _, err := store.Replay(tenant.WithID(context.Background(), "other"), d.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("replay from another campus: err = %v, want ErrNotFound", err)
+	}
+}
+
+func TestWorkerDropsDeliveriesOfDeletedSubscriptions(t *testing.T) {
+	store := newTestStore(t)
+	rc := &receiver{}
+	srv := httptest.NewServer(rc)
+	defer srv.Close()
+	d := enqueue(t, store, srv.URL)
+	if _, err := store.Subscriptions.DeleteMany(tenant.Unscoped(context.Background()), bson.M{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := (&Worker{Store: store}).Drain(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := load(t, store, d); got.Status != StatusDead || rc.hits.Load() != 0 {
+		t.Errorf("delivery = %+v after %d hits, want dead without sending", got, rc.hits.Load())
+	}
+}
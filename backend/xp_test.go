@@ -0,0 +1,58 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+
+	"backend/handlers/common"
+	"backend/models"
+	"backend/tenant"
+)
+
+func TestXPBackfillRunsOnce(t *testing.T) {
+	h := newTestServer(t)
+	ctx := tenant.WithID(context.Background(), testTenantA)
+	// A user from before XP existed: two completed quests and no xp field.
+	if _, err := common.UsersCol.InsertOne(ctx, models.User{UserID: 1, Name: "Student", Role: common.RoleStudent}); err != nil {
+		t.Fatal(err)
+	}
+	at := time.Date(2026, time.March, 2, 9, 0, 0, 0, time.UTC)
+	for _, q := range []models.Quest{{QuestID: 1, Title: "Easy", Difficulty: "easy"}, {QuestID: 2, Title: "Hard", Difficulty: "hard"}} {
+		if _, err := common.QuestsCol.InsertOne(ctx, q); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := common.UserQuestsCol.InsertOne(ctx, bson.M{"user_id": 1, "quest_id": q.QuestID, "completed": true, "completed_at": at}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	token := testToken(t, testTenantA, 1, common.RoleStudent)
+
+	const requests = 8
+	var wg sync.WaitGroup
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if w := serve(h, http.MethodGet, "/api/v2/me/progress", "", token); w.Code != http.StatusOK {
+				t.Errorf("status = %d: %s", w.Code, w.Body)
+			}
+		}()
+	}
+	wg.Wait()
+
+	var user models.User
+	if err := common.UsersCol.FindOne(ctx, bson.M{"user_id": 1}).Decode(&user); err != nil {
+		t.Fatal(err)
+	}
+	if user.XP != 60 {
+		t.Errorf("xp = %d, want 60", user.XP)
+	}
+	if n, _ := common.XPLedgerCol.CountDocuments(ctx, bson.M{"user_id": 1}); n != 2 {
+		t.Errorf("xp ledger entries = %d, want one per completed quest", n)
+	}
+}